@@ -0,0 +1,404 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"phenix/store"
+	"phenix/types"
+	"phenix/types/version"
+	pversion "phenix/version"
+)
+
+const (
+	manifestFile = "manifest.json"
+	configsDir   = "configs"
+	archiveMode  = 0o644
+)
+
+// Manifest describes the contents of a backup archive. The stored versions
+// allow archives to be restored by newer versions of phenix, upgrading configs
+// as needed.
+type Manifest struct {
+	PhenixVersion  string            `json:"phenixVersion"`
+	Created        string            `json:"created"`
+	StoredVersions map[string]string `json:"storedVersions"`
+	Components     []store.Component `json:"components"`
+	Configs        []ManifestEntry   `json:"configs"`
+}
+
+// ManifestEntry describes a single config included in a backup archive.
+type ManifestEntry struct {
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	APIVersion string `json:"apiVersion"`
+	Path       string `json:"path"`
+}
+
+// RestoreAction describes what was (or would be, for dry runs) done with a
+// config during a restore.
+type RestoreAction string
+
+const (
+	RestoreActionCreate    RestoreAction = "create"
+	RestoreActionOverwrite RestoreAction = "overwrite"
+	RestoreActionRename    RestoreAction = "rename"
+	RestoreActionSkip      RestoreAction = "skip"
+)
+
+// RestoreResult describes the outcome of restoring a single config.
+type RestoreResult struct {
+	Kind   string        `json:"kind"`
+	Name   string        `json:"name"`
+	Action RestoreAction `json:"action"`
+
+	// NewName is the name the config was restored as when renamed.
+	NewName string `json:"newName,omitempty"`
+
+	// UpgradedFrom is the API version the config was upgraded from, if it was
+	// upgraded to the currently stored version during the restore.
+	UpgradedFrom string `json:"upgradedFrom,omitempty"`
+}
+
+// Backup writes a gzipped tar archive of every config in the store (including
+// experiment status, settings and config revisions) to the given writer. It
+// returns the manifest written to the archive.
+func Backup(w io.Writer) (manifest *Manifest, err error) {
+	manifest = &Manifest{ //nolint:exhaustruct // partial initialization
+		PhenixVersion:  pversion.Tag,
+		Created:        time.Now().Format(time.RFC3339),
+		StoredVersions: version.StoredVersion,
+	}
+
+	for _, component := range []store.Component{store.ComponentStore, store.ComponentConfigs} {
+		if store.IsInitialized(component) {
+			manifest.Components = append(manifest.Components, component)
+		}
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	defer func() {
+		err = errors.Join(err, tw.Close(), gw.Close())
+	}()
+
+	for _, kind := range store.Kinds() {
+		configs, err := store.List(kind)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s configs: %w", kind, err)
+		}
+
+		for _, c := range configs {
+			entry := ManifestEntry{
+				Kind:       c.Kind,
+				Name:       c.Metadata.Name,
+				APIVersion: c.Version,
+				Path:       configPath(c.Kind, c.Metadata.Name),
+			}
+
+			body, err := json.Marshal(c)
+			if err != nil {
+				return nil, fmt.Errorf("marshaling config %s: %w", c.FullName(), err)
+			}
+
+			if err := writeFile(tw, entry.Path, body); err != nil {
+				return nil, err
+			}
+
+			manifest.Configs = append(manifest.Configs, entry)
+		}
+	}
+
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshaling manifest: %w", err)
+	}
+
+	if err := writeFile(tw, manifestFile, body); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Restore restores the configs in the gzipped tar archive read from the given
+// reader to the store. Configs stored at an older API version than the current
+// stored version for their kind are upgraded as they're restored. It returns
+// the outcome for each config in the archive that was considered.
+func Restore(r io.Reader, opts ...RestoreOption) ([]RestoreResult, error) {
+	o := newRestoreOptions(opts...)
+
+	kinds, err := normalizeKinds(o.kinds)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, files, err := readArchive(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []RestoreResult
+
+	for _, entry := range manifest.Configs {
+		if len(kinds) > 0 && !slices.Contains(kinds, entry.Kind) {
+			continue
+		}
+
+		body, ok := files[entry.Path]
+		if !ok {
+			return results, fmt.Errorf("config %s/%s missing from archive", entry.Kind, entry.Name)
+		}
+
+		var c store.Config
+
+		if err := json.Unmarshal(body, &c); err != nil {
+			return results, fmt.Errorf("unmarshaling config %s/%s: %w", entry.Kind, entry.Name, err)
+		}
+
+		result, err := restoreConfig(&c, o)
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, result)
+	}
+
+	if o.dryRun || len(kinds) > 0 {
+		return results, nil
+	}
+
+	for _, component := range manifest.Components {
+		if err := store.InitializeComponent(component); err != nil {
+			return results, fmt.Errorf("initializing component %s: %w", component, err)
+		}
+	}
+
+	return results, nil
+}
+
+func restoreConfig(c *store.Config, o restoreOptions) (RestoreResult, error) {
+	result := RestoreResult{Kind: c.Kind, Name: c.Metadata.Name} //nolint:exhaustruct // partial initialization
+
+	from, err := upgrade(c)
+	if err != nil {
+		return result, err
+	}
+
+	result.UpgradedFrom = from
+
+	// Restored configs start a new resource version history in the store.
+	c.Metadata.ResourceVersion = 0
+
+	if !exists(c.Kind, c.Metadata.Name) {
+		result.Action = RestoreActionCreate
+
+		if o.dryRun {
+			return result, nil
+		}
+
+		if err := store.Create(c); err != nil {
+			return result, fmt.Errorf("creating config %s: %w", c.FullName(), err)
+		}
+
+		return result, nil
+	}
+
+	switch o.conflict {
+	case ConflictOverwrite:
+		result.Action = RestoreActionOverwrite
+
+		if o.dryRun {
+			return result, nil
+		}
+
+		if err := store.Update(c); err != nil {
+			return result, fmt.Errorf("overwriting config %s: %w", c.FullName(), err)
+		}
+	case ConflictRename:
+		if _, ok := version.StoredVersion[c.Kind]; !ok {
+			result.Action = RestoreActionSkip
+
+			return result, nil
+		}
+
+		result.Action = RestoreActionRename
+		result.NewName = availableName(c.Kind, c.Metadata.Name)
+
+		if o.dryRun {
+			return result, nil
+		}
+
+		rename(c, result.NewName)
+
+		if err := store.Create(c); err != nil {
+			return result, fmt.Errorf("creating renamed config %s: %w", c.FullName(), err)
+		}
+	default:
+		result.Action = RestoreActionSkip
+	}
+
+	return result, nil
+}
+
+// upgrade upgrades the given config to the current stored version for its
+// kind if an upgrader exists for it. It returns the API version the config was
+// upgraded from, or an empty string if it wasn't upgraded.
+func upgrade(c *store.Config) (string, error) {
+	latest, ok := version.StoredVersion[c.Kind]
+	if !ok || c.APIVersion() == latest {
+		return "", nil
+	}
+
+	upgrader := types.GetUpgrader(c.Kind + "/" + latest)
+	if upgrader == nil {
+		return "", nil
+	}
+
+	from := c.APIVersion()
+
+	spec, err := upgrader.Upgrade(from, c.Spec, c.Metadata)
+	if err != nil {
+		return "", fmt.Errorf("upgrading config %s from %s to %s: %w", c.FullName(), from, latest, err)
+	}
+
+	upgraded, err := types.NewConfigFromSpec(c.Metadata.Name, spec)
+	if err != nil {
+		return "", fmt.Errorf("creating upgraded config %s: %w", c.FullName(), err)
+	}
+
+	c.Version = upgraded.Version
+	c.Spec = upgraded.Spec
+
+	return from, nil
+}
+
+// rename renames the given config, including the experiment name in the spec of
+// experiments.
+func rename(c *store.Config, name string) {
+	c.Metadata.Name = name
+
+	if c.Kind == "Experiment" && c.Spec != nil {
+		if _, ok := c.Spec["experimentName"]; ok {
+			c.Spec["experimentName"] = name
+		}
+	}
+}
+
+func availableName(kind, name string) string {
+	candidate := name + "-restored"
+
+	for i := 2; exists(kind, candidate); i++ {
+		candidate = fmt.Sprintf("%s-restored-%d", name, i)
+	}
+
+	return candidate
+}
+
+func exists(kind, name string) bool {
+	c := &store.Config{Kind: kind, Metadata: store.ConfigMetadata{Name: name}} //nolint:exhaustruct // partial initialization
+
+	return store.Get(c) == nil
+}
+
+// normalizeKinds converts the given kinds to the case used by the store,
+// returning an error for unknown kinds.
+func normalizeKinds(kinds []string) ([]string, error) {
+	var normalized []string
+
+	for _, kind := range kinds {
+		idx := slices.IndexFunc(store.Kinds(), func(k string) bool { return strings.EqualFold(k, kind) })
+		if idx == -1 {
+			return nil, fmt.Errorf("unknown config kind '%s'", kind)
+		}
+
+		normalized = append(normalized, store.Kinds()[idx])
+	}
+
+	return normalized, nil
+}
+
+func readArchive(r io.Reader) (*Manifest, map[string][]byte, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading gzipped archive: %w", err)
+	}
+
+	defer func() { _ = gr.Close() }()
+
+	var (
+		tr    = tar.NewReader(gr)
+		files = make(map[string][]byte)
+	)
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		body, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading %s from archive: %w", header.Name, err)
+		}
+
+		files[header.Name] = body
+	}
+
+	body, ok := files[manifestFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("archive is missing %s", manifestFile)
+	}
+
+	var manifest Manifest
+
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, nil, fmt.Errorf("unmarshaling manifest: %w", err)
+	}
+
+	return &manifest, files, nil
+}
+
+func writeFile(tw *tar.Writer, name string, body []byte) error {
+	header := &tar.Header{ //nolint:exhaustruct // partial initialization
+		Name:    name,
+		Mode:    archiveMode,
+		Size:    int64(len(body)),
+		ModTime: time.Now(),
+	}
+
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("writing archive header for %s: %w", name, err)
+	}
+
+	if _, err := tw.Write(body); err != nil {
+		return fmt.Errorf("writing %s to archive: %w", name, err)
+	}
+
+	return nil
+}
+
+// configPath returns the path of the given config in an archive. Names are
+// escaped since some internal kinds (like config revisions) include slashes in
+// their names.
+func configPath(kind, name string) string {
+	return path.Join(configsDir, kind, url.PathEscape(name)+".json")
+}
@@ -0,0 +1,141 @@
+package backup_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"phenix/api/backup"
+	"phenix/store"
+)
+
+func newTestStore(t *testing.T, endpoint string) {
+	t.Helper()
+
+	s, err := store.NewFromEndpoint(endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { _ = s.Close() })
+
+	store.DefaultStore = s //nolint:reassign // testing
+}
+
+func newTestConfig(kind, name, version string) *store.Config {
+	return &store.Config{ //nolint:exhaustruct // partial initialization
+		Version:  "phenix.sandia.gov/" + version,
+		Kind:     kind,
+		Metadata: store.ConfigMetadata{Name: name}, //nolint:exhaustruct // partial initialization
+		Spec: map[string]any{
+			"nodes": []any{map[string]any{"type": "VirtualMachine", "general": map[string]any{"hostname": "host-00"}}},
+		},
+	}
+}
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+
+	newTestStore(t, "bolt://"+filepath.Join(dir, "phenix.bdb"))
+
+	exp := newTestConfig("Experiment", "foo", "v1")
+	exp.Spec = map[string]any{"experimentName": "foo"}
+	exp.Status = map[string]any{"startTime": "now"}
+
+	for _, c := range []*store.Config{newTestConfig("Topology", "foo", "v1"), newTestConfig("Topology", "old", "v0"), exp} {
+		if err := store.Create(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var archive bytes.Buffer
+
+	manifest, err := backup.Backup(&archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifest.Configs) != 3 {
+		t.Fatalf("expected 3 configs in manifest, got %d", len(manifest.Configs))
+	}
+
+	// Restore onto a different store backend.
+	newTestStore(t, "sqlite://"+filepath.Join(dir, "phenix.db"))
+
+	if err := store.Create(newTestConfig("Topology", "foo", "v1")); err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := backup.Restore(bytes.NewReader(archive.Bytes()), backup.RestoreWithDryRun(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if configs, _ := store.List("Topology", "Experiment"); len(configs) != 1 {
+		t.Fatalf("expected dry run to not modify store, got %d configs", len(configs))
+	}
+
+	actions := make(map[string]backup.RestoreAction)
+
+	for _, r := range results {
+		actions[r.Kind+"/"+r.Name] = r.Action
+	}
+
+	if actions["Topology/foo"] != backup.RestoreActionSkip || actions["Experiment/foo"] != backup.RestoreActionCreate {
+		t.Fatalf("unexpected dry run results %+v", results)
+	}
+
+	results, err = backup.Restore(
+		bytes.NewReader(archive.Bytes()),
+		backup.RestoreWithKinds("topology"),
+		backup.RestoreWithConflictPolicy(backup.ConflictRename),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected only topologies to be restored, got %+v", results)
+	}
+
+	for _, r := range results {
+		switch r.Name {
+		case "foo":
+			if r.Action != backup.RestoreActionRename || r.NewName != "foo-restored" {
+				t.Fatalf("expected topology foo to be renamed, got %+v", r)
+			}
+		case "old":
+			if r.Action != backup.RestoreActionCreate || r.UpgradedFrom != "v0" {
+				t.Fatalf("expected topology old to be upgraded, got %+v", r)
+			}
+		}
+	}
+
+	old := newTestConfig("Topology", "old", "")
+
+	if err := store.Get(old); err != nil {
+		t.Fatal(err)
+	}
+
+	if old.APIVersion() != "v1" {
+		t.Fatalf("expected restored topology to be upgraded to v1, got %s", old.APIVersion())
+	}
+
+	if _, err := backup.Restore(bytes.NewReader(archive.Bytes()), backup.RestoreWithConflictPolicy(backup.ConflictOverwrite)); err != nil {
+		t.Fatal(err)
+	}
+
+	restored := newTestConfig("Experiment", "foo", "")
+
+	if err := store.Get(restored); err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.Status["startTime"] != "now" {
+		t.Fatalf("expected experiment status to be restored, got %v", restored.Status)
+	}
+
+	if _, err := backup.Restore(bytes.NewReader(archive.Bytes()), backup.RestoreWithKinds("bogus")); err == nil {
+		t.Fatal("expected error restoring unknown kind")
+	}
+}
@@ -0,0 +1,3 @@
+// Package backup is an implementation of the phenix store backup and restore
+// API.
+package backup
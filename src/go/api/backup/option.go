@@ -0,0 +1,71 @@
+package backup
+
+import "fmt"
+
+// ConflictPolicy determines what happens when a config being restored already
+// exists in the store.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip leaves the existing config in place.
+	ConflictSkip ConflictPolicy = "skip"
+
+	// ConflictOverwrite replaces the existing config with the restored one.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+
+	// ConflictRename restores the config under a new, unused name. Internal
+	// kinds (config revisions and settings) are skipped instead since renaming
+	// them isn't meaningful.
+	ConflictRename ConflictPolicy = "rename"
+)
+
+// ParseConflictPolicy converts the given string into a conflict policy.
+func ParseConflictPolicy(p string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(p); policy {
+	case ConflictSkip, ConflictOverwrite, ConflictRename:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown conflict policy '%s' (must be one of skip, overwrite, rename)", p)
+	}
+}
+
+type RestoreOption func(*restoreOptions)
+
+type restoreOptions struct {
+	dryRun   bool
+	kinds    []string
+	conflict ConflictPolicy
+}
+
+func newRestoreOptions(opts ...RestoreOption) restoreOptions {
+	o := restoreOptions{conflict: ConflictSkip} //nolint:exhaustruct // partial initialization
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// RestoreWithDryRun reports what would be restored without writing anything to
+// the store.
+func RestoreWithDryRun(d bool) RestoreOption {
+	return func(o *restoreOptions) {
+		o.dryRun = d
+	}
+}
+
+// RestoreWithKinds limits the restore to configs of the given kinds.
+func RestoreWithKinds(k ...string) RestoreOption {
+	return func(o *restoreOptions) {
+		o.kinds = append(o.kinds, k...)
+	}
+}
+
+// RestoreWithConflictPolicy sets the policy used for configs that already exist
+// in the store. Configs are skipped by default.
+func RestoreWithConflictPolicy(p ConflictPolicy) RestoreOption {
+	return func(o *restoreOptions) {
+		o.conflict = p
+	}
+}
@@ -0,0 +1,393 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"phenix/api/experiment"
+	"phenix/api/vm"
+	"phenix/util/common"
+	"phenix/util/mm"
+	"phenix/util/plog"
+)
+
+const (
+	vmStateRunning = "RUNNING"
+	vmStatePaused  = "PAUSED"
+
+	percentDivisor = 100.0
+)
+
+var (
+	// ErrCheckpointExists is returned when creating a checkpoint with the same
+	// name as an existing checkpoint for the experiment.
+	ErrCheckpointExists = errors.New("checkpoint already exists")
+
+	// ErrCheckpointNotFound is returned when a checkpoint doesn't exist for the
+	// experiment.
+	ErrCheckpointNotFound = errors.New("checkpoint not found")
+
+	// ErrVLANMismatch is returned when restoring a checkpoint onto an experiment
+	// whose VLAN mappings have changed since the checkpoint was taken.
+	ErrVLANMismatch = errors.New("experiment VLAN mappings have changed since checkpoint")
+
+	nameRegex = regexp.MustCompile(`^[\w.-]+$`)
+)
+
+// Manifest describes an experiment checkpoint.
+type Manifest struct {
+	Name       string    `json:"name"`
+	Experiment string    `json:"experiment"`
+	Created    time.Time `json:"created"`
+
+	VMs       []VMCheckpoint `json:"vms"`
+	Skipped   []string       `json:"skipped,omitempty"`
+	VLANs     map[string]int `json:"vlans"`
+	AppStatus map[string]any `json:"appStatus"`
+}
+
+// VMCheckpoint describes the snapshot taken of a single VM as part of an
+// experiment checkpoint.
+type VMCheckpoint struct {
+	Name     string `json:"name"`
+	Host     string `json:"host"`
+	Snapshot string `json:"snapshot"`
+
+	// State is the state the VM was in before the checkpoint was taken
+	// (RUNNING or PAUSED), and is the state the VM is returned to on restore.
+	State string `json:"state"`
+}
+
+// Create takes a checkpoint of the running experiment with the given name. All
+// the experiment's VMs are paused, disk and memory snapshots are taken of each
+// one, and the experiment's VLAN mappings and app status are recorded in the
+// checkpoint manifest. VMs that were running before the checkpoint was taken
+// are resumed once it completes (or fails). Containers can't be snapshotted, so
+// they're left running and recorded in the manifest as skipped.
+//
+//nolint:funlen // complex logic
+func Create(expName, name string, opts ...Option) (*Manifest, error) {
+	o := newOptions(opts...)
+
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	exp, err := experiment.Get(expName)
+	if err != nil {
+		return nil, fmt.Errorf("getting experiment %s: %w", expName, err)
+	}
+
+	if !exp.Running() {
+		return nil, fmt.Errorf("experiment %s is not running", expName)
+	}
+
+	if _, err := os.Stat(manifestPath(expName, name)); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCheckpointExists, name)
+	}
+
+	manifest := &Manifest{
+		Name:       name,
+		Experiment: expName,
+		Created:    time.Now().UTC(),
+		VMs:        nil,
+		VLANs:      exp.Status.VLANs(),
+		AppStatus:  exp.Status.AppStatus(),
+	}
+
+	manifest.VMs, manifest.Skipped = checkpointVMs(mm.GetVMInfo(mm.NS(expName)), name)
+
+	if len(manifest.VMs) == 0 {
+		return nil, fmt.Errorf("no running KVM VMs in experiment %s", expName)
+	}
+
+	// Pause every VM before snapshotting any of them so the snapshots are all
+	// taken from the same point in time.
+	var paused []string
+
+	defer func() {
+		for _, vmName := range paused {
+			o.progress(Progress{Stage: StageResuming, VM: vmName, Percent: 1})
+
+			if err := vm.Resume(expName, vmName); err != nil {
+				plog.Error(plog.TypeSystem, "resuming VM after checkpoint", "exp", expName, "vm", vmName, "err", err)
+			}
+		}
+	}()
+
+	for _, v := range manifest.VMs {
+		if v.State != vmStateRunning {
+			continue
+		}
+
+		o.progress(Progress{Stage: StagePausing, VM: v.Name, Percent: 0})
+
+		if err := vm.Pause(expName, v.Name); err != nil {
+			return nil, fmt.Errorf("pausing VM %s: %w", v.Name, err)
+		}
+
+		paused = append(paused, v.Name)
+	}
+
+	total := float64(len(manifest.VMs))
+
+	for i, v := range manifest.VMs {
+		cb := func(s string) {
+			percent, err := strconv.ParseFloat(s, 64)
+			if err != nil { // completed
+				percent = percentDivisor
+			}
+
+			o.progress(Progress{Stage: StageSnapshotting, VM: v.Name, Percent: (float64(i) + percent/percentDivisor) / total})
+		}
+
+		out := "checkpoint-" + name
+
+		if err := vm.Snapshot(expName, v.Name, out, cb, vm.LeavePaused(true)); err != nil {
+			return nil, fmt.Errorf("snapshotting VM %s: %w", v.Name, err)
+		}
+	}
+
+	o.progress(Progress{Stage: StageRecording, VM: "", Percent: 1})
+
+	if err := writeManifest(manifest); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Restore returns the running experiment with the given name to the state it
+// was in when the checkpoint with the given name was taken. Every VM in the
+// checkpoint is restored from its disk and memory snapshot while paused, and
+// once all of them have been restored the VMs that were running when the
+// checkpoint was taken are started again. Containers skipped when the
+// checkpoint was taken are left as they are. The app status recorded in the
+// checkpoint replaces the experiment's current app status.
+func Restore(expName, name string, opts ...Option) (*Manifest, error) {
+	o := newOptions(opts...)
+
+	manifest, err := Get(expName, name)
+	if err != nil {
+		return nil, err
+	}
+
+	exp, err := experiment.Get(expName)
+	if err != nil {
+		return nil, fmt.Errorf("getting experiment %s: %w", expName, err)
+	}
+
+	if !exp.Running() {
+		return nil, fmt.Errorf("experiment %s is not running", expName)
+	}
+
+	// VM snapshots include network configuration, so they can only be restored
+	// onto the same VLANs they were taken on.
+	if current := exp.Status.VLANs(); !maps.Equal(current, manifest.VLANs) {
+		var changed []string
+
+		for _, alias := range slices.Sorted(maps.Keys(manifest.VLANs)) {
+			if current[alias] != manifest.VLANs[alias] {
+				changed = append(changed, fmt.Sprintf("%s (%d -> %d)", alias, manifest.VLANs[alias], current[alias]))
+			}
+		}
+
+		for alias := range current {
+			if _, ok := manifest.VLANs[alias]; !ok {
+				changed = append(changed, alias+" (added)")
+			}
+		}
+
+		return nil, fmt.Errorf("%w: %s", ErrVLANMismatch, strings.Join(changed, ", "))
+	}
+
+	total := float64(len(manifest.VMs))
+
+	for i, v := range manifest.VMs {
+		o.progress(Progress{Stage: StageRestoring, VM: v.Name, Percent: float64(i) / total})
+
+		if err := vm.Restore(expName, v.Name, v.Snapshot, vm.LeavePaused(true)); err != nil {
+			return nil, fmt.Errorf("restoring VM %s: %w", v.Name, err)
+		}
+	}
+
+	for _, v := range manifest.VMs {
+		if v.State != vmStateRunning {
+			continue
+		}
+
+		o.progress(Progress{Stage: StageResuming, VM: v.Name, Percent: 1})
+
+		if err := vm.Resume(expName, v.Name); err != nil {
+			return nil, fmt.Errorf("resuming VM %s: %w", v.Name, err)
+		}
+	}
+
+	exp.Status.ResetAppStatus()
+
+	for app, status := range manifest.AppStatus {
+		exp.Status.SetAppStatus(app, status)
+	}
+
+	if err := experiment.Save(experiment.SaveWithName(expName), experiment.SaveWithStatus(exp.Status)); err != nil {
+		return nil, fmt.Errorf("saving experiment app status: %w", err)
+	}
+
+	o.progress(Progress{Stage: StageCompleted, VM: "", Percent: 1})
+
+	return manifest, nil
+}
+
+// List returns the manifests of all the checkpoints taken of the experiment
+// with the given name, ordered by when they were created.
+func List(expName string) ([]Manifest, error) {
+	paths, err := filepath.Glob(filepath.Join(checkpointDir(expName), "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("listing checkpoints: %w", err)
+	}
+
+	manifests := make([]Manifest, 0, len(paths))
+
+	for _, path := range paths {
+		manifest, err := readManifest(path)
+		if err != nil {
+			return nil, err
+		}
+
+		manifests = append(manifests, *manifest)
+	}
+
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Created.Before(manifests[j].Created) })
+
+	return manifests, nil
+}
+
+// Get returns the manifest of the checkpoint with the given name taken of the
+// experiment with the given name.
+func Get(expName, name string) (*Manifest, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	path := manifestPath(expName, name)
+
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrCheckpointNotFound, name)
+	}
+
+	return readManifest(path)
+}
+
+// Delete removes the manifest of the checkpoint with the given name taken of
+// the experiment with the given name. The VM snapshots taken as part of the
+// checkpoint are left in the experiment's files directory.
+func Delete(expName, name string) error {
+	if _, err := Get(expName, name); err != nil {
+		return err
+	}
+
+	if err := os.Remove(manifestPath(expName, name)); err != nil {
+		return fmt.Errorf("deleting checkpoint manifest: %w", err)
+	}
+
+	return nil
+}
+
+// checkpointVMs returns the running or paused KVM VMs to include in the
+// checkpoint with the given name, sorted by name, along with the names of the
+// running or paused containers that are skipped since they can't be
+// snapshotted.
+func checkpointVMs(vms mm.VMs, name string) ([]VMCheckpoint, []string) {
+	var (
+		checkpoints []VMCheckpoint
+		skipped     []string
+	)
+
+	for _, v := range vms {
+		if v.State != vmStateRunning && v.State != vmStatePaused {
+			continue
+		}
+
+		if v.IsContainer() {
+			skipped = append(skipped, v.Name)
+
+			continue
+		}
+
+		checkpoints = append(checkpoints, VMCheckpoint{
+			Name:     v.Name,
+			Host:     v.Host,
+			Snapshot: snapshotName(v.Name, name),
+			State:    v.State,
+		})
+	}
+
+	sort.Slice(checkpoints, func(i, j int) bool { return checkpoints[i].Name < checkpoints[j].Name })
+	sort.Strings(skipped)
+
+	return checkpoints, skipped
+}
+
+func validateName(name string) error {
+	if !nameRegex.MatchString(name) {
+		return fmt.Errorf("invalid checkpoint name '%s' (must only contain letters, numbers, '.', '-' and '_')", name)
+	}
+
+	return nil
+}
+
+func checkpointDir(expName string) string {
+	return filepath.Join(common.PhenixBase, "images", expName, "checkpoints")
+}
+
+func manifestPath(expName, name string) string {
+	return filepath.Join(checkpointDir(expName), name+".json")
+}
+
+// snapshotName returns the name `vm.Snapshot` gives the snapshot of the given
+// VM taken for the checkpoint with the given name.
+func snapshotName(vmName, name string) string {
+	return vmName + "__checkpoint-" + name
+}
+
+func readManifest(path string) (*Manifest, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading checkpoint manifest %s: %w", path, err)
+	}
+
+	var manifest Manifest
+
+	if err := json.Unmarshal(body, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint manifest %s: %w", path, err)
+	}
+
+	return &manifest, nil
+}
+
+func writeManifest(manifest *Manifest) error {
+	if err := os.MkdirAll(checkpointDir(manifest.Experiment), 0o755); err != nil { //nolint:mnd // file permissions
+		return fmt.Errorf("creating checkpoint directory: %w", err)
+	}
+
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling checkpoint manifest: %w", err)
+	}
+
+	if err := os.WriteFile(manifestPath(manifest.Experiment, manifest.Name), body, 0o644); err != nil { //nolint:mnd,gosec // file permissions
+		return fmt.Errorf("writing checkpoint manifest: %w", err)
+	}
+
+	return nil
+}
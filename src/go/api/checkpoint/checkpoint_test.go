@@ -0,0 +1,86 @@
+//nolint:testpackage // testing internals
+package checkpoint
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"phenix/util/common"
+	"phenix/util/mm"
+)
+
+func TestManifests(t *testing.T) {
+	common.PhenixBase = t.TempDir()
+
+	created := time.Now().UTC()
+
+	for i, name := range []string{"second", "first"} {
+		manifest := &Manifest{
+			Name:       name,
+			Experiment: "foo",
+			Created:    created.Add(-time.Duration(i) * time.Hour),
+			VMs:        []VMCheckpoint{{Name: "vm-0", Host: "compute1", Snapshot: snapshotName("vm-0", name), State: vmStateRunning}},
+			VLANs:      map[string]int{"EXP": 101},
+			AppStatus:  map[string]any{"soh": "ok"},
+		}
+
+		if err := writeManifest(manifest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifests, err := List("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(manifests) != 2 || manifests[0].Name != "first" || manifests[1].Name != "second" {
+		t.Fatalf("expected checkpoints to be listed in creation order, got %+v", manifests)
+	}
+
+	manifest, err := Get("foo", "first")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if manifest.VMs[0].Snapshot != "vm-0__checkpoint-first" || manifest.VLANs["EXP"] != 101 {
+		t.Fatalf("unexpected checkpoint manifest %+v", manifest)
+	}
+
+	if err := Delete("foo", "first"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Get("foo", "first"); !errors.Is(err, ErrCheckpointNotFound) {
+		t.Fatalf("expected deleted checkpoint to not be found, got %v", err)
+	}
+
+	if _, err := Get("foo", "../first"); err == nil {
+		t.Fatal("expected error for invalid checkpoint name")
+	}
+}
+
+func TestCheckpointVMs(t *testing.T) {
+	vms := mm.VMs{
+		{Name: "vm-1", Host: "compute2", State: vmStatePaused, VMType: "kvm"},
+		{Name: "ctr-0", Host: "compute1", State: vmStateRunning, VMType: "container"},
+		{Name: "vm-0", Host: "compute1", State: vmStateRunning, VMType: "kvm"},
+		{Name: "vm-2", Host: "compute1", State: "QUIT", VMType: "kvm"},
+		{Name: "ctr-1", Host: "compute1", State: "QUIT", VMType: "container"},
+	}
+
+	checkpoints, skipped := checkpointVMs(vms, "first")
+
+	if len(checkpoints) != 2 || checkpoints[0].Name != "vm-0" || checkpoints[1].Name != "vm-1" {
+		t.Fatalf("expected KVM VMs vm-0 and vm-1 to be checkpointed, got %+v", checkpoints)
+	}
+
+	if checkpoints[1].State != vmStatePaused || checkpoints[1].Snapshot != "vm-1__checkpoint-first" {
+		t.Fatalf("unexpected VM checkpoint %+v", checkpoints[1])
+	}
+
+	if len(skipped) != 1 || skipped[0] != "ctr-0" {
+		t.Fatalf("expected container ctr-0 to be skipped, got %v", skipped)
+	}
+}
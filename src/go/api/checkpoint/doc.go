@@ -0,0 +1,3 @@
+// Package checkpoint is an implementation of the phenix experiment checkpoint
+// and restore API.
+package checkpoint
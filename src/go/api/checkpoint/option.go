@@ -0,0 +1,51 @@
+package checkpoint
+
+// Stage identifies the step a checkpoint or restore is at when progress is
+// reported.
+type Stage string
+
+const (
+	StagePausing      Stage = "pausing"
+	StageSnapshotting Stage = "snapshotting"
+	StageRecording    Stage = "recording"
+	StageRestoring    Stage = "restoring"
+	StageResuming     Stage = "resuming"
+	StageCompleted    Stage = "completed"
+)
+
+// Progress is reported as a checkpoint is taken or restored.
+type Progress struct {
+	Stage Stage  `json:"stage"`
+	VM    string `json:"vm,omitempty"`
+
+	// Percent is the overall progress of the checkpoint or restore, from 0 to 1.
+	Percent float64 `json:"percent"`
+}
+
+// Option is a function that configures options for taking or restoring an
+// experiment checkpoint.
+type Option func(*options)
+
+type options struct {
+	progress func(Progress)
+}
+
+func newOptions(opts ...Option) options {
+	o := options{progress: func(Progress) {}}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithProgress sets a function to be called as progress is made taking or
+// restoring a checkpoint.
+func WithProgress(f func(Progress)) Option {
+	return func(o *options) {
+		if f != nil {
+			o.progress = f
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"phenix/util"
 	"phenix/util/common"
 	"phenix/util/editor"
+	"phenix/util/plog"
 )
 
 //go:embed default
@@ -369,6 +370,8 @@ func Create(opts ...CreateOption) (*store.Config, error) {
 		return nil, fmt.Errorf("storing config: %w", err)
 	}
 
+	logRevision(nil, c, RevisionActionCreate, o.author)
+
 	return c, nil
 }
 
@@ -442,8 +445,10 @@ func Edit(name string, force bool) (*store.Config, error) {
 
 // Update updates the store with the given config. If the name of the config was
 // changed as part of the update, a new config will be created and the old
-// config deleted.
-func Update(name string, c *store.Config) error {
+// config deleted, and the old config's revision history moved to the new name.
+func Update(name string, c *store.Config, opts ...UpdateOption) error {
+	o := newUpdateOptions(opts...)
+
 	old, err := store.NewConfig(name)
 	if err != nil {
 		return fmt.Errorf("getting config to update: %w", err)
@@ -486,20 +491,54 @@ func Update(name string, c *store.Config) error {
 				return fmt.Errorf("renaming updated config in store: %w", deleteErr)
 			}
 
+			if err := renameRevisions(old.Kind, old.Metadata.Name, c.Metadata.Name); err != nil {
+				plog.Error(plog.TypeSystem, "renaming config revisions", "config", c.FullName(), "err", err)
+			}
+
+			logRevision(old, c, o.action, o.author)
+
 			return nil
 		}
 
 		return fmt.Errorf("updating config in store: %w", err)
 	}
 
+	logRevision(old, c, o.action, o.author)
+
 	return nil
 }
 
+// Patch applies the given data to the config with the given name as a JSON
+// merge patch. Unlike Update, the patched config is not validated and config
+// hooks are not called. It returns the patched config and any errors
+// encountered while patching the config.
+func Patch(name string, data map[string]any, opts ...UpdateOption) (*store.Config, error) {
+	o := newUpdateOptions(append(opts, updateWithAction(RevisionActionPatch))...)
+
+	old, err := Get(name, false)
+	if err != nil {
+		return nil, fmt.Errorf("getting config to patch: %w", err)
+	}
+
+	c, err := store.NewConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.Patch(c, data); err != nil {
+		return nil, fmt.Errorf("patching config in store: %w", err)
+	}
+
+	logRevision(old, c, o.action, o.author)
+
+	return c, nil
+}
+
 // Delete removes the config with the given name from the store. The given name
 // should be of the form `type/name`, where `type` is one of `topology,
 // scenario, or experiment`. If `all` is specified, then all the known configs
-// are removed. It returns any errors encountered while removing the config from
-// the store.
+// are removed. The revision history of removed configs is removed as well. It
+// returns any errors encountered while removing the config from the store.
 func Delete(name string) error {
 	if name == "" {
 		return errors.New("no config name provided")
@@ -521,6 +560,10 @@ func Delete(name string) error {
 				continue
 			}
 
+			if err := deleteRevisions(c.Kind, c.Metadata.Name); err != nil {
+				plog.Error(plog.TypeSystem, "deleting config revisions", "config", c.FullName(), "err", err)
+			}
+
 			for _, hook := range hooks[c.Kind] {
 				hookErr := hook("delete", &c)
 				if hookErr != nil {
@@ -549,6 +592,10 @@ func Delete(name string) error {
 		return fmt.Errorf("deleting config %s: %w", name, err)
 	}
 
+	if err := deleteRevisions(c.Kind, c.Metadata.Name); err != nil {
+		plog.Error(plog.TypeSystem, "deleting config revisions", "config", name, "err", err)
+	}
+
 	for _, hook := range hooks[c.Kind] {
 		hookErr := hook("delete", c)
 		if hookErr != nil {
@@ -568,3 +615,10 @@ func Delete(name string) error {
 func IsConfigNotModified(err error) bool {
 	return errors.Is(err, editor.ErrNoChange)
 }
+
+// IsConfigConflict returns a boolean indicating whether the error is known to
+// report that a config was modified in the store since it was read (for
+// example, while it was being edited). It is satisfied by store.ErrConflict.
+func IsConfigConflict(err error) bool {
+	return errors.Is(err, store.ErrConflict)
+}
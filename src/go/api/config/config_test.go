@@ -1,6 +1,8 @@
 package config_test
 
 import (
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -64,6 +66,10 @@ func TestCreateEnv(t *testing.T) {
 
 	m := store.NewMockStore(ctrl)
 	m.EXPECT().Create(gomock.Eq(&expected)).Return(nil).AnyTimes()
+	m.EXPECT().List(gomock.Eq("Setting")).Return(nil, errors.New("settings unavailable"))
+	m.EXPECT().Get(configOf(config.RevisionIndexKind, "Topology/foobar-test-experiment")).Return(store.ErrNotExist)
+	m.EXPECT().Create(configOf(config.RevisionIndexKind, "Topology/foobar-test-experiment")).Return(nil)
+	m.EXPECT().Create(configOf(config.RevisionKind, "Topology/foobar-test-experiment/1")).Return(nil)
 
 	store.DefaultStore = m //nolint:reassign // mocking
 
@@ -76,3 +82,21 @@ func TestCreateEnv(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+type configMatcher struct {
+	kind, name string
+}
+
+func configOf(kind, name string) gomock.Matcher {
+	return configMatcher{kind: kind, name: name}
+}
+
+func (m configMatcher) Matches(x any) bool {
+	c, ok := x.(*store.Config)
+
+	return ok && c.Kind == m.kind && c.Metadata.Name == m.name
+}
+
+func (m configMatcher) String() string {
+	return fmt.Sprintf("is config %s/%s", m.kind, m.name)
+}
@@ -18,6 +18,7 @@ type createOptions struct {
 	data     []byte
 	dataType DataType
 	validate bool
+	author   string
 }
 
 func newCreateOptions(opts ...CreateOption) createOptions {
@@ -61,3 +62,42 @@ func CreateWithValidation() CreateOption {
 		o.validate = true
 	}
 }
+
+// CreateWithAuthor sets the author recorded in the revision history for the
+// created config. If not set, the current OS user is used.
+func CreateWithAuthor(a string) CreateOption {
+	return func(o *createOptions) {
+		o.author = a
+	}
+}
+
+type UpdateOption func(*updateOptions)
+
+type updateOptions struct {
+	author string
+	action RevisionAction
+}
+
+func newUpdateOptions(opts ...UpdateOption) updateOptions {
+	o := updateOptions{action: RevisionActionUpdate} //nolint:exhaustruct // partial initialization
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// UpdateWithAuthor sets the author recorded in the revision history for the
+// updated config. If not set, the current OS user is used.
+func UpdateWithAuthor(a string) UpdateOption {
+	return func(o *updateOptions) {
+		o.author = a
+	}
+}
+
+func updateWithAction(a RevisionAction) UpdateOption {
+	return func(o *updateOptions) {
+		o.action = a
+	}
+}
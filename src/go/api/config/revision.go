@@ -0,0 +1,502 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+
+	"phenix/api/settings"
+	"phenix/store"
+	"phenix/util/plog"
+)
+
+// RevisionKind is the store kind used to persist config revisions.
+const RevisionKind = "Revision"
+
+// RevisionIndexKind is the store kind used to track the range of revisions
+// retained for each config, so a config's revisions can be looked up by number
+// instead of listing the revisions of every config.
+const RevisionIndexKind = "RevisionIndex"
+
+// maxRevisionAttempts limits how many times recording a revision is retried
+// when a concurrent change claims the same revision number.
+const maxRevisionAttempts = 5
+
+type RevisionAction string
+
+const (
+	RevisionActionCreate   RevisionAction = "create"
+	RevisionActionUpdate   RevisionAction = "update"
+	RevisionActionPatch    RevisionAction = "patch"
+	RevisionActionRollback RevisionAction = "rollback"
+)
+
+// Revision is a numbered snapshot of a config, recorded every time the config is
+// created, updated, patched or rolled back. The diff is a unified diff of the
+// YAML representation of the config against the previous revision.
+type Revision struct {
+	Revision  int            `json:"revision"         yaml:"revision"`
+	Kind      string         `json:"kind"             yaml:"kind"`
+	Name      string         `json:"name"             yaml:"name"`
+	Action    RevisionAction `json:"action"           yaml:"action"`
+	Author    string         `json:"author"           yaml:"author"`
+	Timestamp string         `json:"timestamp"        yaml:"timestamp"`
+	Diff      string         `json:"diff,omitempty"   yaml:"diff,omitempty"`
+	Config    *store.Config  `json:"config,omitempty" yaml:"config,omitempty"`
+}
+
+// History returns all the recorded revisions for the config with the given
+// name, ordered from oldest to newest. The given name should be of the form
+// `type/name`.
+func History(name string) ([]Revision, error) {
+	c, err := store.NewConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions(c.Kind, c.Metadata.Name)
+}
+
+// GetRevision returns the given revision of the config with the given name.
+func GetRevision(name string, rev int) (*Revision, error) {
+	c, err := store.NewConfig(name)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := newRevisionConfig(c.Kind, c.Metadata.Name, rev)
+
+	if err := store.Get(rc); err != nil {
+		return nil, fmt.Errorf("getting revision %d of config %s: %w", rev, name, err)
+	}
+
+	r, err := decodeRevision(*rc)
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// DiffRevisions returns a unified diff between two revisions of the config with
+// the given name.
+func DiffRevisions(name string, from, to int) (string, error) {
+	a, err := GetRevision(name, from)
+	if err != nil {
+		return "", err
+	}
+
+	b, err := GetRevision(name, to)
+	if err != nil {
+		return "", err
+	}
+
+	return diffConfigs(
+		a.Config, b.Config,
+		fmt.Sprintf("%s@%d", name, from), fmt.Sprintf("%s@%d", name, to),
+	)
+}
+
+// Rollback restores the spec and annotations of the config with the given name
+// to those recorded in the given revision. The config's status is left as-is.
+// The rollback is itself recorded as a new revision.
+func Rollback(name string, rev int, opts ...UpdateOption) (*store.Config, error) {
+	r, err := GetRevision(name, rev)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Config == nil {
+		return nil, fmt.Errorf("revision %d of config %s has no config recorded", rev, name)
+	}
+
+	c, err := Get(name, false)
+	if err != nil {
+		return nil, fmt.Errorf("getting config %s: %w", name, err)
+	}
+
+	c.Version = r.Config.Version
+	c.Spec = r.Config.Spec
+	c.Metadata.Annotations = r.Config.Metadata.Annotations
+
+	opts = append(opts, updateWithAction(RevisionActionRollback))
+
+	if err := Update(name, c, opts...); err != nil {
+		return nil, fmt.Errorf("rolling back config %s to revision %d: %w", name, rev, err)
+	}
+
+	return c, nil
+}
+
+// revisionIndex is the range of revision numbers retained for a config.
+type revisionIndex struct {
+	Oldest int `json:"oldest"`
+	Latest int `json:"latest"`
+}
+
+// getRevisionIndex returns the revision index of the given config, along with
+// the store config holding it. The store config has a zero resource version if
+// no revisions have been recorded for the config yet.
+func getRevisionIndex(kind, name string) (*store.Config, revisionIndex, error) {
+	var idx revisionIndex
+
+	c := &store.Config{ //nolint:exhaustruct // partial initialization
+		Version: store.APIGroup + "/v1",
+		Kind:    RevisionIndexKind,
+		Metadata: store.ConfigMetadata{ //nolint:exhaustruct // partial initialization
+			Name: fmt.Sprintf("%s/%s", kind, name),
+		},
+	}
+
+	if err := store.Get(c); err != nil {
+		if errors.Is(err, store.ErrNotExist) {
+			return c, idx, nil
+		}
+
+		return nil, idx, fmt.Errorf("getting revision index of config %s/%s: %w", kind, name, err)
+	}
+
+	body, err := json.Marshal(c.Spec)
+	if err != nil {
+		return nil, idx, fmt.Errorf("marshaling revision index %s: %w", c.Metadata.Name, err)
+	}
+
+	if err := json.Unmarshal(body, &idx); err != nil {
+		return nil, idx, fmt.Errorf("unmarshaling revision index %s: %w", c.Metadata.Name, err)
+	}
+
+	return c, idx, nil
+}
+
+// putRevisionIndex stores the given revision index in the given store config.
+// It returns store.ErrExist or store.ErrConflict if the index was changed since
+// it was read.
+func putRevisionIndex(c *store.Config, idx revisionIndex) error {
+	c.Spec = map[string]any{"oldest": idx.Oldest, "latest": idx.Latest}
+
+	if c.Metadata.ResourceVersion == 0 {
+		return store.Create(c)
+	}
+
+	return store.Update(c)
+}
+
+func revisions(kind, name string) ([]Revision, error) {
+	_, idx, err := getRevisionIndex(kind, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var revs []Revision
+
+	for rev := idx.Oldest; rev > 0 && rev <= idx.Latest; rev++ {
+		c := newRevisionConfig(kind, name, rev)
+
+		if err := store.Get(c); err != nil {
+			// A revision number can be claimed without the revision itself being
+			// stored if storing it failed.
+			if errors.Is(err, store.ErrNotExist) {
+				continue
+			}
+
+			return nil, fmt.Errorf("getting revision %d of config %s/%s: %w", rev, kind, name, err)
+		}
+
+		r, err := decodeRevision(*c)
+		if err != nil {
+			return nil, err
+		}
+
+		revs = append(revs, r)
+	}
+
+	return revs, nil
+}
+
+// recordRevision persists a new revision for the given config. The previous
+// config is used to generate the diff and is nil for newly created configs.
+// The oldest revisions of the config are pruned once it has more than the
+// number of revisions allowed by the Config.MaxRevisions setting.
+func recordRevision(prev, curr *store.Config, action RevisionAction, author string) error {
+	if author == "" {
+		author = currentUser()
+	}
+
+	diff, err := diffConfigs(prev, curr, "previous", "current")
+	if err != nil {
+		return err
+	}
+
+	maxRevisions := maxRevisionsPerConfig()
+	snapshot := *curr
+
+	for range maxRevisionAttempts {
+		ic, idx, err := getRevisionIndex(curr.Kind, curr.Metadata.Name)
+		if err != nil {
+			return err
+		}
+
+		pruned := idx.Oldest
+
+		idx.Latest++
+		next := idx.Latest
+
+		if idx.Oldest == 0 {
+			idx.Oldest = next
+		}
+
+		if maxRevisions > 0 && next-idx.Oldest >= maxRevisions {
+			idx.Oldest = next - maxRevisions + 1
+		}
+
+		// Claim the revision number before storing the revision so concurrent
+		// changes to the same config never claim the same number.
+		err = putRevisionIndex(ic, idx)
+		if errors.Is(err, store.ErrExist) || errors.Is(err, store.ErrConflict) {
+			continue // concurrent change claimed this revision number
+		}
+
+		if err != nil {
+			return fmt.Errorf("claiming revision %d of config %s: %w", next, curr.FullName(), err)
+		}
+
+		r := Revision{
+			Revision:  next,
+			Kind:      curr.Kind,
+			Name:      curr.Metadata.Name,
+			Action:    action,
+			Author:    author,
+			Timestamp: time.Now().Format(time.RFC3339),
+			Diff:      diff,
+			Config:    &snapshot,
+		}
+
+		rc, err := encodeRevision(r)
+		if err != nil {
+			return err
+		}
+
+		if err := store.Create(rc); err != nil {
+			return fmt.Errorf("storing revision %d of config %s: %w", next, curr.FullName(), err)
+		}
+
+		for rev := pruned; rev > 0 && rev < idx.Oldest; rev++ {
+			err := store.Delete(newRevisionConfig(curr.Kind, curr.Metadata.Name, rev))
+			if err != nil && !errors.Is(err, store.ErrNotExist) {
+				return fmt.Errorf("pruning revision %d of config %s: %w", rev, curr.FullName(), err)
+			}
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unable to claim revision number for config %s", curr.FullName())
+}
+
+// maxRevisionsPerConfig returns the number of revisions to retain for each
+// config, or zero if all revisions should be retained.
+func maxRevisionsPerConfig() int {
+	s, err := settings.GetConfigSettings()
+	if err != nil {
+		plog.Warn(plog.TypeSystem, "unable to get config settings -- using default max revisions", "err", err)
+
+		return settings.DefaultConfigMaxRevisions
+	}
+
+	return int(s.MaxRevisions)
+}
+
+// logRevision records a new revision for the given config, logging instead of
+// returning any errors since the config change itself has already been
+// persisted.
+func logRevision(prev, curr *store.Config, action RevisionAction, author string) {
+	if err := recordRevision(prev, curr, action, author); err != nil {
+		plog.Error(plog.TypeSystem, "recording config revision", "config", curr.FullName(), "err", err)
+	}
+}
+
+// renameRevisions moves all the revisions of a config to a new config name.
+func renameRevisions(kind, oldName, newName string) error {
+	oldIndex, idx, err := getRevisionIndex(kind, oldName)
+	if err != nil {
+		return err
+	}
+
+	if oldIndex.Metadata.ResourceVersion == 0 {
+		return nil // no revisions recorded
+	}
+
+	revs, err := revisions(kind, oldName)
+	if err != nil {
+		return err
+	}
+
+	newIndex, _, err := getRevisionIndex(kind, newName)
+	if err != nil {
+		return err
+	}
+
+	if err := putRevisionIndex(newIndex, idx); err != nil {
+		return fmt.Errorf("storing renamed revision index: %w", err)
+	}
+
+	for _, r := range revs {
+		old := newRevisionConfig(kind, oldName, r.Revision)
+
+		r.Name = newName
+
+		rc, err := encodeRevision(r)
+		if err != nil {
+			return err
+		}
+
+		if err := store.Create(rc); err != nil {
+			return fmt.Errorf("storing renamed revision %d: %w", r.Revision, err)
+		}
+
+		if err := store.Delete(old); err != nil {
+			return fmt.Errorf("deleting renamed revision %d: %w", r.Revision, err)
+		}
+	}
+
+	if err := store.Delete(oldIndex); err != nil {
+		return fmt.Errorf("deleting renamed revision index: %w", err)
+	}
+
+	return nil
+}
+
+// deleteRevisions removes all the revisions of a config.
+func deleteRevisions(kind, name string) error {
+	ic, _, err := getRevisionIndex(kind, name)
+	if err != nil {
+		return err
+	}
+
+	if ic.Metadata.ResourceVersion == 0 {
+		return nil // no revisions recorded
+	}
+
+	revs, err := revisions(kind, name)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range revs {
+		if err := store.Delete(newRevisionConfig(kind, name, r.Revision)); err != nil {
+			return fmt.Errorf("deleting revision %d: %w", r.Revision, err)
+		}
+	}
+
+	if err := store.Delete(ic); err != nil {
+		return fmt.Errorf("deleting revision index: %w", err)
+	}
+
+	return nil
+}
+
+func newRevisionConfig(kind, name string, rev int) *store.Config {
+	return &store.Config{ //nolint:exhaustruct // partial initialization
+		Version: store.APIGroup + "/v1",
+		Kind:    RevisionKind,
+		Metadata: store.ConfigMetadata{ //nolint:exhaustruct // partial initialization
+			Name: fmt.Sprintf("%s/%s/%d", kind, name, rev),
+		},
+	}
+}
+
+func encodeRevision(r Revision) (*store.Config, error) {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling revision: %w", err)
+	}
+
+	c := newRevisionConfig(r.Kind, r.Name, r.Revision)
+
+	if err := json.Unmarshal(body, &c.Spec); err != nil {
+		return nil, fmt.Errorf("unmarshaling revision: %w", err)
+	}
+
+	return c, nil
+}
+
+func decodeRevision(c store.Config) (Revision, error) {
+	var r Revision
+
+	body, err := json.Marshal(c.Spec)
+	if err != nil {
+		return r, fmt.Errorf("marshaling revision %s: %w", c.Metadata.Name, err)
+	}
+
+	if err := json.Unmarshal(body, &r); err != nil {
+		return r, fmt.Errorf("unmarshaling revision %s: %w", c.Metadata.Name, err)
+	}
+
+	return r, nil
+}
+
+// diffConfigs returns a unified diff of the YAML representation of the given
+// configs. Either config can be nil. The updated timestamp is ignored since it
+// changes with every write.
+func diffConfigs(a, b *store.Config, fromFile, toFile string) (string, error) {
+	toLines := func(c *store.Config) ([]string, error) {
+		if c == nil {
+			return nil, nil
+		}
+
+		clone := *c
+		clone.Metadata.Updated = ""
+
+		body, err := yaml.Marshal(clone)
+		if err != nil {
+			return nil, fmt.Errorf("marshaling config to YAML: %w", err)
+		}
+
+		return difflib.SplitLines(string(body)), nil
+	}
+
+	from, err := toLines(a)
+	if err != nil {
+		return "", err
+	}
+
+	to, err := toLines(b)
+	if err != nil {
+		return "", err
+	}
+
+	diff := difflib.UnifiedDiff{ //nolint:exhaustruct // partial initialization
+		A:        from,
+		B:        to,
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  3, //nolint:mnd // standard unified diff context
+	}
+
+	out, err := difflib.GetUnifiedDiffString(diff)
+	if err != nil {
+		return "", fmt.Errorf("generating config diff: %w", err)
+	}
+
+	return out, nil
+}
+
+func currentUser() string {
+	// Prefer the user that ran sudo, if any, since that's who made the change.
+	if sudo := os.Getenv("SUDO_USER"); sudo != "" {
+		return sudo
+	}
+
+	u, err := user.Current()
+	if err != nil {
+		return "unknown"
+	}
+
+	return u.Username
+}
@@ -0,0 +1,168 @@
+package config_test
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"phenix/api/config"
+	"phenix/api/settings"
+	"phenix/store"
+)
+
+const revisionTopo = `
+apiVersion: phenix.sandia.gov/v1
+kind: Topology
+metadata:
+  name: revisions
+spec:
+  nodes:
+  - type: VirtualMachine
+    general:
+      hostname: host-00
+    hardware:
+      os_type: linux
+      drives:
+      - image: linux.qc2
+`
+
+func TestRevisionHistoryAndRollback(t *testing.T) {
+	s, err := store.NewFromEndpoint("bolt://" + filepath.Join(t.TempDir(), "phenix.bdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store.DefaultStore = s //nolint:reassign // testing
+
+	c, err := config.Create(config.CreateFromYAML([]byte(revisionTopo)), config.CreateWithAuthor("alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nodes, _ := c.Spec["nodes"].([]any)
+	node, _ := nodes[0].(map[string]any)
+	node["general"] = map[string]any{"hostname": "host-01"}
+
+	if err := config.Update("topology/revisions", c, config.UpdateWithAuthor("bob")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := config.Patch("topology/revisions", map[string]any{"metadata": map[string]any{"annotations": map[string]any{"foo": "bar"}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, err := config.History("topology/revisions")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(revs) != 3 {
+		t.Fatalf("expected 3 revisions, got %d", len(revs))
+	}
+
+	if revs[0].Action != config.RevisionActionCreate || revs[0].Author != "alice" {
+		t.Fatalf("unexpected first revision %+v", revs[0])
+	}
+
+	if revs[1].Action != config.RevisionActionUpdate || revs[1].Author != "bob" {
+		t.Fatalf("unexpected second revision %+v", revs[1])
+	}
+
+	if !diffHas(revs[1].Diff, "+", "hostname: host-01") {
+		t.Fatalf("expected update diff to include hostname change, got:\n%s", revs[1].Diff)
+	}
+
+	diff, err := config.DiffRevisions("topology/revisions", 1, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !diffHas(diff, "-", "hostname: host-00") || !diffHas(diff, "+", "foo: bar") {
+		t.Fatalf("unexpected diff between revisions:\n%s", diff)
+	}
+
+	c, err = config.Rollback("topology/revisions", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Metadata.Annotations) != 0 {
+		t.Fatalf("expected annotations to be rolled back, got %v", c.Metadata.Annotations)
+	}
+
+	revs, _ = config.History("topology/revisions")
+	if len(revs) != 4 || revs[3].Action != config.RevisionActionRollback {
+		t.Fatalf("expected rollback to be recorded as revision 4, got %+v", revs)
+	}
+
+	if err := config.Delete("topology/revisions"); err != nil {
+		t.Fatal(err)
+	}
+
+	if revs, _ := config.History("topology/revisions"); len(revs) != 0 {
+		t.Fatalf("expected revisions to be deleted with config, got %d", len(revs))
+	}
+}
+
+func TestRevisionRetention(t *testing.T) {
+	s, err := store.NewFromEndpoint("bolt://" + filepath.Join(t.TempDir(), "phenix.bdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := store.DefaultStore
+	store.DefaultStore = s //nolint:reassign // testing
+
+	t.Cleanup(func() {
+		_ = s.Close()
+		store.DefaultStore = orig //nolint:reassign // testing
+	})
+
+	if err := settings.SetDefaults(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := settings.UpdateWithVerification("Config", "MaxRevisions", "2"); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := config.Create(config.CreateFromYAML([]byte(revisionTopo)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range 3 {
+		c.Metadata.Annotations = map[string]string{"update": strconv.Itoa(i)}
+
+		if err := config.Update("topology/revisions", c); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revs, err := config.History("topology/revisions")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(revs) != 2 || revs[0].Revision != 3 || revs[1].Revision != 4 {
+		t.Fatalf("expected only revisions 3 and 4 to be retained, got %+v", revs)
+	}
+
+	if _, err := config.GetRevision("topology/revisions", 1); !errors.Is(err, store.ErrNotExist) {
+		t.Fatalf("expected revision 1 to be pruned, got %v", err)
+	}
+}
+
+// diffHas checks if the given unified diff has a line with the given prefix
+// (`+` or `-`) and content, ignoring indentation.
+func diffHas(diff, prefix, content string) bool {
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, prefix) && strings.TrimSpace(line[1:]) == content {
+			return true
+		}
+	}
+
+	return false
+}
@@ -17,7 +17,7 @@ type MMDiskFiles struct{}
 func (MMDiskFiles) CommitDisk(path string) error {
 	cmd := mmcli.NewCommand()
 	cmd.Command = "disk commit " + path
-	_, err := mmcli.SingleDataResponse(mmcli.Run(cmd))
+	_, err := mmcli.SingleDataResponse(mm.Run(cmd))
 
 	return err
 }
@@ -25,7 +25,7 @@ func (MMDiskFiles) CommitDisk(path string) error {
 func (MMDiskFiles) SnapshotDisk(src, dst string) error {
 	cmd := mmcli.NewCommand()
 	cmd.Command = fmt.Sprintf("disk snapshot %s %s", src, dst)
-	_, err := mmcli.SingleDataResponse(mmcli.Run(cmd))
+	_, err := mmcli.SingleDataResponse(mm.Run(cmd))
 
 	return err
 }
@@ -38,7 +38,7 @@ func (MMDiskFiles) RebaseDisk(src, dst string, unsafe bool) error {
 		cmd.Command = fmt.Sprintf("disk rebase %s %s", src, dst)
 	}
 
-	_, err := mmcli.SingleDataResponse(mmcli.Run(cmd))
+	_, err := mmcli.SingleDataResponse(mm.Run(cmd))
 
 	return err
 }
@@ -46,7 +46,7 @@ func (MMDiskFiles) RebaseDisk(src, dst string, unsafe bool) error {
 func (MMDiskFiles) ResizeDisk(src, size string) error {
 	cmd := mmcli.NewCommand()
 	cmd.Command = fmt.Sprintf("disk resize %s %s", src, size)
-	_, err := mmcli.SingleDataResponse(mmcli.Run(cmd))
+	_, err := mmcli.SingleDataResponse(mm.Run(cmd))
 
 	return err
 }
@@ -54,7 +54,7 @@ func (MMDiskFiles) ResizeDisk(src, size string) error {
 func (MMDiskFiles) CloneDisk(src, dst string) error {
 	cmd := mmcli.NewCommand()
 	cmd.Command = fmt.Sprintf("shell cp %s %s", src, dst)
-	_, err := mmcli.SingleDataResponse(mmcli.Run(cmd))
+	_, err := mmcli.SingleDataResponse(mm.Run(cmd))
 
 	return err
 }
@@ -62,7 +62,7 @@ func (MMDiskFiles) CloneDisk(src, dst string) error {
 func (MMDiskFiles) RenameDisk(src, dst string) error {
 	cmd := mmcli.NewCommand()
 	cmd.Command = fmt.Sprintf("shell mv %s %s", src, dst)
-	_, err := mmcli.SingleDataResponse(mmcli.Run(cmd))
+	_, err := mmcli.SingleDataResponse(mm.Run(cmd))
 
 	return err
 }
@@ -70,7 +70,7 @@ func (MMDiskFiles) RenameDisk(src, dst string) error {
 func (MMDiskFiles) DeleteDisk(src string) error {
 	cmd := mmcli.NewCommand()
 	cmd.Command = "shell rm " + src
-	_, err := mmcli.SingleDataResponse(mmcli.Run(cmd))
+	_, err := mmcli.SingleDataResponse(mm.Run(cmd))
 
 	return err
 }
@@ -129,7 +129,7 @@ func getAllFiles(details map[string]Details) {
 	cmd := mmcli.NewCommand()
 	cmd.Command = "file list"
 
-	for _, row := range mmcli.RunTabular(cmd) {
+	for _, row := range mm.RunTabular(cmd) {
 		if _, ok := details[row["name"]]; row["dir"] == "" && !ok {
 			for _, image := range resolveImage(mm.GetMMFullPath(row["name"])) {
 				if _, ok2 := details[image.Name]; !ok2 {
@@ -193,7 +193,7 @@ func resolveImage(path string) []Details {
 
 	cmd := mmcli.NewCommand()
 	cmd.Command = fmt.Sprintf("disk info %v recursive", path)
-	images := mmcli.RunTabular(cmd)
+	images := mm.RunTabular(cmd)
 
 	for i, row := range images {
 		image := Details{ //nolint:exhaustruct // partial initialization
@@ -0,0 +1,374 @@
+package experiment
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"phenix/store"
+	"phenix/types"
+	"phenix/util/common"
+	"phenix/util/plog"
+)
+
+const (
+	// Range of VLAN IDs clones are allocated from when a VLAN range isn't
+	// provided. IDs below 101 are left for infrastructure VLANs.
+	cloneVLANFloor   = 101
+	cloneVLANCeiling = 4094
+)
+
+// Clone creates a new experiment from the topology and scenario of an
+// existing experiment. The clone is created the same way Create creates an
+// experiment, so all the create hooks and configure stage apps are run for it.
+//
+// The clone is given its own VLAN range that doesn't overlap the VLAN ranges or
+// VLAN IDs in use by other experiments, unless a VLAN range is provided.
+// Optionally, a suffix can be appended to every hostname and every IPv4
+// address and subnet can be shifted by a fixed offset so multiple clones can
+// be routed to without conflicts.
+func Clone(ctx context.Context, opts ...CloneOption) error {
+	o := newCloneOptions(opts...)
+
+	if o.source == "" {
+		return errors.New("no source experiment name provided")
+	}
+
+	if o.name == "" {
+		return errors.New("no experiment name provided")
+	}
+
+	if strings.ToLower(o.name) == "all" {
+		return errors.New("cannot use 'all' for experiment name")
+	}
+
+	if o.name == o.source {
+		return errors.New("clone must have a different name than the source experiment")
+	}
+
+	srcC, _ := store.NewConfig("experiment/" + o.source)
+
+	if err := store.Get(srcC); err != nil {
+		return fmt.Errorf("getting source experiment %s: %w", o.source, err)
+	}
+
+	src, err := types.DecodeExperimentFromConfig(*srcC)
+	if err != nil {
+		return fmt.Errorf("decoding source experiment %s: %w", o.source, err)
+	}
+
+	rw := cloneRewriter{ //nolint:exhaustruct // partial initialization
+		hostnames: make(map[string]string),
+		bridge:    src.Spec.DefaultBridge(),
+	}
+
+	if o.hostnameSuffix != "" {
+		for _, node := range src.Spec.Topology().Nodes() {
+			hostname := node.General().Hostname()
+			rw.hostnames[hostname] = hostname + o.hostnameSuffix
+		}
+	}
+
+	if o.subnetOffset != "" {
+		if rw.offset, err = parseSubnetOffset(o.subnetOffset); err != nil {
+			return err
+		}
+	}
+
+	vlanMin, vlanMax := o.vlanMin, o.vlanMax
+
+	if vlanMin == 0 && vlanMax == 0 {
+		if vlanMin, vlanMax, err = allocateCloneVLANRange(src); err != nil {
+			return fmt.Errorf("allocating VLAN range for clone: %w", err)
+		}
+	}
+
+	var (
+		aliases = cloneVLANAliases(src, vlanMin, vlanMax)
+		subnets = make(map[string]any)
+	)
+
+	for alias, subnet := range src.Spec.VLANs().Subnets() {
+		subnets[alias] = rw.rewrite("", subnet)
+	}
+
+	createOpts := newCreateOptions(
+		CreateWithName(o.name),
+		CreateWithBaseDirectory(o.baseDir),
+		CreateWithVLANMin(vlanMin),
+		CreateWithVLANMax(vlanMax),
+		CreateWithVLANAliases(aliases),
+		CreateWithDeployMode(common.DeploymentMode(src.Spec.DeployMode())),
+		CreateWithGREMesh(src.Spec.UseGREMesh()),
+		CreateWithOwner(o.owner),
+	)
+
+	meta := store.ConfigMetadata{ //nolint:exhaustruct // partial initialization
+		Name:        o.name,
+		Annotations: map[string]string{"cloned-from": o.source},
+	}
+
+	// The clone is owned by whoever cloned it, not the owner of the source.
+	for k, v := range srcC.Metadata.Annotations {
+		if k == types.AnnotationOwner {
+			continue
+		}
+
+		if _, ok := meta.Annotations[k]; !ok {
+			meta.Annotations[k] = v
+		}
+	}
+
+	specMap := map[string]any{
+		"experimentName": o.name,
+		"baseDir":        createOpts.baseDir,
+		"deployMode":     createOpts.deployMode,
+		"defaultBridge":  createOpts.defaultBridge,
+		"topology":       rw.rewrite("", srcC.Spec["topology"]),
+		"vlans":          map[string]any{"subnets": subnets},
+	}
+
+	// Placement constraints reference hosts by name, so they're carried over
+	// too. Existing schedules aren't, since the clone will be scheduled when
+	// it's started.
+	for _, key := range []string{"scenario", "scheduling"} {
+		if value, ok := srcC.Spec[key]; ok && value != nil {
+			specMap[key] = rw.rewrite("", value)
+		}
+	}
+
+	plog.Info(
+		plog.TypeSystem,
+		"cloning experiment",
+		"source", o.source,
+		"exp", o.name,
+		"vlan-min", vlanMin,
+		"vlan-max", vlanMax,
+	)
+
+	return createFromSpec(createOpts, meta, specMap)
+}
+
+// cloneRewriter rewrites the hostnames, IPv4 addresses and bridges referenced
+// in a source experiment's topology and scenario for a clone.
+type cloneRewriter struct {
+	// old hostname --> new hostname
+	hostnames map[string]string
+
+	// offset added to every IPv4 address
+	offset uint32
+
+	// default bridge of the source experiment; interfaces using it are reset so
+	// they get the clone's default bridge instead
+	bridge string
+}
+
+// rewrite returns a copy of the given value, as decoded from a config spec,
+// with any hostnames and IPv4 addresses rewritten. Map keys matching a
+// hostname are rewritten too, since some apps key their metadata by hostname.
+func (r cloneRewriter) rewrite(key string, value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		rewritten := make(map[string]any, len(value))
+
+		for k, v := range value {
+			if hostname, ok := r.hostnames[k]; ok {
+				k = hostname
+			}
+
+			rewritten[k] = r.rewrite(k, v)
+		}
+
+		return rewritten
+	case []any:
+		rewritten := make([]any, len(value))
+
+		for i, v := range value {
+			rewritten[i] = r.rewrite(key, v)
+		}
+
+		return rewritten
+	case string:
+		switch key {
+		case "vlan":
+			// VLAN aliases can share a name with a host, but aren't renamed.
+			return value
+		case "bridge":
+			if value == r.bridge {
+				return ""
+			}
+
+			return value
+		}
+
+		if hostname, ok := r.hostnames[value]; ok {
+			return hostname
+		}
+
+		return r.shiftIPv4(value)
+	default:
+		return value
+	}
+}
+
+// shiftIPv4 adds the rewriter's offset to the given IPv4 address or IPv4
+// subnet in CIDR notation. Any other string, along with default routes and
+// addresses that aren't unicast (such as loopback and netmasks), is returned
+// as is.
+func (r cloneRewriter) shiftIPv4(value string) string {
+	if r.offset == 0 {
+		return value
+	}
+
+	if addr, err := netip.ParseAddr(value); err == nil {
+		if shifted, ok := r.shiftAddr(addr); ok {
+			return shifted.String()
+		}
+
+		return value
+	}
+
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		if prefix.Bits() == 0 {
+			return value
+		}
+
+		if shifted, ok := r.shiftAddr(prefix.Addr()); ok {
+			return netip.PrefixFrom(shifted, prefix.Bits()).String()
+		}
+	}
+
+	return value
+}
+
+func (r cloneRewriter) shiftAddr(addr netip.Addr) (netip.Addr, bool) {
+	if !addr.Is4() || !addr.IsGlobalUnicast() {
+		return addr, false
+	}
+
+	octets := addr.As4()
+
+	// 240.0.0.0/4 is reserved, so these are most likely netmasks.
+	if octets[0] >= 240 { //nolint:mnd // first reserved octet
+		return addr, false
+	}
+
+	binary.BigEndian.PutUint32(octets[:], binary.BigEndian.Uint32(octets[:])+r.offset)
+
+	return netip.AddrFrom4(octets), true
+}
+
+// parseSubnetOffset parses a subnet offset in dotted IPv4 notation (for
+// example, 0.0.100.0) into the number to add to each IPv4 address.
+func parseSubnetOffset(offset string) (uint32, error) {
+	addr, err := netip.ParseAddr(offset)
+	if err != nil || !addr.Is4() {
+		return 0, fmt.Errorf("invalid subnet offset %s: must be in dotted IPv4 notation (e.g. 0.0.100.0)", offset)
+	}
+
+	octets := addr.As4()
+
+	return binary.BigEndian.Uint32(octets[:]), nil
+}
+
+// cloneVLANAliases maps the VLAN aliases of the source experiment into the
+// given VLAN range. Explicit VLAN IDs are moved to the same position in the new
+// range. IDs that can't be moved, because the source experiment didn't have a
+// range, the ID was outside of it or the new range is too small, are reset so
+// minimega allocates them from the new range when the clone is started.
+func cloneVLANAliases(src *types.Experiment, vlanMin, vlanMax int) map[string]int {
+	var (
+		srcMin  = src.Spec.VLANs().Min()
+		srcMax  = src.Spec.VLANs().Max()
+		aliases = make(map[string]int)
+	)
+
+	for alias, id := range src.Spec.VLANs().Aliases() {
+		aliases[alias] = 0
+
+		if id == 0 || srcMin == 0 || id < srcMin || (srcMax != 0 && id > srcMax) {
+			continue
+		}
+
+		if moved := id - srcMin + vlanMin; vlanMax == 0 || moved <= vlanMax {
+			aliases[alias] = moved
+		}
+	}
+
+	return aliases
+}
+
+// allocateCloneVLANRange finds the lowest VLAN range that doesn't overlap the
+// VLAN range of any existing experiment or the VLAN IDs in use by any running
+// experiment. The range is the same size as the source experiment's VLAN
+// range, or has room for each of its VLAN aliases if it doesn't have one.
+func allocateCloneVLANRange(src *types.Experiment) (int, int, error) {
+	size := len(src.Spec.VLANs().Aliases())
+
+	if srcMin, srcMax := src.Spec.VLANs().Min(), src.Spec.VLANs().Max(); srcMin != 0 && srcMax != 0 {
+		size = srcMax - srcMin + 1
+	}
+
+	if size == 0 {
+		size = 1
+	}
+
+	exps, err := types.Experiments(false)
+	if err != nil {
+		return 0, 0, fmt.Errorf("getting existing experiments: %w", err)
+	}
+
+	var used [][2]int
+
+	for _, exp := range exps {
+		if minVal, maxVal := exp.Spec.VLANs().Min(), exp.Spec.VLANs().Max(); minVal != 0 && maxVal != 0 {
+			used = append(used, [2]int{minVal, maxVal})
+		}
+
+		if !exp.Running() {
+			continue
+		}
+
+		for _, id := range exp.Status.VLANs() {
+			used = append(used, [2]int{id, id})
+		}
+	}
+
+	minVal, ok := freeVLANRange(size, used)
+	if !ok {
+		return 0, 0, fmt.Errorf("no free range of %d VLANs available", size)
+	}
+
+	return minVal, minVal + size - 1, nil
+}
+
+// freeVLANRange returns the start of the lowest range of the given size
+// between cloneVLANFloor and cloneVLANCeiling that doesn't overlap any of the
+// given (inclusive) ranges.
+func freeVLANRange(size int, used [][2]int) (int, bool) {
+	sort.Slice(used, func(i, j int) bool { return used[i][0] < used[j][0] })
+
+	start := cloneVLANFloor
+
+	for _, r := range used {
+		if r[1] < start {
+			continue
+		}
+
+		if r[0] >= start+size {
+			break
+		}
+
+		start = r[1] + 1
+	}
+
+	if start+size-1 > cloneVLANCeiling {
+		return 0, false
+	}
+
+	return start, true
+}
@@ -0,0 +1,173 @@
+package experiment
+
+import (
+	"reflect"
+	"testing"
+
+	"phenix/store"
+	"phenix/types"
+)
+
+func TestCloneRewriter(t *testing.T) {
+	offset, err := parseSubnetOffset("0.0.100.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rw := cloneRewriter{
+		hostnames: map[string]string{"web": "web-s1", "fw": "fw-s1"},
+		offset:    offset,
+		bridge:    "range",
+	}
+
+	topo := map[string]any{
+		"nodes": []any{
+			map[string]any{
+				"general": map[string]any{"hostname": "web"},
+				"network": map[string]any{
+					"interfaces": []any{
+						map[string]any{
+							"vlan":    "web",
+							"bridge":  "range",
+							"address": "10.1.1.10",
+							"gateway": "10.1.1.1",
+							"mask":    24,
+						},
+						map[string]any{"vlan": "mgmt", "bridge": "mgmt-br", "address": "127.0.0.1"},
+					},
+					"routes": []any{
+						map[string]any{"destination": "0.0.0.0/0", "next": "10.1.1.1"},
+						map[string]any{"destination": "192.168.5.0/24", "next": "10.1.1.254"},
+					},
+				},
+			},
+		},
+	}
+
+	expected := map[string]any{
+		"nodes": []any{
+			map[string]any{
+				"general": map[string]any{"hostname": "web-s1"},
+				"network": map[string]any{
+					"interfaces": []any{
+						map[string]any{
+							"vlan":    "web",
+							"bridge":  "",
+							"address": "10.1.101.10",
+							"gateway": "10.1.101.1",
+							"mask":    24,
+						},
+						map[string]any{"vlan": "mgmt", "bridge": "mgmt-br", "address": "127.0.0.1"},
+					},
+					"routes": []any{
+						map[string]any{"destination": "0.0.0.0/0", "next": "10.1.101.1"},
+						map[string]any{"destination": "192.168.105.0/24", "next": "10.1.101.254"},
+					},
+				},
+			},
+		},
+	}
+
+	if got := rw.rewrite("", topo); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected rewritten topology\n got: %v\nwant: %v", got, expected)
+	}
+
+	scenario := map[string]any{
+		"apps": []any{
+			map[string]any{
+				"name": "firewall",
+				"hosts": []any{
+					map[string]any{
+						"hostname": "fw",
+						"metadata": map[string]any{"peer": "web", "netmask": "255.255.255.0"},
+					},
+				},
+				"metadata": map[string]any{"fw": map[string]any{"allow": "10.1.1.0/24"}},
+			},
+		},
+	}
+
+	expected = map[string]any{
+		"apps": []any{
+			map[string]any{
+				"name": "firewall",
+				"hosts": []any{
+					map[string]any{
+						"hostname": "fw-s1",
+						"metadata": map[string]any{"peer": "web-s1", "netmask": "255.255.255.0"},
+					},
+				},
+				"metadata": map[string]any{"fw-s1": map[string]any{"allow": "10.1.101.0/24"}},
+			},
+		},
+	}
+
+	if got := rw.rewrite("", scenario); !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected rewritten scenario\n got: %v\nwant: %v", got, expected)
+	}
+}
+
+func TestParseSubnetOffsetInvalid(t *testing.T) {
+	for _, offset := range []string{"100", "::1", "0.0.300.0"} {
+		if _, err := parseSubnetOffset(offset); err == nil {
+			t.Errorf("expected error for subnet offset %q", offset)
+		}
+	}
+}
+
+func TestFreeVLANRange(t *testing.T) {
+	cases := map[string]struct {
+		size  int
+		used  [][2]int
+		start int
+		ok    bool
+	}{
+		"empty":         {size: 10, start: cloneVLANFloor, ok: true},
+		"after range":   {size: 10, used: [][2]int{{101, 200}}, start: 201, ok: true},
+		"in gap":        {size: 10, used: [][2]int{{300, 400}, {101, 150}}, start: 151, ok: true},
+		"gap too small": {size: 10, used: [][2]int{{101, 150}, {155, 155}}, start: 156, ok: true},
+		"below floor":   {size: 5, used: [][2]int{{1, 50}}, start: cloneVLANFloor, ok: true},
+		"exhausted":     {size: 100, used: [][2]int{{101, 4000}}, ok: false},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			start, ok := freeVLANRange(tc.size, tc.used)
+
+			if ok != tc.ok {
+				t.Fatalf("expected ok to be %v, got %v", tc.ok, ok)
+			}
+
+			if ok && start != tc.start {
+				t.Errorf("expected range to start at %d, got %d", tc.start, start)
+			}
+		})
+	}
+}
+
+func TestCloneVLANAliases(t *testing.T) {
+	src := types.NewExperiment(store.ConfigMetadata{Name: "src"}) //nolint:exhaustruct // partial initialization
+
+	if err := src.Spec.SetVLANRange(100, 109, true); err != nil {
+		t.Fatal(err)
+	}
+
+	src.Spec.VLANs().SetAliases(map[string]int{"first": 100, "mid": 105, "below": 50, "above": 150, "auto": 0})
+
+	// IDs outside of the source range are reset instead of being moved below or
+	// past the new range.
+	aliases := cloneVLANAliases(src, 200, 209)
+	expected := map[string]int{"first": 200, "mid": 205, "below": 0, "above": 0, "auto": 0}
+
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("expected aliases %v, got %v", expected, aliases)
+	}
+
+	// IDs that don't fit a smaller range are reset too.
+	aliases = cloneVLANAliases(src, 200, 202)
+	expected = map[string]int{"first": 200, "mid": 0, "below": 0, "above": 0, "auto": 0}
+
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("expected aliases %v, got %v", expected, aliases)
+	}
+}
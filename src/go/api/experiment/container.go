@@ -0,0 +1,188 @@
+package experiment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"phenix/types"
+	"phenix/util/mm"
+	"phenix/util/plog"
+)
+
+const defaultInjectPerms = 0o644
+
+// injectContainerFiles copies the file injections of each bootable container
+// node into a copy of the node's root filesystem. KVM nodes get their
+// injections written into a disk snapshot by minimega, but containers boot
+// directly from a filesystem directory, so the files have to be in place before
+// the container is launched.
+//
+// Since a filesystem can be shared by multiple container nodes (and
+// experiments), it's never modified. Instead, each container node with
+// injections gets its own copy of the filesystem in the experiment's directory
+// in the minimega files directory, and the node is updated to boot from it. The
+// copy only exists on the headnode, so container nodes with injections are
+// scheduled on the headnode if they aren't already scheduled, and it's an error
+// for them to be scheduled anywhere else.
+//
+// The returned function reverts the changes made to the experiment spec, and
+// should be called once the minimega script has been generated so the changes
+// aren't persisted with the experiment.
+//
+//nolint:funlen // complex logic
+func injectContainerFiles(ctx context.Context, exp *types.Experiment) (func(), error) {
+	var (
+		headnode  = mm.Headnode()
+		schedules = exp.Spec.Schedules()
+		original  = maps.Clone(schedules)
+		restore   []func()
+	)
+
+	revert := func() {
+		for _, f := range restore {
+			f()
+		}
+
+		exp.Spec.SetSchedule(original)
+	}
+
+	for _, node := range exp.Spec.Topology().Nodes() {
+		if node.External() || !node.IsContainer() || node.Container() == nil {
+			continue
+		}
+
+		if dnb := node.General().DoNotBoot(); dnb != nil && *dnb {
+			continue
+		}
+
+		hostname := node.General().Hostname()
+
+		if len(node.Deletions()) > 0 {
+			plog.Warn(
+				plog.TypeSystem,
+				"ignoring file deletions for container node",
+				"exp", exp.Metadata.Name,
+				"node", hostname,
+			)
+		}
+
+		if len(node.Injections()) == 0 {
+			continue
+		}
+
+		if host, ok := schedules[hostname]; ok && !mm.IsHeadnode(host) {
+			revert()
+
+			return nil, fmt.Errorf(
+				"container node %s has file injections but is scheduled on %s; containers with injections must be scheduled on the headnode (%s)",
+				hostname, host, headnode,
+			)
+		}
+
+		schedules[hostname] = headnode
+
+		var (
+			container = node.Container()
+			baseFS    = container.Filesystem()
+			fsPath    = mm.GetMMFullPath(filepath.Join(exp.Metadata.Name, "containers", hostname))
+		)
+
+		if err := copyFilesystem(ctx, mm.GetMMFullPath(baseFS), fsPath); err != nil {
+			revert()
+
+			return nil, fmt.Errorf("copying filesystem for container node %s: %w", hostname, err)
+		}
+
+		container.SetFilesystem(fsPath)
+		restore = append(restore, func() { container.SetFilesystem(baseFS) })
+
+		for _, inject := range node.Injections() {
+			src := inject.Src()
+
+			if !filepath.IsAbs(src) {
+				src = filepath.Join(exp.Spec.BaseDir(), src)
+			}
+
+			dst := filepath.Join(fsPath, filepath.Clean("/"+inject.Dst()))
+			perms := os.FileMode(defaultInjectPerms)
+
+			if p := inject.Permissions(); p != "" {
+				mode, err := strconv.ParseUint(strings.TrimPrefix(p, "0o"), 8, 32)
+				if err != nil {
+					revert()
+
+					return nil, fmt.Errorf("parsing permissions %s for injection %s on node %s: %w", p, inject.Dst(), hostname, err)
+				}
+
+				perms = os.FileMode(mode)
+			}
+
+			if err := copyInjection(src, dst, perms); err != nil {
+				revert()
+
+				return nil, fmt.Errorf("injecting %s into container node %s: %w", inject.Dst(), hostname, err)
+			}
+		}
+	}
+
+	exp.Spec.SetSchedule(schedules)
+
+	return revert, nil
+}
+
+// copyFilesystem replaces the directory at dst with a copy of the container
+// filesystem at src, preserving ownership, permissions and links.
+func copyFilesystem(ctx context.Context, src, dst string) error {
+	if err := os.RemoveAll(dst); err != nil {
+		return fmt.Errorf("removing previous copy: %w", err)
+	}
+
+	if err := os.MkdirAll(dst, 0o755); err != nil { //nolint:gosec // container filesystem dirs must be world readable
+		return fmt.Errorf("creating destination directory: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "cp", "-a", src+"/.", dst).CombinedOutput() //nolint:gosec // Command injection via taint analysis
+	if err != nil {
+		return fmt.Errorf("copying %s: %w (%s)", src, err, strings.TrimSpace(string(out)))
+	}
+
+	return nil
+}
+
+func copyInjection(src, dst string, perms os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("opening source file: %w", err)
+	}
+
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil { //nolint:gosec // container filesystem dirs must be world readable
+		return fmt.Errorf("creating destination directory: %w", err)
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perms)
+	if err != nil {
+		return fmt.Errorf("creating destination file: %w", err)
+	}
+
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return fmt.Errorf("copying file: %w", err)
+	}
+
+	// OpenFile only applies permissions to new files.
+	if err := out.Chmod(perms); err != nil {
+		return fmt.Errorf("setting file permissions: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package experiment
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"phenix/store"
+	"phenix/types"
+	v1 "phenix/types/version/v1"
+	"phenix/util/mm"
+)
+
+func TestInjectContainerFilesOffHeadnode(t *testing.T) {
+	orig := mm.DefaultMM
+	mm.DefaultMM = mm.NewFake() //nolint:reassign // testing
+
+	t.Cleanup(func() { mm.DefaultMM = orig }) //nolint:reassign // testing
+
+	topo := &v1.TopologySpec{
+		NodesF: []*v1.Node{
+			{
+				TypeF:       "VirtualMachine",
+				GeneralF:    &v1.General{HostnameF: "ctr", VMTypeF: "container"},
+				ContainerF:  &v1.Container{FilesystemF: "/phenix/images/base_fs"},
+				InjectionsF: []*v1.Injection{{SrcF: "hosts", DstF: "/etc/hosts"}},
+			},
+		},
+	}
+
+	exp := types.NewExperiment(store.ConfigMetadata{Name: "foo"}) //nolint:exhaustruct // partial initialization
+	exp.Spec = &v1.ExperimentSpec{
+		TopologyF:  topo,
+		SchedulesF: map[string]string{"ctr": "fake-compute1"},
+	}
+
+	_, err := injectContainerFiles(context.Background(), exp)
+	if err == nil || !strings.Contains(err.Error(), "must be scheduled on the headnode") {
+		t.Fatalf("expected error for container with injections scheduled off the headnode, got %v", err)
+	}
+
+	if fs := topo.NodesF[0].Container().Filesystem(); fs != "/phenix/images/base_fs" {
+		t.Errorf("expected container filesystem to be left as is, got %s", fs)
+	}
+
+	if host := exp.Spec.Schedules()["ctr"]; host != "fake-compute1" {
+		t.Errorf("expected container schedule to be left as is, got %s", host)
+	}
+}
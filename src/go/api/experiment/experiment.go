@@ -2,6 +2,7 @@ package experiment
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -15,6 +16,7 @@ import (
 	"github.com/mitchellh/mapstructure"
 
 	"phenix/api/config"
+	"phenix/api/quota"
 	"phenix/app"
 	"phenix/scheduler"
 	"phenix/store"
@@ -25,7 +27,6 @@ import (
 	"phenix/util/common"
 	"phenix/util/file"
 	"phenix/util/mm"
-	"phenix/util/mm/mmcli"
 	"phenix/util/notes"
 	"phenix/util/plog"
 	"phenix/util/pubsub"
@@ -122,6 +123,14 @@ func hookCreate(exp *types.Experiment, c *store.Config) error {
 		}
 	}
 
+	if err := validateLease(exp.Spec); err != nil {
+		return fmt.Errorf("validating experiment lease: %w", err)
+	}
+
+	if err := app.ValidateDependencies(exp); err != nil {
+		return fmt.Errorf("validating app dependencies: %w", err)
+	}
+
 	err = exp.Spec.VerifyScenario(context.Background())
 	if err != nil {
 		return fmt.Errorf("verifying experiment scenario: %w", err)
@@ -133,6 +142,8 @@ func hookCreate(exp *types.Experiment, c *store.Config) error {
 	}
 
 	c.Spec = structs.MapDefaultCase(exp.Spec, structs.CASESNAKE)
+	// Configure stage apps (like IPAM) can record details in the status.
+	c.Status = structs.MapDefaultCase(exp.Status, structs.CASESNAKE)
 	return nil
 }
 
@@ -164,6 +175,14 @@ func hookUpdate(exp *types.Experiment, c *store.Config) error {
 
 	exp.Spec.SetUseGREMesh(exp.Spec.UseGREMesh() || common.UseGREMesh)
 
+	if err := validateLease(exp.Spec); err != nil {
+		return fmt.Errorf("validating experiment lease: %w", err)
+	}
+
+	if err := app.ValidateDependencies(exp); err != nil {
+		return fmt.Errorf("validating app dependencies: %w", err)
+	}
+
 	existing, _ := types.Experiments(false)
 	for _, other := range existing {
 		if other.Metadata.Name == exp.Metadata.Name {
@@ -321,11 +340,6 @@ func Create(ctx context.Context, opts ...CreateOption) error {
 		return errors.New("default bridge name must be 15 characters or less")
 	}
 
-	var (
-		kind       = "Experiment"
-		apiVersion = version.StoredVersion[kind]
-	)
-
 	topoC, _ := store.NewConfig("topology/" + o.topology)
 
 	err := store.Get(topoC)
@@ -333,8 +347,9 @@ func Create(ctx context.Context, opts ...CreateOption) error {
 		return errors.New("topology doesn't exist")
 	}
 
-	// This will upgrade the toplogy to the latest known version if needed.
-	topo, err2 := types.DecodeTopologyFromConfig(*topoC)
+	// This will upgrade the toplogy to the latest known version if needed, as
+	// well as render any parameters and generators in the topology.
+	topo, err2 := types.DecodeTopologyFromConfigWithParameters(*topoC, o.parameters)
 	if err2 != nil {
 		return fmt.Errorf("decoding topology from config: %w", err2)
 	}
@@ -346,6 +361,17 @@ func Create(ctx context.Context, opts ...CreateOption) error {
 		},
 	}
 
+	// Record the parameters so the topology can be rendered the same way when
+	// it's applied to the experiment again (e.g. by the builder or workflows).
+	if len(o.parameters) > 0 {
+		params, err := json.Marshal(o.parameters)
+		if err != nil {
+			return fmt.Errorf("encoding topology parameters: %w", err)
+		}
+
+		meta.Annotations[types.AnnotationTopologyParameters] = string(params)
+	}
+
 	specMap := map[string]any{
 		"experimentName": o.name,
 		"baseDir":        o.baseDir,
@@ -368,6 +394,26 @@ func Create(ctx context.Context, opts ...CreateOption) error {
 		}
 	}
 
+	return createFromSpec(o, meta, specMap)
+}
+
+// createFromSpec stores a new experiment config with the given metadata and
+// spec, applying the VLAN, schedule and GRE mesh settings from the given create
+// options, and runs any registered create hooks once the config is stored.
+func createFromSpec(o createOptions, meta store.ConfigMetadata, specMap map[string]any) error {
+	var (
+		kind       = "Experiment"
+		apiVersion = version.StoredVersion[kind]
+	)
+
+	if o.owner != "" {
+		if meta.Annotations == nil {
+			meta.Annotations = make(map[string]string)
+		}
+
+		meta.Annotations[types.AnnotationOwner] = o.owner
+	}
+
 	c := &store.Config{ //nolint:exhaustruct // partial initialization
 		Version:  store.APIGroup + "/" + apiVersion,
 		Kind:     kind,
@@ -375,9 +421,9 @@ func Create(ctx context.Context, opts ...CreateOption) error {
 		Spec:     specMap,
 	}
 
-	exp, err2 := types.DecodeExperimentFromConfig(*c)
-	if err2 != nil {
-		return fmt.Errorf("decoding experiment from config: %w", err2)
+	exp, err := types.DecodeExperimentFromConfig(*c)
+	if err != nil {
+		return fmt.Errorf("decoding experiment from config: %w", err)
 	}
 
 	err = exp.Spec.SetVLANRange(o.vlanMin, o.vlanMax, false)
@@ -389,6 +435,10 @@ func Create(ctx context.Context, opts ...CreateOption) error {
 	exp.Spec.SetSchedule(o.schedules)
 	exp.Spec.SetUseGREMesh(o.useGREMesh)
 
+	if err := quota.CheckCreate(exp); err != nil {
+		return err
+	}
+
 	c.Spec = structs.MapDefaultCase(exp.Spec, structs.CASESNAKE)
 
 	_, err = config.Create(
@@ -474,7 +524,7 @@ func Schedule(opts ...ScheduleOption) error {
 		return fmt.Errorf("experiment already running (started at: %s)", exp.Status.StartTime())
 	}
 
-	err = scheduler.Schedule(o.algorithm, exp.Spec)
+	err = scheduler.Schedule(o.algorithm, exp.Spec, o.schedOpts...)
 	if err != nil {
 		return fmt.Errorf("running scheduler algorithm: %w", err)
 	}
@@ -489,6 +539,37 @@ func Schedule(opts ...ScheduleOption) error {
 	return nil
 }
 
+// PreviewSchedule runs the given scheduling algorithm against a copy of the
+// experiment with the given name and returns a report of the proposed
+// placement. The experiment's schedule in the store isn't modified.
+func PreviewSchedule(opts ...ScheduleOption) (*scheduler.Report, error) {
+	o := newScheduleOptions(opts...)
+
+	c, _ := store.NewConfig("experiment/" + o.name)
+
+	if err := store.Get(c); err != nil {
+		return nil, fmt.Errorf("getting experiment %s from store: %w", o.name, err)
+	}
+
+	// Decoding the config gives us a copy of the experiment spec that's safe to
+	// schedule without saving.
+	exp, err := types.DecodeExperimentFromConfig(*c)
+	if err != nil {
+		return nil, fmt.Errorf("decoding experiment from config: %w", err)
+	}
+
+	if exp.Running() {
+		return nil, fmt.Errorf("experiment already running (started at: %s)", exp.Status.StartTime())
+	}
+
+	report, err := scheduler.Preview(o.algorithm, exp.Spec, o.schedOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("running scheduler algorithm: %w", err)
+	}
+
+	return report, nil
+}
+
 // Start starts the experiment with the given name. It returns any errors
 // encountered while starting the experiment.
 //
@@ -514,6 +595,12 @@ func Start(ctx context.Context, opts ...StartOption) error {
 		}
 	}
 
+	if !o.dryrun {
+		if err := quota.CheckRunning(exp); err != nil {
+			return err
+		}
+	}
+
 	if o.vlanMin != 0 {
 		exp.Spec.VLANs().SetMin(o.vlanMin)
 	}
@@ -541,7 +628,19 @@ func Start(ctx context.Context, opts ...StartOption) error {
 		)
 	)
 
+	revertContainers := func() {}
+
+	if !o.dryrun {
+		revertContainers, err = injectContainerFiles(ctx, exp)
+		if err != nil {
+			return fmt.Errorf("injecting files into container filesystems: %w", err)
+		}
+	}
+
 	err = tmpl.CreateFileFromTemplate("minimega_script.tmpl", exp.Spec, mmScript)
+
+	revertContainers()
+
 	if err != nil {
 		return fmt.Errorf("generating minimega script: %w", err)
 	}
@@ -725,7 +824,14 @@ func Start(ctx context.Context, opts ...StartOption) error {
 		exp.Status.SetVLANs(vlans)
 	}
 
-	start := time.Now().Format(time.RFC3339)
+	now := time.Now()
+	start := now.Format(time.RFC3339)
+
+	if err := setLeaseExpiration(exp, now); err != nil {
+		_ = mm.ClearNamespace(exp.Spec.ExperimentName())
+
+		return fmt.Errorf("setting experiment lease expiration: %w", err)
+	}
 
 	if o.dryrun {
 		start += "-DRYRUN"
@@ -842,6 +948,11 @@ func Start(ctx context.Context, opts ...StartOption) error {
 	c.Spec = structs.MapDefaultCase(exp.Spec, structs.CASESNAKE)
 	c.Status = structs.MapDefaultCase(exp.Status, structs.CASESNAKE)
 
+	// Apps write their status to the store as they're applied, so the resource
+	// version read above is stale by now. The spec and status of the experiment
+	// that was just started take precedence over anything written since.
+	c.Metadata.ResourceVersion = 0
+
 	err = store.Update(c)
 	if err != nil {
 		_ = mm.ClearNamespace(exp.Spec.ExperimentName())
@@ -898,10 +1009,15 @@ func Stop(name string) error {
 	}
 
 	exp.Status.SetStartTime("")
+	exp.Status.SetLeaseExpires("")
 
 	c.Spec = structs.MapDefaultCase(exp.Spec, structs.CASESNAKE)
 	c.Status = structs.MapDefaultCase(exp.Status, structs.CASESNAKE)
 
+	// As in Start, the cleanup apps have written to the experiment since it was
+	// read, so don't check the resource version.
+	c.Metadata.ResourceVersion = 0
+
 	err = store.Update(c)
 	if err != nil {
 		errors = multierror.Append(errors, fmt.Errorf("updating experiment config: %w", err))
@@ -1014,6 +1130,11 @@ func Reconfigure(name string) error {
 	}
 
 	c.Spec = structs.MapDefaultCase(exp.Spec, structs.CASESNAKE)
+	c.Status = structs.MapDefaultCase(exp.Status, structs.CASESNAKE)
+
+	// Apps record their progress in the store while they configure the
+	// experiment, bumping its resource version.
+	c.Metadata.ResourceVersion = 0
 
 	err = config.Update(c.FullName(), c)
 	if err != nil {
@@ -1261,10 +1382,7 @@ func waitForTimeDelay(ctx context.Context, ns, host string, delay time.Duration)
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-time.After(delay):
-		cmd := mmcli.NewNamespacedCommand(ns)
-		cmd.Command = "vm start " + host
-
-		err := mmcli.ErrorResponse(mmcli.Run(cmd))
+		err := mm.StartVM(mm.NS(ns), mm.VMName(host))
 		if err != nil {
 			return NewDelayedVMError(host, err, "starting VM %s", host)
 		}
@@ -1310,10 +1428,7 @@ func waitForC2(
 			}
 
 			if done {
-				cmd := mmcli.NewNamespacedCommand(ns)
-				cmd.Command = "vm start " + host
-
-				err := mmcli.ErrorResponse(mmcli.Run(cmd))
+				err := mm.StartVM(mm.NS(ns), mm.VMName(host))
 				if err != nil {
 					return NewDelayedVMError(host, err, "starting VM %s", host)
 				}
@@ -1,12 +1,16 @@
 package experiment_test
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 
+	"phenix/api/config"
 	"phenix/api/experiment"
 	"phenix/store"
+	"phenix/util/mm"
 )
 
 func TestList(t *testing.T) {
@@ -41,3 +45,89 @@ func TestList(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+// newLifecycleExperiment replaces the default store with an empty one and
+// minimega with the fake for the duration of the test, then creates an
+// experiment named lifecycle with a single VM.
+func newLifecycleExperiment(t *testing.T) {
+	t.Helper()
+
+	s, err := store.NewFromEndpoint("bolt://" + filepath.Join(t.TempDir(), "store.bdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	origStore, origMM := store.DefaultStore, mm.DefaultMM
+	store.DefaultStore = s                                        //nolint:reassign // testing
+	mm.DefaultMM = mm.NewFake(mm.FakeHost("compute1", 16, 32768)) //nolint:reassign // testing
+
+	t.Cleanup(func() {
+		_ = s.Close()
+		store.DefaultStore = origStore //nolint:reassign // testing
+		mm.DefaultMM = origMM          //nolint:reassign // testing
+	})
+
+	topo := &store.Config{ //nolint:exhaustruct // partial initialization
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Topology",
+		Metadata: store.ConfigMetadata{Name: "lifecycle"}, //nolint:exhaustruct // partial initialization
+		Spec: map[string]any{
+			"nodes": []any{
+				map[string]any{
+					"type":    "VirtualMachine",
+					"general": map[string]any{"hostname": "vm"},
+					"hardware": map[string]any{
+						"os_type": "linux",
+						"vcpus":   1,
+						"memory":  512,
+						"drives":  []any{map[string]any{"image": "linux.qc2"}},
+					},
+				},
+			},
+		},
+	}
+
+	if _, err := config.Create(config.CreateFromConfig(topo), config.CreateWithValidation()); err != nil {
+		t.Fatal(err)
+	}
+
+	err = experiment.Create(
+		context.Background(),
+		experiment.CreateWithName("lifecycle"),
+		experiment.CreateWithTopology("lifecycle"),
+		experiment.CreateWithBaseDirectory(t.TempDir()),
+	)
+	if err != nil {
+		t.Fatalf("creating experiment: %v", err)
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	newLifecycleExperiment(t)
+
+	// Apps write their status to the store while the experiment starts and
+	// stops, so neither should fail with a conflict.
+	if err := experiment.Start(context.Background(), experiment.StartWithName("lifecycle")); err != nil {
+		t.Fatalf("starting experiment: %v", err)
+	}
+
+	if !experiment.Running("lifecycle") {
+		t.Fatal("expected experiment to be running after start")
+	}
+
+	if err := experiment.Stop("lifecycle"); err != nil {
+		t.Fatalf("stopping experiment: %v", err)
+	}
+
+	if experiment.Running("lifecycle") {
+		t.Fatal("expected experiment to be stopped after stop")
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	newLifecycleExperiment(t)
+
+	if err := experiment.Reconfigure("lifecycle"); err != nil {
+		t.Fatalf("reconfiguring experiment: %v", err)
+	}
+}
@@ -0,0 +1,151 @@
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"phenix/store"
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+	"phenix/util/cron"
+)
+
+// ErrNoLease is returned when extending the lease of an experiment that
+// doesn't have a max runtime or idle timeout.
+var ErrNoLease = errors.New("experiment does not have a lease")
+
+// LeaseDurations returns the max runtime and idle timeout of the given lease.
+// Zero is returned for either one that isn't set.
+func LeaseDurations(lease ifaces.LeaseSpec) (time.Duration, time.Duration, error) {
+	var maxRuntime, idleTimeout time.Duration
+
+	if v := lease.MaxRuntime(); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("invalid lease max runtime %s", v)
+		}
+
+		maxRuntime = d
+	}
+
+	if v := lease.IdleTimeout(); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, 0, fmt.Errorf("invalid lease idle timeout %s", v)
+		}
+
+		idleTimeout = d
+	}
+
+	return maxRuntime, idleTimeout, nil
+}
+
+// CronSchedules returns the parsed start and stop schedules of the given cron
+// schedule. Nil is returned for either one that isn't set.
+func CronSchedules(schedule ifaces.CronScheduleSpec) (*cron.Schedule, *cron.Schedule, error) {
+	var start, stop *cron.Schedule
+
+	if expr := schedule.Start(); expr != "" {
+		s, err := cron.Parse(expr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing start schedule: %w", err)
+		}
+
+		start = s
+	}
+
+	if expr := schedule.Stop(); expr != "" {
+		s, err := cron.Parse(expr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("parsing stop schedule: %w", err)
+		}
+
+		stop = s
+	}
+
+	return start, stop, nil
+}
+
+// ExtendLease extends the lease of the running experiment with the given name
+// by the given duration, returning the lease's new expiration. If the
+// experiment doesn't have a max runtime, the zero time is returned since only
+// its idle timer can be reset, which is up to the caller.
+func ExtendLease(name string, d time.Duration) (time.Time, error) {
+	if d <= 0 {
+		return time.Time{}, errors.New("lease extension must be positive")
+	}
+
+	c, _ := store.NewConfig("experiment/" + name)
+
+	if err := store.Get(c); err != nil {
+		return time.Time{}, fmt.Errorf("getting experiment %s from store: %w", name, err)
+	}
+
+	exp, err := types.DecodeExperimentFromConfig(*c)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("decoding experiment from config: %w", err)
+	}
+
+	if !exp.Running() {
+		return time.Time{}, errors.New("experiment isn't running")
+	}
+
+	maxRuntime, idleTimeout, err := LeaseDurations(exp.Spec.Lease())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if maxRuntime == 0 {
+		if idleTimeout == 0 {
+			return time.Time{}, fmt.Errorf("%w: %s", ErrNoLease, name)
+		}
+
+		return time.Time{}, nil
+	}
+
+	expires, err := time.Parse(time.RFC3339, exp.Status.LeaseExpires())
+	if err != nil || expires.Before(time.Now()) {
+		expires = time.Now()
+	}
+
+	expires = expires.Add(d)
+	exp.Status.SetLeaseExpires(expires.Format(time.RFC3339))
+
+	if err := Save(SaveWithName(name), SaveWithStatus(exp.Status)); err != nil {
+		return time.Time{}, fmt.Errorf("saving lease expiration: %w", err)
+	}
+
+	return expires, nil
+}
+
+// validateLease returns an error if the lease or cron schedule of the given
+// experiment can't be parsed.
+func validateLease(spec ifaces.ExperimentSpec) error {
+	if _, _, err := LeaseDurations(spec.Lease()); err != nil {
+		return err
+	}
+
+	if _, _, err := CronSchedules(spec.CronSchedule()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// setLeaseExpiration sets the lease expiration of the given experiment, which
+// is being started at the given time, if it has a max runtime.
+func setLeaseExpiration(exp *types.Experiment, start time.Time) error {
+	maxRuntime, _, err := LeaseDurations(exp.Spec.Lease())
+	if err != nil {
+		return err
+	}
+
+	if maxRuntime == 0 {
+		exp.Status.SetLeaseExpires("")
+	} else {
+		exp.Status.SetLeaseExpires(start.Add(maxRuntime).Format(time.RFC3339))
+	}
+
+	return nil
+}
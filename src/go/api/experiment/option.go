@@ -1,6 +1,7 @@
 package experiment
 
 import (
+	"phenix/scheduler"
 	ifaces "phenix/types/interfaces"
 	"phenix/util/common"
 )
@@ -13,6 +14,7 @@ type createOptions struct {
 	name          string
 	annotations   map[string]string
 	topology      string
+	parameters    map[string]string
 	scenario      string
 	disabledApps  []string
 	vlanMin       int
@@ -23,6 +25,7 @@ type createOptions struct {
 	deployMode    common.DeploymentMode
 	useGREMesh    bool
 	defaultBridge string
+	owner         string
 }
 
 func newCreateOptions(opts ...CreateOption) createOptions {
@@ -64,6 +67,14 @@ func CreateWithTopology(t string) CreateOption {
 	}
 }
 
+// CreateWithTopologyParameters sets the values used for the topology's
+// parameters when rendering it for the experiment.
+func CreateWithTopologyParameters(p map[string]string) CreateOption {
+	return func(o *createOptions) {
+		o.parameters = p
+	}
+}
+
 func CreateWithScenario(s string) CreateOption {
 	return func(o *createOptions) {
 		o.scenario = s
@@ -125,6 +136,91 @@ func CreateWithDefaultBridge(b string) CreateOption {
 	}
 }
 
+// CreateWithOwner sets the user that owns the experiment. The experiment counts
+// against the user's quota.
+func CreateWithOwner(u string) CreateOption {
+	return func(o *createOptions) {
+		o.owner = u
+	}
+}
+
+type CloneOption func(*cloneOptions)
+
+type cloneOptions struct {
+	source         string
+	name           string
+	hostnameSuffix string
+	subnetOffset   string
+	vlanMin        int
+	vlanMax        int
+	baseDir        string
+	owner          string
+}
+
+func newCloneOptions(opts ...CloneOption) cloneOptions {
+	var o cloneOptions
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// CloneWithSource sets the name of the existing experiment to clone.
+func CloneWithSource(s string) CloneOption {
+	return func(o *cloneOptions) {
+		o.source = s
+	}
+}
+
+// CloneWithName sets the name of the new experiment.
+func CloneWithName(n string) CloneOption {
+	return func(o *cloneOptions) {
+		o.name = n
+	}
+}
+
+// CloneWithHostnameSuffix sets a suffix to append to every node hostname in
+// the cloned experiment.
+func CloneWithHostnameSuffix(s string) CloneOption {
+	return func(o *cloneOptions) {
+		o.hostnameSuffix = s
+	}
+}
+
+// CloneWithSubnetOffset sets an offset, in dotted IPv4 notation (for example,
+// 0.0.100.0), to add to every IPv4 address and subnet in the cloned
+// experiment.
+func CloneWithSubnetOffset(s string) CloneOption {
+	return func(o *cloneOptions) {
+		o.subnetOffset = s
+	}
+}
+
+// CloneWithVLANRange sets the VLAN range to use for the cloned experiment. If
+// not set, a free range the same size as the source experiment's is allocated.
+func CloneWithVLANRange(minVal, maxVal int) CloneOption {
+	return func(o *cloneOptions) {
+		o.vlanMin = minVal
+		o.vlanMax = maxVal
+	}
+}
+
+// CloneWithBaseDirectory sets the base directory of the cloned experiment.
+func CloneWithBaseDirectory(b string) CloneOption {
+	return func(o *cloneOptions) {
+		o.baseDir = b
+	}
+}
+
+// CloneWithOwner sets the user that owns the cloned experiment.
+func CloneWithOwner(u string) CloneOption {
+	return func(o *cloneOptions) {
+		o.owner = u
+	}
+}
+
 type SaveOption func(*saveOptions)
 
 type saveOptions struct {
@@ -182,6 +278,7 @@ type ScheduleOption func(*scheduleOptions)
 type scheduleOptions struct {
 	name      string
 	algorithm string
+	schedOpts []scheduler.Option
 }
 
 func newScheduleOptions(opts ...ScheduleOption) scheduleOptions {
@@ -206,6 +303,14 @@ func ScheduleWithAlgorithm(a string) ScheduleOption {
 	}
 }
 
+// ScheduleWithSchedulerOptions passes the given options (for example,
+// overcommit ratios) to the scheduling algorithm.
+func ScheduleWithSchedulerOptions(opts ...scheduler.Option) ScheduleOption {
+	return func(o *scheduleOptions) {
+		o.schedOpts = append(o.schedOpts, opts...)
+	}
+}
+
 type StartOption func(*startOptions)
 
 type startOptions struct {
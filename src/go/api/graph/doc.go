@@ -0,0 +1,17 @@
+/*
+Package graph exports phenix topologies and experiments as graphs for
+documentation and analysis with tools like Graphviz and networkx.
+
+Topology nodes (VMs, routers, firewalls and external nodes) are exported as
+graph nodes, and each VLAN is exported as a hub node that the interfaces in the
+VLAN are connected to. Edges are labeled with the interface name and its
+addresses. Graphs of experiments can optionally be enriched with the live state
+of each VM and the cluster host it's placed on.
+
+Supported Formats
+
+  - dot:        Graphviz DOT
+  - graphml:    GraphML XML
+  - json-graph: node-link JSON, as read by networkx's `node_link_graph`
+*/
+package graph
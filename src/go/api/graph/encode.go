@@ -0,0 +1,302 @@
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ErrUnknownFormat is returned when encoding a graph in an unsupported format.
+var ErrUnknownFormat = errors.New("unknown graph format")
+
+// Format is a graph encoding format.
+type Format string
+
+const (
+	FormatDOT       Format = "dot"
+	FormatGraphML   Format = "graphml"
+	FormatJSONGraph Format = "json-graph"
+)
+
+// Formats returns all the supported graph formats.
+func Formats() []Format {
+	return []Format{FormatDOT, FormatGraphML, FormatJSONGraph}
+}
+
+// ParseFormat returns the graph format with the given name.
+func ParseFormat(f string) (Format, error) {
+	for _, format := range Formats() {
+		if strings.EqualFold(f, string(format)) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, f)
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case FormatDOT:
+		return "text/vnd.graphviz"
+	case FormatGraphML:
+		return "application/graphml+xml"
+	case FormatJSONGraph:
+		return "application/json"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// Encode writes the graph to the given writer in the given format.
+func (g *Graph) Encode(w io.Writer, f Format) error {
+	switch f {
+	case FormatDOT:
+		return g.encodeDOT(w)
+	case FormatGraphML:
+		return g.encodeGraphML(w)
+	case FormatJSONGraph:
+		return g.encodeJSONGraph(w)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, f)
+	}
+}
+
+//nolint:gochecknoglobals // constant map
+var dotShapes = map[NodeKind]string{
+	NodeKindVM:       "box",
+	NodeKindRouter:   "circle",
+	NodeKindFirewall: "hexagon",
+	NodeKindExternal: "box3d",
+	NodeKindVLAN:     "ellipse",
+}
+
+func (g *Graph) encodeDOT(w io.Writer) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "graph %s {\n", dotQuote(g.Name))
+
+	for _, n := range g.Nodes {
+		label := n.Label
+
+		if n.VLANID != 0 {
+			label = fmt.Sprintf("%s (%d)", n.Label, n.VLANID)
+		}
+
+		attrs := []string{
+			"label=" + dotQuote(label),
+			"shape=" + dotShapes[n.Kind],
+			"kind=" + dotQuote(string(n.Kind)),
+		}
+
+		for _, attr := range nodeAttributes(n) {
+			attrs = append(attrs, attr.key+"="+dotQuote(attr.value))
+		}
+
+		fmt.Fprintf(&sb, "  %s [%s];\n", dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, e := range g.Edges {
+		attrs := []string{
+			"label=" + dotQuote(strings.Join(append([]string{e.Interface}, e.Addresses...), "\n")),
+		}
+
+		for _, attr := range edgeAttributes(e) {
+			attrs = append(attrs, attr.key+"="+dotQuote(attr.value))
+		}
+
+		fmt.Fprintf(&sb, "  %s -- %s [%s];\n", dotQuote(e.Source), dotQuote(e.Target), strings.Join(attrs, ", "))
+	}
+
+	sb.WriteString("}\n")
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("writing DOT graph: %w", err)
+	}
+
+	return nil
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+func (g *Graph) encodeGraphML(w io.Writer) error {
+	doc := graphMLDocument{ //nolint:exhaustruct // partial initialization
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "type", For: "node", Name: "type", Type: "string"},
+			{ID: "os_type", For: "node", Name: "os_type", Type: "string"},
+			{ID: "vlan_id", For: "node", Name: "vlan_id", Type: "int"},
+			{ID: "state", For: "node", Name: "state", Type: "string"},
+			{ID: "host", For: "node", Name: "host", Type: "string"},
+			{ID: "interface", For: "edge", Name: "interface", Type: "string"},
+			{ID: "vlan", For: "edge", Name: "vlan", Type: "string"},
+			{ID: "addresses", For: "edge", Name: "addresses", Type: "string"},
+		},
+	}
+
+	doc.Graph.ID = g.Name
+	doc.Graph.EdgeDefault = "undirected"
+
+	for _, n := range g.Nodes {
+		data := []graphMLData{{Key: "label", Value: n.Label}, {Key: "kind", Value: string(n.Kind)}}
+
+		for _, attr := range nodeAttributes(n) {
+			data = append(data, graphMLData{Key: attr.key, Value: attr.value})
+		}
+
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: n.ID, Data: data})
+	}
+
+	for i, e := range g.Edges {
+		var data []graphMLData
+
+		for _, attr := range edgeAttributes(e) {
+			data = append(data, graphMLData{Key: attr.key, Value: attr.value})
+		}
+
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{
+			ID:     "e" + strconv.Itoa(i),
+			Source: e.Source,
+			Target: e.Target,
+			Data:   data,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("writing GraphML graph: %w", err)
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("writing GraphML graph: %w", err)
+	}
+
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("writing GraphML graph: %w", err)
+	}
+
+	return nil
+}
+
+// encodeJSONGraph writes the graph in the node-link format read by networkx's
+// `node_link_graph` function. The graph is a multigraph since a node can have
+// more than one interface in the same VLAN.
+func (g *Graph) encodeJSONGraph(w io.Writer) error {
+	var (
+		nodes = g.Nodes
+		links = g.Edges
+	)
+
+	// networkx expects lists, not nulls, for graphs without nodes or edges.
+	if nodes == nil {
+		nodes = []Node{}
+	}
+
+	if links == nil {
+		links = []Edge{}
+	}
+
+	doc := map[string]any{
+		"directed":   false,
+		"multigraph": true,
+		"graph":      map[string]any{"name": g.Name},
+		"nodes":      nodes,
+		"links":      links,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("writing JSON graph: %w", err)
+	}
+
+	return nil
+}
+
+type attribute struct {
+	key, value string
+}
+
+// nodeAttributes returns the optional attributes of the given node that are
+// set.
+func nodeAttributes(n Node) []attribute {
+	var attrs []attribute
+
+	for _, attr := range []attribute{
+		{"type", n.Type},
+		{"os_type", n.OSType},
+		{"state", n.State},
+		{"host", n.Host},
+	} {
+		if attr.value != "" {
+			attrs = append(attrs, attr)
+		}
+	}
+
+	if n.VLANID != 0 {
+		attrs = append(attrs, attribute{"vlan_id", strconv.Itoa(n.VLANID)})
+	}
+
+	return attrs
+}
+
+func edgeAttributes(e Edge) []attribute {
+	attrs := []attribute{{"interface", e.Interface}, {"vlan", e.VLAN}}
+
+	if len(e.Addresses) > 0 {
+		attrs = append(attrs, attribute{"addresses", strings.Join(e.Addresses, ",")})
+	}
+
+	return attrs
+}
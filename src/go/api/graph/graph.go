@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"phenix/api/experiment"
+	"phenix/api/vm"
+	ifaces "phenix/types/interfaces"
+)
+
+// NodeKind is the kind of a graph node.
+type NodeKind string
+
+const (
+	NodeKindVM       NodeKind = "vm"
+	NodeKindRouter   NodeKind = "router"
+	NodeKindFirewall NodeKind = "firewall"
+	NodeKindExternal NodeKind = "external"
+
+	// NodeKindVLAN is used for the hub nodes representing VLAN segments.
+	NodeKindVLAN NodeKind = "vlan"
+)
+
+// Node is a node in a topology graph, either a topology node or a VLAN hub.
+type Node struct {
+	ID     string   `json:"id"`
+	Label  string   `json:"label"`
+	Kind   NodeKind `json:"kind"`
+	Type   string   `json:"type,omitempty"`
+	OSType string   `json:"os_type,omitempty"`
+
+	// VLANID is the VLAN ID of VLAN hubs, if known.
+	VLANID int `json:"vlan_id,omitempty"`
+
+	// State and Host are only set for experiment graphs built using the
+	// `WithLiveState` option.
+	State string `json:"state,omitempty"`
+	Host  string `json:"host,omitempty"`
+}
+
+// Edge connects a topology node's interface to the hub node of the VLAN the
+// interface is in.
+type Edge struct {
+	Source    string   `json:"source"`
+	Target    string   `json:"target"`
+	Interface string   `json:"interface"`
+	VLAN      string   `json:"vlan"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// Graph is a graph of a topology.
+type Graph struct {
+	Name  string `json:"name"`
+	Nodes []Node `json:"nodes"`
+	Edges []Edge `json:"edges"`
+}
+
+// VLANNodeID returns the ID of the hub node for the VLAN with the given alias.
+func VLANNodeID(alias string) string {
+	return "vlan:" + alias
+}
+
+// Topology builds a graph of the given topology.
+func Topology(name string, topo ifaces.TopologySpec, opts ...Option) *Graph {
+	var (
+		o     = newOptions(opts...)
+		g     = &Graph{Name: name} //nolint:exhaustruct // partial initialization
+		vlans = make(map[string]struct{})
+	)
+
+	if topo == nil {
+		return g
+	}
+
+	for _, node := range topo.Nodes() {
+		hostname := node.General().Hostname()
+
+		n := Node{ //nolint:exhaustruct // partial initialization
+			ID:    hostname,
+			Label: hostname,
+			Kind:  nodeKind(node),
+			Type:  node.Type(),
+		}
+
+		if node.Hardware() != nil {
+			n.OSType = node.Hardware().OSType()
+		}
+
+		g.Nodes = append(g.Nodes, n)
+
+		if node.Network() == nil {
+			continue
+		}
+
+		for _, iface := range node.Network().Interfaces() {
+			alias := iface.VLAN()
+
+			if alias == "" || slices.Contains(o.ignore, alias) {
+				continue
+			}
+
+			if _, ok := vlans[alias]; !ok {
+				vlans[alias] = struct{}{}
+
+				//nolint:exhaustruct // partial initialization
+				g.Nodes = append(g.Nodes, Node{ID: VLANNodeID(alias), Label: alias, Kind: NodeKindVLAN})
+			}
+
+			g.Edges = append(g.Edges, Edge{
+				Source:    hostname,
+				Target:    VLANNodeID(alias),
+				Interface: iface.Name(),
+				VLAN:      alias,
+				Addresses: iface.CIDRs(),
+			})
+		}
+	}
+
+	return g
+}
+
+// Experiment builds a graph of the topology of the experiment with the given
+// name. VLAN hubs include the VLAN ID assigned to the VLAN when known.
+func Experiment(name string, opts ...Option) (*Graph, error) {
+	o := newOptions(opts...)
+
+	exp, err := experiment.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("getting experiment %s: %w", name, err)
+	}
+
+	g := Topology(name, exp.Spec.Topology(), opts...)
+
+	vlanIDs := exp.Spec.VLANs().Aliases()
+
+	if exp.Running() {
+		vlanIDs = exp.Status.VLANs()
+	}
+
+	for i, node := range g.Nodes {
+		if node.Kind == NodeKindVLAN {
+			g.Nodes[i].VLANID = vlanIDs[node.Label]
+		}
+	}
+
+	if !o.live {
+		return g, nil
+	}
+
+	vms, err := vm.List(name)
+	if err != nil {
+		return nil, fmt.Errorf("getting VMs for experiment %s: %w", name, err)
+	}
+
+	var (
+		live     = make(map[string]int, len(vms))
+		schedule = exp.Spec.Schedules()
+	)
+
+	for i, v := range vms {
+		live[v.Name] = i
+	}
+
+	for i, node := range g.Nodes {
+		if node.Kind == NodeKindVLAN {
+			continue
+		}
+
+		g.Nodes[i].Host = schedule[node.ID]
+
+		if idx, ok := live[node.ID]; ok {
+			g.Nodes[i].State = vms[idx].State
+
+			if vms[idx].Host != "" {
+				g.Nodes[i].Host = vms[idx].Host
+			}
+		}
+	}
+
+	return g, nil
+}
+
+func nodeKind(node ifaces.NodeSpec) NodeKind {
+	switch {
+	case node.External():
+		return NodeKindExternal
+	case strings.EqualFold(node.Type(), "router"):
+		return NodeKindRouter
+	case strings.EqualFold(node.Type(), "firewall"):
+		return NodeKindFirewall
+	default:
+		return NodeKindVM
+	}
+}
@@ -0,0 +1,172 @@
+package graph_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"phenix/api/graph"
+	v1 "phenix/types/version/v1"
+)
+
+func testTopology() *v1.TopologySpec {
+	external := true
+
+	return &v1.TopologySpec{
+		NodesF: []*v1.Node{
+			{
+				TypeF:     "Router",
+				GeneralF:  &v1.General{HostnameF: "rtr"},
+				HardwareF: &v1.Hardware{OSTypeF: "linux"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						{NameF: "eth0", VLANF: "EXP", AddressF: "10.0.0.1", MaskF: 24, AddressesF: []string{"2001:db8::1/64"}},
+						{NameF: "eth1", VLANF: "MGMT", AddressF: "172.16.0.1", MaskF: 16},
+					},
+				},
+			},
+			{
+				TypeF:    "VirtualMachine",
+				GeneralF: &v1.General{HostnameF: "host"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{{NameF: "eth0", VLANF: "EXP", AddressF: "10.0.0.2", MaskF: 24}},
+				},
+			},
+			{
+				TypeF:     "HIL",
+				ExternalF: &external,
+				GeneralF:  &v1.General{HostnameF: "plc"},
+			},
+		},
+	}
+}
+
+func TestTopology(t *testing.T) {
+	g := graph.Topology("test", testTopology(), graph.IgnoreVLANs("MGMT"))
+
+	kinds := make(map[string]graph.NodeKind)
+
+	for _, n := range g.Nodes {
+		kinds[n.ID] = n.Kind
+	}
+
+	expected := map[string]graph.NodeKind{
+		"rtr":                   graph.NodeKindRouter,
+		"host":                  graph.NodeKindVM,
+		"plc":                   graph.NodeKindExternal,
+		graph.VLANNodeID("EXP"): graph.NodeKindVLAN,
+	}
+
+	if len(kinds) != len(expected) {
+		t.Fatalf("expected nodes %v, got %v", expected, kinds)
+	}
+
+	for id, kind := range expected {
+		if kinds[id] != kind {
+			t.Errorf("expected node %s to be a %s, got %s", id, kind, kinds[id])
+		}
+	}
+
+	if len(g.Edges) != 2 {
+		t.Fatalf("expected 2 edges (MGMT ignored), got %d", len(g.Edges))
+	}
+
+	e := g.Edges[0]
+
+	if e.Source != "rtr" || e.Target != graph.VLANNodeID("EXP") || e.Interface != "eth0" {
+		t.Errorf("unexpected edge %+v", e)
+	}
+
+	if strings.Join(e.Addresses, ",") != "10.0.0.1/24,2001:db8::1/64" {
+		t.Errorf("expected edge to include every interface address, got %v", e.Addresses)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	g := graph.Topology("test", testTopology())
+
+	t.Run("dot", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		if err := g.Encode(&buf, graph.FormatDOT); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{
+			`graph "test" {`,
+			`"rtr" [label="rtr", shape=circle, kind="router"`,
+			`"vlan:EXP" [label="EXP", shape=ellipse, kind="vlan"]`,
+			`"rtr" -- "vlan:EXP" [label="eth0\n10.0.0.1/24\n2001:db8::1/64", interface="eth0", vlan="EXP"`,
+		}
+
+		for _, line := range expected {
+			if !strings.Contains(buf.String(), line) {
+				t.Errorf("expected %q in DOT graph:\n%s", line, buf.String())
+			}
+		}
+	})
+
+	t.Run("graphml", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		if err := g.Encode(&buf, graph.FormatGraphML); err != nil {
+			t.Fatal(err)
+		}
+
+		var doc struct {
+			Graph struct {
+				Nodes []struct {
+					ID string `xml:"id,attr"`
+				} `xml:"node"`
+				Edges []struct {
+					Source string `xml:"source,attr"`
+				} `xml:"edge"`
+			} `xml:"graph"`
+		}
+
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("expected valid GraphML XML, got %v", err)
+		}
+
+		if len(doc.Graph.Nodes) != len(g.Nodes) || len(doc.Graph.Edges) != len(g.Edges) {
+			t.Errorf(
+				"expected %d nodes and %d edges, got %d and %d",
+				len(g.Nodes), len(g.Edges), len(doc.Graph.Nodes), len(doc.Graph.Edges),
+			)
+		}
+	})
+
+	t.Run("json-graph", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		if err := g.Encode(&buf, graph.FormatJSONGraph); err != nil {
+			t.Fatal(err)
+		}
+
+		var doc struct {
+			Multigraph bool             `json:"multigraph"`
+			Nodes      []map[string]any `json:"nodes"`
+			Links      []map[string]any `json:"links"`
+		}
+
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("expected valid JSON, got %v", err)
+		}
+
+		if !doc.Multigraph || len(doc.Nodes) != len(g.Nodes) || len(doc.Links) != len(g.Edges) {
+			t.Errorf("unexpected node-link graph: %s", buf.String())
+		}
+
+		if doc.Links[0]["source"] != "rtr" || doc.Links[0]["target"] != "vlan:EXP" {
+			t.Errorf("expected first link from rtr to vlan:EXP, got %v", doc.Links[0])
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if _, err := graph.ParseFormat("gexf"); err == nil {
+			t.Error("expected error for unknown format")
+		}
+	})
+}
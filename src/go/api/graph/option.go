@@ -0,0 +1,36 @@
+package graph
+
+// Option is a function that configures options for building an experiment
+// graph.
+type Option func(*options)
+
+type options struct {
+	live   bool
+	ignore []string
+}
+
+func newOptions(opts ...Option) options {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithLiveState enriches the graph of a running experiment with the state of
+// each VM and the cluster host it's running on.
+func WithLiveState() Option {
+	return func(o *options) {
+		o.live = true
+	}
+}
+
+// IgnoreVLANs sets VLAN aliases (for example, management VLANs) that should
+// be left out of the graph.
+func IgnoreVLANs(v ...string) Option {
+	return func(o *options) {
+		o.ignore = append(o.ignore, v...)
+	}
+}
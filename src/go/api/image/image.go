@@ -21,6 +21,7 @@ import (
 	"phenix/tmpl"
 	"phenix/types"
 	v1 "phenix/types/version/v1"
+	"phenix/util/mm"
 	"phenix/util/mm/mmcli"
 	"phenix/util/shell"
 )
@@ -713,7 +714,7 @@ func inject(disk string, injects ...string) error {
 	cmd := mmcli.NewCommand()
 	cmd.Command = fmt.Sprintf("disk inject %s files %s", disk, files)
 
-	err := mmcli.ErrorResponse(mmcli.Run(cmd))
+	err := mmcli.ErrorResponse(mm.Run(cmd))
 	if err != nil {
 		return fmt.Errorf("injecting files into disk %s: %w", disk, err)
 	}
@@ -0,0 +1,239 @@
+package importer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	v1 "phenix/types/version/v1"
+)
+
+type clabLab struct {
+	Name     string `yaml:"name"`
+	Topology struct {
+		Defaults clabNode            `yaml:"defaults"`
+		Kinds    map[string]clabNode `yaml:"kinds"`
+		Nodes    map[string]clabNode `yaml:"nodes"`
+		Links    []clabLink          `yaml:"links"`
+	} `yaml:"topology"`
+}
+
+type clabNode struct {
+	Kind          string            `yaml:"kind"`
+	Image         string            `yaml:"image"`
+	StartupConfig string            `yaml:"startup-config"`
+	Binds         []string          `yaml:"binds"`
+	Exec          []string          `yaml:"exec"`
+	Env           map[string]string `yaml:"env"`
+	Ports         []string          `yaml:"ports"`
+}
+
+type clabLink struct {
+	Type      string `yaml:"type"`
+	Endpoints []any  `yaml:"endpoints"`
+}
+
+type clabEndpoint struct {
+	Node      string
+	Interface string
+}
+
+// Containerlab node kinds that are converted to VLANs instead of nodes.
+//
+//nolint:gochecknoglobals // constant list
+var clabBridgeKinds = []string{"bridge", "ovs-bridge"}
+
+// Containerlab node kinds that are converted to phenix VMs. Nodes of all other
+// kinds are network operating systems, which are converted to phenix routers.
+//
+//nolint:gochecknoglobals // constant list
+var clabHostKinds = []string{"linux", "host"}
+
+// Containerlab nodes that can be used as link endpoints without being defined
+// in the lab's nodes.
+//
+//nolint:gochecknoglobals // constant list
+var clabSpecialNodes = []string{"host", "mgmt-net", "macvlan"}
+
+//nolint:cyclop,funlen // complex logic
+func convertContainerlab(data []byte, b *builder) (string, error) {
+	var lab clabLab
+
+	if err := yaml.Unmarshal(data, &lab); err != nil {
+		return "", fmt.Errorf("parsing containerlab topology: %w", err)
+	}
+
+	var (
+		names   = make([]string, 0, len(lab.Topology.Nodes))
+		nodes   = make(map[string]*v1.Node)
+		bridges = make(map[string]string) // bridge node --> VLAN alias
+	)
+
+	for name := range lab.Topology.Nodes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		var (
+			element = fmt.Sprintf("nodes[%s]", name)
+			node    = lab.Topology.Nodes[name]
+			kind    = firstNonEmpty(node.Kind, lab.Topology.Defaults.Kind)
+			image   = firstNonEmpty(node.Image, lab.Topology.Kinds[kind].Image, lab.Topology.Defaults.Image, kind)
+		)
+
+		if slices.Contains(clabBridgeKinds, kind) {
+			bridges[name] = b.alias(name)
+
+			continue
+		}
+
+		typ := "Router"
+
+		if slices.Contains(clabHostKinds, kind) {
+			typ = "VirtualMachine"
+		}
+
+		nodes[name] = b.addNode(element, name, image, typ)
+
+		if node.StartupConfig != "" {
+			b.report(element, "startup config %s is not imported", node.StartupConfig)
+		}
+
+		if len(node.Binds) > 0 {
+			b.report(element, "bind mounts are not imported")
+		}
+
+		if len(node.Exec) > 0 {
+			b.report(element, "exec commands are not imported")
+		}
+
+		if len(node.Env) > 0 {
+			b.report(element, "environment variables are not imported")
+		}
+
+		if len(node.Ports) > 0 {
+			b.report(element, "port publishing is not imported")
+		}
+	}
+
+	for i, link := range lab.Topology.Links {
+		element := fmt.Sprintf("links[%d]", i)
+
+		if link.Type != "" && link.Type != "veth" {
+			b.report(element, "%s links are not supported", link.Type)
+
+			continue
+		}
+
+		if len(link.Endpoints) != 2 { //nolint:mnd // point-to-point link
+			b.report(element, "links must have exactly two endpoints")
+
+			continue
+		}
+
+		endpoints := make([]clabEndpoint, 2) //nolint:mnd // point-to-point link
+
+		var skip bool
+
+		for j, e := range link.Endpoints {
+			endpoint, err := parseClabEndpoint(e)
+			if err != nil {
+				b.report(element, "%v", err)
+
+				skip = true
+
+				break
+			}
+
+			_, isNode := nodes[endpoint.Node]
+			_, isBridge := bridges[endpoint.Node]
+
+			if !isNode && !isBridge {
+				if slices.Contains(clabSpecialNodes, endpoint.Node) {
+					b.report(element, "links to %s are not supported", endpoint.Node)
+				} else {
+					b.report(element, "endpoint node %s is not defined", endpoint.Node)
+				}
+
+				skip = true
+
+				break
+			}
+
+			endpoints[j] = endpoint
+		}
+
+		if skip {
+			continue
+		}
+
+		var (
+			a, z = endpoints[0], endpoints[1]
+			vlan string
+		)
+
+		bridgeA, isBridgeA := bridges[a.Node]
+		bridgeZ, isBridgeZ := bridges[z.Node]
+
+		switch {
+		case isBridgeA && isBridgeZ:
+			b.report(element, "links between bridges %s and %s are not supported", a.Node, z.Node)
+
+			continue
+		case isBridgeA:
+			vlan = bridgeA
+		case isBridgeZ:
+			vlan = bridgeZ
+		default:
+			vlan = b.alias(nodes[a.Node].GeneralF.HostnameF + "-" + nodes[z.Node].GeneralF.HostnameF)
+		}
+
+		for _, e := range endpoints {
+			if node, ok := nodes[e.Node]; ok {
+				b.connect(node, e.Interface, vlan)
+			}
+		}
+	}
+
+	return lab.Name, nil
+}
+
+// parseClabEndpoint parses a link endpoint in either the brief (`node:iface`)
+// or extended (`{node: node, interface: iface}`) containerlab link format.
+func parseClabEndpoint(e any) (clabEndpoint, error) {
+	switch e := e.(type) {
+	case string:
+		node, iface, ok := strings.Cut(e, ":")
+		if !ok || node == "" || iface == "" {
+			return clabEndpoint{}, fmt.Errorf("invalid endpoint %s", e)
+		}
+
+		return clabEndpoint{Node: node, Interface: iface}, nil
+	case map[string]any:
+		node, _ := e["node"].(string)
+		iface, _ := e["interface"].(string)
+
+		if node == "" || iface == "" {
+			return clabEndpoint{}, fmt.Errorf("invalid endpoint %v", e)
+		}
+
+		return clabEndpoint{Node: node, Interface: iface}, nil
+	default:
+		return clabEndpoint{}, fmt.Errorf("invalid endpoint %v", e)
+	}
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
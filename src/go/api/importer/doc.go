@@ -0,0 +1,17 @@
+/*
+Package importer translates network descriptions from third-party tools into
+phenix topologies.
+
+Supported Formats
+
+  - containerlab: containerlab topology YAML files (`*.clab.yml`)
+  - gns3:         GNS3 project JSON files (`*.gns3`)
+
+Nodes are converted to phenix VMs (or routers), and point-to-point links are
+converted to VLAN aliases shared by the interfaces at each end of the link.
+Bridges, switches and hubs are converted to a single VLAN alias shared by all
+the interfaces linked to them. The images used by nodes are mapped to phenix
+drive images using a user-provided image mapping. Anything that can't be
+translated is reported as an issue rather than causing the import to fail.
+*/
+package importer
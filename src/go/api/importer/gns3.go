@@ -0,0 +1,182 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	v1 "phenix/types/version/v1"
+)
+
+type gns3Project struct {
+	Name     string `json:"name"`
+	Topology struct {
+		Nodes []gns3Node `json:"nodes"`
+		Links []gns3Link `json:"links"`
+	} `json:"topology"`
+}
+
+type gns3Node struct {
+	NodeID     string         `json:"node_id"`
+	Name       string         `json:"name"`
+	NodeType   string         `json:"node_type"`
+	Properties map[string]any `json:"properties"`
+}
+
+type gns3Link struct {
+	Nodes []gns3LinkEndpoint `json:"nodes"`
+}
+
+type gns3LinkEndpoint struct {
+	NodeID        string `json:"node_id"`
+	AdapterNumber int    `json:"adapter_number"`
+	PortNumber    int    `json:"port_number"`
+}
+
+// gns3ImageProperties maps GNS3 node types that run an image to the node
+// property holding the image. Nodes of types without an image property (such
+// as VPCS) use the node type as their image.
+//
+//nolint:gochecknoglobals // constant map
+var gns3ImageProperties = map[string]string{
+	"qemu":       "hda_disk_image",
+	"docker":     "image",
+	"dynamips":   "image",
+	"iou":        "path",
+	"virtualbox": "vmname",
+	"vmware":     "vmx_path",
+}
+
+// gns3NodeTypes maps GNS3 node types to phenix node types. GNS3 node types
+// missing from this map (and not a segment type) aren't supported.
+//
+//nolint:gochecknoglobals // constant map
+var gns3NodeTypes = map[string]string{
+	"qemu":       "VirtualMachine",
+	"docker":     "VirtualMachine",
+	"virtualbox": "VirtualMachine",
+	"vmware":     "VirtualMachine",
+	"vpcs":       "VirtualMachine",
+	"dynamips":   "Router",
+	"iou":        "Router",
+}
+
+// GNS3 node types that are converted to VLANs instead of nodes.
+//
+//nolint:gochecknoglobals // constant map
+var gns3SegmentTypes = map[string]struct{}{
+	"ethernet_switch": {},
+	"ethernet_hub":    {},
+}
+
+//nolint:cyclop,funlen // complex logic
+func convertGNS3(data []byte, b *builder) (string, error) {
+	var project gns3Project
+
+	if err := json.Unmarshal(data, &project); err != nil {
+		return "", fmt.Errorf("parsing GNS3 project: %w", err)
+	}
+
+	var (
+		nodes    = make(map[string]*v1.Node)
+		segments = make(map[string]string) // switch/hub node ID --> VLAN alias
+		names    = make(map[string]string) // node ID --> node name
+	)
+
+	for _, node := range project.Topology.Nodes {
+		element := fmt.Sprintf("nodes[%s]", node.Name)
+		names[node.NodeID] = node.Name
+
+		if _, ok := gns3SegmentTypes[node.NodeType]; ok {
+			segments[node.NodeID] = b.alias(node.Name)
+
+			if node.NodeType == "ethernet_switch" {
+				b.report(element, "switch port VLAN configuration is not imported")
+			}
+
+			continue
+		}
+
+		typ, ok := gns3NodeTypes[node.NodeType]
+		if !ok {
+			b.report(element, "%s nodes are not supported", node.NodeType)
+
+			continue
+		}
+
+		image := node.NodeType
+
+		if prop, ok := gns3ImageProperties[node.NodeType]; ok {
+			if v, ok := node.Properties[prop].(string); ok && v != "" {
+				image = v
+			}
+		}
+
+		n := b.addNode(element, node.Name, image, typ)
+
+		if ram, ok := node.Properties["ram"].(float64); ok {
+			n.HardwareF.MemoryF = int(ram)
+		}
+
+		if cpus, ok := node.Properties["cpus"].(float64); ok {
+			n.HardwareF.VCPUF = int(cpus)
+		}
+
+		nodes[node.NodeID] = n
+	}
+
+	for i, link := range project.Topology.Links {
+		element := fmt.Sprintf("links[%d]", i)
+
+		if len(link.Nodes) != 2 { //nolint:mnd // point-to-point link
+			b.report(element, "links must have exactly two endpoints")
+
+			continue
+		}
+
+		var (
+			a, z             = link.Nodes[0], link.Nodes[1]
+			segA, isSegmentA = segments[a.NodeID]
+			segZ, isSegmentZ = segments[z.NodeID]
+			nodeA, isNodeA   = nodes[a.NodeID]
+			nodeZ, isNodeZ   = nodes[z.NodeID]
+			vlan             string
+		)
+
+		if (!isNodeA && !isSegmentA) || (!isNodeZ && !isSegmentZ) {
+			b.report(element, "link between %s and %s includes an unsupported node", names[a.NodeID], names[z.NodeID])
+
+			continue
+		}
+
+		switch {
+		case isSegmentA && isSegmentZ:
+			b.report(element, "links between switches %s and %s are not supported", names[a.NodeID], names[z.NodeID])
+
+			continue
+		case isSegmentA:
+			vlan = segA
+		case isSegmentZ:
+			vlan = segZ
+		default:
+			vlan = b.alias(nodeA.GeneralF.HostnameF + "-" + nodeZ.GeneralF.HostnameF)
+		}
+
+		for _, e := range link.Nodes {
+			if node, ok := nodes[e.NodeID]; ok {
+				b.connect(node, gns3InterfaceName(e), vlan)
+			}
+		}
+	}
+
+	return project.Name, nil
+}
+
+// gns3InterfaceName returns the phenix interface name for the given GNS3 link
+// endpoint, based on its adapter (and port, for multi-port adapters).
+func gns3InterfaceName(e gns3LinkEndpoint) string {
+	if e.PortNumber == 0 {
+		return fmt.Sprintf("eth%d", e.AdapterNumber)
+	}
+
+	return fmt.Sprintf("eth%d-%d", e.AdapterNumber, e.PortNumber)
+}
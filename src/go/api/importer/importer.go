@@ -0,0 +1,369 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+
+	"phenix/api/config"
+	"phenix/store"
+	"phenix/types"
+	v1 "phenix/types/version/v1"
+)
+
+const maxHostnameLength = 63
+
+var (
+	// ErrUnknownFormat is returned when importing a topology from an unsupported
+	// format.
+	ErrUnknownFormat = errors.New("unknown import format")
+
+	hostnameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9-]+`)
+)
+
+// Format is a third-party network description format topologies can be
+// imported from.
+type Format string
+
+const (
+	FormatContainerlab Format = "containerlab"
+	FormatGNS3         Format = "gns3"
+)
+
+// Formats returns all the supported import formats.
+func Formats() []Format {
+	return []Format{FormatContainerlab, FormatGNS3}
+}
+
+// ParseFormat returns the import format with the given name.
+func ParseFormat(f string) (Format, error) {
+	for _, format := range Formats() {
+		if strings.EqualFold(f, string(format)) {
+			return format, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFormat, f)
+}
+
+// ImageMapping maps a source image (a containerlab image or GNS3 disk image)
+// to a phenix drive image, and optionally to the node type and OS type used
+// for nodes using the image.
+type ImageMapping struct {
+	Image  string `json:"image"   mapstructure:"image"   yaml:"image"`
+	Type   string `json:"type"    mapstructure:"type"    yaml:"type"`
+	OSType string `json:"os_type" mapstructure:"os_type" yaml:"os_type"`
+}
+
+// ImageMappingFromFile reads an image mapping from the given JSON or YAML
+// file. The file maps each source image to either the name of a phenix drive
+// image or an object with `image`, `type` and `os_type` keys.
+func ImageMappingFromFile(path string) (map[string]ImageMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading image mapping file %s: %w", path, err)
+	}
+
+	var raw map[string]any
+
+	// YAML is a superset of JSON, so this handles both.
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parsing image mapping file %s: %w", path, err)
+	}
+
+	mapping := make(map[string]ImageMapping, len(raw))
+
+	for src, dst := range raw {
+		switch dst := dst.(type) {
+		case string:
+			mapping[src] = ImageMapping{Image: dst} //nolint:exhaustruct // partial initialization
+		case map[string]any:
+			var m ImageMapping
+
+			if err := mapstructure.Decode(dst, &m); err != nil {
+				return nil, fmt.Errorf("decoding image mapping for %s: %w", src, err)
+			}
+
+			mapping[src] = m
+		default:
+			return nil, fmt.Errorf("invalid image mapping for %s", src)
+		}
+	}
+
+	return mapping, nil
+}
+
+// Issue is an element of an imported network description that couldn't be
+// translated (or could only be partially translated) to a phenix topology.
+type Issue struct {
+	// Element is the element of the imported file the issue applies to, for
+	// example `nodes[r1]` or `links[3]`.
+	Element string `json:"element"`
+	Message string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Element, i.Message)
+}
+
+// Result is the result of importing a topology.
+type Result struct {
+	Config *store.Config `json:"config"`
+	Issues []Issue       `json:"issues"`
+}
+
+type converter func([]byte, *builder) (string, error)
+
+//nolint:gochecknoglobals // format registry
+var converters = map[Format]converter{
+	FormatContainerlab: convertContainerlab,
+	FormatGNS3:         convertGNS3,
+}
+
+// Convert translates the given network description in the given format to a
+// phenix v1 topology config. Elements that can't be translated are included
+// in the result's issues.
+func Convert(format Format, data []byte, opts ...Option) (*Result, error) {
+	convert, ok := converters[format]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+
+	var (
+		o = newOptions(opts...)
+		b = newBuilder(o.images)
+	)
+
+	name, err := convert(data, b)
+	if err != nil {
+		return nil, fmt.Errorf("converting %s topology: %w", format, err)
+	}
+
+	if o.name != "" {
+		name = o.name
+	}
+
+	if name == "" {
+		return nil, errors.New("no topology name provided or found in imported file")
+	}
+
+	c, err := types.NewConfigFromSpec(name, b.topology())
+	if err != nil {
+		return nil, fmt.Errorf("creating topology config: %w", err)
+	}
+
+	return &Result{Config: c, Issues: b.issues}, nil
+}
+
+// Import translates the network description in the given file to a phenix v1
+// topology config (see `Convert`) and stores it, unless the `DryRun` option is
+// used.
+func Import(format Format, path string, opts ...Option) (*Result, error) {
+	o := newOptions(opts...)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	result, err := Convert(format, data, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.dryRun {
+		return result, nil
+	}
+
+	createOpts := []config.CreateOption{config.CreateFromConfig(result.Config)}
+
+	if o.validate {
+		createOpts = append(createOpts, config.CreateWithValidation())
+	}
+
+	if _, err := config.Create(createOpts...); err != nil {
+		return nil, fmt.Errorf("creating topology config: %w", err)
+	}
+
+	return result, nil
+}
+
+// builder incrementally builds a phenix topology from the nodes and links of
+// an imported network description.
+type builder struct {
+	images map[string]ImageMapping
+
+	nodes     []*v1.Node
+	hostnames map[string]struct{}
+	aliases   map[string]int
+	issues    []Issue
+}
+
+func newBuilder(images map[string]ImageMapping) *builder {
+	return &builder{ //nolint:exhaustruct // partial initialization
+		images:    images,
+		hostnames: make(map[string]struct{}),
+		aliases:   make(map[string]int),
+	}
+}
+
+func (b *builder) report(element, format string, args ...any) {
+	b.issues = append(b.issues, Issue{Element: element, Message: fmt.Sprintf(format, args...)})
+}
+
+// addNode adds a node with the given source name to the topology. The given
+// image is looked up in the image mapping to determine the node's drive image,
+// type and OS type, falling back to the given default node type.
+func (b *builder) addNode(element, name, image, defaultType string) *v1.Node {
+	hostname := b.hostname(element, name)
+
+	mapping, ok := b.images[image]
+	if !ok {
+		b.report(element, "no drive image mapping for image %s", image)
+
+		mapping.Image = image
+	}
+
+	if mapping.Type == "" {
+		mapping.Type = defaultType
+	}
+
+	if mapping.OSType == "" {
+		mapping.OSType = "linux"
+	}
+
+	node := &v1.Node{ //nolint:exhaustruct // partial initialization
+		TypeF:    mapping.Type,
+		GeneralF: &v1.General{HostnameF: hostname}, //nolint:exhaustruct // partial initialization
+		HardwareF: &v1.Hardware{ //nolint:exhaustruct // partial initialization
+			OSTypeF: mapping.OSType,
+			DrivesF: []*v1.Drive{{ImageF: mapping.Image}}, //nolint:exhaustruct // partial initialization
+		},
+	}
+
+	b.nodes = append(b.nodes, node)
+
+	return node
+}
+
+// hostname returns a unique, valid phenix hostname for the given source node
+// name, reporting an issue if the name had to be changed.
+func (b *builder) hostname(element, name string) string {
+	hostname := strings.Trim(hostnameInvalidChars.ReplaceAllString(name, "-"), "-")
+
+	if len(hostname) > maxHostnameLength {
+		hostname = strings.TrimRight(hostname[:maxHostnameLength], "-")
+	}
+
+	if hostname == "" {
+		hostname = "node"
+	}
+
+	unique := hostname
+
+	for i := 2; ; i++ {
+		if _, ok := b.hostnames[unique]; !ok {
+			break
+		}
+
+		unique = fmt.Sprintf("%s-%d", hostname, i)
+	}
+
+	if unique != name {
+		b.report(element, "node name %s is not a valid hostname, renamed to %s", name, unique)
+	}
+
+	b.hostnames[unique] = struct{}{}
+
+	return unique
+}
+
+// alias returns a unique VLAN alias based on the given name.
+func (b *builder) alias(name string) string {
+	b.aliases[name]++
+
+	if n := b.aliases[name]; n > 1 {
+		return fmt.Sprintf("%s-%d", name, n)
+	}
+
+	return name
+}
+
+// connect adds an interface with the given name in the given VLAN to the given
+// node.
+func (b *builder) connect(node *v1.Node, iface, vlan string) {
+	if node.NetworkF == nil {
+		node.NetworkF = new(v1.Network)
+	}
+
+	node.NetworkF.InterfacesF = append(node.NetworkF.InterfacesF, &v1.Interface{ //nolint:exhaustruct // partial initialization
+		NameF:  iface,
+		TypeF:  "ethernet",
+		ProtoF: "manual",
+		VLANF:  vlan,
+	})
+}
+
+// topology returns the built topology. Each node's interfaces are ordered by
+// name so they're assigned to VM NICs in port order.
+func (b *builder) topology() *v1.TopologySpec {
+	for _, node := range b.nodes {
+		if node.NetworkF == nil {
+			continue
+		}
+
+		ifaces := node.NetworkF.InterfacesF
+
+		sort.SliceStable(ifaces, func(i, j int) bool {
+			return naturalLess(ifaces[i].NameF, ifaces[j].NameF)
+		})
+	}
+
+	return &v1.TopologySpec{NodesF: b.nodes} //nolint:exhaustruct // partial initialization
+}
+
+// naturalLess compares strings by treating runs of digits as numbers, so that
+// eth2 sorts before eth10.
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		ca, restA := nextChunk(a)
+		cb, restB := nextChunk(b)
+
+		if ca != cb {
+			na, errA := strconv.Atoi(ca)
+			nb, errB := strconv.Atoi(cb)
+
+			if errA == nil && errB == nil && na != nb {
+				return na < nb
+			}
+
+			return ca < cb
+		}
+
+		a, b = restA, restB
+	}
+
+	return len(a) < len(b)
+}
+
+// nextChunk splits the leading run of digits or non-digits off the given
+// string.
+func nextChunk(s string) (string, string) {
+	digit := unicode.IsDigit(rune(s[0]))
+
+	for i, r := range s {
+		if unicode.IsDigit(r) != digit {
+			return s[:i], s[i:]
+		}
+	}
+
+	return s, ""
+}
@@ -0,0 +1,241 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+
+	"phenix/api/importer"
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+)
+
+const clabLab = `
+name: srl-lab
+topology:
+  kinds:
+    nokia_srlinux:
+      image: ghcr.io/nokia/srlinux
+  nodes:
+    srl1:
+      kind: nokia_srlinux
+    srl2:
+      kind: nokia_srlinux
+      startup-config: srl2.cfg
+    client_1:
+      kind: linux
+      image: alpine:latest
+    br0:
+      kind: bridge
+  links:
+    - endpoints: ["srl1:e1-1", "srl2:e1-1"]
+    - endpoints:
+      - node: client_1
+        interface: eth1
+      - node: br0
+        interface: eth1
+    - endpoints: ["srl1:e1-10", "br0:eth2"]
+    - endpoints: ["srl1:e1-2", "br0:eth3"]
+    - endpoints: ["srl2:e1-2", "host:srl2-e1-2"]
+`
+
+const gns3Project = `{
+  "name": "campus",
+  "topology": {
+    "nodes": [
+      {"node_id": "r1", "name": "R1", "node_type": "dynamips", "properties": {"image": "c7200.image", "ram": 512}},
+      {"node_id": "pc1", "name": "PC1", "node_type": "qemu", "properties": {"hda_disk_image": "ubuntu.qcow2", "ram": 2048, "cpus": 2}},
+      {"node_id": "sw1", "name": "SW1", "node_type": "ethernet_hub"},
+      {"node_id": "nat", "name": "NAT1", "node_type": "nat"}
+    ],
+    "links": [
+      {"nodes": [{"node_id": "r1", "adapter_number": 1, "port_number": 0}, {"node_id": "sw1", "adapter_number": 0, "port_number": 0}]},
+      {"nodes": [{"node_id": "pc1", "adapter_number": 0, "port_number": 0}, {"node_id": "sw1", "adapter_number": 0, "port_number": 1}]},
+      {"nodes": [{"node_id": "r1", "adapter_number": 0, "port_number": 0}, {"node_id": "nat", "adapter_number": 0, "port_number": 0}]}
+    ]
+  }
+}`
+
+func findNode(t *testing.T, topo ifaces.TopologySpec, hostname string) ifaces.NodeSpec {
+	t.Helper()
+
+	node := topo.FindNodeByName(hostname)
+	if node == nil {
+		t.Fatalf("expected node %s in imported topology", hostname)
+	}
+
+	return node
+}
+
+func interfaceVLANs(node ifaces.NodeSpec) map[string]string {
+	vlans := make(map[string]string)
+
+	for _, iface := range node.Network().Interfaces() {
+		vlans[iface.Name()] = iface.VLAN()
+	}
+
+	return vlans
+}
+
+func hasIssue(issues []importer.Issue, element, substr string) bool {
+	for _, i := range issues {
+		if i.Element == element && strings.Contains(i.Message, substr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestConvertContainerlab(t *testing.T) {
+	images := map[string]importer.ImageMapping{
+		"ghcr.io/nokia/srlinux": {Image: "srlinux.qc2", OSType: "linux"},
+	}
+
+	result, err := importer.Convert(importer.FormatContainerlab, []byte(clabLab), importer.WithImageMapping(images))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Config.Metadata.Name != "srl-lab" {
+		t.Errorf("expected topology name srl-lab, got %s", result.Config.Metadata.Name)
+	}
+
+	if err := types.ValidateConfigSpec(*result.Config); err != nil {
+		t.Fatalf("expected imported topology to be valid, got %v", err)
+	}
+
+	topo, err := types.DecodeTopologyFromConfig(*result.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(topo.Nodes()); n != 3 {
+		t.Fatalf("expected 3 nodes (bridges aren't nodes), got %d", n)
+	}
+
+	srl1 := findNode(t, topo, "srl1")
+
+	if srl1.Type() != "Router" {
+		t.Errorf("expected srl1 to be a Router, got %s", srl1.Type())
+	}
+
+	if image := srl1.Hardware().Drives()[0].Image(); image != "srlinux.qc2" {
+		t.Errorf("expected srl1 drive image srlinux.qc2, got %s", image)
+	}
+
+	var names []string
+
+	for _, iface := range srl1.Network().Interfaces() {
+		names = append(names, iface.Name())
+	}
+
+	if got := strings.Join(names, ","); got != "e1-1,e1-2,e1-10" {
+		t.Errorf("expected srl1 interfaces in port order, got %s", got)
+	}
+
+	expected := map[string]string{"e1-1": "srl1-srl2", "e1-2": "br0", "e1-10": "br0"}
+
+	for name, vlan := range interfaceVLANs(srl1) {
+		if expected[name] != vlan {
+			t.Errorf("expected srl1 %s in VLAN %s, got %s", name, expected[name], vlan)
+		}
+	}
+
+	client := findNode(t, topo, "client-1")
+
+	if client.Type() != "VirtualMachine" {
+		t.Errorf("expected client-1 to be a VirtualMachine, got %s", client.Type())
+	}
+
+	if vlan := interfaceVLANs(client)["eth1"]; vlan != "br0" {
+		t.Errorf("expected client-1 eth1 in VLAN br0, got %s", vlan)
+	}
+
+	issues := []struct{ element, message string }{
+		{"nodes[client_1]", "renamed to client-1"},
+		{"nodes[client_1]", "no drive image mapping for image alpine:latest"},
+		{"nodes[srl2]", "startup config srl2.cfg"},
+		{"links[4]", "links to host are not supported"},
+	}
+
+	for _, i := range issues {
+		if !hasIssue(result.Issues, i.element, i.message) {
+			t.Errorf("expected issue %q for %s, got %v", i.message, i.element, result.Issues)
+		}
+	}
+}
+
+func TestConvertGNS3(t *testing.T) {
+	images := map[string]importer.ImageMapping{
+		"c7200.image":  {Image: "vyos.qc2", OSType: "vyos"},
+		"ubuntu.qcow2": {Image: "ubuntu.qc2"},
+	}
+
+	result, err := importer.Convert(
+		importer.FormatGNS3,
+		[]byte(gns3Project),
+		importer.WithImageMapping(images),
+		importer.WithName("campus-import"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Config.Metadata.Name != "campus-import" {
+		t.Errorf("expected topology name campus-import, got %s", result.Config.Metadata.Name)
+	}
+
+	if err := types.ValidateConfigSpec(*result.Config); err != nil {
+		t.Fatalf("expected imported topology to be valid, got %v", err)
+	}
+
+	topo, err := types.DecodeTopologyFromConfig(*result.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(topo.Nodes()); n != 2 {
+		t.Fatalf("expected 2 nodes, got %d", n)
+	}
+
+	r1 := findNode(t, topo, "R1")
+
+	if r1.Type() != "Router" || r1.Hardware().OSType() != "vyos" || r1.Hardware().Memory() != 512 {
+		t.Errorf(
+			"expected R1 to be a vyos Router with 512 MB memory, got %s/%s/%d",
+			r1.Type(), r1.Hardware().OSType(), r1.Hardware().Memory(),
+		)
+	}
+
+	if vlans := interfaceVLANs(r1); len(vlans) != 1 || vlans["eth1"] != "SW1" {
+		t.Errorf("expected R1 to only have eth1 in VLAN SW1, got %v", vlans)
+	}
+
+	pc1 := findNode(t, topo, "PC1")
+
+	if pc1.Hardware().VCPU() != 2 || pc1.Hardware().Drives()[0].Image() != "ubuntu.qc2" {
+		t.Errorf("expected PC1 to have 2 VCPUs and drive ubuntu.qc2")
+	}
+
+	if vlans := interfaceVLANs(pc1); vlans["eth0"] != "SW1" {
+		t.Errorf("expected PC1 eth0 in VLAN SW1, got %v", vlans)
+	}
+
+	if !hasIssue(result.Issues, "nodes[NAT1]", "nat nodes are not supported") {
+		t.Errorf("expected unsupported NAT node to be reported, got %v", result.Issues)
+	}
+
+	if !hasIssue(result.Issues, "links[2]", "unsupported node") {
+		t.Errorf("expected link to unsupported NAT node to be reported, got %v", result.Issues)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := importer.ParseFormat("GNS3"); err != nil || f != importer.FormatGNS3 {
+		t.Errorf("expected gns3 format, got %s (%v)", f, err)
+	}
+
+	if _, err := importer.ParseFormat("eve-ng"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
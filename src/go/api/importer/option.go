@@ -0,0 +1,52 @@
+package importer
+
+// Option is a function that configures options for importing a topology.
+type Option func(*options)
+
+type options struct {
+	name     string
+	images   map[string]ImageMapping
+	validate bool
+	dryRun   bool
+}
+
+func newOptions(opts ...Option) options {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// WithName sets the name of the imported topology. If not set, the name of
+// the lab or project in the imported file is used.
+func WithName(n string) Option {
+	return func(o *options) {
+		o.name = n
+	}
+}
+
+// WithImageMapping sets the mapping of source images to phenix drive images
+// used when converting nodes.
+func WithImageMapping(m map[string]ImageMapping) Option {
+	return func(o *options) {
+		o.images = m
+	}
+}
+
+// WithValidation validates the imported topology against its schema before
+// it's stored.
+func WithValidation() Option {
+	return func(o *options) {
+		o.validate = true
+	}
+}
+
+// DryRun converts the topology without storing it.
+func DryRun() Option {
+	return func(o *options) {
+		o.dryRun = true
+	}
+}
@@ -0,0 +1,23 @@
+/*
+Package lint is a semantic linter for phenix topologies.
+
+JSON schema validation catches malformed configs, but not topologies that are
+well formed and still can't be deployed successfully, such as topologies with
+duplicate IP addresses in a VLAN or interfaces referencing rulesets that don't
+exist. The linter runs a set of pluggable rules against a topology (or the
+topology of an experiment), each of which reports errors and warnings with the
+path to the offending node or interface.
+
+Additional rules can be registered using `lint.Register`.
+
+Default Rules
+
+  - drive-image:          drive images exist in the minimega files directory
+  - duplicate-ip:         IP addresses are unique within each VLAN
+  - duplicate-mac:        MAC addresses are unique across the topology
+  - gateway-subnet:       interface gateways are within the interface subnet
+  - ospf-network:         OSPF area networks match an interface on the node
+  - route-next-hop:       route next hops are reachable from a node interface
+  - ruleset-reference:    rulesets referenced by interfaces exist on the node
+*/
+package lint
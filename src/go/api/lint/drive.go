@@ -0,0 +1,41 @@
+package lint
+
+import (
+	"fmt"
+	"os"
+
+	ifaces "phenix/types/interfaces"
+	"phenix/util/mm"
+)
+
+func init() { //nolint:gochecknoinits // rule registration
+	Register(NewRule("drive-image", "drive images exist in the minimega files directory", checkDriveImages))
+}
+
+func checkDriveImages(topo ifaces.TopologySpec) Findings {
+	var findings Findings
+
+	for _, node := range topo.Nodes() {
+		if node.External() {
+			continue
+		}
+
+		for i, drive := range node.Hardware().Drives() {
+			if drive.Image() == "" {
+				continue
+			}
+
+			if _, err := os.Stat(mm.GetMMFullPath(drive.Image())); err != nil {
+				// Images may still be available on other cluster hosts, so this is
+				// only a warning.
+				findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+					Severity: SeverityWarning,
+					Path:     fmt.Sprintf("%s.hardware.drives[%d]", nodePath(node), i),
+					Message:  fmt.Sprintf("image %s does not exist in the minimega files directory", drive.Image()),
+				})
+			}
+		}
+	}
+
+	return findings
+}
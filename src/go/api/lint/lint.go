@@ -0,0 +1,254 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"phenix/api/config"
+	// The experiment config hook populates the experiment's topology on create,
+	// so it needs to be registered before the lint hook.
+	_ "phenix/api/experiment"
+	"phenix/api/settings"
+	"phenix/store"
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+	"phenix/util/plog"
+)
+
+// ErrLintFailed is returned when a topology has lint errors.
+var ErrLintFailed = errors.New("lint failed")
+
+// Severity is the severity of a lint finding.
+type Severity string
+
+const (
+	// SeverityError is used for findings that will keep an experiment from
+	// starting or running correctly.
+	SeverityError Severity = "error"
+
+	// SeverityWarning is used for findings that might keep an experiment from
+	// running correctly.
+	SeverityWarning Severity = "warning"
+)
+
+// Finding is a single issue found by a lint rule.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+
+	// Path is the path to the node (and interface, route, etc.) in the topology
+	// the finding applies to, for example `nodes[router].network.interfaces[eth0]`.
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s: %s (%s)", f.Severity, f.Path, f.Message, f.Rule)
+}
+
+// Findings is a list of lint findings.
+type Findings []Finding
+
+// Errors returns the findings with error severity.
+func (f Findings) Errors() Findings {
+	return f.withSeverity(SeverityError)
+}
+
+// Warnings returns the findings with warning severity.
+func (f Findings) Warnings() Findings {
+	return f.withSeverity(SeverityWarning)
+}
+
+// Err returns an error wrapping ErrLintFailed that lists every error finding,
+// or nil if there aren't any.
+func (f Findings) Err() error {
+	errs := f.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+
+	msgs := make([]string, len(errs))
+
+	for i, e := range errs {
+		msgs[i] = e.String()
+	}
+
+	return fmt.Errorf("%w: %d error(s): %s", ErrLintFailed, len(errs), strings.Join(msgs, "; "))
+}
+
+func (f Findings) withSeverity(s Severity) Findings {
+	var findings Findings
+
+	for _, finding := range f {
+		if finding.Severity == s {
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// Rule is a lint rule run against topologies.
+type Rule interface {
+	// Name returns the name of the rule, which is included in each of the rule's
+	// findings.
+	Name() string
+
+	// Description returns a short description of what the rule checks.
+	Description() string
+
+	// Check runs the rule against the given topology.
+	Check(ifaces.TopologySpec) Findings
+}
+
+// CheckFunc is a function that checks a topology for a lint rule.
+type CheckFunc func(ifaces.TopologySpec) Findings
+
+type rule struct {
+	name        string
+	description string
+	check       CheckFunc
+}
+
+// NewRule returns a lint rule with the given name and description that uses
+// the given function to check topologies.
+func NewRule(name, description string, check CheckFunc) Rule { //nolint:ireturn // factory
+	return rule{name: name, description: description, check: check}
+}
+
+func (r rule) Name() string {
+	return r.name
+}
+
+func (r rule) Description() string {
+	return r.description
+}
+
+func (r rule) Check(topo ifaces.TopologySpec) Findings {
+	return r.check(topo)
+}
+
+var rules = make(map[string]Rule) //nolint:gochecknoglobals // global registry
+
+// Register registers the given rule to be run when linting topologies. Rules
+// registered with the name of an existing rule replace it.
+func Register(r Rule) {
+	rules[r.Name()] = r
+}
+
+// Rules returns all the registered lint rules, ordered by name.
+func Rules() []Rule {
+	list := make([]Rule, 0, len(rules))
+
+	for _, r := range rules {
+		list = append(list, r)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name() < list[j].Name() })
+
+	return list
+}
+
+// Topology runs all the registered lint rules against the given topology.
+// Findings are ordered by path, then by rule.
+func Topology(topo ifaces.TopologySpec, opts ...Option) Findings {
+	var (
+		o        = newOptions(opts...)
+		findings Findings
+	)
+
+	for _, r := range Rules() {
+		if slices.Contains(o.skip, r.Name()) {
+			continue
+		}
+
+		for _, finding := range r.Check(topo) {
+			finding.Rule = r.Name()
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+
+		return findings[i].Rule < findings[j].Rule
+	})
+
+	return findings
+}
+
+// Config runs all the registered lint rules against the topology in the given
+// config. Topology and Experiment configs are supported. Topology templates are
+// rendered using the parameter values set with the TopologyParameters option
+// (and the parameters' defaults) before they're linted. If a required
+// parameter has no value, the template can't be rendered, so a warning is
+// returned instead of linting it.
+func Config(c store.Config, opts ...Option) (Findings, error) {
+	var topo ifaces.TopologySpec
+
+	switch c.Kind {
+	case "Topology":
+		var err error
+
+		topo, err = types.DecodeTopologyFromConfigWithParameters(c, newOptions(opts...).params)
+		if errors.Is(err, types.ErrMissingTopologyParameter) {
+			finding := Finding{
+				Rule:     "topology-template",
+				Severity: SeverityWarning,
+				Path:     "parameters",
+				Message:  fmt.Sprintf("topology template not linted (%v)", err),
+			}
+
+			return Findings{finding}, nil
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("decoding topology: %w", err)
+		}
+	case "Experiment":
+		exp, err := types.DecodeExperimentFromConfig(c)
+		if err != nil {
+			return nil, fmt.Errorf("decoding experiment: %w", err)
+		}
+
+		topo = exp.Spec.Topology()
+	default:
+		return nil, fmt.Errorf("linting %s configs is not supported", c.Kind)
+	}
+
+	return Topology(topo, opts...), nil
+}
+
+func init() { //nolint:gochecknoinits // config hook
+	hook := func(stage string, c *store.Config) error {
+		if stage != "create" {
+			return nil
+		}
+
+		enabled, err := settings.GetConfigSettings()
+		if err != nil {
+			plog.Warn(plog.TypeSystem, "unable to get config settings -- not linting config", "config", c.FullName(), "err", err)
+
+			return nil
+		}
+
+		if !enabled.LintOnCreate {
+			return nil
+		}
+
+		findings, err := Config(*c)
+		if err != nil {
+			return fmt.Errorf("linting config %s: %w", c.FullName(), err)
+		}
+
+		return findings.Err()
+	}
+
+	config.RegisterConfigHook("Topology", hook)
+	config.RegisterConfigHook("Experiment", hook)
+}
@@ -0,0 +1,252 @@
+package lint_test
+
+import (
+	"errors"
+	"testing"
+
+	"phenix/api/lint"
+	"phenix/store"
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+	v1 "phenix/types/version/v1"
+)
+
+func TestTopology(t *testing.T) {
+	topo := &v1.TopologySpec{
+		NodesF: []*v1.Node{
+			{
+				GeneralF: &v1.General{HostnameF: "router"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						{NameF: "eth0", VLANF: "EXP", AddressF: "10.0.0.1", MaskF: 24, MACF: "00:00:00:00:00:01", RulesetInF: "bogus"},
+						{NameF: "eth1", VLANF: "MGMT", AddressF: "172.16.0.1", MaskF: 16},
+					},
+					RoutesF: []v1.Route{
+						{DestinationF: "192.168.0.0/24", NextF: "10.0.0.254"},
+						{DestinationF: "0.0.0.0/0", NextF: "192.168.1.1"},
+					},
+					OSPFF: &v1.OSPF{
+						AreasF: []v1.Area{
+							{AreaNetworksF: []v1.AreaNetwork{{NetworkF: "10.0.0.0/24"}, {NetworkF: "10.1.0.0/24"}}},
+						},
+					},
+				},
+			},
+			{
+				GeneralF: &v1.General{HostnameF: "host"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						// Same IP as router eth0, but in a different VLAN.
+						{NameF: "eth0", VLANF: "OTHER", AddressF: "10.0.0.1", MaskF: 24, GatewayF: "10.1.0.1"},
+						{NameF: "eth1", VLANF: "MGMT", AddressF: "172.16.0.1", MaskF: 16, MACF: "00:00:00:00:00:01"},
+					},
+				},
+			},
+			{
+				// Nodes without a network shouldn't cause any findings.
+				GeneralF: &v1.General{HostnameF: "empty"},
+			},
+		},
+	}
+
+	expected := map[string]lint.Severity{
+		"duplicate-ip nodes[host].network.interfaces[eth1]":                      lint.SeverityError,
+		"duplicate-mac nodes[host].network.interfaces[eth1]":                     lint.SeverityError,
+		"gateway-subnet nodes[host].network.interfaces[eth0]":                    lint.SeverityWarning,
+		"ospf-network nodes[router].network.ospf.areas[0].networks[10.1.0.0/24]": lint.SeverityWarning,
+		"route-next-hop nodes[router].network.routes[0.0.0.0/0]":                 lint.SeverityWarning,
+		"ruleset-reference nodes[router].network.interfaces[eth0]":               lint.SeverityError,
+	}
+
+	findings := lint.Topology(topo, lint.SkipRules("drive-image"))
+
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %d: %v", len(expected), len(findings), findings)
+	}
+
+	for _, f := range findings {
+		severity, ok := expected[f.Rule+" "+f.Path]
+		if !ok {
+			t.Errorf("unexpected finding %v", f)
+
+			continue
+		}
+
+		if f.Severity != severity {
+			t.Errorf("expected %s severity for %v", severity, f)
+		}
+	}
+
+	if len(findings.Errors()) != 3 || len(findings.Warnings()) != 3 {
+		t.Fatalf("expected 3 errors and 3 warnings, got %v", findings)
+	}
+
+	if err := findings.Err(); !errors.Is(err, lint.ErrLintFailed) {
+		t.Fatalf("expected lint failed error, got %v", err)
+	}
+
+	findings = lint.Topology(topo, lint.SkipRules("drive-image", "duplicate-ip", "duplicate-mac", "ruleset-reference"))
+
+	if err := findings.Err(); err != nil {
+		t.Fatalf("expected no lint errors, got %v", err)
+	}
+}
+
+// TestConfigTemplate verifies that topology templates are rendered before being
+// linted, and are skipped with a warning when a required parameter is missing.
+func TestConfigTemplate(t *testing.T) {
+	c := store.Config{ //nolint:exhaustruct // partial initialization
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Topology",
+		Metadata: store.ConfigMetadata{Name: "template"}, //nolint:exhaustruct // partial initialization
+		Spec: map[string]any{
+			"parameters": map[string]any{"subnet": map[string]any{"type": "string"}},
+			"generators": []any{
+				map[string]any{
+					"count": 2,
+					"nodes": []any{
+						map[string]any{
+							"type":    "VirtualMachine",
+							"general": map[string]any{"hostname": "host-{{ .index }}"},
+							"network": map[string]any{
+								"interfaces": []any{
+									// Every generated host gets the same address.
+									map[string]any{"name": "eth0", "vlan": "EXP", "address": "{{ .subnet }}.1", "mask": 24},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	findings, err := lint.Config(c, lint.SkipRules("drive-image"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(findings) != 1 || findings[0].Rule != "topology-template" || findings[0].Severity != lint.SeverityWarning {
+		t.Fatalf("expected a topology template warning, got %v", findings)
+	}
+
+	findings, err = lint.Config(c, lint.SkipRules("drive-image"), lint.TopologyParameters(map[string]string{"subnet": "10.0.0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(findings) != 1 || findings[0].Rule != "duplicate-ip" {
+		t.Fatalf("expected a duplicate IP finding for the rendered topology, got %v", findings)
+	}
+
+	if _, err := lint.Config(c, lint.TopologyParameters(map[string]string{"bogus": "1"})); !errors.Is(err, types.ErrInvalidTopologyParameter) {
+		t.Fatalf("expected invalid topology parameter error, got %v", err)
+	}
+}
+
+func TestBGP(t *testing.T) {
+	topo := &v1.TopologySpec{
+		NodesF: []*v1.Node{
+			{
+				GeneralF: &v1.General{HostnameF: "edge"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						{NameF: "eth0", VLANF: "WAN", AddressF: "10.0.0.1", MaskF: 30},
+						{NameF: "eth1", VLANF: "CORE", AddressF: "10.1.0.1", MaskF: 24},
+					},
+					RoutesF: []v1.Route{{DestinationF: "10.9.0.0/16", NextF: "10.1.0.254"}},
+					BGPF: &v1.BGP{
+						ASNF: 65001,
+						NeighborsF: []v1.BGPNeighbor{
+							{AddressF: "10.0.0.2", RemoteASNF: 65000}, // wrong ASN for isp
+							{AddressF: "10.1.0.2", RemoteASNF: 65001}, // iBGP, directly connected
+							{AddressF: "10.9.0.1", RemoteASNF: 65009}, // routed, but no multihop
+							{AddressF: "10.9.0.2", RemoteASNF: 65009, EBGPMultihopF: 2},
+							{AddressF: "192.168.0.1", RemoteASNF: 65003}, // unreachable
+							{AddressF: "10.1.0.1", RemoteASNF: 65001},    // itself
+							{AddressF: "bogus", RemoteASNF: 65001},
+						},
+						RedistributeF: []v1.BGPRedistribute{
+							{ProtocolF: "connected", RouteMapF: "CONNECTED"},
+							{ProtocolF: "static", RouteMapF: "MISSING"},
+						},
+						RouteMapsF: []v1.BGPRouteMap{
+							{NameF: "CONNECTED", RulesF: []v1.BGPRouteMapRule{{ActionF: "permit"}}},
+							{NameF: "MIXED", RulesF: []v1.BGPRouteMapRule{
+								{ActionF: "permit", PrefixesF: []string{"2001:db8::/32"}},
+								{ActionF: "permit", PrefixesF: []string{"10.0.0.0/8", "2001:db8::/32"}},
+							}},
+						},
+					},
+				},
+			},
+			{
+				GeneralF: &v1.General{HostnameF: "isp"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						{NameF: "eth0", VLANF: "WAN", AddressF: "10.0.0.2", MaskF: 30},
+					},
+					BGPF: &v1.BGP{
+						ASNF:       65002,
+						NeighborsF: []v1.BGPNeighbor{{AddressF: "10.0.0.1", RemoteASNF: 65001}},
+					},
+				},
+			},
+		},
+	}
+
+	expected := map[string]lint.Severity{
+		"bgp-peer nodes[edge].network.bgp.neighbors[10.0.0.2]":             lint.SeverityError,
+		"bgp-peer nodes[edge].network.bgp.neighbors[10.9.0.1]":             lint.SeverityWarning,
+		"bgp-peer nodes[edge].network.bgp.neighbors[192.168.0.1]":          lint.SeverityWarning,
+		"bgp-peer nodes[edge].network.bgp.neighbors[10.1.0.1]":             lint.SeverityError,
+		"bgp-peer nodes[edge].network.bgp.neighbors[bogus]":                lint.SeverityError,
+		"bgp-route-map nodes[edge].network.bgp.redistribute[static]":       lint.SeverityError,
+		"bgp-route-map nodes[edge].network.bgp.route_maps[MIXED].rules[1]": lint.SeverityError,
+	}
+
+	findings := lint.Topology(topo, lint.SkipRules("drive-image"))
+
+	if len(findings) != len(expected) {
+		t.Fatalf("expected %d findings, got %d: %v", len(expected), len(findings), findings)
+	}
+
+	for _, f := range findings {
+		severity, ok := expected[f.Rule+" "+f.Path]
+		if !ok {
+			t.Errorf("unexpected finding %v", f)
+
+			continue
+		}
+
+		if f.Severity != severity {
+			t.Errorf("expected %s severity for %v", severity, f)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	lint.Register(lint.NewRule("test-rule", "nodes are named", func(topo ifaces.TopologySpec) lint.Findings {
+		var findings lint.Findings
+
+		for _, node := range topo.Nodes() {
+			if node.General().Hostname() == "" {
+				findings = append(findings, lint.Finding{ //nolint:exhaustruct // partial initialization
+					Severity: lint.SeverityWarning,
+					Path:     "nodes[]",
+					Message:  "node has no hostname",
+				})
+			}
+		}
+
+		return findings
+	}))
+
+	topo := &v1.TopologySpec{NodesF: []*v1.Node{{GeneralF: &v1.General{}}}}
+
+	findings := lint.Topology(topo, lint.SkipRules("drive-image"))
+
+	if len(findings) != 1 || findings[0].Rule != "test-rule" {
+		t.Fatalf("expected a single test-rule finding, got %v", findings)
+	}
+}
@@ -0,0 +1,484 @@
+package lint
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	ifaces "phenix/types/interfaces"
+)
+
+func init() { //nolint:gochecknoinits // rule registration
+	Register(NewRule("bgp-peer", "BGP neighbors are reachable from the node and use the peer's ASN", checkBGPPeers))
+	Register(NewRule("bgp-route-map", "route maps referenced by BGP redistribution exist on the node and their rules match a single address family", checkBGPRouteMaps))
+	Register(NewRule("duplicate-ip", "IP addresses are unique within each VLAN", checkDuplicateIPs))
+	Register(NewRule("duplicate-mac", "MAC addresses are unique across the topology", checkDuplicateMACs))
+	Register(NewRule("gateway-subnet", "interface gateways are within the interface subnet", checkGatewaySubnets))
+	Register(NewRule("ospf-network", "OSPF area networks match an interface on the node", checkOSPFNetworks))
+	Register(NewRule("route-next-hop", "route next hops are reachable from a node interface", checkRouteNextHops))
+	Register(NewRule("ruleset-reference", "rulesets referenced by interfaces exist on the node", checkRulesetReferences))
+}
+
+func checkDuplicateIPs(topo ifaces.TopologySpec) Findings {
+	var (
+		findings Findings
+		seen     = make(map[string]string) // VLAN/IP --> path
+	)
+
+	for _, node := range topo.Nodes() {
+		for _, iface := range node.Network().Interfaces() {
+			for _, addr := range interfacePrefixes(iface) {
+				var (
+					path = interfacePath(node, iface)
+					key  = strings.ToLower(iface.VLAN()) + "/" + addr.Addr().String()
+				)
+
+				if other, ok := seen[key]; ok {
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityError,
+						Path:     path,
+						Message:  fmt.Sprintf("IP address %s in VLAN %s is also used by %s", addr.Addr(), iface.VLAN(), other),
+					})
+
+					continue
+				}
+
+				seen[key] = path
+			}
+		}
+	}
+
+	return findings
+}
+
+func checkDuplicateMACs(topo ifaces.TopologySpec) Findings {
+	var (
+		findings Findings
+		seen     = make(map[string]string) // MAC --> path
+	)
+
+	for _, node := range topo.Nodes() {
+		for _, iface := range node.Network().Interfaces() {
+			if iface.MAC() == "" {
+				continue
+			}
+
+			var (
+				path = interfacePath(node, iface)
+				mac  = strings.ToLower(iface.MAC())
+			)
+
+			if other, ok := seen[mac]; ok {
+				findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+					Severity: SeverityError,
+					Path:     path,
+					Message:  fmt.Sprintf("MAC address %s is also used by %s", iface.MAC(), other),
+				})
+
+				continue
+			}
+
+			seen[mac] = path
+		}
+	}
+
+	return findings
+}
+
+func checkGatewaySubnets(topo ifaces.TopologySpec) Findings {
+	var findings Findings
+
+	for _, node := range topo.Nodes() {
+		for _, iface := range node.Network().Interfaces() {
+			prefixes := interfacePrefixes(iface)
+			if len(prefixes) == 0 {
+				continue
+			}
+
+			for _, gateway := range []string{iface.Gateway(), iface.Gateway6()} {
+				if gateway == "" {
+					continue
+				}
+
+				gw, err := netip.ParseAddr(gateway)
+				if err != nil {
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityError,
+						Path:     interfacePath(node, iface),
+						Message:  fmt.Sprintf("gateway %s is not a valid IP address", gateway),
+					})
+
+					continue
+				}
+
+				if !containsAny(gw, prefixes) {
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityWarning,
+						Path:     interfacePath(node, iface),
+						Message:  fmt.Sprintf("gateway %s is not within any interface subnet", gw),
+					})
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+func checkOSPFNetworks(topo ifaces.TopologySpec) Findings {
+	var findings Findings
+
+	for _, node := range topo.Nodes() {
+		ospf := node.Network().OSPF()
+		if ospf == nil {
+			continue
+		}
+
+		subnets := interfaceSubnets(node)
+
+		for _, area := range ospf.Areas() {
+			var id int
+
+			if area.AreaID() != nil {
+				id = *area.AreaID()
+			}
+
+			for _, network := range area.AreaNetworks() {
+				path := fmt.Sprintf("%s.network.ospf.areas[%d].networks[%s]", nodePath(node), id, network.Network())
+
+				prefix, err := netip.ParsePrefix(network.Network())
+				if err != nil {
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityError,
+						Path:     path,
+						Message:  fmt.Sprintf("network %s is not a valid CIDR", network.Network()),
+					})
+
+					continue
+				}
+
+				if !overlapsAny(prefix.Masked(), subnets) {
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityWarning,
+						Path:     path,
+						Message:  fmt.Sprintf("network %s does not match any interface on the node", prefix.Masked()),
+					})
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+//nolint:funlen // complex logic
+func checkBGPPeers(topo ifaces.TopologySpec) Findings {
+	var (
+		findings Findings
+		owners   = make(map[netip.Addr]ifaces.NodeSpec) // address --> node
+		ospf     []netip.Prefix                         // OSPF area networks across the topology
+	)
+
+	for _, node := range topo.Nodes() {
+		for _, iface := range node.Network().Interfaces() {
+			for _, prefix := range interfacePrefixes(iface) {
+				owners[prefix.Addr()] = node
+			}
+		}
+
+		if node.Network().OSPF() == nil {
+			continue
+		}
+
+		for _, area := range node.Network().OSPF().Areas() {
+			for _, network := range area.AreaNetworks() {
+				if prefix, err := netip.ParsePrefix(network.Network()); err == nil {
+					ospf = append(ospf, prefix.Masked())
+				}
+			}
+		}
+	}
+
+	for _, node := range topo.Nodes() {
+		bgp := node.Network().BGP()
+		if bgp == nil {
+			continue
+		}
+
+		var (
+			subnets = interfaceSubnets(node)
+			routes  = routeDestinations(node)
+		)
+
+		for _, neighbor := range bgp.Neighbors() {
+			path := fmt.Sprintf("%s.network.bgp.neighbors[%s]", nodePath(node), neighbor.Address())
+
+			peer, err := netip.ParseAddr(neighbor.Address())
+			if err != nil {
+				findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+					Severity: SeverityError,
+					Path:     path,
+					Message:  fmt.Sprintf("neighbor %s is not a valid IP address", neighbor.Address()),
+				})
+
+				continue
+			}
+
+			owner := owners[peer]
+
+			if owner == node {
+				findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+					Severity: SeverityError,
+					Path:     path,
+					Message:  fmt.Sprintf("neighbor %s is an address of the node itself", peer),
+				})
+
+				continue
+			}
+
+			if !containsAny(peer, subnets) {
+				routed := containsAny(peer, routes) || (node.Network().OSPF() != nil && containsAny(peer, ospf))
+
+				switch {
+				case !routed:
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityWarning,
+						Path:     path,
+						Message:  fmt.Sprintf("neighbor %s is not within any interface subnet or route on the node", peer),
+					})
+				case neighbor.RemoteASN() != bgp.ASN() && neighbor.EBGPMultihop() == 0:
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityWarning,
+						Path:     path,
+						Message:  fmt.Sprintf("eBGP neighbor %s is not directly connected and ebgp_multihop is not set", peer),
+					})
+				}
+			}
+
+			if owner == nil || owner.Network().BGP() == nil {
+				continue
+			}
+
+			if asn := owner.Network().BGP().ASN(); asn != neighbor.RemoteASN() {
+				findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+					Severity: SeverityError,
+					Path:     path,
+					Message: fmt.Sprintf(
+						"neighbor %s (%s) has ASN %d, not remote ASN %d",
+						peer, owner.General().Hostname(), asn, neighbor.RemoteASN(),
+					),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+func checkBGPRouteMaps(topo ifaces.TopologySpec) Findings {
+	var findings Findings
+
+	for _, node := range topo.Nodes() {
+		bgp := node.Network().BGP()
+		if bgp == nil {
+			continue
+		}
+
+		maps := make(map[string]struct{})
+
+		for _, m := range bgp.RouteMaps() {
+			maps[m.Name()] = struct{}{}
+
+			for i, rule := range m.Rules() {
+				var v4, v6 bool
+
+				for _, cidr := range rule.Prefixes() {
+					prefix, err := netip.ParsePrefix(cidr)
+					if err != nil {
+						continue
+					}
+
+					v4 = v4 || prefix.Addr().Is4()
+					v6 = v6 || prefix.Addr().Is6()
+				}
+
+				// Routers match IPv4 and IPv6 prefix lists in the same rule as
+				// a logical AND, so a rule mixing both would never match.
+				if v4 && v6 {
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityError,
+						Path:     fmt.Sprintf("%s.network.bgp.route_maps[%s].rules[%d]", nodePath(node), m.Name(), i),
+						Message:  "rule mixes IPv4 and IPv6 prefixes; use a separate rule for each address family",
+					})
+				}
+			}
+		}
+
+		for _, redist := range bgp.Redistribute() {
+			if redist.RouteMap() == "" {
+				continue
+			}
+
+			if _, ok := maps[redist.RouteMap()]; !ok {
+				findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+					Severity: SeverityError,
+					Path:     fmt.Sprintf("%s.network.bgp.redistribute[%s]", nodePath(node), redist.Protocol()),
+					Message:  fmt.Sprintf("route_map %s is not defined on the node", redist.RouteMap()),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+func checkRouteNextHops(topo ifaces.TopologySpec) Findings {
+	var findings Findings
+
+	for _, node := range topo.Nodes() {
+		subnets := interfaceSubnets(node)
+
+		for _, route := range node.Network().Routes() {
+			path := fmt.Sprintf("%s.network.routes[%s]", nodePath(node), route.Destination())
+
+			next, err := netip.ParseAddr(route.Next())
+			if err != nil {
+				findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+					Severity: SeverityError,
+					Path:     path,
+					Message:  fmt.Sprintf("next hop %s is not a valid IP address", route.Next()),
+				})
+
+				continue
+			}
+
+			if !containsAny(next, subnets) {
+				findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+					Severity: SeverityWarning,
+					Path:     path,
+					Message:  fmt.Sprintf("next hop %s is not within any interface subnet on the node", next),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+func checkRulesetReferences(topo ifaces.TopologySpec) Findings {
+	var findings Findings
+
+	for _, node := range topo.Nodes() {
+		rulesets := make(map[string]struct{})
+
+		for _, ruleset := range node.Network().Rulesets() {
+			rulesets[ruleset.Name()] = struct{}{}
+		}
+
+		for _, iface := range node.Network().Interfaces() {
+			refs := []struct{ direction, name string }{
+				{"in", iface.RulesetIn()},
+				{"out", iface.RulesetOut()},
+			}
+
+			for _, ref := range refs {
+				if ref.name == "" {
+					continue
+				}
+
+				if _, ok := rulesets[ref.name]; !ok {
+					findings = append(findings, Finding{ //nolint:exhaustruct // partial initialization
+						Severity: SeverityError,
+						Path:     interfacePath(node, iface),
+						Message:  fmt.Sprintf("ruleset_%s %s is not defined on the node", ref.direction, ref.name),
+					})
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+// interfacePrefixes returns the primary and any additional addresses of the
+// given interface as prefixes. Nothing is returned for interfaces without a
+// static address (serial, DHCP, etc.).
+func interfacePrefixes(iface ifaces.NodeNetworkInterface) []netip.Prefix {
+	if iface.Type() == "serial" || iface.Address() == "" {
+		return nil
+	}
+
+	var prefixes []netip.Prefix
+
+	for _, cidr := range iface.CIDRs() {
+		if prefix, err := netip.ParsePrefix(cidr); err == nil {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+
+	return prefixes
+}
+
+func interfaceSubnets(node ifaces.NodeSpec) []netip.Prefix {
+	var subnets []netip.Prefix
+
+	for _, iface := range node.Network().Interfaces() {
+		for _, prefix := range interfacePrefixes(iface) {
+			subnets = append(subnets, prefix.Masked())
+		}
+	}
+
+	return subnets
+}
+
+// routeDestinations returns the destinations of the static routes of the given
+// node, including default routes for any interface gateways.
+func routeDestinations(node ifaces.NodeSpec) []netip.Prefix {
+	var routes []netip.Prefix
+
+	for _, route := range node.Network().Routes() {
+		if prefix, err := netip.ParsePrefix(route.Destination()); err == nil {
+			routes = append(routes, prefix.Masked())
+		}
+	}
+
+	for _, iface := range node.Network().Interfaces() {
+		if iface.Gateway() != "" {
+			routes = append(routes, netip.MustParsePrefix("0.0.0.0/0"))
+		}
+
+		if iface.Gateway6() != "" {
+			routes = append(routes, netip.MustParsePrefix("::/0"))
+		}
+	}
+
+	return routes
+}
+
+func containsAny(addr netip.Addr, subnets []netip.Prefix) bool {
+	for _, subnet := range subnets {
+		if subnet.Masked().Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func overlapsAny(prefix netip.Prefix, subnets []netip.Prefix) bool {
+	for _, subnet := range subnets {
+		if subnet.Overlaps(prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func nodePath(node ifaces.NodeSpec) string {
+	return fmt.Sprintf("nodes[%s]", node.General().Hostname())
+}
+
+func interfacePath(node ifaces.NodeSpec, iface ifaces.NodeNetworkInterface) string {
+	return fmt.Sprintf("%s.network.interfaces[%s]", nodePath(node), iface.Name())
+}
@@ -0,0 +1,34 @@
+package lint
+
+// Option is a function that configures options for linting a topology.
+type Option func(*options)
+
+type options struct {
+	skip   []string
+	params map[string]string
+}
+
+func newOptions(opts ...Option) options {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// SkipRules sets the names of rules that should not be run.
+func SkipRules(r ...string) Option {
+	return func(o *options) {
+		o.skip = append(o.skip, r...)
+	}
+}
+
+// TopologyParameters sets the values used for the parameters of topology
+// templates when rendering them to be linted.
+func TopologyParameters(p map[string]string) Option {
+	return func(o *options) {
+		o.params = p
+	}
+}
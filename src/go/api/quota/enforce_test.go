@@ -0,0 +1,146 @@
+package quota_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"phenix/api/experiment"
+	"phenix/api/quota"
+	"phenix/api/vm"
+	"phenix/store"
+)
+
+// newQuotaStore replaces the default store with an empty one for the duration
+// of the test, holding a user named alice with a quota of 4 vCPUs.
+func newQuotaStore(t *testing.T) {
+	t.Helper()
+
+	s, err := store.NewFromEndpoint("bolt://" + filepath.Join(t.TempDir(), "store.bdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := store.DefaultStore
+	store.DefaultStore = s //nolint:reassign // testing
+
+	t.Cleanup(func() {
+		_ = s.Close()
+		store.DefaultStore = orig //nolint:reassign // testing
+	})
+
+	createConfig(t, "User", "alice", map[string]any{
+		"username": "alice",
+		"quota":    map[string]any{"vcpus": 4},
+	}, nil)
+}
+
+func createConfig(t *testing.T, kind, name string, spec, status map[string]any) {
+	t.Helper()
+
+	c := &store.Config{ //nolint:exhaustruct // partial initialization
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     kind,
+		Metadata: store.ConfigMetadata{Name: name, Annotations: map[string]string{"owner": "alice"}}, //nolint:exhaustruct // partial initialization
+		Spec:     spec,
+		Status:   status,
+	}
+
+	if err := store.Create(c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// topologySpec returns a topology spec with a single VM that has the given
+// number of vCPUs.
+func topologySpec(vcpus int) map[string]any {
+	return map[string]any{
+		"nodes": []any{
+			map[string]any{
+				"type":     "VirtualMachine",
+				"general":  map[string]any{"hostname": "vm"},
+				"hardware": map[string]any{"vcpus": vcpus, "memory": 512},
+			},
+		},
+	}
+}
+
+func createExperiment(t *testing.T, name string, vcpus int, running bool) {
+	t.Helper()
+
+	var status map[string]any
+
+	if running {
+		status = map[string]any{"startTime": "2026-01-01T00:00:00Z"}
+	}
+
+	createConfig(t, "Experiment", name, map[string]any{
+		"experimentName": name,
+		"topology":       topologySpec(vcpus),
+	}, status)
+}
+
+func TestEnforceCreate(t *testing.T) {
+	newQuotaStore(t)
+	createConfig(t, "Topology", "big", topologySpec(8), nil)
+
+	err := experiment.Create(
+		context.Background(),
+		experiment.CreateWithName("big"),
+		experiment.CreateWithTopology("big"),
+		experiment.CreateWithOwner("alice"),
+	)
+	if !errors.Is(err, quota.ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded error creating experiment, got %v", err)
+	}
+}
+
+func TestEnforceStart(t *testing.T) {
+	newQuotaStore(t)
+	createExperiment(t, "running", 3, true)
+	createExperiment(t, "stopped", 2, false)
+
+	// The stopped experiment fits the quota on its own, but not along with the
+	// experiment alice already has running.
+	err := experiment.Start(context.Background(), experiment.StartWithName("stopped"))
+	if !errors.Is(err, quota.ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded error starting experiment, got %v", err)
+	}
+}
+
+func TestEnforceVMUpdate(t *testing.T) {
+	newQuotaStore(t)
+	createExperiment(t, "stopped", 2, false)
+
+	err := vm.Update(vm.UpdateExperiment("stopped"), vm.UpdateVM("vm"), vm.UpdateWithCPU(8))
+	if !errors.Is(err, quota.ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded error updating VM, got %v", err)
+	}
+
+	if err := vm.Update(vm.UpdateExperiment("stopped"), vm.UpdateVM("vm"), vm.UpdateWithCPU(4)); err != nil {
+		t.Fatalf("expected VM update within quota to succeed, got %v", err)
+	}
+}
+
+func TestCheckExperiment(t *testing.T) {
+	newQuotaStore(t)
+	createExperiment(t, "running", 3, true)
+	createExperiment(t, "other", 1, true)
+	createExperiment(t, "stopped", 1, false)
+
+	for name, exceeded := range map[string]bool{"other": true, "stopped": false} {
+		exp, err := experiment.Get(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		exp.Spec.Topology().Nodes()[0].Hardware().SetVCPU(2)
+
+		// Running experiments are checked along with the owner's other running
+		// experiments, and stopped ones on their own.
+		if err := quota.Check(exp); errors.Is(err, quota.ErrQuotaExceeded) != exceeded {
+			t.Errorf("expected quota exceeded to be %t for %s experiment, got %v", exceeded, name, err)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+// Package quota implements per-user and per-role resource quotas.
+//
+// Quotas can be set on a role, in which case they apply to every user with the
+// role, and on a user, in which case they override the limits of the user's
+// role. Experiments count against the quota of the user that created them,
+// which is recorded in the experiment's `owner` annotation. Experiments
+// without an owner (e.g. created via the command line) aren't limited.
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+
+	"phenix/api/config"
+	"phenix/store"
+	"phenix/types"
+	v1 "phenix/types/version/v1"
+)
+
+// diskAnnotationPrefix prefixes the user config annotations used to track the
+// disks uploaded by a user. The rest of the annotation key is the path to the
+// disk.
+const diskAnnotationPrefix = "disk/"
+
+// ErrQuotaExceeded is returned when an action would cause a user to exceed one
+// of their quota limits.
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// Usage is the amount of resources currently consumed by a user.
+type Usage struct {
+	RunningExperiments int   `json:"runningExperiments"`
+	VCPUs              int   `json:"vcpus"`
+	Memory             int   `json:"memory"`
+	VLANs              int   `json:"vlans"`
+	DiskBytes          int64 `json:"diskBytes"`
+}
+
+func (u *Usage) add(o Usage) {
+	u.RunningExperiments += o.RunningExperiments
+	u.VCPUs += o.VCPUs
+	u.Memory += o.Memory
+	u.VLANs += o.VLANs
+	u.DiskBytes += o.DiskBytes
+}
+
+// ForUser returns the effective quota for the given user. Limits set on the
+// user take precedence over the ones set on the user's role. A nil quota is
+// returned if neither the user nor the role has a quota.
+func ForUser(username string) (*v1.QuotaSpec, error) {
+	c, err := getUserConfig(username)
+	if err != nil || c == nil {
+		return nil, err
+	}
+
+	var user v1.UserSpec
+	if err := mapstructure.Decode(c.Spec, &user); err != nil {
+		return nil, fmt.Errorf("decoding user config: %w", err)
+	}
+
+	var role *v1.QuotaSpec
+
+	if user.Role != nil {
+		role = user.Role.Quota
+	}
+
+	return Merge(user.Quota, role), nil
+}
+
+// Merge returns the quota resulting from applying the limits set in the given
+// user quota over the ones in the given role quota. Either can be nil.
+func Merge(user, role *v1.QuotaSpec) *v1.QuotaSpec {
+	if user == nil && role == nil {
+		return nil
+	}
+
+	var merged v1.QuotaSpec
+
+	if role != nil {
+		merged = *role
+	}
+
+	if user != nil {
+		if user.RunningExperiments > 0 {
+			merged.RunningExperiments = user.RunningExperiments
+		}
+
+		if user.VCPUs > 0 {
+			merged.VCPUs = user.VCPUs
+		}
+
+		if user.Memory > 0 {
+			merged.Memory = user.Memory
+		}
+
+		if user.VLANs > 0 {
+			merged.VLANs = user.VLANs
+		}
+
+		if user.DiskBytes > 0 {
+			merged.DiskBytes = user.DiskBytes
+		}
+	}
+
+	return &merged
+}
+
+// UsageForUser returns the resources currently consumed by the given user,
+// which includes the running experiments they own and the disks they've
+// uploaded.
+func UsageForUser(username string) (Usage, error) {
+	usage, err := runningUsage(username, "")
+	if err != nil {
+		return Usage{}, err
+	}
+
+	disk, err := diskUsage(username)
+	if err != nil {
+		return Usage{}, err
+	}
+
+	usage.DiskBytes = disk
+
+	return usage, nil
+}
+
+// ExperimentUsage returns the resources the given experiment consumes when
+// it's running. Nodes that are external or flagged to not boot aren't counted.
+func ExperimentUsage(exp *types.Experiment) Usage {
+	usage := Usage{RunningExperiments: 1} //nolint:exhaustruct // partial initialization
+	vlans := make(map[string]struct{})
+
+	for alias := range exp.Spec.VLANs().Aliases() {
+		vlans[strings.ToLower(alias)] = struct{}{}
+	}
+
+	for _, node := range exp.Spec.Topology().Nodes() {
+		if node.Network() != nil {
+			for _, iface := range node.Network().Interfaces() {
+				if vlan := iface.VLAN(); vlan != "" {
+					vlans[strings.ToLower(vlan)] = struct{}{}
+				}
+			}
+		}
+
+		if node.External() {
+			continue
+		}
+
+		if dnb := node.General().DoNotBoot(); dnb != nil && *dnb {
+			continue
+		}
+
+		if hw := node.Hardware(); hw != nil {
+			usage.VCPUs += hw.VCPU()
+			usage.Memory += hw.Memory()
+		}
+	}
+
+	usage.VLANs = len(vlans)
+
+	return usage
+}
+
+// CheckCreate returns an error if the given experiment, on its own, would
+// exceed the quota of its owner when started. This catches experiments that
+// could never be started by their owner.
+func CheckCreate(exp *types.Experiment) error {
+	owner := exp.Owner()
+	if owner == "" {
+		return nil
+	}
+
+	quota, err := ForUser(owner)
+	if err != nil {
+		return err
+	}
+
+	return check(owner, quota, ExperimentUsage(exp))
+}
+
+// CheckRunning returns an error if starting the given experiment would exceed
+// the quota of its owner, taking into account the other experiments the owner
+// already has running.
+func CheckRunning(exp *types.Experiment) error {
+	owner := exp.Owner()
+	if owner == "" {
+		return nil
+	}
+
+	quota, err := ForUser(owner)
+	if err != nil {
+		return err
+	}
+
+	if quota == nil {
+		return nil
+	}
+
+	usage, err := runningUsage(owner, exp.Metadata.Name)
+	if err != nil {
+		return err
+	}
+
+	usage.add(ExperimentUsage(exp))
+
+	return check(owner, quota, usage)
+}
+
+// Check returns an error if the given experiment, as changed, would exceed the
+// quota of its owner. Running experiments are checked along with the other
+// experiments the owner has running (see CheckRunning), and stopped ones on
+// their own (see CheckCreate) since the rest are checked when they're started.
+func Check(exp *types.Experiment) error {
+	if exp.Running() {
+		return CheckRunning(exp)
+	}
+
+	return CheckCreate(exp)
+}
+
+// CheckDisk returns an error if uploading a disk of the given size would
+// exceed the disk quota of the given user.
+func CheckDisk(username string, size int64) error {
+	quota, err := ForUser(username)
+	if err != nil {
+		return err
+	}
+
+	if quota == nil || quota.DiskBytes == 0 {
+		return nil
+	}
+
+	used, err := diskUsage(username)
+	if err != nil {
+		return err
+	}
+
+	if used+size > quota.DiskBytes {
+		return fmt.Errorf(
+			"%w for user %s: disk limit of %d bytes exceeded (%d bytes already used, %d bytes requested)",
+			ErrQuotaExceeded, username, quota.DiskBytes, used, size,
+		)
+	}
+
+	return nil
+}
+
+// RecordDisk records the disk at the given path as uploaded by the given user
+// so it counts against their disk quota for as long as it exists.
+func RecordDisk(username, path string) error {
+	c, err := getUserConfig(username)
+	if err != nil || c == nil {
+		return err
+	}
+
+	if c.Metadata.Annotations == nil {
+		c.Metadata.Annotations = make(map[string]string)
+	}
+
+	c.Metadata.Annotations[diskAnnotationPrefix+path] = username
+
+	if err := store.Update(c); err != nil {
+		return fmt.Errorf("recording disk for user %s: %w", username, err)
+	}
+
+	return nil
+}
+
+// check returns an error naming the first limit in the given quota that the
+// given usage exceeds. A nil quota never errors.
+func check(username string, quota *v1.QuotaSpec, usage Usage) error {
+	if quota == nil {
+		return nil
+	}
+
+	limits := []struct {
+		name       string
+		limit, use int
+	}{
+		{"running experiment", quota.RunningExperiments, usage.RunningExperiments},
+		{"vCPU", quota.VCPUs, usage.VCPUs},
+		{"memory (MB)", quota.Memory, usage.Memory},
+		{"VLAN", quota.VLANs, usage.VLANs},
+	}
+
+	for _, l := range limits {
+		if l.limit > 0 && l.use > l.limit {
+			return fmt.Errorf(
+				"%w for user %s: %s limit of %d exceeded (requires %d)",
+				ErrQuotaExceeded, username, l.name, l.limit, l.use,
+			)
+		}
+	}
+
+	return nil
+}
+
+// getUserConfig returns the config for the given user, or nil if the user
+// doesn't exist (e.g. when authentication is disabled), in which case no quota
+// applies.
+func getUserConfig(username string) (*store.Config, error) {
+	if username == "" {
+		return nil, nil //nolint:nilnil // no user
+	}
+
+	c, err := config.Get("user/"+username, false)
+	if err != nil {
+		if errors.Is(err, store.ErrNotExist) {
+			return nil, nil //nolint:nilnil // no user
+		}
+
+		return nil, fmt.Errorf("getting user config: %w", err)
+	}
+
+	return c, nil
+}
+
+// runningUsage sums the usage of the running experiments owned by the given
+// user, skipping the experiment with the given name (if any).
+func runningUsage(username, skip string) (Usage, error) {
+	exps, err := types.Experiments(true)
+	if err != nil {
+		return Usage{}, fmt.Errorf("getting experiments: %w", err)
+	}
+
+	var usage Usage
+
+	for _, exp := range exps {
+		if exp.Metadata.Name == skip || exp.Owner() != username {
+			continue
+		}
+
+		if exp.DryRun() {
+			continue
+		}
+
+		usage.add(ExperimentUsage(exp))
+	}
+
+	return usage, nil
+}
+
+// diskUsage sums the sizes of the disks uploaded by the given user that still
+// exist.
+func diskUsage(username string) (int64, error) {
+	c, err := getUserConfig(username)
+	if err != nil || c == nil {
+		return 0, err
+	}
+
+	var total int64
+
+	for key := range c.Metadata.Annotations {
+		path, ok := strings.CutPrefix(key, diskAnnotationPrefix)
+		if !ok {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		total += info.Size()
+	}
+
+	return total, nil
+}
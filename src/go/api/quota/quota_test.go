@@ -0,0 +1,50 @@
+package quota
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	v1 "phenix/types/version/v1"
+)
+
+func TestMerge(t *testing.T) {
+	if got := Merge(nil, nil); got != nil {
+		t.Fatalf("expected nil quota, got %+v", got)
+	}
+
+	role := &v1.QuotaSpec{RunningExperiments: 1, VCPUs: 8, Memory: 16384} //nolint:exhaustruct // partial initialization
+	user := &v1.QuotaSpec{VCPUs: 32, DiskBytes: 1024}                     //nolint:exhaustruct // partial initialization
+
+	got := Merge(user, role)
+	want := v1.QuotaSpec{RunningExperiments: 1, VCPUs: 32, Memory: 16384, VLANs: 0, DiskBytes: 1024}
+
+	if *got != want {
+		t.Errorf("expected %+v, got %+v", want, *got)
+	}
+
+	if role.VCPUs != 8 {
+		t.Errorf("expected role quota to be unchanged, got %+v", *role)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	quota := &v1.QuotaSpec{RunningExperiments: 2, VCPUs: 8} //nolint:exhaustruct // partial initialization
+
+	if err := check("alice", quota, Usage{RunningExperiments: 2, VCPUs: 8, Memory: 1 << 20}); err != nil { //nolint:exhaustruct // partial initialization
+		t.Errorf("expected usage within quota, got %v", err)
+	}
+
+	err := check("alice", quota, Usage{RunningExperiments: 1, VCPUs: 16}) //nolint:exhaustruct // partial initialization
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("expected quota exceeded error, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "vCPU limit of 8 exceeded (requires 16)") {
+		t.Errorf("expected error to name the vCPU limit, got %v", err)
+	}
+
+	if err := check("alice", nil, Usage{VCPUs: 1000}); err != nil { //nolint:exhaustruct // partial initialization
+		t.Errorf("expected nil quota to be unlimited, got %v", err)
+	}
+}
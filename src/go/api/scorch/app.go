@@ -23,6 +23,7 @@ import (
 	"phenix/types"
 	ifaces "phenix/types/interfaces"
 	"phenix/util"
+	"phenix/util/mm"
 	"phenix/util/mm/mmcli"
 	"phenix/util/plog"
 	"phenix/util/shell"
@@ -452,7 +453,7 @@ func (s Scorch) recordInfo(
 	c := mmcli.NewCommand()
 	c.Command = "version"
 
-	mmVersion, err := mmcli.SingleResponse(mmcli.Run(c))
+	mmVersion, err := mmcli.SingleResponse(mm.Run(c))
 	if err != nil {
 		return fmt.Errorf("getting minimega version: %w", err)
 	}
@@ -0,0 +1,83 @@
+package settings
+
+import (
+	"fmt"
+	"strconv"
+
+	"phenix/types"
+	"phenix/util/plog"
+)
+
+type ConfigSettings struct {
+	LintOnCreate bool  `json:"lint_on_create"`
+	MaxRevisions int32 `json:"max_revisions"`
+}
+
+func GetConfigSettings() (ConfigSettings, error) {
+	plog.Debug(plog.TypeSystem, "Getting all config settings")
+
+	settings, err := List()
+	if err != nil {
+		return ConfigSettings{}, fmt.Errorf("error listing settings: %w", err)
+	}
+
+	return GetConfigSettingsFromList(settings)
+}
+
+func GetConfigSettingsFromList(settings []types.Setting) (ConfigSettings, error) {
+	configSettings := ConfigSettings{} //nolint:exhaustruct // partial initialization
+
+	var err error
+
+	for _, setting := range settings {
+		category := setting.Spec.Category
+		name := setting.Spec.Name
+
+		if category != "Config" {
+			continue
+		}
+
+		switch name {
+		case "LintOnCreate":
+			configSettings.LintOnCreate, err = strconv.ParseBool(setting.Spec.Value)
+			if err != nil {
+				return configSettings, fmt.Errorf(
+					"error parsing %s.%s setting: %w",
+					category,
+					name,
+					err,
+				)
+			}
+		case "MaxRevisions":
+			configSettings.MaxRevisions, err = parseInt(setting.Spec.Value)
+			if err != nil {
+				return configSettings, fmt.Errorf(
+					"error parsing %s.%s setting: %w",
+					category,
+					name,
+					err,
+				)
+			}
+		}
+	}
+
+	return configSettings, nil
+}
+
+func UpdateConfigSettings(newSettings ConfigSettings) error {
+	plog.Debug(plog.TypeSystem, "Updating config settings")
+
+	_, err := Update("Config", "LintOnCreate", strconv.FormatBool(newSettings.LintOnCreate))
+	if err != nil {
+		return fmt.Errorf("error updating Config.LintOnCreate: %w", err)
+	}
+
+	_, err = Update("Config", "MaxRevisions", formatInt(newSettings.MaxRevisions))
+	if err != nil {
+		return fmt.Errorf("error updating Config.MaxRevisions: %w", err)
+	}
+
+	plog.Debug(plog.TypeSystem, "Updated config settings successfully")
+
+	return nil
+}
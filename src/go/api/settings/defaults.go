@@ -12,9 +12,10 @@ import (
 )
 
 const (
-	DefaultPasswordMinLength = 8
-	DefaultLogMaxFileSize    = 100
-	DefaultLogMaxFileAge     = 90
+	DefaultPasswordMinLength  = 8
+	DefaultLogMaxFileSize     = 100
+	DefaultLogMaxFileAge      = 90
+	DefaultConfigMaxRevisions = 100
 )
 
 var DefaultSettings = []v2.Setting{ //nolint:gochecknoglobals // global constant
@@ -47,6 +48,9 @@ var DefaultSettings = []v2.Setting{ //nolint:gochecknoglobals // global constant
 	{Category: "Logging", Name: "MaxFileRotations", Type: v2.SettingValueInt, Value: formatInt(0)},
 	{Category: "Logging", Name: "MaxFileSize", Type: v2.SettingValueInt, Value: formatInt(DefaultLogMaxFileSize)},
 	{Category: "Logging", Name: "MaxFileAge", Type: v2.SettingValueInt, Value: formatInt(DefaultLogMaxFileAge)},
+
+	{Category: "Config", Name: "LintOnCreate", Type: v2.SettingValueBool, Value: strconv.FormatBool(false)},
+	{Category: "Config", Name: "MaxRevisions", Type: v2.SettingValueInt, Value: formatInt(DefaultConfigMaxRevisions)},
 }
 
 func GetDefault(category, name string) (v2.Setting, bool) {
@@ -30,6 +30,7 @@ Adding a new setting:
 type Settings struct {
 	PasswordSettings PasswordSettings `json:"password_settings"`
 	LoggingSettings  LoggingSettings  `json:"logging_settings"`
+	ConfigSettings   ConfigSettings   `json:"config_settings"`
 }
 
 func GetSettings() (*Settings, error) {
@@ -52,6 +53,11 @@ func GetSettings() (*Settings, error) {
 		return nil, fmt.Errorf("error getting logging settings: %w", err)
 	}
 
+	settings.ConfigSettings, err = GetConfigSettingsFromList(settingList)
+	if err != nil {
+		return nil, fmt.Errorf("error getting config settings: %w", err)
+	}
+
 	return settings, nil
 }
 
@@ -66,6 +72,11 @@ func UpdateAllSettings(newSettings Settings) error {
 		return fmt.Errorf("error updating logging settings: %w", err)
 	}
 
+	err = UpdateConfigSettings(newSettings.ConfigSettings)
+	if err != nil {
+		return fmt.Errorf("error updating config settings: %w", err)
+	}
+
 	return nil
 }
 
@@ -193,8 +204,13 @@ func List() ([]types.Setting, error) {
 }
 
 func GetSetting(category, name string) (*types.Setting, error) {
-	combined := fmt.Sprintf("%s.%s", category, name)
-	c, _ := store.NewConfig("setting/" + combined)
+	c := &store.Config{ //nolint:exhaustruct // partial initialization
+		Version: "phenix.sandia.gov/v2",
+		Kind:    "Setting",
+		Metadata: store.ConfigMetadata{ //nolint:exhaustruct // partial initialization
+			Name: GetStoreName(category, name),
+		},
+	}
 
 	err := store.Get(c)
 	if err != nil {
@@ -614,10 +614,16 @@ func (s *SOH) gatherNodeIPs(node ifaces.NodeSpec) {
 			continue
 		}
 
-		s.addrHosts[iface.Address()] = host
+		// Include any additional (IPv4 or IPv6) addresses so dual-stack interfaces
+		// are tested for reachability using each of their addresses.
+		for _, cidr := range iface.CIDRs() {
+			addr, _, _ := strings.Cut(cidr, "/")
 
-		if iface.VLAN() != "" {
-			s.vlans[iface.VLAN()] = append(s.vlans[iface.VLAN()], iface.Address())
+			s.addrHosts[addr] = host
+
+			if iface.VLAN() != "" {
+				s.vlans[iface.VLAN()] = append(s.vlans[iface.VLAN()], addr)
+			}
 		}
 
 		ips, ok := s.hostIPs[host]
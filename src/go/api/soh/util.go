@@ -28,10 +28,6 @@ const appName = "soh"
 const (
 	loadAvgParts = 5
 	portParts    = 2
-	byteMask     = 0xFF
-	shift8       = 8
-	shift16      = 16
-	shift24      = 24
 )
 
 var stringSpacePattern = regexp.MustCompile(`\s+`)
@@ -1051,28 +1047,31 @@ func (s SOH) isNetworkingConfigured( //nolint:funlen // complex logic
 		meta    = map[string]any{"host": host}
 	)
 
+	// Only one default gateway is checked, so fall back to the IPv6 gateway for
+	// interfaces that only have an IPv6 default gateway.
+	if gateway == "" {
+		gateway = iface.Gateway6()
+	}
+
 	// First, we wait for the IP address to be set on the interface. Then, we wait
 	// for the default gateway to be set. Last, we wait for the default gateway to
 	// be up (pingable). This is all done via nested commands streamed to the C2
 	// processor within `expected` functions.
 	ipExpected := func(resp string) error {
 		if addr != "" {
-			if strings.EqualFold(node.Hardware().OSType(), "windows") {
-				// If `resp` doesn't contain the IP address, then the IP address isn't
-				// configured yet, so keep retrying the C2 command.
-				if !strings.Contains(resp, addr) {
-					if time.Now().After(retryUntil) {
-						return errors.New("retry time expired waiting for IP to be set")
-					}
+			// Dual-stack interfaces can have more than one address configured, so
+			// wait for all of them to be set.
+			for _, cidr := range iface.CIDRs() {
+				expected := cidr
 
-					return mm.C2RetryError{Delay: c2RetryDelay}
+				if strings.EqualFold(node.Hardware().OSType(), "windows") {
+					// Windows doesn't include the prefix length with each address.
+					expected, _, _ = strings.Cut(cidr, "/")
 				}
-			} else {
-				cidr := fmt.Sprintf("%s/%d", addr, iface.Mask())
 
 				// If `resp` doesn't contain the IP address, then the IP address isn't
 				// configured yet, so keep retrying the C2 command.
-				if !strings.Contains(resp, cidr) {
+				if !strings.Contains(resp, expected) {
 					if time.Now().After(retryUntil) {
 						return errors.New("retry time expired waiting for IP to be set")
 					}
@@ -1081,7 +1080,7 @@ func (s SOH) isNetworkingConfigured( //nolint:funlen // complex logic
 				}
 			}
 
-			wg.AddSuccess(fmt.Sprintf("IP %s configured", addr), meta)
+			wg.AddSuccess(fmt.Sprintf("IP %s configured", strings.Join(iface.CIDRs(), ", ")), meta)
 		}
 
 		if gateway != "" {
@@ -1091,6 +1090,10 @@ func (s SOH) isNetworkingConfigured( //nolint:funlen // complex logic
 				if strings.EqualFold(node.Hardware().OSType(), "windows") {
 					expected := "0.0.0.0\\s+0.0.0.0\\s+" + gateway
 
+					if isIPv6(gateway) {
+						expected = "::/0\\s+" + gateway
+					}
+
 					// If `resp` doesn't contain the default gateway, then the default gateway
 					// isn't configured yet, so keep retrying the C2 command.
 					if found, _ := regexp.MatchString(expected, resp); !found {
@@ -1147,13 +1150,7 @@ func (s SOH) isNetworkingConfigured( //nolint:funlen // complex logic
 					return nil
 				}
 
-				exec := "ping -c 1 " + gateway
-
-				if strings.EqualFold(node.Hardware().OSType(), "windows") {
-					exec = "ping -n 1 " + gateway
-				}
-
-				cmd := s.newParallelCommand(ns, host, exec)
+				cmd := s.newParallelCommand(ns, host, pingCommand(node, gateway))
 				cmd.Wait = wg
 				cmd.Meta = map[string]any{"host": host}
 				cmd.Expected = gwPingExpected
@@ -1167,6 +1164,8 @@ func (s SOH) isNetworkingConfigured( //nolint:funlen // complex logic
 
 			if strings.EqualFold(node.Hardware().OSType(), "windows") {
 				exec = "route print"
+			} else if isIPv6(gateway) {
+				exec = "ip -6 route"
 			}
 
 			cmd := s.newParallelCommand(ns, host, exec)
@@ -1201,13 +1200,8 @@ func (s SOH) pingTest(
 	node ifaces.NodeSpec,
 	target string,
 ) {
-	exec := "ping -c 1 " + target
-
-	if strings.EqualFold(node.Hardware().OSType(), "windows") {
-		exec = "ping -n 1 " + target
-	}
-
 	var (
+		exec = pingCommand(node, target)
 		host = node.General().Hostname()
 		meta = map[string]any{"host": host, "target": target}
 	)
@@ -1655,7 +1649,7 @@ func skip(node ifaces.NodeSpec, toSkip []string) bool {
 		// Check to see if this is a reference to an image. If so, skip this host if
 		// it's using the referenced image.
 		if ext := filepath.Ext(skipHost); ext == ".qc2" || ext == ".qcow2" {
-			if filepath.Base(node.Hardware().Drives()[0].Image()) == skipHost {
+			if drives := node.Hardware().Drives(); len(drives) > 0 && filepath.Base(drives[0].Image()) == skipHost {
 				return true
 			}
 		}
@@ -1710,16 +1704,44 @@ func periodicallyNotify(
 	return cancel
 }
 
+// pingCommand returns the command used to send a single ping to the given
+// target from the given node, forcing IPv6 when the target is an IPv6 address.
+func pingCommand(node ifaces.NodeSpec, target string) string {
+	count := "-c 1"
+
+	if strings.EqualFold(node.Hardware().OSType(), "windows") {
+		count = "-n 1"
+	}
+
+	if isIPv6(target) {
+		return fmt.Sprintf("ping -6 %s %s", count, target)
+	}
+
+	return fmt.Sprintf("ping %s %s", count, target)
+}
+
+func isIPv6(addr string) bool {
+	ip := net.ParseIP(addr)
+
+	return ip != nil && ip.To4() == nil
+}
+
+// nextIP returns the address after the given IPv4 or IPv6 address.
 func nextIP(ip net.IP) net.IP {
-	i := ip.To4()
-	v := uint(i[0])<<shift24 + uint(i[1])<<shift16 + uint(i[2])<<shift8 + uint(i[3])
+	next := make(net.IP, len(ip))
+	copy(next, ip)
 
-	v++
+	if v4 := next.To4(); v4 != nil {
+		next = v4
+	}
+
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
 
-	v3 := byte(v & byteMask)
-	v2 := byte((v >> shift8) & byteMask)
-	v1 := byte((v >> shift16) & byteMask)
-	v0 := byte((v >> shift24) & byteMask)
+		if next[i] != 0 {
+			break
+		}
+	}
 
-	return net.IPv4(v0, v1, v2, v3)
+	return next
 }
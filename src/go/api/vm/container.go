@@ -0,0 +1,113 @@
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"phenix/api/experiment"
+	ifaces "phenix/types/interfaces"
+	"phenix/util/mm"
+)
+
+// ErrContainerUnsupported is returned for VM operations that only apply to KVM
+// VMs (screenshots, disk and memory snapshots, optical discs, etc.) when the
+// VM is a container.
+var ErrContainerUnsupported = errors.New("operation not supported for container VMs")
+
+// ErrNotContainer is returned when executing a console command in a VM that
+// isn't a container.
+var ErrNotContainer = errors.New("VM is not a container")
+
+// requireKVM returns ErrContainerUnsupported if the VM with the given name in
+// the experiment with the given name is a container.
+func requireKVM(expName, vmName string) error {
+	container, err := isContainer(expName, vmName)
+	if err != nil {
+		return err
+	}
+
+	if container {
+		return fmt.Errorf("%w: %s", ErrContainerUnsupported, vmName)
+	}
+
+	return nil
+}
+
+func isContainer(expName, vmName string) (bool, error) {
+	exp, err := experiment.Get(expName)
+	if err != nil {
+		return false, fmt.Errorf("getting experiment %s: %w", expName, err)
+	}
+
+	node := exp.Spec.Topology().FindNodeByName(vmName)
+	if node == nil {
+		return false, fmt.Errorf("vm %s not found in experiment %s", vmName, expName)
+	}
+
+	return node.IsContainer(), nil
+}
+
+// Exec runs the given command in the console of the running container with the
+// given name in the experiment with the given name, returning the command's
+// output. Containers don't have a VNC console, and don't need the miniccc
+// agent for this since the command is run in the container's namespaces on the
+// cluster host the container is running on.
+func Exec(expName, vmName, command string) (string, error) {
+	if expName == "" {
+		return "", errors.New("no experiment name provided")
+	}
+
+	if vmName == "" {
+		return "", errors.New("no VM name provided")
+	}
+
+	if command == "" {
+		return "", errors.New("no command provided")
+	}
+
+	container, err := isContainer(expName, vmName)
+	if err != nil {
+		return "", err
+	}
+
+	if !container {
+		return "", fmt.Errorf("%w: %s", ErrNotContainer, vmName)
+	}
+
+	out, err := mm.ExecContainer(mm.NS(expName), mm.VMName(vmName), mm.Command(command))
+	if err != nil {
+		return "", fmt.Errorf("executing command in container %s: %w", vmName, err)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// vmType returns the minimega VM type of the given node.
+func vmType(node ifaces.NodeSpec) string {
+	if node.IsContainer() {
+		return "container"
+	}
+
+	return "kvm"
+}
+
+// vmDisk returns the full path to the given node's first disk image and its
+// inject partition. For containers, the path to the node's root filesystem is
+// returned instead.
+func vmDisk(node ifaces.NodeSpec) (string, int) {
+	if node.IsContainer() {
+		if node.Container() == nil {
+			return "", 0
+		}
+
+		return mm.GetMMFullPath(node.Container().Filesystem()), 0
+	}
+
+	drives := node.Hardware().Drives()
+	if len(drives) == 0 {
+		return "", 0
+	}
+
+	return mm.GetMMFullPath(drives[0].Image()), *drives[0].InjectPartition()
+}
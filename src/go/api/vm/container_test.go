@@ -0,0 +1,109 @@
+package vm_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"phenix/api/vm"
+	"phenix/store"
+	"phenix/util/mm"
+)
+
+const containerScript = `namespace foo
+
+clear vm config
+vm config schedule compute1
+vm config filesystem base_fs
+vm launch container ctr
+
+clear vm config
+vm config schedule compute1
+vm config disk linux.qc2
+vm launch kvm host
+`
+
+func TestExec(t *testing.T) {
+	orig := mm.DefaultMM
+	fake := mm.NewFake(mm.FakeHost("compute1", 16, 32768))
+	mm.DefaultMM = fake //nolint:reassign // testing
+
+	t.Cleanup(func() { mm.DefaultMM = orig }) //nolint:reassign // testing
+
+	script := filepath.Join(t.TempDir(), "foo.mm")
+
+	if err := os.WriteFile(script, []byte(containerScript), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.ReadScriptFromFile(script); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.LaunchVMs("foo", "ctr", "host"); err != nil {
+		t.Fatal(err)
+	}
+
+	exp := store.Config{
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Experiment",
+		Metadata: store.ConfigMetadata{Name: "foo"}, //nolint:exhaustruct // partial initialization
+		Spec: map[string]any{
+			"experimentName": "foo",
+			"topology": map[string]any{
+				"nodes": []any{
+					map[string]any{
+						"type":      "VirtualMachine",
+						"general":   map[string]any{"hostname": "ctr", "vm_type": "container"},
+						"container": map[string]any{"filesystem": "base_fs"},
+					},
+					map[string]any{
+						"type":    "VirtualMachine",
+						"general": map[string]any{"hostname": "host"},
+					},
+				},
+			},
+		},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := store.NewMockStore(ctrl)
+	m.EXPECT().Get(gomock.Any()).DoAndReturn(func(c *store.Config) error {
+		*c = exp
+
+		return nil
+	}).AnyTimes()
+
+	origStore := store.DefaultStore
+	store.DefaultStore = m //nolint:reassign // monkey patching for test
+
+	t.Cleanup(func() { store.DefaultStore = origStore }) //nolint:reassign // monkey patching for test
+
+	if _, err := vm.Exec("foo", "ctr", `echo "it's $HOME"`); err != nil {
+		t.Fatal(err)
+	}
+
+	history := fake.History()
+	expected := `mesh send compute1 shell nsenter --target 1 --mount --uts --ipc --net --pid -- /bin/sh -c 'echo "it'\''s $HOME"'`
+
+	if last := history[len(history)-1]; last != expected {
+		t.Errorf("expected command to be single quoted:\n%s\ngot:\n%s", expected, last)
+	}
+
+	if _, err := vm.Exec("foo", "host", "id"); err == nil || !strings.Contains(err.Error(), "not a container") {
+		t.Errorf("expected error executing a command in a KVM VM, got %v", err)
+	}
+
+	if err := fake.StopVM(mm.NS("foo"), mm.VMName("ctr")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := vm.Exec("foo", "ctr", "id"); err == nil || !strings.Contains(err.Error(), "not running") {
+		t.Errorf("expected error executing a command in a stopped container, got %v", err)
+	}
+}
@@ -164,3 +164,31 @@ func InjectPartition(p int) RedeployOption {
 		o.part = p
 	}
 }
+
+// SnapshotOption is a function that configures options for taking or restoring
+// a VM snapshot. It is used in `vm.Snapshot` and `vm.Restore`.
+type SnapshotOption func(*snapshotOptions)
+
+type snapshotOptions struct {
+	leavePaused bool
+}
+
+func newSnapshotOptions(opts ...SnapshotOption) snapshotOptions {
+	var o snapshotOptions
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// LeavePaused sets whether or not the VM is left paused once a snapshot has
+// been taken or restored instead of being started. When taking a snapshot, it
+// also allows a VM that's already paused to be snapshotted. It defaults to
+// false.
+func LeavePaused(p bool) SnapshotOption {
+	return func(o *snapshotOptions) {
+		o.leavePaused = p
+	}
+}
@@ -60,6 +60,10 @@ func getVMImage(expName, vmName string) (string, error) {
 		return "", fmt.Errorf("getting vm %s for experiment %s", vmName, expName)
 	}
 
+	if vm.IsContainer() {
+		return "", fmt.Errorf("%w: %s has no disk image", ErrContainerUnsupported, vmName)
+	}
+
 	// base image from topology
 	return vm.Hardware().Drives()[0].Image(), nil
 }
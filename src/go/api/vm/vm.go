@@ -18,6 +18,7 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"phenix/api/experiment"
+	"phenix/api/quota"
 	"phenix/util/common"
 	"phenix/util/file"
 	"phenix/util/mm"
@@ -27,6 +28,7 @@ import (
 const (
 	vmStateQuit          = "QUIT"
 	vmStateRunning       = "RUNNING"
+	vmStatePaused        = "PAUSED"
 	vmInfoCmd            = "vm info"
 	statusCompleted      = "completed"
 	shutdownTimeout      = 30 * time.Second
@@ -77,16 +79,11 @@ func List(expName string) ([]mm.VM, error) { //nolint:funlen // complex logic
 
 	for idx, node := range exp.Spec.Topology().Nodes() {
 		var (
-			disk            string
-			dnb             bool
-			snapshot        bool
-			injectPartition int
+			dnb      bool
+			snapshot bool
 		)
 
-		if drives := node.Hardware().Drives(); len(drives) > 0 {
-			disk = mm.GetMMFullPath(drives[0].Image())
-			injectPartition = *drives[0].InjectPartition()
-		}
+		disk, injectPartition := vmDisk(node)
 
 		if node.General().DoNotBoot() != nil {
 			dnb = *node.General().DoNotBoot()
@@ -107,6 +104,7 @@ func List(expName string) ([]mm.VM, error) { //nolint:funlen // complex logic
 			Interfaces:      make(map[string]string),
 			DoNotBoot:       dnb,
 			Type:            node.Type(),
+			VMType:          vmType(node),
 			OSType:          node.Hardware().OSType(),
 			Snapshot:        snapshot,
 			Tags:            node.Labels(),
@@ -201,23 +199,24 @@ func Get(expName, vmName string) (*mm.VM, error) {
 		}
 
 		vm = &mm.VM{ //nolint:exhaustruct // partial initialization
-			ID:              idx,
-			Name:            node.General().Hostname(),
-			Experiment:      exp.Spec.ExperimentName(),
-			CPUs:            node.Hardware().VCPU(),
-			RAM:             node.Hardware().Memory(),
-			Disk:            mm.GetMMFullPath(node.Hardware().Drives()[0].Image()),
-			InjectPartition: *node.Hardware().Drives()[0].InjectPartition(),
-			Interfaces:      make(map[string]string),
-			DoNotBoot:       *node.General().DoNotBoot(),
-			OSType:          node.Hardware().OSType(),
-			Snapshot:        *node.General().Snapshot(),
-			Metadata:        make(map[string]any),
-			Labels:          node.Labels(),
-			Tags:            node.Labels(),
-			Annotations:     node.Annotations(),
+			ID:          idx,
+			Name:        node.General().Hostname(),
+			Experiment:  exp.Spec.ExperimentName(),
+			CPUs:        node.Hardware().VCPU(),
+			RAM:         node.Hardware().Memory(),
+			Interfaces:  make(map[string]string),
+			DoNotBoot:   *node.General().DoNotBoot(),
+			VMType:      vmType(node),
+			OSType:      node.Hardware().OSType(),
+			Snapshot:    *node.General().Snapshot(),
+			Metadata:    make(map[string]any),
+			Labels:      node.Labels(),
+			Tags:        node.Labels(),
+			Annotations: node.Annotations(),
 		}
 
+		vm.Disk, vm.InjectPartition = vmDisk(node)
+
 		for _, iface := range node.Network().Interfaces() {
 			vm.IPv4 = append(vm.IPv4, iface.Address()) // empty for DHCP
 			vm.Networks = append(vm.Networks, iface.VLAN())
@@ -385,6 +384,10 @@ func Update(opts ...UpdateOption) error { //nolint:funlen,gocyclo,cyclop  // com
 		vm.Hardware().SetMemory(o.mem)
 	}
 
+	if (o.disk != "" || o.partition != 0) && vm.IsContainer() {
+		return fmt.Errorf("%w: cannot set disk for %s", ErrContainerUnsupported, o.vm)
+	}
+
 	if o.disk != "" {
 		vm.Hardware().Drives()[0].SetImage(o.disk)
 	}
@@ -413,6 +416,14 @@ func Update(opts ...UpdateOption) error { //nolint:funlen,gocyclo,cyclop  // com
 		vm.General().SetSnapshot(*o.snapshot)
 	}
 
+	// Changing the resources of a VM can push its experiment over the owner's
+	// quota.
+	if o.cpu != 0 || o.mem != 0 || o.dnb != nil {
+		if err := quota.Check(exp); err != nil {
+			return err
+		}
+	}
+
 	err = experiment.Save(experiment.SaveWithName(o.exp), experiment.SaveWithSpec(exp.Spec))
 	if err != nil {
 		return fmt.Errorf("unable to save experiment with updated VM: %w", err)
@@ -422,6 +433,10 @@ func Update(opts ...UpdateOption) error { //nolint:funlen,gocyclo,cyclop  // com
 }
 
 func Screenshot(expName, vmName, size string) ([]byte, error) {
+	if err := requireKVM(expName, vmName); err != nil {
+		return nil, err
+	}
+
 	screenshot, err := mm.GetVMScreenshot(
 		mm.NS(expName),
 		mm.VMName(vmName),
@@ -482,7 +497,7 @@ func Restart(expName, vmName string) error {
 	qmp := `{ "execute": "system_reset" }`
 	cmd.Command = fmt.Sprintf("vm qmp %s '%s'", vmName, qmp)
 
-	_, err = mmcli.SingleResponse(mmcli.Run(cmd))
+	_, err = mmcli.SingleResponse(mm.Run(cmd))
 	if err != nil {
 		return fmt.Errorf("restarting VM %s: %w", vmName, err)
 	}
@@ -522,11 +537,11 @@ func Shutdown(expName, vmName string) error {
 	qmp := `{ "execute": "system_powerdown" }`
 	cmd.Command = fmt.Sprintf("vm qmp %s '%s'", vmName, qmp)
 
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		// return fmt.Errorf("powering down VM %s: %w", vmName, err)
 		cmd.Command = "vm kill " + vmName
 
-		err := mmcli.ErrorResponse(mmcli.Run(cmd))
+		err := mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return fmt.Errorf("shutting down VM %s in experiment %s: %w", vmName, expName, err)
 		}
@@ -556,7 +571,7 @@ func Shutdown(expName, vmName string) error {
 		// flush to preserve the state.
 		cmd.Command = "vm kill " + vmName
 
-		err := mmcli.ErrorResponse(mmcli.Run(cmd))
+		err := mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return fmt.Errorf("shutting down VM %s in experiment %s: %w", vmName, expName, err)
 		}
@@ -576,6 +591,10 @@ func ResetDiskState(expName, vmName string) error {
 		return errors.New("no VM name provided")
 	}
 
+	if err := requireKVM(expName, vmName); err != nil {
+		return err
+	}
+
 	// Overwrite the snapshot in the vm instance
 	// directory with a new snapshot.
 	cmd := mmcli.NewNamespacedCommand(expName)
@@ -583,7 +602,7 @@ func ResetDiskState(expName, vmName string) error {
 	cmd.Columns = []string{"host", "name", "id", "state", "disks", "snapshot"}
 	cmd.Filters = []string{"name=" + vmName}
 
-	status := mmcli.RunTabular(cmd)
+	status := mm.RunTabular(cmd)
 
 	if len(status) == 0 {
 		return errors.New("vm not found")
@@ -620,7 +639,7 @@ func ResetDiskState(expName, vmName string) error {
 		cmd := mmcli.NewNamespacedCommand(expName)
 		cmd.Command = "vm kill " + vmName
 
-		if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+		if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 			return fmt.Errorf("killing VM %s in experiment %s: %w", vmName, expName, err)
 		}
 	}
@@ -633,7 +652,7 @@ func ResetDiskState(expName, vmName string) error {
 	// to exclude file injections in the new snapshot
 	cmd.Command = fmt.Sprintf("%s disk snapshot %s %s", cmdPrefix, origSnap, tmpSnap)
 
-	err := mmcli.ErrorResponse(mmcli.Run(cmd))
+	err := mmcli.ErrorResponse(mm.Run(cmd))
 	if err != nil {
 		return fmt.Errorf(
 			"taking disk snapshot remotely for VM %s in experiment %s: %w",
@@ -647,7 +666,7 @@ func ResetDiskState(expName, vmName string) error {
 	tmpSnapFullPath := fmt.Sprintf("%s/%s", filepath.Dir(origSnap), tmpSnap)
 	cmd.Command = fmt.Sprintf("%s shell mv %s %s", cmdPrefix, tmpSnapFullPath, finalDst)
 
-	err = mmcli.ErrorResponse(mmcli.Run(cmd))
+	err = mmcli.ErrorResponse(mm.Run(cmd))
 	if err != nil {
 		return fmt.Errorf(
 			"moving disk snapshot remotely for VM %s in experiment %s: %w",
@@ -700,6 +719,12 @@ func Redeploy(expName, vmName string, opts ...RedeployOption) error {
 
 	o := newRedeployOptions(opts...)
 
+	if o.disk != "" || o.inject {
+		if err := requireKVM(expName, vmName); err != nil {
+			return err
+		}
+	}
+
 	var injects []string
 
 	if o.inject {
@@ -785,13 +810,21 @@ func Snapshots(expName, vmName string) ([]string, error) {
 
 // Snapshot takes a snapshot of the current state of the VM (disk and memory)
 // snapshots can later be restored.
-func Snapshot(expName, vmName, out string, cb func(string)) error { //nolint:funlen // complex logic
+//
+//nolint:funlen // complex logic
+func Snapshot(expName, vmName, out string, cb func(string), opts ...SnapshotOption) error {
+	o := newSnapshotOptions(opts...)
+
+	if err := requireKVM(expName, vmName); err != nil {
+		return err
+	}
+
 	vm, err := Get(expName, vmName)
 	if err != nil {
 		return fmt.Errorf("getting VM details: %w", err)
 	}
 
-	if !vm.Running {
+	if !vm.Running && (!o.leavePaused || vm.State != vmStatePaused) {
 		return errors.New("VM is not running")
 	}
 
@@ -807,7 +840,7 @@ func Snapshot(expName, vmName, out string, cb func(string)) error { //nolint:fun
 	cmd.Columns = []string{"host", "id"}
 	cmd.Filters = []string{"name=" + vmName}
 
-	status := mmcli.RunTabular(cmd)
+	status := mm.RunTabular(cmd)
 
 	if len(status) == 0 {
 		return fmt.Errorf("vm %s not found", vmName)
@@ -822,7 +855,7 @@ func Snapshot(expName, vmName, out string, cb func(string)) error { //nolint:fun
 
 	cmd.Command = fmt.Sprintf("vm migrate %s %s", vmName, out)
 
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("starting memory snapshot for VM %s: %w", vmName, err)
 	}
 
@@ -836,7 +869,7 @@ func Snapshot(expName, vmName, out string, cb func(string)) error { //nolint:fun
 	time.Sleep(1 * time.Second)
 
 	for {
-		status := mmcli.RunTabular(cmd)[0]
+		status := mm.RunTabular(cmd)[0]
 
 		if cb != nil {
 			if status["status"] == statusCompleted {
@@ -856,10 +889,12 @@ func Snapshot(expName, vmName, out string, cb func(string)) error { //nolint:fun
 
 	// ***** END: MIGRATE VM *****
 
-	cmd.Command = "vm start " + vmName
+	if !o.leavePaused {
+		cmd.Command = "vm start " + vmName
 
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
-		return fmt.Errorf("resuming VM %s after snapshot: %w", vmName, err)
+		if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
+			return fmt.Errorf("resuming VM %s after snapshot: %w", vmName, err)
+		}
 	}
 
 	var (
@@ -874,7 +909,7 @@ func Snapshot(expName, vmName, out string, cb func(string)) error { //nolint:fun
 	cmd = mmcli.NewCommand()
 	cmd.Command = fmt.Sprintf("%s shell mkdir -p %s", cmdPrefix, dst)
 
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("ensuring experiment files directory exists: %w", err)
 	}
 
@@ -889,7 +924,7 @@ func Snapshot(expName, vmName, out string, cb func(string)) error { //nolint:fun
 		final,
 	)
 
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("moving memory snapshot to experiment files directory: %w", err)
 	}
 
@@ -902,14 +937,20 @@ func Snapshot(expName, vmName, out string, cb func(string)) error { //nolint:fun
 		final,
 	)
 
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("moving disk snapshot to experiment files directory: %w", err)
 	}
 
 	return nil
 }
 
-func Restore(expName, vmName, snap string) error {
+func Restore(expName, vmName, snap string, opts ...SnapshotOption) error {
+	o := newSnapshotOptions(opts...)
+
+	if err := requireKVM(expName, vmName); err != nil {
+		return err
+	}
+
 	snap = strings.TrimSuffix(snap, filepath.Ext(snap))
 
 	snapshots, err := Snapshots(expName, vmName)
@@ -937,49 +978,53 @@ func Restore(expName, vmName, snap string) error {
 	cmd := mmcli.NewNamespacedCommand(expName)
 
 	cmd.Command = "vm config clone " + vmName
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("cloning config for VM %s: %w", vmName, err)
 	}
 
 	// Have to copy over UUID separate from clone.
 	// Needs to stay the same for miniccc agent to connect
 	cmd.Command = "vm config uuid " + details[0].UUID
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("setting uuid for VM %s: %w", vmName, err)
 	}
 
 	cmd.Command = fmt.Sprintf("vm config migrate %s.state", snap)
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("configuring migrate file for VM %s: %w", vmName, err)
 	}
 
 	cmd.Command = fmt.Sprintf("vm config disk %s.hdd,writeback", snap)
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("configuring disk file for VM %s: %w", vmName, err)
 	}
 
 	cmd.Command = "vm kill " + vmName
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("killing VM %s: %w", vmName, err)
 	}
 
 	cmd.Command = "vm flush " + vmName
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("flushing VMs: %w", err)
 	}
 
 	cmd.Command = "vm launch kvm " + vmName
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("relaunching VM %s: %w", vmName, err)
 	}
 
 	cmd.Command = "vm launch"
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("scheduling VM %s: %w", vmName, err)
 	}
 
+	if o.leavePaused {
+		return nil
+	}
+
 	cmd.Command = "vm start " + vmName
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return fmt.Errorf("starting VM %s: %w", vmName, err)
 	}
 
@@ -995,6 +1040,10 @@ func Restore(expName, vmName, snap string) error {
 //
 //nolint:cyclop,funlen,gocyclo,maintidx // complex logic
 func CommitToDisk(expName, vmName, out string, cb func(float64)) (string, error) {
+	if err := requireKVM(expName, vmName); err != nil {
+		return "", err
+	}
+
 	// Determine name of new disk image, if not provided.
 	if out == "" {
 		var err error
@@ -1055,7 +1104,7 @@ func CommitToDisk(expName, vmName, out string, cb func(float64)) (string, error)
 	cmd.Columns = []string{"host", "name", "id", "state"}
 	cmd.Filters = []string{"name=" + vmName}
 
-	status := mmcli.RunTabular(cmd)
+	status := mm.RunTabular(cmd)
 
 	if len(status) == 0 {
 		return "", errors.New("vm not found")
@@ -1155,7 +1204,7 @@ func CommitToDisk(expName, vmName, out string, cb func(float64)) (string, error)
 		cmd := mmcli.NewCommand()
 		cmd.Command = fmt.Sprintf("%s shell mkdir -p %s", cmdPrefix, tmp)
 
-		err := mmcli.ErrorResponse(mmcli.Run(cmd))
+		err := mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return fmt.Errorf("ensuring experiment tmp directory exists: %w", err)
 		}
@@ -1163,7 +1212,7 @@ func CommitToDisk(expName, vmName, out string, cb func(float64)) (string, error)
 		tmp = fmt.Sprintf("%s/images/%s/tmp/%s.qc2", common.PhenixBase, expName, vmName)
 		cmd.Command = fmt.Sprintf("%s shell cp %s %s", cmdPrefix, snap, tmp)
 
-		err = mmcli.ErrorResponse(mmcli.Run(cmd))
+		err = mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return fmt.Errorf("copying snapshot remotely: %w", err)
 		}
@@ -1278,6 +1327,10 @@ func CommitToDisk(expName, vmName, out string, cb func(float64)) (string, error)
 //
 //	that is compatible with memory forensic toolkits Volatility and Google's Rekall.
 func MemorySnapshot(expName, vmName, out string, cb func(string)) (string, error) { //nolint:funlen // complex logic
+	if err := requireKVM(expName, vmName); err != nil {
+		return "", err
+	}
+
 	_, err := Get(expName, vmName)
 	if err != nil {
 		return "", fmt.Errorf("getting VM details: %w", err)
@@ -1303,7 +1356,7 @@ func MemorySnapshot(expName, vmName, out string, cb func(string)) (string, error
 	cmd.Columns = []string{"host", "name", "id", "state"}
 	cmd.Filters = []string{"name=" + vmName}
 
-	status := mmcli.RunTabular(cmd)
+	status := mm.RunTabular(cmd)
 
 	if len(status) == 0 {
 		return "", errors.New("vm not found")
@@ -1326,7 +1379,7 @@ func MemorySnapshot(expName, vmName, out string, cb func(string)) (string, error
 	cmd.Filters = nil
 	cmd.Command = fmt.Sprintf("%s shell mkdir -p %s", cmdPrefix, filepath.Dir(out))
 
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return "", fmt.Errorf("ensuring experiment files directory exists: %w", err)
 	}
 	// ***** BEGIN: MEMORY SNAPSHOT VM *****
@@ -1337,7 +1390,7 @@ func MemorySnapshot(expName, vmName, out string, cb func(string)) (string, error
 	)
 	cmd.Command = fmt.Sprintf("vm qmp %s '%s'", vmName, qmp)
 
-	if err := mmcli.ErrorResponse(mmcli.Run(cmd)); err != nil {
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
 		return "", fmt.Errorf("starting memory snapshot for VM %s: ERROR: %w", vmName, err)
 	}
 
@@ -1354,7 +1407,7 @@ func MemorySnapshot(expName, vmName, out string, cb func(string)) (string, error
 		// sleep before querying the vm to prevent errors from start delays
 		time.Sleep(1 * time.Second)
 
-		res, err = mmcli.SingleResponse(mmcli.Run(cmd))
+		res, err = mmcli.SingleResponse(mm.Run(cmd))
 		if err != nil {
 			if cb != nil {
 				cb("failed")
@@ -1400,7 +1453,7 @@ func MemorySnapshot(expName, vmName, out string, cb func(string)) (string, error
 
 		cmd.Command = "file get " + memoryDumpPath
 
-		err := mmcli.ErrorResponse(mmcli.Run(cmd))
+		err := mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return "", fmt.Errorf("pulling ELF memory snapshot to headnode: %w", err)
 		}
@@ -1628,10 +1681,14 @@ func ChangeOpticalDisc(expName, vmName, isoPath string) error {
 		return errors.New("no optical disc path provided")
 	}
 
+	if err := requireKVM(expName, vmName); err != nil {
+		return err
+	}
+
 	cmd := mmcli.NewNamespacedCommand(expName)
 	cmd.Command = fmt.Sprintf("vm cdrom change %s %s", vmName, isoPath)
 
-	err := mmcli.ErrorResponse(mmcli.Run(cmd))
+	err := mmcli.ErrorResponse(mm.Run(cmd))
 	if err != nil {
 		return fmt.Errorf("changing optical disc for VM %s: %w", vmName, err)
 	}
@@ -1649,10 +1706,14 @@ func EjectOpticalDisc(expName, vmName string) error {
 		return errors.New("no VM name provided")
 	}
 
+	if err := requireKVM(expName, vmName); err != nil {
+		return err
+	}
+
 	cmd := mmcli.NewNamespacedCommand(expName)
 	cmd.Command = "vm cdrom eject " + vmName
 
-	err := mmcli.ErrorResponse(mmcli.Run(cmd))
+	err := mmcli.ErrorResponse(mm.Run(cmd))
 	if err != nil {
 		return fmt.Errorf("ejecting optical disc for VM %s: %w", vmName, err)
 	}
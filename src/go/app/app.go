@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -37,6 +39,16 @@ type TriggerPublication struct {
 	Resource   string
 	State      string
 	Error      error
+
+	// Set when publishing progress of an app being applied for a lifecycle
+	// stage.
+	Stage       Action
+	Attempt     int
+	MaxAttempts int
+	OnFailure   string
+
+	// Set when publishing progress reported by a persistent user app.
+	Message string
 }
 
 const (
@@ -54,6 +66,7 @@ var (
 	apps = make(map[string]AppFactory) //nolint:gochecknoglobals // global registry
 
 	defaultApps = map[string]struct{}{ //nolint:gochecknoglobals // global constant
+		"ipam":    {},
 		"ntp":     {},
 		"serial":  {},
 		"startup": {},
@@ -65,11 +78,15 @@ var ErrUserAppAlreadyRegistered = errors.New("user app already registered")
 
 func init() { //nolint:gochecknoinits // app registration
 	// Default apps (always run)
+	apps["ipam"] = func() App { return new(IPAM) }
 	apps["ntp"] = func() App { return new(NTP) }
 	apps["serial"] = func() App { return new(Serial) }
 	apps["startup"] = func() App { return new(Startup) }
 	apps["vrouter"] = func() App { return new(Vrouter) }
 
+	// Scenario apps
+	apps["dns"] = func() App { return new(DNS) }
+
 	// External user apps
 	apps["user-shell"] = func() App { return new(UserApp) }
 }
@@ -126,15 +143,10 @@ func GetApp(name string) App { //nolint:ireturn // factory
 	return app()
 }
 
-// DefaultApps returns a slice of all the initialized default phenix apps.
+// DefaultApps returns a slice of all the initialized default phenix apps,
+// sorted by name.
 func DefaultApps() []string {
-	apps := make([]string, 0, len(defaultApps))
-
-	for app := range defaultApps {
-		apps = append(apps, app)
-	}
-
-	return apps
+	return slices.Sorted(maps.Keys(defaultApps))
 }
 
 // App is the interface that identifies all the required functionality for a
@@ -172,15 +184,13 @@ type App interface {
 }
 
 // ApplyApps applies all the default phenix apps and any configured user apps to
-// the given experiment for the given lifecycle phase. It returns any errors
-// encountered while applying the apps.
-//
-//nolint:cyclop,funlen,gocyclo,maintidx // complex logic
+// the given experiment for the given lifecycle phase. Apps are applied in
+// dependency order (see Dependent and the scenario `dependsOn` setting), in
+// parallel where possible if the scenario has `parallelApps` enabled and the
+// stage allows it (see applyInParallel). It returns any errors encountered
+// while applying the apps.
 func ApplyApps(ctx context.Context, exp *types.Experiment, opts ...Option) error {
-	var (
-		options = NewOptions(opts...)
-		err     error
-	)
+	options := NewOptions(opts...)
 
 	if options.Stage == ActionPreStart {
 		// Reset status.apps for experiment. Note that this will get rid of any app
@@ -192,231 +202,398 @@ func ApplyApps(ctx context.Context, exp *types.Experiment, opts ...Option) error
 		exp.Status.ResetAppStatus()
 	}
 
-	// Publish triggered app events so web broker can propagate the publish out to
-	// web clients. This was initially setup to help convey SOH status in the UI.
-	publish := func(app, state string, err error) {
-		pubsub.Publish("trigger-app", TriggerPublication{ //nolint:exhaustruct // partial initialization
-			Experiment: exp.Metadata.Name,
-			App:        app,
-			State:      state,
-			Error:      err,
-		})
+	order, err := sortApps(dependencyGraph(exp, options.Stage, false))
+	if err != nil {
+		return fmt.Errorf("ordering apps for action %s: %w", options.Stage, err)
 	}
 
-	for _, name := range DefaultApps() {
-		if ctx.Err() != nil {
-			return ctx.Err()
+	if applyInParallel(exp, options.Stage) {
+		var mu sync.Mutex
+
+		options.Lock = &mu
+
+		err = applyParallel(ctx, order, func(ctx context.Context, node appNode) error {
+			return applyApp(ctx, exp, node, options)
+		})
+		if err != nil {
+			return err
 		}
+	} else {
+		for _, node := range order {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 
-		a := GetApp(name)
-		_ = a.Init(Name(name), DryRun(options.DryRun))
-
-		publish(a.Name(), "start", nil)
-
-		switch options.Stage {
-		case ActionConfigure:
-			err = a.Configure(ctx, exp)
-		case ActionPreStart:
-			err = a.PreStart(ctx, exp)
-		case ActionPostStart:
-			err = a.PostStart(ctx, exp)
-		case ActionRunning:
-			continue // silently ignore running stage for default apps
-		case ActionCleanup:
-			err = a.Cleanup(ctx, exp)
+			if err := applyApp(ctx, exp, node, options); err != nil {
+				return err
+			}
 		}
+	}
 
-		if err != nil {
-			publish(a.Name(), "error", err)
+	if options.Stage == ActionConfigure || options.Stage == ActionPreStart {
+		// just in case one of the apps added some nodes to the topology...
+		_ = exp.Spec.Topology().Init(exp.Spec.DefaultBridge())
+	}
 
-			plog.Error(
-				plog.TypePhenixApp,
-				fmt.Sprintf("[✗] '%s' default app (%s)", a.Name(), options.Stage),
-			)
+	return nil
+}
 
-			return fmt.Errorf(
-				"applying default app %s for action %s: %w",
-				a.Name(),
-				options.Stage,
-				err,
-			)
+// publish publishes triggered app events so web broker can propagate the
+// publish out to web clients. This was initially setup to help convey SOH
+// status in the UI.
+func publish(exp *types.Experiment, trigger TriggerPublication) {
+	trigger.Experiment = exp.Metadata.Name
+
+	pubsub.Publish("trigger-app", trigger)
+}
+
+// applyInParallel returns whether apps should be applied in parallel for the
+// given stage. User apps in the configure, pre-start and cleanup stages return
+// a complete experiment spec that replaces the spec they were given, so apps in
+// these stages are always applied one at a time to keep them from overwriting
+// each other's changes. In the post-start and running stages, user apps only
+// update their own app status.
+func applyInParallel(exp *types.Experiment, stage Action) bool {
+	if exp.Spec.Scenario() == nil || !exp.Spec.Scenario().ParallelApps() {
+		return false
+	}
+
+	return stage == ActionPostStart || stage == ActionRunning
+}
+
+// applyApp applies a single app to the given experiment for the lifecycle
+// phase in the given options, honoring the app's execution policy for the
+// phase (timeout, retries and what to do on failure).
+//
+//nolint:funlen // complex logic
+func applyApp(ctx context.Context, exp *types.Experiment, node appNode, options Options) error {
+	a := GetApp(node.name)
+	_ = a.Init(Name(node.name), DryRun(options.DryRun), ExperimentLock(options.Lock), RPC(node.scenario))
+
+	kind := "user"
+	if node.builtin {
+		kind = "default"
+	}
+
+	if options.Stage == ActionRunning && skipRunningStage(ctx, exp, a.Name(), options) {
+		return nil
+	}
+
+	policy, err := newAppPolicy(node.scenario, options.Stage)
+	if err != nil {
+		return fmt.Errorf("applying %s app %s for action %s: %w", kind, a.Name(), options.Stage, err)
+	}
+
+	trigger := TriggerPublication{ //nolint:exhaustruct // partial initialization
+		App:         a.Name(),
+		Stage:       options.Stage,
+		MaxAttempts: policy.retries + 1,
+		OnFailure:   policy.onFailure,
+	}
+
+	for trigger.Attempt = 1; ; trigger.Attempt++ {
+		trigger.State, trigger.Error = "start", nil
+		publish(exp, trigger)
+
+		err = runApp(ctx, exp, a, node.builtin, policy.timeout, options)
+
+		done := err == nil ||
+			errors.Is(err, ErrUserAppNotFound) ||
+			ctx.Err() != nil ||
+			trigger.Attempt > policy.retries
+
+		if done {
+			break
 		}
 
-		publish(a.Name(), "success", nil)
+		trigger.State, trigger.Error = "retry", err
+		publish(exp, trigger)
 
-		plog.Info(
+		plog.Warn(
 			plog.TypePhenixApp,
-			fmt.Sprintf("[✓] '%s' default app (%s)", a.Name(), options.Stage),
+			fmt.Sprintf("[↻] '%s' %s app (%s)", a.Name(), kind, options.Stage),
+			"attempt", trigger.Attempt,
+			"err", err,
 		)
 	}
 
-	if exp.Spec.Scenario() != nil {
-		for _, app := range exp.Spec.Scenario().Apps() {
-			if ctx.Err() != nil {
-				return ctx.Err()
-			}
+	recordAppResult(exp, a.Name(), options, trigger.Attempt, policy.onFailure, err)
 
-			// Don't apply default apps again if configured via the Scenario.
-			if _, ok := defaultApps[app.Name()]; ok {
-				continue
-			}
+	if err == nil {
+		trigger.State = "success"
+		publish(exp, trigger)
 
-			// Skip app if disabled, unless stage is ACTIONRUNNING
-			if app.Disabled() && options.Stage != ActionRunning {
-				continue
-			}
+		plog.Info(
+			plog.TypePhenixApp,
+			fmt.Sprintf("[✓] '%s' %s app (%s)", a.Name(), kind, options.Stage),
+		)
 
-			a := GetApp(app.Name())
-			_ = a.Init(Name(app.Name()), DryRun(options.DryRun))
+		return nil
+	}
 
-			publish(a.Name(), "start", nil)
+	trigger.State, trigger.Error = "error", err
+	publish(exp, trigger)
 
-			switch options.Stage {
-			case ActionConfigure:
-				exp.Status.SetAppRunning(app.Name(), true)
-				_ = exp.WriteToStore(true)
+	if errors.Is(err, ErrUserAppNotFound) {
+		plog.Warn(
+			plog.TypePhenixApp,
+			fmt.Sprintf("[?] '%s' %s app (%s)", a.Name(), kind, options.Stage),
+		)
 
-				err = a.Configure(ctx, exp)
+		return nil
+	}
 
-				exp.Status.SetAppRunning(app.Name(), false)
-				_ = exp.WriteToStore(true)
-			case ActionPreStart:
-				exp.Status.SetAppRunning(app.Name(), true)
-				_ = exp.WriteToStore(true)
+	err = fmt.Errorf("applying %s app %s for action %s: %w", kind, a.Name(), options.Stage, err)
 
-				err = a.PreStart(ctx, exp)
+	// Errors in the running stage are always reported via notes instead of
+	// being returned, since other apps may still need to be triggered.
+	if options.Stage == ActionRunning {
+		notes.AddErrors(ctx, false, err)
 
-				exp.Status.SetAppRunning(app.Name(), false)
-				_ = exp.WriteToStore(true)
-			case ActionPostStart:
-				exp.Status.SetAppRunning(app.Name(), true)
-				_ = exp.WriteToStore(true)
+		return nil
+	}
 
-				err = a.PostStart(ctx, exp)
+	switch policy.onFailure {
+	case OnFailureWarn:
+		plog.Warn(
+			plog.TypePhenixApp,
+			fmt.Sprintf("[!] '%s' %s app (%s)", a.Name(), kind, options.Stage),
+			"err", err,
+		)
 
-				exp.Status.SetAppRunning(app.Name(), false)
-				_ = exp.WriteToStore(true)
-			case ActionRunning:
-				if len(options.Filter) > 0 {
-					if _, ok := options.Filter[app.Name()]; !ok {
-						plog.Warn(
-							plog.TypePhenixApp,
-							fmt.Sprintf(
-								"Skipping '%s' experiment app (%s)",
-								app.Name(),
-								options.Stage,
-							),
-						)
+		notes.AddWarnings(ctx, false, err)
 
-						continue
-					}
-				}
+		return nil
+	case OnFailureContinue:
+		plog.Info(
+			plog.TypePhenixApp,
+			fmt.Sprintf("[-] '%s' %s app (%s)", a.Name(), kind, options.Stage),
+			"err", err,
+		)
 
-				// Check to make sure this app isn't already running via an automatic
-				// periodic execution.
-				if running := exp.Status.AppRunning()[app.Name()]; running {
-					notes.AddInfo(
-						ctx,
-						false,
-						fmt.Sprintf(
-							"app %s is currently already executing its running stage -- skipping",
-							app.Name(),
-						),
-					)
+		return nil
+	}
 
-					continue
-				}
+	plog.Error(
+		plog.TypePhenixApp,
+		fmt.Sprintf("[✗] '%s' %s app (%s)", a.Name(), kind, options.Stage),
+	)
 
-				exp.Status.SetAppRunning(app.Name(), true)
+	return err
+}
 
-				err := exp.WriteToStore(true)
-				if err != nil {
-					notes.AddErrors(
-						ctx,
-						false,
-						fmt.Errorf(
-							"error updating store with experiment (%s): %w",
-							exp.Spec.ExperimentName(),
-							err,
-						),
-					)
-				}
+// skipRunningStage returns true if the running stage of the given app should
+// be skipped, either because it was filtered out or because it's already
+// running.
+func skipRunningStage(ctx context.Context, exp *types.Experiment, name string, options Options) bool {
+	if len(options.Filter) > 0 {
+		if _, ok := options.Filter[name]; !ok {
+			plog.Warn(
+				plog.TypePhenixApp,
+				fmt.Sprintf("Skipping '%s' experiment app (%s)", name, options.Stage),
+			)
 
-				if err := a.Running(ctx, exp); err != nil {
-					notes.AddErrors(
-						ctx,
-						false,
-						fmt.Errorf("running app %s: %w", app.Name(), err),
-					)
-				}
+			return true
+		}
+	}
 
-				_ = exp.Reload() // reload experiment from store in case status was updated during run
-				exp.Status.SetAppRunning(app.Name(), false)
+	// Check to make sure this app isn't already running via an automatic
+	// periodic execution.
+	unlock := options.lockExperiment()
+	running := exp.Status.AppRunning()[name]
+
+	unlock()
+
+	if running {
+		notes.AddInfo(
+			ctx,
+			false,
+			fmt.Sprintf(
+				"app %s is currently already executing its running stage -- skipping",
+				name,
+			),
+		)
+	}
 
-				err = exp.WriteToStore(true)
-				if err != nil {
-					notes.AddErrors(
-						ctx,
-						false,
-						fmt.Errorf(
-							"error updating store with experiment (%s): %w",
-							exp.Spec.ExperimentName(),
-							err,
-						),
-					)
-				}
-			case ActionCleanup:
-				exp.Status.SetAppRunning(app.Name(), true)
-				_ = exp.WriteToStore(true)
+	return running
+}
 
-				err = a.Cleanup(ctx, exp)
+// runApp makes a single attempt at applying the given app, canceling it if it
+// takes longer than the given timeout (if not zero).
+func runApp(
+	ctx context.Context,
+	exp *types.Experiment,
+	a App,
+	builtin bool,
+	timeout time.Duration,
+	options Options,
+) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
 
-				exp.Status.SetAppRunning(app.Name(), false)
-				_ = exp.WriteToStore(true)
-			}
+	var err error
 
-			if err != nil {
-				publish(a.Name(), "error", err)
+	if builtin {
+		err = runDefaultApp(ctx, exp, a, timeout > 0, options)
+	} else {
+		err = runUserApp(ctx, exp, a, options)
+	}
 
-				if errors.Is(err, ErrUserAppNotFound) {
-					plog.Warn(
-						plog.TypePhenixApp,
-						fmt.Sprintf("[?] '%s' user app (%s)", a.Name(), options.Stage),
-					)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %v: %w", timeout, err)
+	}
 
-					continue
-				}
+	return err
+}
 
-				plog.Error(
-					plog.TypePhenixApp,
-					fmt.Sprintf("[✗] '%s' user app (%s)", a.Name(), options.Stage),
-				)
+// runDefaultApp applies the given default app. Default apps run in process, so
+// the experiment is locked for the duration of the app when apps are applied
+// in parallel. Default apps don't necessarily honor context cancellation, so
+// when a timeout is set the app is applied to a copy of the experiment that's
+// only copied back if the app finishes in time.
+func runDefaultApp(
+	ctx context.Context,
+	exp *types.Experiment,
+	a App,
+	timeout bool,
+	options Options,
+) error {
+	defer options.lockExperiment()()
+
+	if !timeout {
+		return applyStage(ctx, exp, a, options.Stage)
+	}
 
-				return fmt.Errorf(
-					"applying user app %s for action %s: %w",
-					a.Name(),
-					options.Stage,
-					err,
-				)
-			}
+	clone, err := cloneExperiment(exp)
+	if err != nil {
+		return err
+	}
 
-			publish(a.Name(), "success", nil)
+	done := make(chan error, 1)
 
-			plog.Info(
-				plog.TypePhenixApp,
-				fmt.Sprintf("[✓] '%s' user app (%s)", a.Name(), options.Stage),
-			)
+	go func() { done <- applyStage(ctx, clone, a, options.Stage) }()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			exp.SetSpec(clone.Spec)
+			exp.Status = clone.Status
 		}
+
+		return err
+	case <-ctx.Done():
+		return ctx.Err() //nolint:wrapcheck // wrapped by caller
 	}
+}
 
-	if options.Stage == ActionConfigure || options.Stage == ActionPreStart {
-		// just in case one of the apps added some nodes to the topology...
-		_ = exp.Spec.Topology().Init(exp.Spec.DefaultBridge())
+// runUserApp applies the given user app, tracking that it's running in the
+// experiment status.
+func runUserApp(ctx context.Context, exp *types.Experiment, a App, options Options) error {
+	if err := setAppRunning(exp, a.Name(), true, options); err != nil && options.Stage == ActionRunning {
+		notes.AddErrors(
+			ctx,
+			false,
+			fmt.Errorf(
+				"error updating store with experiment (%s): %w",
+				exp.Spec.ExperimentName(),
+				err,
+			),
+		)
+	}
+
+	err := applyStage(ctx, exp, a, options.Stage)
+
+	if options.Stage == ActionRunning {
+		unlock := options.lockExperiment()
+		_ = exp.Reload() // reload experiment from store in case status was updated during run
+		unlock()
+	}
+
+	if err := setAppRunning(exp, a.Name(), false, options); err != nil && options.Stage == ActionRunning {
+		notes.AddErrors(
+			ctx,
+			false,
+			fmt.Errorf(
+				"error updating store with experiment (%s): %w",
+				exp.Spec.ExperimentName(),
+				err,
+			),
+		)
+	}
+
+	return err
+}
+
+// applyStage calls the function of the given app for the given stage.
+func applyStage(ctx context.Context, exp *types.Experiment, a App, stage Action) error {
+	switch stage {
+	case ActionConfigure:
+		return a.Configure(ctx, exp)
+	case ActionPreStart:
+		return a.PreStart(ctx, exp)
+	case ActionPostStart:
+		return a.PostStart(ctx, exp)
+	case ActionRunning:
+		return a.Running(ctx, exp)
+	case ActionCleanup:
+		return a.Cleanup(ctx, exp)
 	}
 
 	return nil
 }
 
+// setAppRunning updates the running status of the given app and writes it to
+// the store.
+func setAppRunning(exp *types.Experiment, name string, running bool, options Options) error {
+	defer options.lockExperiment()()
+
+	exp.Status.SetAppRunning(name, running)
+
+	return exp.WriteToStore(true) //nolint:wrapcheck // passthrough
+}
+
+// recordAppResult records the outcome of applying the given app in the
+// experiment status and writes it to the store. Results of the configure stage
+// aren't written, since the experiment config is written as a whole after it's
+// configured (and may not be in the store yet when it's being created).
+func recordAppResult(
+	exp *types.Experiment,
+	name string,
+	options Options,
+	attempts int,
+	onFailure string,
+	err error,
+) {
+	defer options.lockExperiment()()
+
+	var msg string
+
+	if err != nil {
+		msg = err.Error()
+	}
+
+	exp.Status.SetAppResult(name, string(options.Stage), attempts, onFailure, msg)
+
+	if options.Stage == ActionConfigure {
+		return
+	}
+
+	if err := exp.WriteToStore(true); err != nil {
+		plog.Error(
+			plog.TypePhenixApp,
+			"[✗] error recording app result in store",
+			"exp", exp.Metadata.Name,
+			"app", name,
+			"stage", options.Stage,
+			"err", err,
+		)
+	}
+}
+
 // PeriodicallyRunApps checks the configuration for each app in the scenario to
 // see if it's configured to have its "running" stage run periodically. A
 // Goroutine is scheduled for each applicable app.
@@ -521,7 +698,7 @@ func PeriodicallyRunApps(ctx context.Context, wg *sync.WaitGroup, exp *types.Exp
 							// might be a good place for optimistic locking.
 
 							a := GetApp(app.Name())
-							_ = a.Init(Name(app.Name()))
+							_ = a.Init(Name(app.Name()), RPC(app))
 
 							exp.Status.SetAppRunning(app.Name(), true)
 
@@ -0,0 +1,449 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/mapstructure"
+
+	"phenix/tmpl"
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+)
+
+const (
+	appNameDNS = "dns"
+
+	dnsServerLabel = "dns-server"
+
+	DNSServerDnsmasq = "dnsmasq"
+	DNSServerBind    = "bind"
+)
+
+var ErrDNSServerNotFound = errors.New("no node labeled dns-server")
+
+// DNSAppMetadata is the metadata of the `dns` scenario app. Domain is the
+// domain nodes are named in (defaults to the experiment name with a `.lan`
+// suffix), and VLANs maps VLAN aliases to the domain used for interfaces in
+// that VLAN instead. Server is the DNS server to configure, either dnsmasq (the
+// default) or bind. Queries for names outside of the generated zones are sent
+// to the given forwarders, if any.
+type DNSAppMetadata struct {
+	Domain     string            `mapstructure:"domain"`
+	Server     string            `mapstructure:"server"`
+	Forwarders []string          `mapstructure:"forwarders"`
+	VLANs      map[string]string `mapstructure:"vlans"`
+	Records    []DNSAppRecord    `mapstructure:"records"`
+}
+
+// DNSAppRecord is an extra record to add to the generated zones. Names without
+// a dot are in the default domain, all others are fully qualified.
+type DNSAppRecord struct {
+	Name  string `mapstructure:"name"`
+	Type  string `mapstructure:"type"`
+	Value string `mapstructure:"value"`
+}
+
+// DNSRecord is a record in a generated zone. Names are fully qualified, without
+// the trailing dot.
+type DNSRecord struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// DNSZone is a generated forward or reverse zone.
+type DNSZone struct {
+	Name       string
+	Reverse    bool
+	NameServer string
+	Serial     int64
+	Records    []DNSRecord
+}
+
+// DNSTemplateData is the data passed to DNS server configuration templates.
+type DNSTemplateData struct {
+	Forwarders []string
+	Zones      []DNSZone
+}
+
+// DNS is a scenario app that generates authoritative forward and reverse zones
+// from the hostnames and interface addresses of topology nodes and injects the
+// DNS server configuration into the node labeled `dns-server`. The value of the
+// label is the name of the server interface, as with the `ntp-server` label
+// used by the NTP app. In the configure stage, static interfaces of other
+// nodes that don't already have DNS servers configured are given the server's
+// address so the startup app configures it as their resolver.
+type DNS struct{}
+
+func (DNS) Init(...Option) error {
+	return nil
+}
+
+func (DNS) Name() string {
+	return appNameDNS
+}
+
+// DependsOn makes sure IPAM has allocated interface addresses first.
+func (DNS) DependsOn() []string {
+	return []string{appNameIPAM}
+}
+
+func (DNS) Configure(_ context.Context, exp *types.Experiment) error {
+	server, addr, err := dnsServer(exp)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range exp.Spec.Topology().Nodes() {
+		if node == server || node.External() || strings.EqualFold(node.Type(), "router") {
+			continue
+		}
+
+		if iface := dnsClientInterface(node); iface != nil {
+			iface.SetDNS([]string{addr})
+		}
+	}
+
+	return nil
+}
+
+func (DNS) PreStart(_ context.Context, exp *types.Experiment) error {
+	server, _, err := dnsServer(exp)
+	if err != nil {
+		return err
+	}
+
+	var md DNSAppMetadata
+
+	if scenario := exp.Spec.Scenario(); scenario != nil {
+		if app := scenario.App(appNameDNS); app != nil {
+			if err := mapstructure.Decode(app.Metadata(), &md); err != nil {
+				return fmt.Errorf("decoding %s app metadata: %w", appNameDNS, err)
+			}
+		}
+	}
+
+	zones, err := DNSZones(exp, md)
+	if err != nil {
+		return err
+	}
+
+	var (
+		dnsDir = exp.Spec.BaseDir() + "/dns"
+		data   = DNSTemplateData{Forwarders: md.Forwarders, Zones: zones}
+	)
+
+	if err := os.MkdirAll(dnsDir, 0o750); err != nil {
+		return fmt.Errorf("creating experiment DNS directory path: %w", err)
+	}
+
+	switch strings.ToLower(md.Server) {
+	case "", DNSServerDnsmasq:
+		cfg := dnsDir + "/dnsmasq.conf"
+
+		if err := tmpl.CreateFileFromTemplate("dnsmasq.tmpl", data, cfg); err != nil {
+			return fmt.Errorf("generating dnsmasq config: %w", err)
+		}
+
+		server.AddInject(cfg, "/etc/dnsmasq.d/phenix.conf", "", "")
+	case DNSServerBind:
+		configs := map[string]string{
+			"bind_options.tmpl": "named.conf.options",
+			"bind_local.tmpl":   "named.conf.local",
+		}
+
+		for name, file := range configs {
+			cfg := dnsDir + "/" + file
+
+			if err := tmpl.CreateFileFromTemplate(name, data, cfg); err != nil {
+				return fmt.Errorf("generating bind config %s: %w", file, err)
+			}
+
+			server.AddInject(cfg, "/etc/bind/"+file, "", "")
+		}
+
+		for _, zone := range zones {
+			cfg := dnsDir + "/db." + zone.Name
+
+			if err := tmpl.CreateFileFromTemplate("bind_zone.tmpl", zone, cfg); err != nil {
+				return fmt.Errorf("generating bind zone %s: %w", zone.Name, err)
+			}
+
+			server.AddInject(cfg, "/etc/bind/zones/db."+zone.Name, "", "")
+		}
+	default:
+		return fmt.Errorf("unknown DNS server type %s (must be dnsmasq or bind)", md.Server)
+	}
+
+	return nil
+}
+
+func (DNS) PostStart(context.Context, *types.Experiment) error {
+	return nil
+}
+
+func (DNS) Running(context.Context, *types.Experiment) error {
+	return nil
+}
+
+func (DNS) Cleanup(context.Context, *types.Experiment) error {
+	return nil
+}
+
+// DNSZones returns the forward and reverse zones for the given experiment,
+// sorted by name. Every interface address is given a record named after the
+// node's hostname in the domain of the interface's VLAN, along with a PTR
+// record in the reverse zone of the interface's subnet. Reverse zones are
+// rounded down to octet (IPv4) or nibble (IPv6) boundaries.
+//
+//nolint:cyclop,funlen // complex logic
+func DNSZones(exp *types.Experiment, md DNSAppMetadata) ([]DNSZone, error) {
+	domain := dnsName(md.Domain)
+	if domain == "" {
+		domain = dnsName(exp.Metadata.Name) + ".lan"
+	}
+
+	vlanDomains := make(map[string]string)
+
+	for alias, d := range md.VLANs {
+		vlanDomains[strings.ToLower(alias)] = dnsName(d)
+	}
+
+	var (
+		zones  = make(map[string]*DNSZone)
+		serial = time.Now().Unix()
+	)
+
+	zone := func(name string, reverse bool) *DNSZone {
+		z, ok := zones[name]
+		if !ok {
+			z = &DNSZone{Name: name, Reverse: reverse, NameServer: "", Serial: serial, Records: nil}
+			zones[name] = z
+		}
+
+		return z
+	}
+
+	zone(domain, false)
+
+	for _, d := range vlanDomains {
+		zone(d, false)
+	}
+
+	server, _, err := dnsServer(exp)
+	if err != nil {
+		return nil, err
+	}
+
+	var nameServer string
+
+	for _, node := range exp.Spec.Topology().Nodes() {
+		host := dnsName(node.General().Hostname())
+
+		for _, iface := range node.Network().Interfaces() {
+			d, ok := vlanDomains[strings.ToLower(iface.VLAN())]
+			if !ok {
+				d = domain
+			}
+
+			fqdn := host + "." + d
+
+			if node == server && strings.EqualFold(iface.Name(), node.Labels()[dnsServerLabel]) {
+				nameServer = fqdn
+			}
+
+			for _, cidr := range iface.CIDRs() {
+				prefix, err := netip.ParsePrefix(cidr)
+				if err != nil {
+					continue
+				}
+
+				addr := prefix.Addr()
+
+				typ := "A"
+				if addr.Is6() {
+					typ = "AAAA"
+				}
+
+				fwd := zone(d, false)
+				fwd.Records = append(fwd.Records, DNSRecord{Name: fqdn, Type: typ, Value: addr.String()})
+
+				name, rev := dnsReverse(prefix)
+
+				z := zone(rev, true)
+				z.Records = append(z.Records, DNSRecord{Name: name, Type: "PTR", Value: fqdn})
+			}
+		}
+	}
+
+	for _, r := range md.Records {
+		name := strings.ToLower(strings.TrimSuffix(r.Name, "."))
+
+		if !strings.Contains(name, ".") {
+			name += "." + domain
+		}
+
+		typ := strings.ToUpper(r.Type)
+
+		switch typ {
+		case "A", "AAAA":
+			addr, err := netip.ParseAddr(r.Value)
+			if err != nil || addr.Is6() != (typ == "AAAA") {
+				return nil, fmt.Errorf("invalid address %s for %s record %s", r.Value, typ, r.Name)
+			}
+		case "CNAME":
+			r.Value = strings.TrimSuffix(r.Value, ".")
+		case "TXT":
+		default:
+			return nil, fmt.Errorf("unsupported record type %s for record %s (must be A, AAAA, CNAME or TXT)", r.Type, r.Name)
+		}
+
+		z := dnsZoneFor(zones, name, domain)
+		z.Records = append(z.Records, DNSRecord{Name: name, Type: typ, Value: r.Value})
+	}
+
+	if nameServer == "" {
+		nameServer = dnsName(server.General().Hostname()) + "." + domain
+	}
+
+	sorted := make([]DNSZone, 0, len(zones))
+
+	for _, z := range zones {
+		z.NameServer = nameServer
+		sorted = append(sorted, *z)
+	}
+
+	slices.SortFunc(sorted, func(a, b DNSZone) int { return strings.Compare(a.Name, b.Name) })
+
+	return sorted, nil
+}
+
+// dnsServer returns the node labeled `dns-server` (the first one if there are
+// several) and the address of the interface named by the label.
+func dnsServer(exp *types.Experiment) (ifaces.NodeSpec, string, error) { //nolint:ireturn // interface
+	servers := exp.Spec.Topology().FindNodesWithLabels(dnsServerLabel)
+	if len(servers) == 0 {
+		return nil, "", ErrDNSServerNotFound
+	}
+
+	server := servers[0] // use first server if more than one present
+
+	ifaceName := server.Labels()[dnsServerLabel]
+
+	addr := server.Network().InterfaceAddress(ifaceName)
+	if addr == "" {
+		return nil, "", fmt.Errorf("no IP address for DNS server %s interface %s", server.General().Hostname(), ifaceName)
+	}
+
+	return server, addr, nil
+}
+
+// dnsClientInterface returns the interface of the given node to configure the
+// DNS server on -- the first static interface with a gateway, or the first
+// static interface with an address if none have a gateway. Nil is returned if
+// any of the node's interfaces already have DNS servers configured.
+func dnsClientInterface(node ifaces.NodeSpec) ifaces.NodeNetworkInterface { //nolint:ireturn // interface
+	var candidate ifaces.NodeNetworkInterface
+
+	for _, iface := range node.Network().Interfaces() {
+		if len(iface.DNS()) > 0 {
+			return nil
+		}
+
+		if proto := strings.ToLower(iface.Proto()); proto != "" && proto != "static" {
+			continue
+		}
+
+		if iface.Address() == "" {
+			continue
+		}
+
+		if iface.Gateway() != "" {
+			if candidate == nil || candidate.Gateway() == "" {
+				candidate = iface
+			}
+
+			continue
+		}
+
+		if candidate == nil {
+			candidate = iface
+		}
+	}
+
+	return candidate
+}
+
+// dnsZoneFor returns the forward zone the given name belongs to, using the
+// longest matching zone name. Names outside of all zones belong to the default
+// domain.
+func dnsZoneFor(zones map[string]*DNSZone, name, domain string) *DNSZone {
+	var match *DNSZone
+
+	for _, z := range zones {
+		if z.Reverse || (name != z.Name && !strings.HasSuffix(name, "."+z.Name)) {
+			continue
+		}
+
+		if match == nil || len(z.Name) > len(match.Name) {
+			match = z
+		}
+	}
+
+	if match == nil {
+		return zones[domain]
+	}
+
+	return match
+}
+
+// dnsReverse returns the PTR record name for the address of the given prefix
+// and the name of the reverse zone it belongs to.
+func dnsReverse(prefix netip.Prefix) (string, string) {
+	var labels []string
+
+	addr := prefix.Addr()
+
+	if addr.Is4() {
+		for _, b := range addr.As4() {
+			labels = append(labels, fmt.Sprint(b))
+		}
+
+		octets := min(max(prefix.Bits()/8, 1), 3) //nolint:mnd // classful reverse zones
+
+		slices.Reverse(labels)
+
+		return strings.Join(labels, ".") + ".in-addr.arpa", strings.Join(labels[len(labels)-octets:], ".") + ".in-addr.arpa"
+	}
+
+	for _, b := range addr.As16() {
+		labels = append(labels, fmt.Sprintf("%x", b>>4), fmt.Sprintf("%x", b&0xf)) //nolint:mnd // nibbles
+	}
+
+	nibbles := min(max(prefix.Bits()/4, 1), 31) //nolint:mnd // nibble boundaries
+
+	slices.Reverse(labels)
+
+	return strings.Join(labels, ".") + ".ip6.arpa", strings.Join(labels[len(labels)-nibbles:], ".") + ".ip6.arpa"
+}
+
+// dnsName lower cases the given name and replaces characters that aren't
+// valid in hostnames with dashes.
+func dnsName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '.' {
+			return r
+		}
+
+		return '-'
+	}, name)
+}
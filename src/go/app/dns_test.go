@@ -0,0 +1,265 @@
+package app_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"phenix/app"
+	"phenix/store"
+	"phenix/tmpl"
+	"phenix/types"
+	v1 "phenix/types/version/v1"
+)
+
+func newDNSExperiment() *types.Experiment {
+	topo := &v1.TopologySpec{
+		NodesF: []*v1.Node{
+			{
+				TypeF:    "VirtualMachine",
+				GeneralF: &v1.General{HostnameF: "ns1"},
+				LabelsF:  map[string]string{"dns-server": "eth0"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						{NameF: "eth0", VLANF: "MGMT", AddressF: "10.0.0.53", MaskF: 24, GatewayF: "10.0.0.1"},
+					},
+				},
+			},
+			{
+				TypeF:    "VirtualMachine",
+				GeneralF: &v1.General{HostnameF: "Web_01"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						{NameF: "eth0", VLANF: "EXP", AddressF: "10.1.0.10", MaskF: 24},
+						{
+							NameF:      "eth1",
+							VLANF:      "MGMT",
+							AddressF:   "10.0.0.10",
+							MaskF:      24,
+							GatewayF:   "10.0.0.1",
+							AddressesF: []string{"2001:db8::10/64"},
+						},
+					},
+				},
+			},
+			{
+				TypeF:    "VirtualMachine",
+				GeneralF: &v1.General{HostnameF: "db"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						{NameF: "eth0", VLANF: "EXP", AddressF: "10.1.0.20", MaskF: 24, DNSF: []string{"8.8.8.8"}},
+					},
+				},
+			},
+			{
+				TypeF:    "VirtualMachine",
+				GeneralF: &v1.General{HostnameF: "client"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{{NameF: "eth0", VLANF: "EXP", ProtoF: "dhcp"}},
+				},
+			},
+			{
+				TypeF:    "Router",
+				GeneralF: &v1.General{HostnameF: "rtr"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{{NameF: "eth0", VLANF: "MGMT", AddressF: "10.0.0.1", MaskF: 24}},
+				},
+			},
+		},
+	}
+
+	exp := types.NewExperiment(store.ConfigMetadata{Name: "range"}) //nolint:exhaustruct // partial initialization
+	exp.Spec = &v1.ExperimentSpec{TopologyF: topo}
+
+	return exp
+}
+
+func TestDNSConfigure(t *testing.T) {
+	exp := newDNSExperiment()
+
+	if err := new(app.DNS).Configure(context.Background(), exp); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][]string{ // hostname/interface --> DNS servers
+		"ns1/eth0":    nil, // the DNS server itself
+		"Web_01/eth0": nil,
+		"Web_01/eth1": {"10.0.0.53"}, // interface with the gateway
+		"db/eth0":     {"8.8.8.8"},   // set by hand
+		"client/eth0": nil,           // DHCP
+		"rtr/eth0":    nil,
+	}
+
+	for _, node := range exp.Spec.Topology().Nodes() {
+		for _, iface := range node.Network().Interfaces() {
+			key := node.General().Hostname() + "/" + iface.Name()
+
+			if got := strings.Join(iface.DNS(), ","); got != strings.Join(expected[key], ",") {
+				t.Errorf("expected DNS servers %v for %s, got %v", expected[key], key, iface.DNS())
+			}
+		}
+	}
+}
+
+func TestDNSConfigureNoServer(t *testing.T) {
+	exp := newDNSExperiment()
+	exp.Spec.Topology().Nodes()[0].Labels()["dns-server"] = "eth9"
+
+	if err := new(app.DNS).Configure(context.Background(), exp); err == nil {
+		t.Fatal("expected error for DNS server interface without an address")
+	}
+
+	delete(exp.Spec.Topology().Nodes()[0].Labels(), "dns-server")
+
+	if err := new(app.DNS).Configure(context.Background(), exp); err == nil {
+		t.Fatal("expected error for missing DNS server")
+	}
+}
+
+func TestDNSZones(t *testing.T) {
+	md := app.DNSAppMetadata{
+		VLANs: map[string]string{"MGMT": "mgmt.example.com"},
+		Records: []app.DNSAppRecord{
+			{Name: "www", Type: "cname", Value: "web-01.range.lan"},
+			{Name: "vip.mgmt.example.com", Type: "A", Value: "10.0.0.100"},
+		},
+	}
+
+	zones, err := app.DNSZones(newDNSExperiment(), md)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := make(map[string][]string) // zone --> records
+
+	for _, zone := range zones {
+		if zone.NameServer != "ns1.mgmt.example.com" {
+			t.Errorf("expected name server ns1.mgmt.example.com for %s, got %s", zone.Name, zone.NameServer)
+		}
+
+		for _, r := range zone.Records {
+			records[zone.Name] = append(records[zone.Name], r.Name+" "+r.Type+" "+r.Value)
+		}
+	}
+
+	expected := map[string][]string{
+		"range.lan": {
+			"web-01.range.lan A 10.1.0.10",
+			"db.range.lan A 10.1.0.20",
+			"www.range.lan CNAME web-01.range.lan",
+		},
+		"mgmt.example.com": {
+			"ns1.mgmt.example.com A 10.0.0.53",
+			"web-01.mgmt.example.com A 10.0.0.10",
+			"web-01.mgmt.example.com AAAA 2001:db8::10",
+			"rtr.mgmt.example.com A 10.0.0.1",
+			"vip.mgmt.example.com A 10.0.0.100",
+		},
+		"0.1.10.in-addr.arpa": {
+			"10.0.1.10.in-addr.arpa PTR web-01.range.lan",
+			"20.0.1.10.in-addr.arpa PTR db.range.lan",
+		},
+		"0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa": {
+			"0.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa PTR web-01.mgmt.example.com",
+		},
+	}
+
+	for zone, want := range expected {
+		if got := records[zone]; strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("unexpected records in zone %s:\n%s", zone, strings.Join(got, "\n"))
+		}
+	}
+
+	if len(zones) != 5 {
+		t.Errorf("expected 5 zones, got %d", len(zones))
+	}
+}
+
+func TestDNSZonesInvalidRecord(t *testing.T) {
+	md := app.DNSAppMetadata{Records: []app.DNSAppRecord{{Name: "mail", Type: "AAAA", Value: "10.0.0.25"}}}
+
+	if _, err := app.DNSZones(newDNSExperiment(), md); err == nil {
+		t.Fatal("expected error for IPv4 address in AAAA record")
+	}
+
+	md = app.DNSAppMetadata{Records: []app.DNSAppRecord{{Name: "mail", Type: "MX", Value: "10 mx.range.lan"}}}
+
+	if _, err := app.DNSZones(newDNSExperiment(), md); err == nil {
+		t.Fatal("expected error for unsupported record type")
+	}
+}
+
+// TestDNSTemplates verifies that the dnsmasq and bind templates include the
+// generated records and forwarders.
+func TestDNSTemplates(t *testing.T) {
+	zone := app.DNSZone{
+		Name:       "range.lan",
+		NameServer: "ns1.range.lan",
+		Serial:     42,
+		Records: []app.DNSRecord{
+			{Name: "ns1.range.lan", Type: "A", Value: "10.0.0.53"},
+			{Name: "www.range.lan", Type: "CNAME", Value: "ns1.range.lan"},
+			{Name: "range.lan", Type: "TXT", Value: "v=spf1 -all"},
+		},
+	}
+
+	reverse := app.DNSZone{
+		Name:       "0.10.in-addr.arpa",
+		Reverse:    true,
+		NameServer: "ns1.range.lan",
+		Serial:     42,
+		Records:    []app.DNSRecord{{Name: "53.0.0.10.in-addr.arpa", Type: "PTR", Value: "ns1.range.lan"}},
+	}
+
+	data := app.DNSTemplateData{Forwarders: []string{"1.1.1.1"}, Zones: []app.DNSZone{reverse, zone}}
+
+	expected := map[string][]string{
+		"dnsmasq.tmpl": {
+			"server=1.1.1.1",
+			"local=/range.lan/",
+			"local=/0.10.in-addr.arpa/",
+			"host-record=ns1.range.lan,10.0.0.53",
+			"cname=www.range.lan,ns1.range.lan",
+			`txt-record=range.lan,"v=spf1 -all"`,
+		},
+		"bind_options.tmpl": {"forward only;", "1.1.1.1;"},
+		"bind_local.tmpl": {
+			`zone "range.lan" {`,
+			`file "/etc/bind/zones/db.0.10.in-addr.arpa";`,
+		},
+	}
+
+	for name, lines := range expected {
+		var buf bytes.Buffer
+
+		if err := tmpl.GenerateFromTemplate(name, data, &buf); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, line := range lines {
+			if !strings.Contains(buf.String(), line) {
+				t.Errorf("expected %q in %s:\n%s", line, name, buf.String())
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.GenerateFromTemplate("bind_zone.tmpl", zone, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		"@ IN SOA ns1.range.lan. hostmaster.range.lan. (",
+		"42 ; serial",
+		"@ IN NS ns1.range.lan.",
+		"ns1.range.lan. IN A 10.0.0.53",
+		"www.range.lan. IN CNAME ns1.range.lan.",
+		`range.lan. IN TXT "v=spf1 -all"`,
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("expected %q in zone:\n%s", line, buf.String())
+		}
+	}
+}
@@ -3,6 +3,7 @@ Package app provides Phenix apps that customize experiments using metadata.
 
 Default Apps
 
+  - ipam.go:    allocates interface addresses from per-VLAN subnet pools
   - ntp.go:     configures a NTP server into the experiment infrastructure
   - serial.go:  configures a Serial interface on a VM image
   - startup.go: configures minimega startup injections based on OS type
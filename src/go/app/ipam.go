@@ -0,0 +1,329 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+)
+
+const appNameIPAM = "ipam"
+
+// IPAM is a default app that allocates addresses for topology interfaces from
+// the per-VLAN subnet pools configured in the experiment spec (`vlans.subnets`).
+// Interfaces in a VLAN with a subnet pool that are missing an address are
+// allocated the next free address in the pool, along with its mask and, for
+// nodes without a default gateway, the address of the first router in the
+// VLAN as the gateway. Addresses already set in the topology are never
+// changed.
+//
+// Allocation is deterministic -- router interfaces are allocated first, then
+// all other interfaces ordered by hostname and interface name -- and
+// allocations are recorded in the experiment status so the same interface is
+// given the same address each time the experiment is configured.
+type IPAM struct{}
+
+func (IPAM) Init(...Option) error {
+	return nil
+}
+
+func (IPAM) Name() string {
+	return appNameIPAM
+}
+
+//nolint:cyclop,funlen // complex logic
+func (IPAM) Configure(ctx context.Context, exp *types.Experiment) error {
+	pools, err := newIPAMPools(exp.Spec.VLANs().Subnets())
+	if err != nil {
+		return err
+	}
+
+	if len(pools) == 0 {
+		return nil
+	}
+
+	var (
+		previous   = exp.Status.IPAM()
+		allocated  = make(map[string]string)
+		candidates []ipamInterface
+	)
+
+	// Reserve addresses already set in the topology so they're never allocated
+	// to other interfaces.
+	for _, node := range exp.Spec.Topology().Nodes() {
+		for _, iface := range node.Network().Interfaces() {
+			pool, ok := pools[strings.ToLower(iface.VLAN())]
+			if !ok {
+				continue
+			}
+
+			candidate := ipamInterface{node: node, iface: iface}
+
+			if iface.Address() != "" {
+				addr, err := netip.ParseAddr(iface.Address())
+				if err != nil || !pool.prefix.Contains(addr) {
+					continue
+				}
+
+				pool.reserve(addr)
+
+				if iface.Mask() == 0 {
+					iface.SetMask(pool.prefix.Bits())
+				}
+
+				// Keep track of addresses previously allocated by IPAM that are still
+				// in use.
+				if prev, ok := previous[candidate.key()]; ok && prev == pool.cidr(addr) {
+					allocated[candidate.key()] = prev
+				}
+
+				continue
+			}
+
+			if node.External() || iface.Type() == "serial" {
+				continue
+			}
+
+			if proto := strings.ToLower(iface.Proto()); proto != "" && proto != "static" {
+				continue
+			}
+
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	sortIPAMInterfaces(candidates)
+
+	var unallocated []ipamInterface
+
+	// Give interfaces the same address they were allocated previously, as long
+	// as it's still in the VLAN's pool and hasn't been taken by a static address.
+	for _, c := range candidates {
+		pool := pools[strings.ToLower(c.iface.VLAN())]
+
+		if prev, ok := previous[c.key()]; ok {
+			if prefix, err := netip.ParsePrefix(prev); err == nil && pool.available(prefix.Addr()) {
+				pool.reserve(prefix.Addr())
+				c.assign(pool, prefix.Addr())
+				allocated[c.key()] = pool.cidr(prefix.Addr())
+
+				continue
+			}
+		}
+
+		unallocated = append(unallocated, c)
+	}
+
+	for _, c := range unallocated {
+		pool := pools[strings.ToLower(c.iface.VLAN())]
+
+		addr, ok := pool.next()
+		if !ok {
+			return fmt.Errorf(
+				"subnet %s for VLAN %s exhausted allocating address for %s",
+				pool.prefix,
+				c.iface.VLAN(),
+				c.key(),
+			)
+		}
+
+		c.assign(pool, addr)
+		allocated[c.key()] = pool.cidr(addr)
+	}
+
+	setIPAMGateways(exp.Spec.Topology(), pools)
+
+	exp.Status.SetIPAM(allocated)
+
+	return nil
+}
+
+func (IPAM) PreStart(ctx context.Context, exp *types.Experiment) error {
+	return nil
+}
+
+func (IPAM) PostStart(ctx context.Context, exp *types.Experiment) error {
+	return nil
+}
+
+func (IPAM) Running(ctx context.Context, exp *types.Experiment) error {
+	return nil
+}
+
+func (IPAM) Cleanup(ctx context.Context, exp *types.Experiment) error {
+	return nil
+}
+
+// setIPAMGateways sets the gateway of the first interface in a VLAN with a
+// subnet pool for each non-router node that doesn't already have a gateway.
+// The gateway used is the address of the first router interface in the VLAN.
+func setIPAMGateways(topo ifaces.TopologySpec, pools map[string]*ipamPool) {
+	var routers []ipamInterface
+
+	for _, node := range topo.Nodes() {
+		if !strings.EqualFold(node.Type(), "router") {
+			continue
+		}
+
+		for _, iface := range node.Network().Interfaces() {
+			routers = append(routers, ipamInterface{node: node, iface: iface})
+		}
+	}
+
+	sortIPAMInterfaces(routers)
+
+	gateways := make(map[string]string) // VLAN --> gateway
+
+	for _, r := range routers {
+		vlan := strings.ToLower(r.iface.VLAN())
+
+		pool, ok := pools[vlan]
+		if !ok {
+			continue
+		}
+
+		if _, ok := gateways[vlan]; ok {
+			continue
+		}
+
+		if addr, err := netip.ParseAddr(r.iface.Address()); err == nil && pool.prefix.Contains(addr) {
+			gateways[vlan] = addr.String()
+		}
+	}
+
+	for _, node := range topo.Nodes() {
+		if node.External() || strings.EqualFold(node.Type(), "router") {
+			continue
+		}
+
+		var hasGateway bool
+
+		for _, iface := range node.Network().Interfaces() {
+			if iface.Gateway() != "" {
+				hasGateway = true
+
+				break
+			}
+		}
+
+		if hasGateway {
+			continue
+		}
+
+		for _, iface := range node.Network().Interfaces() {
+			if gw, ok := gateways[strings.ToLower(iface.VLAN())]; ok && iface.Address() != "" {
+				iface.SetGateway(gw)
+
+				break
+			}
+		}
+	}
+}
+
+type ipamInterface struct {
+	node  ifaces.NodeSpec
+	iface ifaces.NodeNetworkInterface
+}
+
+func (i ipamInterface) key() string {
+	return i.node.General().Hostname() + "/" + i.iface.Name()
+}
+
+func (i ipamInterface) assign(pool *ipamPool, addr netip.Addr) {
+	i.iface.SetAddress(addr.String())
+	i.iface.SetMask(pool.prefix.Bits())
+
+	if i.iface.Proto() == "" {
+		i.iface.SetProto("static")
+	}
+}
+
+// sortIPAMInterfaces sorts router interfaces first, then by hostname and
+// interface name.
+func sortIPAMInterfaces(list []ipamInterface) {
+	sort.SliceStable(list, func(i, j int) bool {
+		ri := strings.EqualFold(list[i].node.Type(), "router")
+		rj := strings.EqualFold(list[j].node.Type(), "router")
+
+		if ri != rj {
+			return ri
+		}
+
+		return list[i].key() < list[j].key()
+	})
+}
+
+type ipamPool struct {
+	prefix netip.Prefix
+	used   map[netip.Addr]struct{}
+	cursor netip.Addr
+}
+
+func newIPAMPools(subnets map[string]string) (map[string]*ipamPool, error) {
+	pools := make(map[string]*ipamPool)
+
+	for alias, subnet := range subnets {
+		prefix, err := netip.ParsePrefix(subnet)
+		if err != nil {
+			return nil, fmt.Errorf("parsing subnet %s for VLAN %s: %w", subnet, alias, err)
+		}
+
+		prefix = prefix.Masked()
+
+		pools[strings.ToLower(alias)] = &ipamPool{
+			prefix: prefix,
+			used:   make(map[netip.Addr]struct{}),
+			cursor: prefix.Addr(), // network address is never allocated
+		}
+	}
+
+	return pools, nil
+}
+
+func (p *ipamPool) reserve(addr netip.Addr) {
+	p.used[addr] = struct{}{}
+}
+
+// available returns true if the given address is a usable host address in the
+// pool that hasn't been reserved.
+func (p *ipamPool) available(addr netip.Addr) bool {
+	if !p.prefix.Contains(addr) || addr == p.prefix.Addr() || p.broadcast(addr) {
+		return false
+	}
+
+	_, used := p.used[addr]
+
+	return !used
+}
+
+// next reserves and returns the lowest available address in the pool.
+func (p *ipamPool) next() (netip.Addr, bool) {
+	for addr := p.cursor.Next(); addr.IsValid() && p.prefix.Contains(addr); addr = addr.Next() {
+		if p.available(addr) {
+			p.cursor = addr
+			p.reserve(addr)
+
+			return addr, true
+		}
+	}
+
+	return netip.Addr{}, false
+}
+
+// broadcast returns true if the given address is the broadcast address of an
+// IPv4 pool.
+func (p *ipamPool) broadcast(addr netip.Addr) bool {
+	if !addr.Is4() || p.prefix.Bits() >= 31 { //nolint:mnd // point-to-point links have no broadcast
+		return false
+	}
+
+	return !p.prefix.Contains(addr.Next())
+}
+
+func (p *ipamPool) cidr(addr netip.Addr) string {
+	return netip.PrefixFrom(addr, p.prefix.Bits()).String()
+}
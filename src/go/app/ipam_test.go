@@ -0,0 +1,125 @@
+package app_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"phenix/app"
+	"phenix/types"
+	v1 "phenix/types/version/v1"
+)
+
+func newIPAMExperiment() *types.Experiment {
+	topo := &v1.TopologySpec{
+		NodesF: []*v1.Node{
+			{
+				TypeF:    "VirtualMachine",
+				GeneralF: &v1.General{HostnameF: "web"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{{NameF: "eth0", VLANF: "EXP"}},
+				},
+			},
+			{
+				TypeF:    "VirtualMachine",
+				GeneralF: &v1.General{HostnameF: "db"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{
+						{NameF: "eth0", VLANF: "EXP", AddressF: "10.1.0.2"},
+						{NameF: "eth1", VLANF: "OTHER"},
+					},
+				},
+			},
+			{
+				TypeF:    "Router",
+				GeneralF: &v1.General{HostnameF: "rtr"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{{NameF: "eth0", VLANF: "EXP"}},
+				},
+			},
+			{
+				TypeF:    "VirtualMachine",
+				GeneralF: &v1.General{HostnameF: "client"},
+				NetworkF: &v1.Network{
+					InterfacesF: []*v1.Interface{{NameF: "eth0", VLANF: "EXP", ProtoF: "dhcp"}},
+				},
+			},
+		},
+	}
+
+	spec := &v1.ExperimentSpec{
+		TopologyF: topo,
+		VLANsF:    &v1.VLANSpec{SubnetsF: map[string]string{"EXP": "10.1.0.0/24"}},
+	}
+
+	return &types.Experiment{Spec: spec, Status: new(v1.ExperimentStatus)}
+}
+
+func TestIPAMConfigure(t *testing.T) {
+	exp := newIPAMExperiment()
+
+	if err := new(app.IPAM).Configure(context.Background(), exp); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string][3]string{ // hostname --> address, mask, gateway
+		"rtr":    {"10.1.0.1", "24", ""},
+		"db":     {"10.1.0.2", "24", "10.1.0.1"}, // set by hand
+		"web":    {"10.1.0.3", "24", "10.1.0.1"},
+		"client": {"", "0", ""}, // DHCP
+	}
+
+	for host, want := range expected {
+		iface := exp.Spec.Topology().FindNodeByName(host).Network().Interfaces()[0]
+
+		got := [3]string{iface.Address(), strconv.Itoa(iface.Mask()), iface.Gateway()}
+		if got != want {
+			t.Errorf("expected %s eth0 to be %v, got %v", host, want, got)
+		}
+	}
+
+	if iface := exp.Spec.Topology().FindNodeByName("db").Network().Interfaces()[1]; iface.Address() != "" {
+		t.Errorf("expected interface in VLAN without subnet pool to be skipped, got %s", iface.Address())
+	}
+
+	ipam := exp.Status.IPAM()
+
+	if len(ipam) != 2 || ipam["rtr/eth0"] != "10.1.0.1/24" || ipam["web/eth0"] != "10.1.0.3/24" {
+		t.Fatalf("unexpected IPAM status: %v", ipam)
+	}
+
+	// Reconfiguring an experiment whose topology was reset should give the same
+	// interfaces the same addresses, even when new interfaces sort before them.
+	reset := newIPAMExperiment()
+	reset.Status = exp.Status
+
+	topo, _ := reset.Spec.Topology().(*v1.TopologySpec)
+	topo.NodesF = append(topo.NodesF, &v1.Node{
+		TypeF:    "VirtualMachine",
+		GeneralF: &v1.General{HostnameF: "app"},
+		NetworkF: &v1.Network{
+			InterfacesF: []*v1.Interface{{NameF: "eth0", VLANF: "EXP"}},
+		},
+	})
+
+	if err := new(app.IPAM).Configure(context.Background(), reset); err != nil {
+		t.Fatal(err)
+	}
+
+	if addr := reset.Spec.Topology().FindNodeByName("web").Network().Interfaces()[0].Address(); addr != "10.1.0.3" {
+		t.Errorf("expected web eth0 to keep 10.1.0.3, got %s", addr)
+	}
+
+	if addr := reset.Spec.Topology().FindNodeByName("app").Network().Interfaces()[0].Address(); addr != "10.1.0.4" {
+		t.Errorf("expected app eth0 to be allocated 10.1.0.4, got %s", addr)
+	}
+}
+
+func TestIPAMExhausted(t *testing.T) {
+	exp := newIPAMExperiment()
+	exp.Spec.VLANs().SetSubnets(map[string]string{"EXP": "10.1.0.0/30"})
+
+	if err := new(app.IPAM).Configure(context.Background(), exp); err == nil {
+		t.Fatal("expected error allocating from exhausted subnet")
+	}
+}
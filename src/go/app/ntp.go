@@ -77,6 +77,11 @@ func (NTP) Name() string {
 	return appNameNTP
 }
 
+// DependsOn makes sure IPAM has allocated the NTP server address first.
+func (NTP) DependsOn() []string {
+	return []string{appNameIPAM}
+}
+
 func (NTP) Configure(ctx context.Context, exp *types.Experiment) error {
 	return nil
 }
@@ -1,5 +1,11 @@
 package app
 
+import (
+	"sync"
+
+	ifaces "phenix/types/interfaces"
+)
+
 // Option is a function that configures options for a phenix app. It is used in
 // `app.Init`.
 type Option func(*Options)
@@ -10,6 +16,14 @@ type Options struct {
 	Name   string // used to set the app name
 	DryRun bool
 	Filter map[string]struct{}
+
+	// Lock guards the experiment when apps are applied in parallel. It's nil
+	// when apps are applied one at a time.
+	Lock sync.Locker
+
+	// RPC is the transport used to talk to persistent user apps, or empty if
+	// user apps are run once per stage.
+	RPC string
 }
 
 // NewOptions returns an Options struct initialized with the given option list.
@@ -46,6 +60,36 @@ func DryRun(d bool) Option {
 	}
 }
 
+// ExperimentLock sets the lock used to guard the experiment when apps are
+// applied in parallel.
+func ExperimentLock(l sync.Locker) Option {
+	return func(o *Options) {
+		o.Lock = l
+	}
+}
+
+// RPC sets the transport used to talk to the user app from the `rpc` setting
+// of the given scenario app, which can be nil.
+func RPC(app ifaces.ScenarioApp) Option {
+	return func(o *Options) {
+		if app != nil {
+			o.RPC = app.RPC()
+		}
+	}
+}
+
+// lockExperiment locks the experiment, if apps are being applied in parallel,
+// and returns a function to unlock it.
+func (o Options) lockExperiment() func() {
+	if o.Lock == nil {
+		return func() {}
+	}
+
+	o.Lock.Lock()
+
+	return o.Lock.Unlock
+}
+
 // FilterApp adds an app(s) to the list of filtered apps.
 func FilterApp(a ...string) Option {
 	return func(o *Options) {
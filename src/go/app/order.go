@@ -0,0 +1,224 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/sync/errgroup"
+
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+)
+
+// Dependent is an optional interface that apps can implement to declare the
+// apps that must be applied before them in every lifecycle stage. User apps
+// declare their dependencies using the `dependsOn` setting in the scenario.
+type Dependent interface {
+	DependsOn() []string
+}
+
+// appNode is an app to apply in a lifecycle stage along with the names of the
+// apps it depends on.
+type appNode struct {
+	name     string
+	scenario ifaces.ScenarioApp // nil for default apps not configured in the scenario
+	deps     []string
+	builtin  bool
+}
+
+// ValidateDependencies returns an error if any of the apps for the given
+// experiment depend on an unknown app or if there's a dependency cycle between
+// them. Disabled apps are included so enabling them later can't introduce a
+// cycle.
+func ValidateDependencies(exp *types.Experiment) error {
+	nodes := dependencyGraph(exp, ActionConfigure, true)
+
+	known := make(map[string]struct{}, len(nodes))
+	for _, n := range nodes {
+		known[n.name] = struct{}{}
+	}
+
+	for _, n := range nodes {
+		for _, dep := range n.deps {
+			if _, ok := known[dep]; !ok {
+				return fmt.Errorf("app %s depends on unknown app %s", n.name, dep)
+			}
+		}
+	}
+
+	if _, err := sortApps(nodes); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// dependencyGraph returns the apps to apply to the given experiment for the
+// given lifecycle stage. Default apps come first, sorted by name, followed by
+// the scenario apps in the order they're listed in the scenario. Apps without
+// dependencies between them are applied in this order.
+func dependencyGraph(exp *types.Experiment, stage Action, includeDisabled bool) []appNode {
+	var (
+		scenario = exp.Spec.Scenario()
+		nodes    []appNode
+	)
+
+	// Default apps aren't applied in the running stage.
+	if stage != ActionRunning {
+		for _, name := range DefaultApps() {
+			node := appNode{name: name, scenario: nil, deps: nil, builtin: true}
+
+			if d, ok := GetApp(name).(Dependent); ok {
+				node.deps = append(node.deps, d.DependsOn()...)
+			}
+
+			// Default apps can be given additional dependencies via the scenario.
+			if scenario != nil {
+				if app := scenario.App(name); app != nil {
+					node.scenario = app
+					node.deps = append(node.deps, app.DependsOn()...)
+				}
+			}
+
+			nodes = append(nodes, node)
+		}
+	}
+
+	if scenario == nil {
+		return nodes
+	}
+
+	for _, app := range scenario.Apps() {
+		// Don't apply default apps again if configured via the Scenario.
+		if _, ok := defaultApps[app.Name()]; ok {
+			continue
+		}
+
+		// Skip app if disabled, unless stage is ACTIONRUNNING
+		if app.Disabled() && stage != ActionRunning && !includeDisabled {
+			continue
+		}
+
+		nodes = append(nodes, appNode{name: app.Name(), scenario: app, deps: app.DependsOn(), builtin: false})
+	}
+
+	return nodes
+}
+
+// sortApps orders the given apps so every app comes after the apps it depends
+// on. Dependencies on apps that aren't in the given list (e.g. disabled apps)
+// are ignored. Apps without dependencies between them keep their relative
+// order, making the result deterministic. An error is returned if there's a
+// dependency cycle.
+func sortApps(nodes []appNode) ([]appNode, error) {
+	var (
+		indexes    = make(map[string][]int)
+		dependents = make([][]int, len(nodes))
+		pending    = make([]int, len(nodes))
+		sorted     = make([]appNode, 0, len(nodes))
+		ready      []int
+	)
+
+	for i, n := range nodes {
+		indexes[n.name] = append(indexes[n.name], i)
+	}
+
+	for i, n := range nodes {
+		for _, dep := range slices.Compact(slices.Sorted(slices.Values(n.deps))) {
+			for _, j := range indexes[dep] {
+				if j == i {
+					return nil, fmt.Errorf("app %s depends on itself", n.name)
+				}
+
+				dependents[j] = append(dependents[j], i)
+				pending[i]++
+			}
+		}
+	}
+
+	for i := range nodes {
+		if pending[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	for len(ready) > 0 {
+		// Always apply the first ready app in the original order.
+		slices.Sort(ready)
+
+		i := ready[0]
+		ready = ready[1:]
+
+		sorted = append(sorted, nodes[i])
+
+		for _, j := range dependents[i] {
+			if pending[j]--; pending[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+
+	if len(sorted) != len(nodes) {
+		cycle := make(map[string]struct{})
+
+		for i, n := range nodes {
+			if pending[i] > 0 {
+				cycle[n.name] = struct{}{}
+			}
+		}
+
+		return nil, fmt.Errorf(
+			"dependency cycle between apps %s",
+			strings.Join(slices.Sorted(maps.Keys(cycle)), ", "),
+		)
+	}
+
+	return sorted, nil
+}
+
+// applyParallel applies the given apps, which must already be sorted, in
+// parallel, with each app waiting for the apps it depends on to be applied
+// first. The first error encountered cancels any apps not yet applied.
+func applyParallel(
+	ctx context.Context,
+	nodes []appNode,
+	apply func(context.Context, appNode) error,
+) error {
+	var (
+		g, gctx = errgroup.WithContext(ctx)
+		done    = make(map[string][]chan struct{})
+		chans   = make([]chan struct{}, len(nodes))
+	)
+
+	for i, n := range nodes {
+		chans[i] = make(chan struct{})
+		done[n.name] = append(done[n.name], chans[i])
+	}
+
+	for i, n := range nodes {
+		g.Go(func() error {
+			for _, dep := range n.deps {
+				for _, ch := range done[dep] {
+					select {
+					case <-ch:
+					case <-gctx.Done():
+						return gctx.Err()
+					}
+				}
+			}
+
+			if err := apply(gctx, n); err != nil {
+				return err
+			}
+
+			close(chans[i])
+
+			return nil
+		})
+	}
+
+	return g.Wait() //nolint:wrapcheck // errors already wrapped by apply
+}
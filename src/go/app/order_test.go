@@ -0,0 +1,108 @@
+package app
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"phenix/types"
+	v1 "phenix/types/version/v1"
+	v2 "phenix/types/version/v2"
+)
+
+func TestSortApps(t *testing.T) {
+	nodes := []appNode{
+		{name: "ipam"},
+		{name: "ntp", deps: []string{"ipam"}},
+		{name: "soh", deps: []string{"scorch", "missing"}},
+		{name: "scorch"},
+		{name: "tap", deps: []string{"soh"}},
+	}
+
+	sorted, err := sortApps(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, n := range sorted {
+		names = append(names, n.name)
+	}
+
+	want := []string{"ipam", "ntp", "scorch", "soh", "tap"}
+	if !slices.Equal(names, want) {
+		t.Errorf("expected order %v, got %v", want, names)
+	}
+}
+
+func TestSortAppsCycle(t *testing.T) {
+	nodes := []appNode{
+		{name: "ipam"},
+		{name: "a", deps: []string{"c"}},
+		{name: "b", deps: []string{"a"}},
+		{name: "c", deps: []string{"b"}},
+	}
+
+	_, err := sortApps(nodes)
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle between apps a, b, c") {
+		t.Errorf("expected dependency cycle error, got %v", err)
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	newExp := func(apps ...*v2.ScenarioApp) *types.Experiment {
+		spec := &v1.ExperimentSpec{ScenarioF: &v2.ScenarioSpec{AppsF: apps}}
+		return &types.Experiment{Spec: spec, Status: new(v1.ExperimentStatus)}
+	}
+
+	exp := newExp(
+		&v2.ScenarioApp{NameF: "soh", DependsOnF: []string{"vrouter"}},
+		&v2.ScenarioApp{NameF: "scorch", DependsOnF: []string{"soh"}, DisabledF: true},
+	)
+
+	if err := ValidateDependencies(exp); err != nil {
+		t.Errorf("expected valid dependencies, got %v", err)
+	}
+
+	exp = newExp(&v2.ScenarioApp{NameF: "soh", DependsOnF: []string{"nope"}})
+
+	if err := ValidateDependencies(exp); err == nil {
+		t.Error("expected error for unknown dependency")
+	}
+
+	// Dependencies can be added to default apps via the scenario.
+	exp = newExp(
+		&v2.ScenarioApp{NameF: "vrouter", DependsOnF: []string{"soh"}},
+		&v2.ScenarioApp{NameF: "soh", DependsOnF: []string{"startup"}, DisabledF: true},
+		&v2.ScenarioApp{NameF: "startup", DependsOnF: []string{"vrouter"}},
+	)
+
+	if err := ValidateDependencies(exp); err == nil {
+		t.Error("expected error for dependency cycle")
+	}
+}
+
+func TestApplyInParallel(t *testing.T) {
+	spec := &v1.ExperimentSpec{ScenarioF: &v2.ScenarioSpec{ParallelAppsF: true}}
+	exp := &types.Experiment{Spec: spec, Status: new(v1.ExperimentStatus)}
+
+	expected := map[Action]bool{
+		ActionConfigure: false,
+		ActionPreStart:  false,
+		ActionPostStart: true,
+		ActionRunning:   true,
+		ActionCleanup:   false,
+	}
+
+	for stage, parallel := range expected {
+		if got := applyInParallel(exp, stage); got != parallel {
+			t.Errorf("expected parallel %t for stage %s, got %t", parallel, stage, got)
+		}
+	}
+
+	spec.ScenarioF.SetParallelApps(false)
+
+	if applyInParallel(exp, ActionPostStart) {
+		t.Error("expected apps to be applied one at a time without parallelApps")
+	}
+}
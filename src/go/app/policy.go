@@ -0,0 +1,78 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+)
+
+// What ApplyApps does when an app still fails after all its retries. The
+// default is to abort, returning the error. Both warn and continue move on to
+// the next app, with warn also logging the error as a warning and adding it to
+// the notes returned to the user.
+const (
+	OnFailureAbort    = "abort"
+	OnFailureWarn     = "warn"
+	OnFailureContinue = "continue"
+)
+
+// appPolicy is the execution policy of an app for a lifecycle stage.
+type appPolicy struct {
+	timeout   time.Duration
+	retries   int
+	onFailure string
+}
+
+// newAppPolicy returns the execution policy configured in the given scenario
+// app for the given stage. The scenario app can be nil (e.g. for default apps
+// that aren't configured in the scenario), in which case apps have no timeout,
+// aren't retried and abort on failure.
+func newAppPolicy(app ifaces.ScenarioApp, stage Action) (appPolicy, error) {
+	policy := appPolicy{timeout: 0, retries: 0, onFailure: OnFailureAbort}
+
+	if app == nil {
+		return policy, nil
+	}
+
+	if v := app.Timeout(string(stage)); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return policy, fmt.Errorf("invalid timeout %s", v)
+		}
+
+		policy.timeout = d
+	}
+
+	if v := app.Retries(string(stage)); v > 0 {
+		policy.retries = v
+	}
+
+	switch v := app.OnFailure(string(stage)); v {
+	case "":
+	case OnFailureAbort, OnFailureWarn, OnFailureContinue:
+		policy.onFailure = v
+	default:
+		return policy, fmt.Errorf("invalid onFailure setting %s", v)
+	}
+
+	return policy, nil
+}
+
+// cloneExperiment returns a deep copy of the given experiment.
+func cloneExperiment(exp *types.Experiment) (*types.Experiment, error) {
+	data, err := json.Marshal(exp)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling experiment to JSON: %w", err)
+	}
+
+	clone := types.NewExperiment(exp.Metadata)
+
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, fmt.Errorf("unmarshaling experiment from JSON: %w", err)
+	}
+
+	return clone, nil
+}
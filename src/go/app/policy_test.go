@@ -0,0 +1,153 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"phenix/store"
+	"phenix/types"
+	v2 "phenix/types/version/v2"
+)
+
+type slowApp struct {
+	IPAM
+
+	delay time.Duration
+}
+
+func (a slowApp) Configure(_ context.Context, exp *types.Experiment) error {
+	time.Sleep(a.delay)
+
+	exp.Spec.SetDefaultBridge("slow")
+
+	return nil
+}
+
+func TestNewAppPolicy(t *testing.T) {
+	sa := &v2.ScenarioApp{
+		NameF:      "soh",
+		TimeoutF:   "5m",
+		RetriesF:   1,
+		OnFailureF: OnFailureWarn,
+		StagesF: map[string]*v2.ScenarioAppPolicy{
+			"post-start": {TimeoutF: "30m", RetriesF: 3, OnFailureF: OnFailureContinue},
+		},
+	}
+
+	policy, err := newAppPolicy(sa, ActionConfigure)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if policy != (appPolicy{timeout: 5 * time.Minute, retries: 1, onFailure: OnFailureWarn}) {
+		t.Errorf("unexpected configure policy %+v", policy)
+	}
+
+	policy, err = newAppPolicy(sa, ActionPostStart)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if policy != (appPolicy{timeout: 30 * time.Minute, retries: 3, onFailure: OnFailureContinue}) {
+		t.Errorf("unexpected post-start policy %+v", policy)
+	}
+
+	policy, err = newAppPolicy(nil, ActionPostStart)
+	if err != nil || policy.onFailure != OnFailureAbort {
+		t.Errorf("expected default abort policy, got %+v (%v)", policy, err)
+	}
+
+	sa.OnFailureF = "explode"
+
+	if _, err := newAppPolicy(sa, ActionConfigure); err == nil {
+		t.Error("expected error for invalid onFailure setting")
+	}
+}
+
+func TestRunDefaultAppTimeout(t *testing.T) {
+	exp := types.NewExperiment(store.ConfigMetadata{Name: "test"}) //nolint:exhaustruct // partial initialization
+	exp.Spec.SetDefaultBridge("phenix")
+
+	options := NewOptions(Stage(ActionConfigure))
+
+	err := runApp(context.Background(), exp, slowApp{delay: time.Second}, true, 10*time.Millisecond, options)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+
+	if exp.Spec.DefaultBridge() != "phenix" {
+		t.Errorf("expected experiment to be unchanged after timeout, got bridge %s", exp.Spec.DefaultBridge())
+	}
+
+	err = runApp(context.Background(), exp, slowApp{delay: 0}, true, time.Second, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp.Spec.DefaultBridge() != "slow" {
+		t.Errorf("expected changes to be copied back, got bridge %s", exp.Spec.DefaultBridge())
+	}
+}
+
+func TestRecordAppResult(t *testing.T) {
+	s, err := store.NewFromEndpoint("bolt://" + filepath.Join(t.TempDir(), "store.bdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := store.DefaultStore
+	store.DefaultStore = s //nolint:reassign // testing
+
+	t.Cleanup(func() {
+		_ = s.Close()
+		store.DefaultStore = orig //nolint:reassign // testing
+	})
+
+	c := &store.Config{ //nolint:exhaustruct // partial initialization
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Experiment",
+		Metadata: store.ConfigMetadata{Name: "test"}, //nolint:exhaustruct // partial initialization
+		Spec:     map[string]any{"experimentName": "test"},
+	}
+
+	if err := store.Create(c); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := types.DecodeExperimentFromConfig(*c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Configure results are only recorded in memory, since the experiment config
+	// is written as a whole once it's configured.
+	recordAppResult(exp, "soh", NewOptions(Stage(ActionConfigure)), 1, OnFailureAbort, nil)
+
+	if _, ok := exp.Status.AppResults()["soh/configure"]; !ok {
+		t.Error("expected configure result to be recorded in experiment status")
+	}
+
+	if err := store.Get(c); err != nil || c.Metadata.ResourceVersion != 1 {
+		t.Fatalf("expected configure result not to be written to store, got version %d (%v)", c.Metadata.ResourceVersion, err)
+	}
+
+	recordAppResult(exp, "soh", NewOptions(Stage(ActionPreStart)), 2, OnFailureAbort, errors.New("failed"))
+
+	if err := store.Get(c); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := types.DecodeExperimentFromConfig(*c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, ok := stored.Status.AppResults()["soh/pre-start"]
+	if !ok || result.Attempts() != 2 || result.LastError() != "failed" {
+		t.Errorf("expected pre-start result to be written to store, got %+v", result)
+	}
+}
@@ -0,0 +1,695 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"phenix/scheduler"
+	"phenix/store"
+	"phenix/types"
+	ifaces "phenix/types/interfaces"
+	"phenix/util/jsonrpc"
+	"phenix/util/mm"
+	"phenix/util/plog"
+)
+
+// Transports supported for persistent user apps, configured using the `rpc`
+// setting of the app in the scenario.
+//
+// Persistent user apps are started once per experiment, with `rpc` as their
+// only argument, instead of once per lifecycle stage. phenix then talks
+// JSON-RPC 2.0 to the app, either over the app's STDIN and STDOUT or over the
+// unix socket at the path in the PHENIX_RPC_SOCKET environment variable, which
+// the app must connect to. STDERR is still used for logs.
+//
+// phenix sends the following requests to the app:
+//
+//   - initialize: {"app": <name>, "experiment": <name>, "version": 1}, sent
+//     once after the app is started.
+//   - stage: {"stage": <stage>, "dryRun": <bool>, "experiment": <experiment>},
+//     sent for each lifecycle stage. The result is an object with an optional
+//     JSON merge patch (RFC 7386) to apply to the experiment spec
+//     ("specPatch"), app status to set ("status") and scheduler to schedule the
+//     experiment with before sending the stage again ("schedule"). Spec patches
+//     are only applied in the configure, pre-start and cleanup stages, and app
+//     status is only set in the post-start, running and cleanup stages. If the
+//     stage context is done (e.g. the stage timed out) a `$/cancelRequest`
+//     notification is sent.
+//   - shutdown: sent after the configure and cleanup stages, after which the
+//     app should exit. Since it may be a long time between an experiment being
+//     configured and started, apps are started again for the pre-start stage.
+//
+// While handling a stage the app can send the following requests to phenix:
+//
+//   - progress: {"message": <message>}, logs the message and publishes it to
+//     web clients. Can also be sent as a notification.
+//   - exec: {"vm": <vm>, "command": <command>, "wait": <bool>, "timeout":
+//     <duration>}, executes a command in a VM via C2. The result is an object
+//     with the ID of the command ("id") and, if waiting for it, the response
+//     ("response").
+//   - vm.info: {"vm": <vm>}, returns the minimega info for a VM.
+//   - status.publish: {"status": <status>}, sets the app status in the
+//     experiment status and writes it to the store right away.
+const (
+	RPCTransportStdio = "stdio"
+	RPCTransportUnix  = "unix"
+
+	rpcVersion        = 1
+	rpcConnectTimeout = 30 * time.Second
+	rpcShutdownDelay  = 10 * time.Second
+)
+
+var (
+	rpcSessions   = make(map[string]*rpcSessionSlot) //nolint:gochecknoglobals // global registry
+	rpcSessionsMu sync.Mutex                         //nolint:gochecknoglobals // guards rpcSessions
+)
+
+// rpcSessionSlot holds the session for the persistent user app of a single
+// experiment. Its lock is held while the app is started and initialized, so
+// concurrent stages for the same app wait for it without blocking other apps.
+type rpcSessionSlot struct {
+	mu      sync.Mutex
+	session *rpcSession
+	removed bool // removed from rpcSessions by closeRPCSession
+}
+
+// rpcSession is a persistent user app process along with the JSON-RPC
+// connection used to talk to it.
+type rpcSession struct {
+	name   string
+	exp    string
+	cmd    *exec.Cmd
+	conn   *jsonrpc.Conn
+	socket string
+	exited chan struct{}
+
+	callMu sync.Mutex // serializes stages
+
+	mu    sync.Mutex
+	stage *rpcStage // stage being applied, if any
+
+	closeOnce sync.Once
+}
+
+// rpcStage is the lifecycle stage currently being applied by a persistent user
+// app. Requests from the app are handled in the context of the stage.
+type rpcStage struct {
+	ctx     context.Context //nolint:containedctx // context of the stage
+	action  Action
+	exp     *types.Experiment
+	options Options
+}
+
+// rpcStageResult is the result of a stage request.
+type rpcStageResult struct {
+	SpecPatch map[string]any `json:"specPatch"`
+	Status    any            `json:"status"`
+	Schedule  string         `json:"schedule"`
+}
+
+// rpcStdio is the STDOUT and STDIN of a persistent user app.
+type rpcStdio struct {
+	io.ReadCloser
+	io.WriteCloser
+}
+
+func (s rpcStdio) Close() error {
+	rerr := s.ReadCloser.Close()
+	werr := s.WriteCloser.Close()
+
+	return errors.Join(rerr, werr)
+}
+
+// rpcApply applies the given lifecycle stage using the persistent user app
+// for the given experiment, starting the app if it isn't already running.
+func (u UserApp) rpcApply(ctx context.Context, action Action, exp *types.Experiment, hosts mm.Hosts) error {
+	s, err := getRPCSession(ctx, exp, u.options)
+	if err != nil {
+		return fmt.Errorf("starting persistent user app %s: %w", u.options.Name, err)
+	}
+
+	if action == ActionConfigure || action == ActionCleanup {
+		defer closeRPCSession(exp.Metadata.Name, u.options.Name)
+	}
+
+	s.callMu.Lock()
+	defer s.callMu.Unlock()
+
+	s.setStage(&rpcStage{ctx: ctx, action: action, exp: exp, options: u.options})
+	defer s.setStage(nil)
+
+	for {
+		unlock := u.options.lockExperiment()
+
+		exp.Hosts = hosts
+
+		data, err := json.Marshal(exp)
+
+		unlock()
+
+		if err != nil {
+			return fmt.Errorf("marshaling experiment to JSON: %w", err)
+		}
+
+		params := map[string]any{
+			"stage":      action,
+			"dryRun":     u.options.DryRun,
+			"experiment": json.RawMessage(data),
+		}
+
+		var result rpcStageResult
+
+		if err := s.conn.Call(ctx, "stage", params, &result); err != nil {
+			return fmt.Errorf("persistent user app %s stage %s failed: %w", u.options.Name, action, err)
+		}
+
+		if result.Schedule != "" {
+			unlock := u.options.lockExperiment()
+			err := scheduler.Schedule(result.Schedule, exp.Spec)
+
+			unlock()
+
+			if err != nil {
+				return fmt.Errorf("scheduling experiment with %s: %w", result.Schedule, err)
+			}
+
+			continue
+		}
+
+		return u.rpcResult(action, exp, result)
+	}
+}
+
+// rpcResult applies the result of a stage request to the given experiment.
+func (u UserApp) rpcResult(action Action, exp *types.Experiment, result rpcStageResult) error {
+	defer u.options.lockExperiment()()
+
+	var patch, status bool
+
+	switch action {
+	case ActionConfigure, ActionPreStart:
+		patch = true
+	case ActionPostStart, ActionRunning:
+		status = true
+	case ActionCleanup:
+		patch, status = true, true
+	}
+
+	if patch && len(result.SpecPatch) > 0 {
+		spec, err := patchSpec(exp, result.SpecPatch)
+		if err != nil {
+			return err
+		}
+
+		exp.SetSpec(spec)
+	}
+
+	if status && result.Status != nil {
+		exp.Status.SetAppStatus(u.options.Name, result.Status)
+	}
+
+	return nil
+}
+
+// patchSpec returns the spec resulting from applying the given JSON merge
+// patch to the spec of the given experiment. The experiment isn't modified.
+func patchSpec(exp *types.Experiment, patch map[string]any) (ifaces.ExperimentSpec, error) { //nolint:ireturn // interface
+	data, err := json.Marshal(exp.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling experiment spec to JSON: %w", err)
+	}
+
+	var doc map[string]any
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshaling experiment spec from JSON: %w", err)
+	}
+
+	store.MergePatch(doc, patch)
+
+	data, err = json.Marshal(map[string]any{"spec": doc})
+	if err != nil {
+		return nil, fmt.Errorf("marshaling patched experiment spec to JSON: %w", err)
+	}
+
+	result := types.NewExperiment(exp.Metadata)
+
+	if err := json.Unmarshal(data, result); err != nil {
+		return nil, fmt.Errorf("unmarshaling patched experiment spec from JSON: %w", err)
+	}
+
+	return result.Spec, nil
+}
+
+// getRPCSession returns the session for the persistent user app in the given
+// options for the given experiment, starting the app if it isn't running.
+func getRPCSession(ctx context.Context, exp *types.Experiment, options Options) (*rpcSession, error) {
+	key := exp.Metadata.Name + "/" + options.Name
+
+	for {
+		rpcSessionsMu.Lock()
+
+		slot, ok := rpcSessions[key]
+		if !ok {
+			slot = new(rpcSessionSlot)
+			rpcSessions[key] = slot
+		}
+
+		rpcSessionsMu.Unlock()
+
+		slot.mu.Lock()
+
+		if slot.removed {
+			// The session was closed while waiting on the slot, so start over with
+			// a new slot rather than starting an app nothing will shut down.
+			slot.mu.Unlock()
+
+			continue
+		}
+
+		s, err := slot.get(ctx, exp, options)
+
+		slot.mu.Unlock()
+
+		return s, err
+	}
+}
+
+// get returns the session in the slot, starting the app if it isn't running.
+// The slot's lock must be held.
+func (slot *rpcSessionSlot) get(ctx context.Context, exp *types.Experiment, options Options) (*rpcSession, error) {
+	if s := slot.session; s != nil {
+		select {
+		case <-s.conn.Done():
+			// The app exited or closed the connection, so start it again.
+			s.close()
+			slot.session = nil
+		default:
+			return s, nil
+		}
+	}
+
+	s, err := startRPCSession(exp, options)
+	if err != nil {
+		return nil, err
+	}
+
+	params := map[string]any{"app": options.Name, "experiment": exp.Metadata.Name, "version": rpcVersion}
+
+	if err := s.conn.Call(ctx, "initialize", params, nil); err != nil {
+		s.close()
+
+		return nil, fmt.Errorf("initializing app: %w", err)
+	}
+
+	slot.session = s
+
+	return s, nil
+}
+
+// closeRPCSession shuts down the persistent user app with the given name for
+// the given experiment, if it's running. It waits for the app to finish
+// starting if another stage is starting it.
+func closeRPCSession(exp, name string) {
+	rpcSessionsMu.Lock()
+
+	key := exp + "/" + name
+	slot, ok := rpcSessions[key]
+
+	delete(rpcSessions, key)
+
+	rpcSessionsMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	slot.mu.Lock()
+
+	s := slot.session
+
+	slot.session = nil
+	slot.removed = true
+
+	slot.mu.Unlock()
+
+	if s != nil {
+		s.close()
+	}
+}
+
+// startRPCSession starts the persistent user app in the given options for the
+// given experiment and connects to it using the app's configured transport.
+//
+//nolint:funlen // complex logic
+func startRPCSession(exp *types.Experiment, options Options) (*rpcSession, error) {
+	cmdName := UserAppPrefix + options.Name
+
+	s := &rpcSession{ //nolint:exhaustruct // partial initialization
+		name:   options.Name,
+		exp:    exp.Metadata.Name,
+		cmd:    exec.Command(cmdName, "rpc"), //nolint:gosec,noctx // outlives stage context
+		exited: make(chan struct{}),
+	}
+
+	s.cmd.Env = append(os.Environ(), userAppEnv(exp, options)...)
+
+	var (
+		rwc      io.ReadWriteCloser
+		listener net.Listener
+		child    []*os.File // child's ends of pipes, closed once it's started
+	)
+
+	switch options.RPC {
+	case RPCTransportStdio:
+		stdinR, stdinW, err := os.Pipe()
+		if err != nil {
+			return nil, fmt.Errorf("creating STDIN pipe: %w", err)
+		}
+
+		stdoutR, stdoutW, err := os.Pipe()
+		if err != nil {
+			_ = stdinR.Close()
+			_ = stdinW.Close()
+
+			return nil, fmt.Errorf("creating STDOUT pipe: %w", err)
+		}
+
+		s.cmd.Stdin, s.cmd.Stdout = stdinR, stdoutW
+
+		rwc = rpcStdio{ReadCloser: stdoutR, WriteCloser: stdinW}
+		child = append(child, stdinR, stdoutW)
+	case RPCTransportUnix:
+		s.socket = filepath.Join(os.TempDir(), fmt.Sprintf("%s-%s.sock", cmdName, exp.Metadata.Name))
+
+		_ = os.Remove(s.socket)
+
+		var err error
+
+		listener, err = net.Listen("unix", s.socket)
+		if err != nil {
+			return nil, fmt.Errorf("listening on unix socket %s: %w", s.socket, err)
+		}
+
+		defer listener.Close()
+
+		s.cmd.Env = append(s.cmd.Env, "PHENIX_RPC_SOCKET="+s.socket)
+	default:
+		return nil, fmt.Errorf("unknown RPC transport %s", options.RPC)
+	}
+
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("creating STDERR pipe: %w", err)
+	}
+
+	s.cmd.Stderr = stderrW
+	child = append(child, stderrW)
+
+	err = s.cmd.Start()
+
+	for _, f := range child {
+		_ = f.Close()
+	}
+
+	if err != nil {
+		_ = stderrR.Close()
+
+		if rwc != nil {
+			_ = rwc.Close()
+		}
+
+		return nil, fmt.Errorf("starting command %s: %w", cmdName, err)
+	}
+
+	go s.logStderr(stderrR)
+
+	go func() {
+		_ = s.cmd.Wait()
+
+		close(s.exited)
+	}()
+
+	if listener != nil {
+		rwc, err = s.accept(listener)
+		if err != nil {
+			s.close()
+
+			return nil, err
+		}
+	}
+
+	s.conn = jsonrpc.NewConn(rwc, s.handle)
+
+	// Close the connection if the app exits so pending calls fail right away.
+	go func() {
+		<-s.exited
+		_ = s.conn.Close()
+	}()
+
+	return s, nil
+}
+
+// accept waits for the persistent user app to connect to the given listener.
+func (s *rpcSession) accept(listener net.Listener) (net.Conn, error) {
+	var (
+		accepted = make(chan net.Conn, 1)
+		failed   = make(chan error, 1)
+	)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			failed <- err
+
+			return
+		}
+
+		accepted <- conn
+	}()
+
+	select {
+	case conn := <-accepted:
+		return conn, nil
+	case err := <-failed:
+		return nil, fmt.Errorf("accepting connection on unix socket %s: %w", s.socket, err)
+	case <-s.exited:
+		return nil, fmt.Errorf("app exited before connecting to unix socket %s", s.socket)
+	case <-time.After(rpcConnectTimeout):
+		return nil, fmt.Errorf("app did not connect to unix socket %s within %v", s.socket, rpcConnectTimeout)
+	}
+}
+
+// logStderr passes the logs written by the app to STDERR to phenix's logger.
+func (s *rpcSession) logStderr(stderr io.ReadCloser) {
+	defer stderr.Close()
+
+	stderrChan := make(chan []byte)
+
+	go plog.ProcessStderrLogs(stderrChan, plog.TypePhenixApp, "app", s.name, "exp", s.exp)
+
+	scanner := bufio.NewScanner(stderr)
+
+	for scanner.Scan() {
+		stderrChan <- append([]byte(nil), scanner.Bytes()...)
+	}
+
+	close(stderrChan)
+}
+
+// close asks the app to shut down, killing it if it doesn't exit in time.
+func (s *rpcSession) close() {
+	s.closeOnce.Do(func() {
+		if s.conn != nil {
+			ctx, cancel := context.WithTimeout(context.Background(), rpcShutdownDelay)
+
+			_ = s.conn.Call(ctx, "shutdown", nil, nil)
+			_ = s.conn.Close()
+
+			cancel()
+		}
+
+		select {
+		case <-s.exited:
+		case <-time.After(rpcShutdownDelay):
+			_ = s.cmd.Process.Signal(syscall.SIGTERM)
+
+			select {
+			case <-s.exited:
+			case <-time.After(rpcShutdownDelay):
+				_ = s.cmd.Process.Kill()
+			}
+		}
+
+		if s.socket != "" {
+			_ = os.Remove(s.socket)
+		}
+	})
+}
+
+func (s *rpcSession) setStage(stage *rpcStage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stage = stage
+}
+
+func (s *rpcSession) currentStage() *rpcStage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.stage
+}
+
+// handle handles requests sent by the app while a stage is being applied.
+// Requests are canceled when the stage is done.
+func (s *rpcSession) handle(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	stage := s.currentStage()
+	if stage == nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidRequest, Message: "no stage in progress"} //nolint:exhaustruct // no data
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stop := context.AfterFunc(stage.ctx, cancel)
+	defer stop()
+
+	switch method {
+	case "progress":
+		return s.progress(stage, params)
+	case "exec":
+		return s.exec(ctx, params)
+	case "vm.info":
+		return s.vmInfo(params)
+	case "status.publish":
+		return s.publishStatus(stage, params)
+	}
+
+	return nil, &jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound, Message: "method not found: " + method} //nolint:exhaustruct // no data
+}
+
+func (s *rpcSession) progress(stage *rpcStage, params json.RawMessage) (any, error) {
+	var p struct {
+		Message string `json:"message"`
+	}
+
+	if err := decodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	plog.Info(plog.TypePhenixApp, p.Message, "app", s.name, "exp", s.exp, "stage", stage.action)
+
+	publish(stage.exp, TriggerPublication{ //nolint:exhaustruct // partial initialization
+		App:     s.name,
+		State:   "progress",
+		Stage:   stage.action,
+		Message: p.Message,
+	})
+
+	return nil, nil //nolint:nilnil // no result
+}
+
+func (s *rpcSession) exec(ctx context.Context, params json.RawMessage) (any, error) {
+	var p struct {
+		VM      string `json:"vm"`
+		Command string `json:"command"`
+		Wait    bool   `json:"wait"`
+		Timeout string `json:"timeout"`
+	}
+
+	if err := decodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	if p.VM == "" || p.Command == "" {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "vm and command are required"} //nolint:exhaustruct // no data
+	}
+
+	id, err := mm.ExecC2Command(mm.C2NS(s.exp), mm.C2VM(p.VM), mm.C2Command(p.Command))
+	if err != nil {
+		return nil, fmt.Errorf("executing command in VM %s: %w", p.VM, err)
+	}
+
+	result := map[string]any{"id": id}
+
+	if !p.Wait {
+		return result, nil
+	}
+
+	opts := []mm.C2Option{mm.C2NS(s.exp), mm.C2Context(ctx), mm.C2CommandID(id)}
+
+	if p.Timeout != "" {
+		d, err := time.ParseDuration(p.Timeout)
+		if err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: "invalid timeout " + p.Timeout} //nolint:exhaustruct // no data
+		}
+
+		opts = append(opts, mm.C2Timeout(d))
+	}
+
+	resp, err := mm.WaitForC2Response(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("waiting for response to command in VM %s: %w", p.VM, err)
+	}
+
+	result["response"] = resp
+
+	return result, nil
+}
+
+func (s *rpcSession) vmInfo(params json.RawMessage) (any, error) {
+	var p struct {
+		VM string `json:"vm"`
+	}
+
+	if err := decodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	vms := mm.GetVMInfo(mm.NS(s.exp), mm.VMName(p.VM))
+	if len(vms) == 0 {
+		return nil, fmt.Errorf("VM %s not found", p.VM)
+	}
+
+	return vms[0], nil
+}
+
+func (s *rpcSession) publishStatus(stage *rpcStage, params json.RawMessage) (any, error) {
+	var p struct {
+		Status any `json:"status"`
+	}
+
+	if err := decodeRPCParams(params, &p); err != nil {
+		return nil, err
+	}
+
+	defer stage.options.lockExperiment()()
+
+	stage.exp.Status.SetAppStatus(s.name, p.Status)
+
+	if err := stage.exp.WriteToStore(true); err != nil {
+		return nil, fmt.Errorf("writing experiment status to store: %w", err)
+	}
+
+	return nil, nil //nolint:nilnil // no result
+}
+
+func decodeRPCParams(params json.RawMessage, v any) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: err.Error()} //nolint:exhaustruct // no data
+	}
+
+	return nil
+}
@@ -0,0 +1,175 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"phenix/store"
+	"phenix/types"
+	v2 "phenix/types/version/v2"
+	"phenix/util/jsonrpc"
+)
+
+// TestRPCHelperApp isn't a real test. It's run as a persistent user app by
+// TestRPCApply.
+func TestRPCHelperApp(t *testing.T) {
+	if os.Getenv("PHENIX_TEST_RPC_APP") != "1" {
+		t.Skip("only run as a persistent user app")
+	}
+
+	var rwc io.ReadWriteCloser = rpcStdio{ReadCloser: os.Stdin, WriteCloser: os.Stdout}
+
+	if path := os.Getenv("PHENIX_RPC_SOCKET"); path != "" {
+		conn, err := net.Dial("unix", path)
+		if err != nil {
+			os.Exit(1)
+		}
+
+		rwc = conn
+	}
+
+	var conn *jsonrpc.Conn
+
+	conn = jsonrpc.NewConn(rwc, func(_ context.Context, method string, params json.RawMessage) (any, error) {
+		switch method {
+		case "initialize", "shutdown":
+			return nil, nil
+		case "stage":
+			var p struct {
+				Stage      Action `json:"stage"`
+				Experiment struct {
+					Metadata store.ConfigMetadata `json:"metadata"`
+				} `json:"experiment"`
+			}
+
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+
+			_ = conn.Notify("progress", map[string]any{"message": "configuring " + p.Experiment.Metadata.Name})
+
+			return map[string]any{"specPatch": map[string]any{"defaultBridge": "rpc"}}, nil
+		}
+
+		return nil, fmt.Errorf("unexpected method %s", method)
+	})
+
+	<-conn.Done()
+	os.Exit(0)
+}
+
+// installRPCHelperApp installs TestRPCHelperApp as the persistent user app
+// named rpctest.
+func installRPCHelperApp(t *testing.T) {
+	t.Helper()
+
+	dir := t.TempDir()
+	script := fmt.Sprintf("#!/bin/sh\nexec %s -test.run=TestRPCHelperApp\n", os.Args[0])
+
+	if err := os.WriteFile(filepath.Join(dir, UserAppPrefix+"rpctest"), []byte(script), 0o755); err != nil { //nolint:gosec // executable script
+		t.Fatal(err)
+	}
+
+	t.Setenv("PATH", dir+":"+os.Getenv("PATH"))
+	t.Setenv("PHENIX_TEST_RPC_APP", "1")
+}
+
+func TestRPCApply(t *testing.T) {
+	installRPCHelperApp(t)
+
+	for _, transport := range []string{RPCTransportStdio, RPCTransportUnix} {
+		t.Run(transport, func(t *testing.T) {
+			exp := types.NewExperiment(store.ConfigMetadata{Name: "test"}) //nolint:exhaustruct // partial initialization
+			exp.Spec.SetDefaultBridge("phenix")
+
+			sa := &v2.ScenarioApp{NameF: "rpctest", RPCF: transport} //nolint:exhaustruct // partial initialization
+
+			var u UserApp
+
+			_ = u.Init(Name("rpctest"), RPC(sa))
+
+			if err := u.rpcApply(context.Background(), ActionConfigure, exp, nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if exp.Spec.DefaultBridge() != "rpc" {
+				t.Errorf("expected spec patch to be applied, got bridge %s", exp.Spec.DefaultBridge())
+			}
+
+			rpcSessionsMu.Lock()
+			defer rpcSessionsMu.Unlock()
+
+			if len(rpcSessions) != 0 {
+				t.Errorf("expected app to be shut down after configure stage")
+			}
+		})
+	}
+}
+
+// TestRPCSessionSlots verifies that starting a persistent user app isn't
+// blocked by another app that's still starting.
+func TestRPCSessionSlots(t *testing.T) {
+	installRPCHelperApp(t)
+
+	// Simulate another app that's still starting by holding its slot's lock.
+	starting := new(rpcSessionSlot)
+	starting.mu.Lock()
+
+	rpcSessionsMu.Lock()
+	rpcSessions["test/starting"] = starting
+	rpcSessionsMu.Unlock()
+
+	t.Cleanup(func() {
+		rpcSessionsMu.Lock()
+		delete(rpcSessions, "test/starting")
+		rpcSessionsMu.Unlock()
+
+		starting.mu.Unlock()
+	})
+
+	exp := types.NewExperiment(store.ConfigMetadata{Name: "test"})   //nolint:exhaustruct // partial initialization
+	sa := &v2.ScenarioApp{NameF: "rpctest", RPCF: RPCTransportStdio} //nolint:exhaustruct // partial initialization
+
+	var u UserApp
+
+	_ = u.Init(Name("rpctest"), RPC(sa))
+
+	done := make(chan error, 1)
+
+	go func() { done <- u.rpcApply(context.Background(), ActionConfigure, exp, nil) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(rpcConnectTimeout):
+		t.Fatal("starting app was blocked by another app that's still starting")
+	}
+}
+
+func TestPatchSpec(t *testing.T) {
+	exp := types.NewExperiment(store.ConfigMetadata{Name: "test"}) //nolint:exhaustruct // partial initialization
+	exp.Spec.SetDefaultBridge("phenix")
+	exp.Spec.SetExperimentName("test")
+
+	spec, err := patchSpec(exp, map[string]any{"defaultBridge": "patched"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if spec.DefaultBridge() != "patched" || spec.ExperimentName() != "test" {
+		t.Errorf("unexpected patched spec: bridge %s, name %s", spec.DefaultBridge(), spec.ExperimentName())
+	}
+
+	if exp.Spec.DefaultBridge() != "phenix" {
+		t.Errorf("expected experiment to be unchanged, got bridge %s", exp.Spec.DefaultBridge())
+	}
+}
@@ -37,6 +37,11 @@ func (Startup) Name() string {
 	return "startup"
 }
 
+// DependsOn makes sure IPAM has allocated interface addresses first.
+func (Startup) DependsOn() []string {
+	return []string{appNameIPAM}
+}
+
 func (s *Startup) Configure(ctx context.Context, exp *types.Experiment) error {
 	return nil
 }
@@ -119,37 +124,16 @@ func (s Startup) PreStart(ctx context.Context, exp *types.Experiment) error {
 			continue
 		}
 
-		// Ensure a node has at least one drive
-		drives := node.Hardware().Drives()
-		if len(drives) == 0 {
-			return fmt.Errorf(
-				"node %q has no drives defined; cannot determine disk image",
-				node.General().Hostname(),
-			)
-		}
-
-		// Check if user provided an absolute path to image. If not, prepend path
-		// with default image path.
-		imagePath := drives[0].Image()
-
-		if !filepath.IsAbs(imagePath) {
-			imagePath = imageDir + imagePath
-		}
-
-		// check if the disk image is present, if not set do not boot to true and warn user
-		if _, err := os.Stat(imagePath); os.IsNotExist(err) {
-			node.General().SetDoNotBoot(true)
-			plog.Warn(
-				plog.TypeSystem,
-				"disk image not found; node will not boot",
-				"node", node.General().Hostname(),
-				"image", imagePath,
-			)
-
-			notes.AddWarnings(ctx, false, fmt.Errorf(
-				"node %q will not boot: disk image %q not found",
-				node.General().Hostname(), imagePath,
-			))
+		if node.IsContainer() {
+			err := checkContainerFilesystem(ctx, node)
+			if err != nil {
+				return err
+			}
+		} else {
+			err := checkDiskImage(ctx, node, imageDir)
+			if err != nil {
+				return err
+			}
 		}
 
 		// if type is router, skip it and continue
@@ -303,6 +287,78 @@ func (s Startup) PreStart(ctx context.Context, exp *types.Experiment) error {
 	return nil
 }
 
+// checkDiskImage ensures the given KVM node has at least one drive, setting the
+// node to not boot if its disk image is missing.
+func checkDiskImage(ctx context.Context, node ifaces.NodeSpec, imageDir string) error {
+	// Ensure a node has at least one drive
+	drives := node.Hardware().Drives()
+	if len(drives) == 0 {
+		return fmt.Errorf(
+			"node %q has no drives defined; cannot determine disk image",
+			node.General().Hostname(),
+		)
+	}
+
+	// Check if user provided an absolute path to image. If not, prepend path
+	// with default image path.
+	imagePath := drives[0].Image()
+
+	if !filepath.IsAbs(imagePath) {
+		imagePath = imageDir + imagePath
+	}
+
+	// check if the disk image is present, if not set do not boot to true and warn user
+	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
+		node.General().SetDoNotBoot(true)
+		plog.Warn(
+			plog.TypeSystem,
+			"disk image not found; node will not boot",
+			"node", node.General().Hostname(),
+			"image", imagePath,
+		)
+
+		notes.AddWarnings(ctx, false, fmt.Errorf(
+			"node %q will not boot: disk image %q not found",
+			node.General().Hostname(), imagePath,
+		))
+	}
+
+	return nil
+}
+
+// checkContainerFilesystem ensures the given container node has a root
+// filesystem, setting the node to not boot if the filesystem is missing. The
+// startup scripts generated for container nodes are added as file injections
+// just like they are for KVM nodes, but get copied into the filesystem before
+// the container is launched instead of being injected into a disk snapshot.
+func checkContainerFilesystem(ctx context.Context, node ifaces.NodeSpec) error {
+	if node.Container() == nil || node.Container().Filesystem() == "" {
+		return fmt.Errorf(
+			"container node %q has no filesystem defined",
+			node.General().Hostname(),
+		)
+	}
+
+	fsPath := mm.GetMMFullPath(node.Container().Filesystem())
+
+	if info, err := os.Stat(fsPath); err != nil || !info.IsDir() {
+		node.General().SetDoNotBoot(true)
+		plog.Warn(
+			plog.TypeSystem,
+			"container filesystem not found; node will not boot",
+			"node", node.General().Hostname(),
+			"filesystem", fsPath,
+		)
+
+		notes.AddWarnings(ctx, false, fmt.Errorf(
+			"node %q will not boot: container filesystem %q not found",
+			node.General().Hostname(), fsPath,
+		))
+	}
+
+	return nil
+}
+
 func (Startup) PostStart(ctx context.Context, exp *types.Experiment) error {
 	for _, node := range exp.Spec.Topology().Nodes() {
 		if node.External() {
@@ -0,0 +1,60 @@
+package app_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"phenix/tmpl"
+	v1 "phenix/types/version/v1"
+)
+
+// TestMinimegaScriptContainerNode verifies that container nodes are launched
+// from their filesystem with their init command, and that the KVM-only disk
+// and QEMU settings are left out of their minimega config.
+func TestMinimegaScriptContainerNode(t *testing.T) {
+	snapshot := true
+
+	spec := &v1.ExperimentSpec{ //nolint:exhaustruct // partial initialization
+		ExperimentNameF: "test",
+		VLANsF:          &v1.VLANSpec{},
+		TopologyF: &v1.TopologySpec{
+			NodesF: []*v1.Node{
+				{
+					TypeF:      "VirtualMachine",
+					GeneralF:   &v1.General{HostnameF: "web-1", VMTypeF: "container", SnapshotF: &snapshot},
+					HardwareF:  &v1.Hardware{OSTypeF: "linux", VCPUF: 1, MemoryF: 512},
+					ContainerF: &v1.Container{FilesystemF: "alpine-rootfs", InitF: "/sbin/init"},
+					InjectionsF: []*v1.Injection{
+						{SrcF: "startup/web-1-hostname.sh", DstF: "/etc/phenix/startup/1_hostname-start.sh"},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.GenerateFromTemplate("minimega_script.tmpl", spec, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	script := buf.String()
+
+	for _, line := range []string{
+		"vm config filesystem alpine-rootfs",
+		"vm config init /sbin/init",
+		"vm config snapshot true",
+		"vm launch container web-1",
+	} {
+		if !strings.Contains(script, line) {
+			t.Errorf("expected %q in minimega script:\n%s", line, script)
+		}
+	}
+
+	for _, line := range []string{"disk snapshot", "disk inject", "vm config disk", "qemu-append", "vm config cpu "} {
+		if strings.Contains(script, line) {
+			t.Errorf("expected no %q for container node in minimega script:\n%s", line, script)
+		}
+	}
+}
@@ -102,9 +102,18 @@ func (u UserApp) shellOut(ctx context.Context, action Action, exp *types.Experim
 		return fmt.Errorf("getting cluster hosts: %w", err)
 	}
 
+	if u.options.RPC != "" {
+		return u.rpcApply(ctx, action, exp, cluster)
+	}
+
+	unlock := u.options.lockExperiment()
+
 	exp.Hosts = cluster
 
 	data, err := json.Marshal(exp)
+
+	unlock()
+
 	if err != nil {
 		return fmt.Errorf("marshaling experiment to JSON: %w", err)
 	}
@@ -126,14 +135,7 @@ func (u UserApp) shellOut(ctx context.Context, action Action, exp *types.Experim
 		shell.Args(string(action)),
 		shell.Stdin(data),
 		shell.SplitBytes(),
-		shell.Env(
-			"PHENIX_DIR="+common.PhenixBase,
-			"PHENIX_FILES_DIR="+exp.FilesDir(),
-			"PHENIX_LOG_LEVEL="+util.GetEnv("PHENIX_LOG_LEVEL", "DEBUG"),
-			"PHENIX_LOG_FILE=stderr",
-			"PHENIX_DRYRUN="+strconv.FormatBool(u.options.DryRun),
-			"PHENIX_STORE_ENDPOINT="+common.StoreEndpoint,
-		),
+		shell.Env(userAppEnv(exp, u.options)...),
 		shell.StreamStderr(stderrChan),
 	}
 
@@ -146,7 +148,11 @@ func (u UserApp) shellOut(ctx context.Context, action Action, exp *types.Experim
 		if errors.As(err, &exitErr) && exitErr.ExitCode() == ExitSchedule {
 			sched := strings.TrimSpace(string(stdOut))
 
+			unlock := u.options.lockExperiment()
 			err := scheduler.Schedule(sched, exp.Spec)
+
+			unlock()
+
 			if err != nil {
 				return fmt.Errorf("scheduling experiment with %s: %w", sched, err)
 			}
@@ -171,6 +177,8 @@ func (u UserApp) shellOut(ctx context.Context, action Action, exp *types.Experim
 		return fmt.Errorf("unmarshaling experiment from JSON: %w", err)
 	}
 
+	defer u.options.lockExperiment()()
+
 	switch action {
 	case ActionConfigure, ActionPreStart:
 		exp.SetSpec(result.Spec)
@@ -188,3 +196,15 @@ func (u UserApp) shellOut(ctx context.Context, action Action, exp *types.Experim
 
 	return nil
 }
+
+// userAppEnv returns the environment variables passed to user apps.
+func userAppEnv(exp *types.Experiment, options Options) []string {
+	return []string{
+		"PHENIX_DIR=" + common.PhenixBase,
+		"PHENIX_FILES_DIR=" + exp.FilesDir(),
+		"PHENIX_LOG_LEVEL=" + util.GetEnv("PHENIX_LOG_LEVEL", "DEBUG"),
+		"PHENIX_LOG_FILE=stderr",
+		"PHENIX_DRYRUN=" + strconv.FormatBool(options.DryRun),
+		"PHENIX_STORE_ENDPOINT=" + common.StoreEndpoint,
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"math/rand/v2"
 	"net"
 	"os"
+	"slices"
 	"strings"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	ifaces "phenix/types/interfaces"
 	"phenix/types/version"
 	"phenix/util"
+	"phenix/util/mm"
 	"phenix/util/mm/mmcli"
 	"phenix/util/plog"
 )
@@ -126,6 +128,13 @@ func (r NATRule) TranslationPort() string {
 	return ""
 }
 
+// OSPFv3Area is an OSPF area with IPv6 area networks, along with the names of
+// the router interfaces with an address in one of the area networks.
+type OSPFv3Area struct {
+	ID         int
+	Interfaces []string
+}
+
 type Vrouter struct {
 	ipsecPresharedKeys map[string]string
 }
@@ -138,6 +147,11 @@ func (Vrouter) Name() string {
 	return appNameVrouter
 }
 
+// DependsOn makes sure IPAM has allocated interface addresses first.
+func (Vrouter) DependsOn() []string {
+	return []string{appNameIPAM}
+}
+
 func (v Vrouter) Configure(ctx context.Context, exp *types.Experiment) error {
 	// Check to see if a scenario exists for this experiment and if it contains
 	// a "vrouter" app. If so, update the topology with the app's ACL configs.
@@ -262,6 +276,10 @@ func (v *Vrouter) PreStart(ctx context.Context, exp *types.Experiment) error {
 			"passwd":   "vyos", // will only be used if `isVyos` is true
 		}
 
+		if areas := ospfv3Areas(node.Network()); len(areas) > 0 {
+			data["ospfv3"] = areas
+		}
+
 		if passwd, ok := node.GetAnnotation("vrouter/vyos-password"); ok {
 			data["passwd"] = passwd // will only be used if `isVyos` is true
 		}
@@ -448,14 +466,18 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 			switch strings.ToLower(iface.Proto()) {
 			case "static":
 				// We only want to set a default route if OSPF isn't being used.
-				if iface.Gateway() != "" {
+				for _, gw := range []string{iface.Gateway(), iface.Gateway6()} {
+					if gw == "" {
+						continue
+					}
+
 					cmd.Command = fmt.Sprintf(
 						"router %s gw %s",
 						node.General().Hostname(),
-						iface.Gateway(),
+						gw,
 					)
 
-					err := mmcli.ErrorResponse(mmcli.Run(cmd))
+					err := mmcli.ErrorResponse(mm.Run(cmd))
 					if err != nil {
 						return fmt.Errorf(
 							"configuring default gateway for router %s: %w",
@@ -468,21 +490,22 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 				// We need to set the IP address for both static and OSPF interfaces, so we fallthrough here.
 				fallthrough
 			case "ospf":
-				cmd.Command = fmt.Sprintf(
-					"router %s interface %d %s/%d",
-					node.General().Hostname(),
-					idx,
-					iface.Address(),
-					iface.Mask(),
-				)
-
-				err := mmcli.ErrorResponse(mmcli.Run(cmd))
-				if err != nil {
-					return fmt.Errorf(
-						"configuring interface for router %s: %w",
+				for _, cidr := range iface.CIDRs() {
+					cmd.Command = fmt.Sprintf(
+						"router %s interface %d %s",
 						node.General().Hostname(),
-						err,
+						idx,
+						cidr,
 					)
+
+					err := mmcli.ErrorResponse(mm.Run(cmd))
+					if err != nil {
+						return fmt.Errorf(
+							"configuring interface for router %s: %w",
+							node.General().Hostname(),
+							err,
+						)
+					}
 				}
 			case "dhcp":
 				cmd.Command = fmt.Sprintf(
@@ -491,7 +514,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 					idx,
 				)
 
-				err := mmcli.ErrorResponse(mmcli.Run(cmd))
+				err := mmcli.ErrorResponse(mm.Run(cmd))
 				if err != nil {
 					return fmt.Errorf(
 						"configuring interface for router %s: %w",
@@ -510,7 +533,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 				route.Next(),
 			)
 
-			err := mmcli.ErrorResponse(mmcli.Run(cmd))
+			err := mmcli.ErrorResponse(mm.Run(cmd))
 			if err != nil {
 				return fmt.Errorf(
 					"configuring static route for router %s: %w",
@@ -527,7 +550,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 				node.Network().OSPF().RouterID(),
 			)
 
-			err := mmcli.ErrorResponse(mmcli.Run(cmd))
+			err := mmcli.ErrorResponse(mm.Run(cmd))
 			if err != nil {
 				return fmt.Errorf(
 					"configuring router ID for router %s: %w",
@@ -537,50 +560,44 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 			}
 
 			for _, area := range node.Network().OSPF().Areas() {
-				for idx, iface := range node.Network().Interfaces() {
-					if !strings.EqualFold(iface.Proto(), "ospf") {
-						continue
-					}
-
-					ip := net.ParseIP(iface.Address())
-					if ip == nil {
-						continue
-					}
+				var aid int // assume area ID of 0 if not provided
 
-					for _, network := range area.AreaNetworks() {
-						_, ipnet, err := net.ParseCIDR(network.Network())
-						if err != nil {
-							continue
-						}
+				if area.AreaID() != nil {
+					aid = *area.AreaID()
+				}
 
-						if ipnet.Contains(ip) {
-							var aid int // assume area ID of 0 if not provided
+				// minirouter runs OSPF for both IPv4 and IPv6 on each interface added
+				// to an area, so interfaces only need to be added once.
+				idxs := append(ospfInterfaces(node.Network(), area, false), ospfInterfaces(node.Network(), area, true)...)
 
-							if area.AreaID() != nil {
-								aid = *area.AreaID()
-							}
+				slices.Sort(idxs)
 
-							cmd.Command = fmt.Sprintf(
-								"router %s route ospf %d %d",
-								node.General().Hostname(),
-								aid,
-								idx,
-							)
+				for _, idx := range slices.Compact(idxs) {
+					cmd.Command = fmt.Sprintf(
+						"router %s route ospf %d %d",
+						node.General().Hostname(),
+						aid,
+						idx,
+					)
 
-							err := mmcli.ErrorResponse(mmcli.Run(cmd))
-							if err != nil {
-								return fmt.Errorf(
-									"configuring OSPF area network for router %s: %w",
-									node.General().Hostname(),
-									err,
-								)
-							}
-						}
+					err := mmcli.ErrorResponse(mm.Run(cmd))
+					if err != nil {
+						return fmt.Errorf(
+							"configuring OSPF area network for router %s: %w",
+							node.General().Hostname(),
+							err,
+						)
 					}
 				}
 			}
 		}
 
+		if node.Network().BGP() != nil {
+			if err := configureMinirouterBGP(cmd, node.General().Hostname(), node.Network()); err != nil {
+				return fmt.Errorf("configuring BGP for router %s: %w", node.General().Hostname(), err)
+			}
+		}
+
 		for idx, iface := range node.Network().Interfaces() {
 			if name := iface.RulesetIn(); name != "" {
 				for _, ruleset := range node.Network().Rulesets() {
@@ -597,7 +614,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 							idx,
 						)
 
-						err = mmcli.ErrorResponse(mmcli.Run(cmd))
+						err = mmcli.ErrorResponse(mm.Run(cmd))
 						if err != nil {
 							return fmt.Errorf(
 								"applying firewall chain to interface for router %s: %w",
@@ -626,7 +643,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 							idx,
 						)
 
-						err = mmcli.ErrorResponse(mmcli.Run(cmd))
+						err = mmcli.ErrorResponse(mm.Run(cmd))
 						if err != nil {
 							return fmt.Errorf(
 								"applying firewall chain to interface for router %s: %w",
@@ -667,7 +684,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 								r.HighAddr,
 							)
 
-							err := mmcli.ErrorResponse(mmcli.Run(cmd))
+							err := mmcli.ErrorResponse(mm.Run(cmd))
 							if err != nil {
 								return fmt.Errorf(
 									"configuring DHCP range for router %s: %w",
@@ -685,7 +702,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 								d.DefaultRoute,
 							)
 
-							err := mmcli.ErrorResponse(mmcli.Run(cmd))
+							err := mmcli.ErrorResponse(mm.Run(cmd))
 							if err != nil {
 								return fmt.Errorf(
 									"configuring DHCP default route for router %s: %w",
@@ -703,7 +720,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 								ns,
 							)
 
-							err := mmcli.ErrorResponse(mmcli.Run(cmd))
+							err := mmcli.ErrorResponse(mm.Run(cmd))
 							if err != nil {
 								return fmt.Errorf(
 									"configuring DHCP DNS server for router %s: %w",
@@ -722,7 +739,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 								ip,
 							)
 
-							err := mmcli.ErrorResponse(mmcli.Run(cmd))
+							err := mmcli.ErrorResponse(mm.Run(cmd))
 							if err != nil {
 								return fmt.Errorf(
 									"configuring DHCP static assignment for router %s: %w",
@@ -745,7 +762,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 					for ip, name := range dns {
 						cmd.Command = fmt.Sprintf("router %s dns %s %s", host.Hostname(), ip, name)
 
-						err := mmcli.ErrorResponse(mmcli.Run(cmd))
+						err := mmcli.ErrorResponse(mm.Run(cmd))
 						if err != nil {
 							return fmt.Errorf(
 								"configuring DNS mapping for router %s: %w",
@@ -760,7 +777,7 @@ func (Vrouter) PostStart(ctx context.Context, exp *types.Experiment) error {
 
 		cmd.Command = fmt.Sprintf("router %s commit", node.General().Hostname())
 
-		err := mmcli.ErrorResponse(mmcli.Run(cmd))
+		err := mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return fmt.Errorf("committing config for router %s: %w", node.General().Hostname(), err)
 		}
@@ -1039,6 +1056,144 @@ func (v *Vrouter) processNAT(
 	return sources, destinations, nil
 }
 
+// ospfv3Areas returns the OSPF areas of the given network that include IPv6
+// area networks, along with the OSPF interfaces in each area.
+func ospfv3Areas(network ifaces.NodeNetwork) []OSPFv3Area {
+	if network == nil || network.OSPF() == nil {
+		return nil
+	}
+
+	var areas []OSPFv3Area
+
+	for _, area := range network.OSPF().Areas() {
+		idxs := ospfInterfaces(network, area, true)
+		if len(idxs) == 0 {
+			continue
+		}
+
+		var a OSPFv3Area // assume area ID of 0 if not provided
+
+		if area.AreaID() != nil {
+			a.ID = *area.AreaID()
+		}
+
+		for _, idx := range idxs {
+			a.Interfaces = append(a.Interfaces, fmt.Sprintf("eth%d", idx))
+		}
+
+		areas = append(areas, a)
+	}
+
+	return areas
+}
+
+// ospfInterfaces returns the indexes of the OSPF interfaces in the given
+// network with an address in one of the given area's networks. Only IPv6 area
+// networks are considered if ipv6 is true, otherwise only IPv4 area networks
+// are considered.
+func ospfInterfaces(network ifaces.NodeNetwork, area ifaces.NodeNetworkOSPFArea, ipv6 bool) []int {
+	var idxs []int
+
+	for idx, iface := range network.Interfaces() {
+		if !strings.EqualFold(iface.Proto(), "ospf") {
+			continue
+		}
+
+	cidrs:
+		for _, cidr := range iface.CIDRs() {
+			ip, _, err := net.ParseCIDR(cidr)
+			if err != nil || (ip.To4() == nil) != ipv6 {
+				continue
+			}
+
+			for _, n := range area.AreaNetworks() {
+				_, ipnet, err := net.ParseCIDR(n.Network())
+				if err != nil {
+					continue
+				}
+
+				if ipnet.Contains(ip) {
+					idxs = append(idxs, idx)
+
+					break cidrs
+				}
+			}
+		}
+	}
+
+	return idxs
+}
+
+// configureMinirouterBGP configures the BGP settings of the given network on
+// the given minirouter. minirouter uses a separate BGP process for each
+// neighbor, with the local address being the router's address in the
+// neighbor's subnet (or the router ID for multihop neighbors). Redistribution
+// and route maps aren't supported by minirouter, so they're ignored.
+func configureMinirouterBGP(cmd *mmcli.Command, router string, network ifaces.NodeNetwork) error {
+	bgp := network.BGP()
+
+	if len(bgp.Redistribute()) > 0 || len(bgp.RouteMaps()) > 0 {
+		plog.Warn(
+			plog.TypePhenixApp,
+			"BGP redistribution and route maps aren't supported by minirouter -- ignoring",
+			"router",
+			router,
+		)
+	}
+
+	for i, neighbor := range bgp.Neighbors() {
+		var (
+			process = fmt.Sprintf("bgp%d", i)
+			local   = bgpLocalAddress(network, neighbor.Address())
+		)
+
+		if local == "" {
+			return fmt.Errorf("no local address for BGP neighbor %s", neighbor.Address())
+		}
+
+		cmds := []string{
+			fmt.Sprintf("router %s route bgp %s local %s %d", router, process, local, bgp.ASN()),
+			fmt.Sprintf("router %s route bgp %s neighbor %s %d", router, process, neighbor.Address(), neighbor.RemoteASN()),
+		}
+
+		for _, n := range bgp.Networks() {
+			cmds = append(cmds, fmt.Sprintf("router %s route bgp %s network %s", router, process, n))
+		}
+
+		for _, c := range cmds {
+			cmd.Command = c
+
+			if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
+				return fmt.Errorf("configuring BGP neighbor %s: %w", neighbor.Address(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// bgpLocalAddress returns the address of the interface in the given network
+// that's in the same subnet as the given BGP neighbor address, falling back to
+// the BGP router ID if the neighbor isn't directly connected.
+func bgpLocalAddress(network ifaces.NodeNetwork, neighbor string) string {
+	peer := net.ParseIP(neighbor)
+
+	for _, iface := range network.Interfaces() {
+		for _, cidr := range iface.CIDRs() {
+			ip, ipnet, err := net.ParseCIDR(cidr)
+			if err != nil {
+				continue
+			}
+
+			if peer != nil && ipnet.Contains(peer) {
+				return ip.String()
+			}
+		}
+	}
+
+	return network.BGP().RouterID()
+}
+
 func configureNTP(exp *types.Experiment, hostname string) (string, error) {
 	// Check to see if a scenario exists for this experiment and if it contains
 	// a "ntp" app. If so, use it to configure NTP for the experiment.
@@ -1124,7 +1279,7 @@ func addChainRules(cmd *mmcli.Command, node string, ruleset ifaces.NodeNetworkRu
 			)
 		}
 
-		err := mmcli.ErrorResponse(mmcli.Run(cmd))
+		err := mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return fmt.Errorf("adding firewall rule for router %s: %w", node, err)
 		}
@@ -1136,7 +1291,7 @@ func addChainRules(cmd *mmcli.Command, node string, ruleset ifaces.NodeNetworkRu
 			ruleset.Default(),
 		)
 
-		err = mmcli.ErrorResponse(mmcli.Run(cmd))
+		err = mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return fmt.Errorf("setting default firewall chain action for router %s: %w", node, err)
 		}
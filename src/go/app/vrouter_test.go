@@ -0,0 +1,262 @@
+package app_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"phenix/app"
+	"phenix/tmpl"
+	v1 "phenix/types/version/v1"
+)
+
+func newDualStackRouter() *v1.Node {
+	area := 0
+
+	return &v1.Node{
+		TypeF:    "Router",
+		GeneralF: &v1.General{HostnameF: "rtr"},
+		NetworkF: &v1.Network{
+			InterfacesF: []*v1.Interface{
+				{
+					NameF:      "eth0",
+					VLANF:      "EXP",
+					ProtoF:     "ospf",
+					AddressF:   "10.1.0.1",
+					MaskF:      24,
+					AddressesF: []string{"2001:db8:1::1/64"},
+				},
+			},
+			RoutesF: []v1.Route{
+				{DestinationF: "10.2.0.0/24", NextF: "10.1.0.254"},
+				{DestinationF: "2001:db8:2::/64", NextF: "2001:db8:1::254"},
+			},
+			OSPFF: &v1.OSPF{
+				RouterIDF: "0.0.0.1",
+				AreasF: []v1.Area{
+					{
+						AreaIDF: &area,
+						AreaNetworksF: []v1.AreaNetwork{
+							{NetworkF: "10.1.0.0/24"},
+							{NetworkF: "2001:db8:1::/64"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// TestVyOSTemplateDualStack verifies that vyatta.tmpl configures every address
+// of dual-stack interfaces, IPv6 static routes, and OSPFv3 for IPv6 area
+// networks.
+func TestVyOSTemplateDualStack(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		data = map[string]any{
+			"node":   newDualStackRouter(),
+			"vyos":   true,
+			"ospfv3": []app.OSPFv3Area{{ID: 0, Interfaces: []string{"eth0"}}},
+		}
+	)
+
+	if err := tmpl.GenerateFromTemplate("vyatta.tmpl", data, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	config := buf.String()
+
+	expected := []string{
+		"set interface ethernet eth0 address 10.1.0.1/24",
+		"set interface ethernet eth0 address 2001:db8:1::1/64",
+		"set protocols static route 10.2.0.0/24 next-hop 10.1.0.254",
+		"set protocols static route6 2001:db8:2::/64 next-hop 2001:db8:1::254",
+		"set protocols ospf area 0 network 10.1.0.0/24",
+		"set protocols ospfv3 interface eth0 area 0",
+		"set protocols ospfv3 parameters router-id 0.0.0.1",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(config, line) {
+			t.Errorf("expected %q in config:\n%s", line, config)
+		}
+	}
+
+	if strings.Contains(config, "set protocols ospf area 0 network 2001:db8:1::/64") {
+		t.Errorf("expected IPv6 area network to be excluded from OSPFv2 config:\n%s", config)
+	}
+}
+
+func newBGPRouter() *v1.Node {
+	return &v1.Node{
+		TypeF:    "Router",
+		GeneralF: &v1.General{HostnameF: "edge"},
+		NetworkF: &v1.Network{
+			InterfacesF: []*v1.Interface{
+				{NameF: "eth0", VLANF: "WAN", ProtoF: "static", AddressF: "10.0.0.1", MaskF: 30},
+			},
+			BGPF: &v1.BGP{
+				ASNF:      65001,
+				RouterIDF: "10.0.0.1",
+				NeighborsF: []v1.BGPNeighbor{
+					{AddressF: "10.0.0.2", RemoteASNF: 65002, DescriptionF: "isp", EBGPMultihopF: 2},
+				},
+				NetworksF: []string{"192.168.0.0/16", "2001:db8::/32"},
+				RedistributeF: []v1.BGPRedistribute{
+					{ProtocolF: "connected", RouteMapF: "CONNECTED"},
+					{ProtocolF: "static"},
+				},
+				RouteMapsF: []v1.BGPRouteMap{
+					{
+						NameF: "CONNECTED",
+						RulesF: []v1.BGPRouteMapRule{
+							{ActionF: "permit", PrefixesF: []string{"172.16.0.0/12"}},
+							{ActionF: "permit", PrefixesF: []string{"2001:db8:1::/48"}},
+							{ActionF: "deny"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// TestVyOSTemplateBGP verifies that vyatta.tmpl configures BGP neighbors,
+// advertised networks, redistribution and route maps as VyOS commands.
+func TestVyOSTemplateBGP(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		data = map[string]any{"node": newBGPRouter(), "vyos": true}
+	)
+
+	if err := tmpl.GenerateFromTemplate("vyatta.tmpl", data, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	config := buf.String()
+
+	expected := []string{
+		"set policy prefix-list CONNECTED-1 rule 1 prefix 172.16.0.0/12",
+		"set policy route-map CONNECTED rule 1 action permit",
+		"set policy route-map CONNECTED rule 1 match ip address prefix-list CONNECTED-1",
+		"set policy prefix-list6 CONNECTED-2 rule 1 prefix 2001:db8:1::/48",
+		"set policy route-map CONNECTED rule 2 match ipv6 address prefix-list CONNECTED-2",
+		"set policy route-map CONNECTED rule 3 action deny",
+		"set protocols bgp system-as 65001",
+		"set protocols bgp parameters router-id 10.0.0.1",
+		"set protocols bgp neighbor 10.0.0.2 remote-as 65002",
+		"set protocols bgp neighbor 10.0.0.2 description 'isp'",
+		"set protocols bgp neighbor 10.0.0.2 ebgp-multihop 2",
+		"set protocols bgp neighbor 10.0.0.2 address-family ipv4-unicast",
+		"set protocols bgp address-family ipv4-unicast network 192.168.0.0/16",
+		"set protocols bgp address-family ipv6-unicast network 2001:db8::/32",
+		"set protocols bgp address-family ipv4-unicast redistribute connected route-map CONNECTED",
+		"set protocols bgp address-family ipv4-unicast redistribute static\n",
+		"set protocols bgp address-family ipv6-unicast redistribute connected route-map CONNECTED",
+		"set protocols bgp address-family ipv6-unicast redistribute static\n",
+	}
+
+	unexpected := []string{
+		"rule 1 match ipv6",
+		"rule 2 match ip address",
+		"rule 3 match",
+	}
+
+	for _, line := range unexpected {
+		if strings.Contains(config, line) {
+			t.Errorf("unexpected %q in config:\n%s", line, config)
+		}
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(config, line) {
+			t.Errorf("expected %q in config:\n%s", line, config)
+		}
+	}
+}
+
+// TestVyattaTemplateBGP verifies that vyatta.tmpl configures BGP and route
+// map policies in Vyatta config.boot files.
+func TestVyattaTemplateBGP(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		data = map[string]any{"node": newBGPRouter(), "vyos": false}
+	)
+
+	if err := tmpl.GenerateFromTemplate("vyatta.tmpl", data, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	config := buf.String()
+
+	expected := []string{
+		"prefix-list CONNECTED-1 {",
+		"prefix-list6 CONNECTED-2 {",
+		"route-map CONNECTED {",
+		"ip {\n                    address {\n                        prefix-list CONNECTED-1\n",
+		"ipv6 {\n                    address {\n                        prefix-list CONNECTED-2\n",
+		"ipv6-unicast {",
+		"bgp 65001 {",
+		"neighbor 10.0.0.2 {",
+		"remote-as 65002",
+		"network 192.168.0.0/16 {",
+		"network 2001:db8::/32 {",
+		"router-id 10.0.0.1",
+		"route-map CONNECTED\n",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(config, line) {
+			t.Errorf("expected %q in config:\n%s", line, config)
+		}
+	}
+}
+
+// TestLinuxInterfacesTemplateDualStack verifies that linux_interfaces.tmpl
+// adds every address of dual-stack interfaces, along with IPv4 and IPv6
+// default gateways and routes.
+func TestLinuxInterfacesTemplateDualStack(t *testing.T) {
+	node := &v1.Node{
+		TypeF:    "VirtualMachine",
+		GeneralF: &v1.General{HostnameF: "host"},
+		NetworkF: &v1.Network{
+			InterfacesF: []*v1.Interface{
+				{
+					NameF:      "eth0",
+					VLANF:      "EXP",
+					ProtoF:     "static",
+					AddressF:   "10.1.0.2",
+					MaskF:      24,
+					GatewayF:   "10.1.0.1",
+					AddressesF: []string{"2001:db8:1::2/64"},
+					Gateway6F:  "2001:db8:1::1",
+				},
+			},
+			RoutesF: []v1.Route{{DestinationF: "2001:db8:2::/64", NextF: "2001:db8:1::254"}},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.GenerateFromTemplate("linux_interfaces.tmpl", node, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	config := buf.String()
+
+	expected := []string{
+		`ip addr add 10.1.0.2/24 dev "$dev"`,
+		`ip addr add 2001:db8:1::2/64 dev "$dev"`,
+		`ip route add default via 10.1.0.1 dev "$dev"`,
+		`ip -6 route add default via 2001:db8:1::1 dev "$dev"`,
+		`ip -6 route add 2001:db8:2::/64 via 2001:db8:1::254`,
+		`ifconfig "$dev" inet6 add 2001:db8:1::2/64`,
+		`route -A inet6 add default gw 2001:db8:1::1 dev "$dev"`,
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(config, line) {
+			t.Errorf("expected %q in config:\n%s", line, config)
+		}
+	}
+}
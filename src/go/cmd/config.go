@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
 	"phenix/api/config"
+	"phenix/api/importer"
+	"phenix/api/lint"
+	"phenix/store"
+	"phenix/types"
 	"phenix/util"
 	"phenix/util/plog"
 	"phenix/util/printer"
@@ -346,6 +351,114 @@ func newConfigCreateCmd() *cobra.Command {
 	return cmd
 }
 
+func newConfigImportCmd() *cobra.Command {
+	desc := `Import a topology from another tool
+
+  This subcommand is used to create a topology configuration by translating a
+  network description from another tool, either a containerlab topology file
+  or a GNS3 project file. Nodes are converted to VMs (or routers), and
+  point-to-point links are converted to VLAN aliases. Bridges, switches and
+  hubs are converted to a single VLAN alias shared by all the nodes linked to
+  them.
+
+  Source images are mapped to drive images using a JSON or YAML image mapping
+  file, which maps each source image to either a drive image name or an object
+  with image, type (e.g. Router) and os_type keys. Nodes, links and settings
+  that can't be mapped are reported after the topology is imported.`
+
+	example := `
+  phenix config import --from containerlab --image-map images.yml lab.clab.yml
+  phenix config import --from gns3 --name campus --dry-run campus.gns3`
+
+	cmd := &cobra.Command{
+		Use:     "import </path/to/filename>",
+		Short:   "Import a topology from another tool",
+		Long:    desc,
+		Example: example,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := importer.ParseFormat(MustGetString(cmd.Flags(), "from"))
+			if err != nil {
+				err := util.HumanizeError(err, "%s", "Unable to import topology from "+args[0])
+
+				return err.Humanized()
+			}
+
+			opts := []importer.Option{importer.WithName(MustGetString(cmd.Flags(), "name"))}
+
+			if path := MustGetString(cmd.Flags(), "image-map"); path != "" {
+				images, err := importer.ImageMappingFromFile(path)
+				if err != nil {
+					err := util.HumanizeError(err, "%s", "Unable to read image mapping from "+path)
+
+					return err.Humanized()
+				}
+
+				opts = append(opts, importer.WithImageMapping(images))
+			}
+
+			if !MustGetBool(cmd.Flags(), "skip-validation") {
+				opts = append(opts, importer.WithValidation())
+			}
+
+			dryRun := MustGetBool(cmd.Flags(), "dry-run")
+
+			if dryRun {
+				opts = append(opts, importer.DryRun())
+			}
+
+			result, err := importer.Import(format, args[0], opts...)
+			if err != nil {
+				err := util.HumanizeError(err, "%s", "Unable to import topology from "+args[0])
+
+				return err.Humanized()
+			}
+
+			if len(result.Issues) > 0 {
+				fmt.Fprintln(os.Stdout)
+				printer.PrintTableOfImportIssues(os.Stdout, result.Issues)
+				fmt.Fprintln(os.Stdout)
+			}
+
+			if dryRun {
+				out, err := yaml.Marshal(result.Config)
+				if err != nil {
+					err := util.HumanizeError(err, "%s", "Unable to convert topology to YAML")
+
+					return err.Humanized()
+				}
+
+				fmt.Fprintln(os.Stdout, string(out))
+
+				return nil
+			}
+
+			plog.Info(
+				plog.TypeSystem,
+				"configuration imported",
+				"kind",
+				result.Config.Kind,
+				"name",
+				result.Config.Metadata.Name,
+				"issues",
+				len(result.Issues),
+			)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("from", "", "Format of the file to import (containerlab, gns3)")
+	cmd.Flags().String("name", "", "Name of the imported topology (defaults to the lab/project name)")
+	cmd.Flags().String("image-map", "", "Path to JSON or YAML file mapping source images to drive images")
+	cmd.Flags().Bool("dry-run", false, "Print the imported topology instead of storing it")
+	cmd.Flags().Bool("skip-validation", false, "Skip configuration spec validation against schema")
+
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
 func newConfigEditCmd() *cobra.Command {
 	desc := `Edit a configuration
 
@@ -368,6 +481,16 @@ func newConfigEditCmd() *cobra.Command {
 					return nil
 				}
 
+				if config.IsConfigConflict(err) {
+					err := util.HumanizeError(
+						err,
+						"%s",
+						"The "+args[0]+" configuration was modified by someone else while being edited, please edit it again",
+					)
+
+					return err.Humanized()
+				}
+
 				err := util.HumanizeError(
 					err,
 					"%s",
@@ -419,14 +542,264 @@ func newConfigDeleteCmd() *cobra.Command {
 	return cmd
 }
 
+func newConfigHistoryCmd() *cobra.Command {
+	desc := `Show the revision history of a configuration
+
+  This subcommand is used to show the numbered revisions recorded each time a
+  configuration was created, updated, patched or rolled back.`
+
+	example := `
+  phenix config history topology/foo
+  phenix config history topology/foo --diff`
+
+	cmd := &cobra.Command{
+		Use:               "history <kind/name>",
+		Short:             "Show the revision history of a configuration",
+		Long:              desc,
+		Example:           example,
+		Args:              configKindArgsValidator(false, false),
+		ValidArgsFunction: configGetArgsCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			revs, err := config.History(args[0])
+			if err != nil {
+				err := util.HumanizeError(err, "%s", "Unable to get the "+args[0]+" configuration history")
+
+				return err.Humanized()
+			}
+
+			if len(revs) == 0 {
+				fmt.Fprintln(os.Stdout, "There are no revisions recorded for "+args[0])
+
+				return nil
+			}
+
+			if MustGetBool(cmd.Flags(), "diff") {
+				for _, r := range revs {
+					fmt.Fprintf(os.Stdout, "revision %d (%s by %s at %s)\n", r.Revision, r.Action, r.Author, r.Timestamp)
+					fmt.Fprintln(os.Stdout, r.Diff)
+				}
+
+				return nil
+			}
+
+			fmt.Fprintln(os.Stdout)
+			printer.PrintTableOfConfigRevisions(os.Stdout, revs)
+			fmt.Fprintln(os.Stdout)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("diff", false, "Include the diff recorded with each revision")
+
+	return cmd
+}
+
+func newConfigDiffCmd() *cobra.Command {
+	desc := `Show the differences between two revisions of a configuration
+
+  This subcommand is used to show a unified diff between two revisions of a
+  configuration. Use the history subcommand to list available revisions.`
+
+	example := `
+  phenix config diff topology/foo 1 3`
+
+	cmd := &cobra.Command{
+		Use:     "diff <kind/name> <revision> <revision>",
+		Short:   "Show the differences between two revisions of a configuration",
+		Long:    desc,
+		Example: example,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 3 { //nolint:mnd // config name and two revisions
+				return fmt.Errorf("expected three arguments, received %d", len(args))
+			}
+
+			return configKindArgsValidator(false, false)(cmd, args[:1])
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid revision '%s'", args[1])
+			}
+
+			to, err := strconv.Atoi(args[2])
+			if err != nil {
+				return fmt.Errorf("invalid revision '%s'", args[2])
+			}
+
+			diff, err := config.DiffRevisions(args[0], from, to)
+			if err != nil {
+				err := util.HumanizeError(err, "%s", "Unable to diff the "+args[0]+" configuration revisions")
+
+				return err.Humanized()
+			}
+
+			fmt.Fprint(os.Stdout, diff)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newConfigRollbackCmd() *cobra.Command {
+	desc := `Roll back a configuration to a previous revision
+
+  This subcommand is used to restore the spec and annotations of a
+  configuration to those recorded in the given revision. The rollback is
+  recorded as a new revision, so it can itself be rolled back.`
+
+	example := `
+  phenix config rollback topology/foo 2`
+
+	cmd := &cobra.Command{
+		Use:     "rollback <kind/name> <revision>",
+		Short:   "Roll back a configuration to a previous revision",
+		Long:    desc,
+		Example: example,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 2 { //nolint:mnd // config name and revision
+				return fmt.Errorf("expected two arguments, received %d", len(args))
+			}
+
+			return configKindArgsValidator(false, false)(cmd, args[:1])
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rev, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid revision '%s'", args[1])
+			}
+
+			if _, err := config.Rollback(args[0], rev); err != nil {
+				if config.IsConfigConflict(err) {
+					err := util.HumanizeError(
+						err,
+						"%s",
+						"The "+args[0]+" configuration was modified by someone else during the rollback, please try again",
+					)
+
+					return err.Humanized()
+				}
+
+				err := util.HumanizeError(err, "%s", "Unable to roll back the "+args[0]+" configuration")
+
+				return err.Humanized()
+			}
+
+			plog.Info(plog.TypeSystem, "configuration rolled back", "config", args[0], "revision", rev)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newConfigValidateCmd() *cobra.Command {
+	desc := `Validate a configuration
+
+  This subcommand is used to validate a configuration, either one already
+  stored (by kind/name) or one in a JSON or YAML file, against its schema. Use
+  --deep to also run the semantic topology linter against topology and
+  experiment configurations, which reports problems the schema can't catch,
+  such as duplicate IP addresses or routes with unreachable next hops.
+  Topology templates are rendered before they're linted, using the values set
+  with --param for their parameters.`
+
+	example := `
+  phenix config validate topology/foo --deep
+  phenix config validate /path/to/topology.yml --deep --skip-rule drive-image
+  phenix config validate topology/substations --deep --param substations=20`
+
+	cmd := &cobra.Command{
+		Use:     "validate <kind/name | /path/to/filename>",
+		Short:   "Validate a configuration",
+		Long:    desc,
+		Example: example,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				c   *store.Config
+				err error
+			)
+
+			if _, statErr := os.Stat(args[0]); statErr == nil {
+				c, err = store.NewConfigFromFile(args[0])
+			} else {
+				if err := configKindArgsValidator(false, false)(cmd, args); err != nil {
+					return err
+				}
+
+				c, err = config.Get(args[0], false)
+			}
+
+			if err != nil {
+				err := util.HumanizeError(err, "%s", "Unable to get the "+args[0]+" configuration")
+
+				return err.Humanized()
+			}
+
+			if err := types.ValidateConfigSpec(*c); err != nil {
+				err := util.HumanizeError(err, "%s", "The "+args[0]+" configuration is not valid")
+
+				return err.Humanized()
+			}
+
+			if MustGetBool(cmd.Flags(), "deep") {
+				skip := MustGetStringArray(cmd.Flags(), "skip-rule")
+
+				params, err := topologyParameters(cmd)
+				if err != nil {
+					return err
+				}
+
+				findings, err := lint.Config(*c, lint.SkipRules(skip...), lint.TopologyParameters(params))
+				if err != nil {
+					err := util.HumanizeError(err, "%s", "Unable to lint the "+args[0]+" configuration")
+
+					return err.Humanized()
+				}
+
+				if len(findings) > 0 {
+					fmt.Fprintln(os.Stdout)
+					printer.PrintTableOfLintFindings(os.Stdout, findings)
+					fmt.Fprintln(os.Stdout)
+				}
+
+				if err := findings.Err(); err != nil {
+					err := util.HumanizeError(err, "%s", "The "+args[0]+" configuration has lint errors")
+
+					return err.Humanized()
+				}
+			}
+
+			fmt.Fprintln(os.Stdout, "The "+args[0]+" configuration is valid")
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("deep", false, "Run the semantic topology linter against topology and experiment configurations")
+	cmd.Flags().StringArray("skip-rule", nil, "Name of a lint rule to skip (can be specified multiple times)")
+	cmd.Flags().StringArray("param", nil, "Topology parameter in the form key=value used to render topology templates when linting (can be specified multiple times)")
+
+	return cmd
+}
+
 func init() { //nolint:gochecknoinits // cobra command
 	configCmd := newConfigCmd()
 
 	configCmd.AddCommand(newConfigListCmd())
 	configCmd.AddCommand(newConfigGetCmd())
 	configCmd.AddCommand(newConfigCreateCmd())
+	configCmd.AddCommand(newConfigImportCmd())
 	configCmd.AddCommand(newConfigEditCmd())
 	configCmd.AddCommand(newConfigDeleteCmd())
+	configCmd.AddCommand(newConfigHistoryCmd())
+	configCmd.AddCommand(newConfigDiffCmd())
+	configCmd.AddCommand(newConfigRollbackCmd())
+	configCmd.AddCommand(newConfigValidateCmd())
 
 	rootCmd.AddCommand(configCmd)
 }
@@ -12,8 +12,10 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"phenix/api/checkpoint"
 	"phenix/api/config"
 	"phenix/api/experiment"
+	"phenix/api/graph"
 	"phenix/api/scorch/scorchexe"
 	"phenix/app"
 	"phenix/scheduler"
@@ -28,6 +30,8 @@ import (
 
 const allExperiments = "all"
 const scheduleArgs = 2
+const checkpointArgs = 2
+const cloneArgs = 2
 
 func expNameCompletion(includeAll bool) func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 	return func(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -151,7 +155,8 @@ func newExperimentCreateCmd() *cobra.Command {
   phenix experiment create <experiment name> -t <topology name or /path/to/filename>
   phenix experiment create <experiment name> -t <topology name or /path/to/filename> -s <scenario name or /path/to/filename>
   phenix experiment create <experiment name> -t <topology name or /path/to/filename> -s <scenario name or /path/to/filename> -d </path/to/dir/>
-  phenix experiment create <experiment name> -t <topology name or /path/to/filename> -s <scenario name or /path/to/filename> --disabled-apps "app1,app2"`
+  phenix experiment create <experiment name> -t <topology name or /path/to/filename> -s <scenario name or /path/to/filename> --disabled-apps "app1,app2"
+  phenix experiment create <experiment name> -t <topology name or /path/to/filename> --param substations=20 --param prefix=sub`
 
 	cmd := &cobra.Command{
 		Use:     "create <experiment name>",
@@ -225,9 +230,15 @@ func newExperimentCreateCmd() *cobra.Command {
 				disabledApps[idx] = strings.TrimSpace(disabledApps[idx])
 			}
 
+			params, err := topologyParameters(cmd)
+			if err != nil {
+				return err
+			}
+
 			opts := []experiment.CreateOption{
 				experiment.CreateWithName(args[0]),
 				experiment.CreateWithTopology(topology),
+				experiment.CreateWithTopologyParameters(params),
 				experiment.CreateWithScenario(scenario),
 				experiment.CreateWithBaseDirectory(MustGetString(cmd.Flags(), "base-dir")),
 				experiment.CreateWithVLANMin(MustGetInt(cmd.Flags(), "vlan-min")),
@@ -258,6 +269,8 @@ func newExperimentCreateCmd() *cobra.Command {
 	cmd.Flags().StringP("base-dir", "d", "", "Base directory to use for experiment (optional)")
 	cmd.Flags().
 		StringP("default-bridge", "b", "phenix", "Default bridge name to use for experiment (optional)")
+	cmd.Flags().
+		StringArray("param", nil, "Topology parameter in the form key=value (can be specified multiple times)")
 	cmd.Flags().Int("vlan-min", 0, "VLAN pool minimum")
 	cmd.Flags().Int("vlan-max", 0, "VLAN pool maximum")
 	cmd.Flags().StringSlice("disabled-apps", []string{}, "Comma separated ist of apps to disable")
@@ -265,6 +278,63 @@ func newExperimentCreateCmd() *cobra.Command {
 	return cmd
 }
 
+func newExperimentCloneCmd() *cobra.Command {
+	desc := `Clone an experiment
+
+  Used to create a new experiment from the topology and scenario of an
+  existing experiment, such as when running several copies of the same range.
+  The clone is allocated a VLAN range that doesn't overlap any other
+  experiment unless one is provided. Optionally, a suffix can be appended to
+  every hostname and every IPv4 address and subnet can be shifted by a fixed
+  offset.`
+
+	example := `
+  phenix experiment clone <source experiment> <experiment name>
+  phenix experiment clone <source experiment> <experiment name> --hostname-suffix=-student1 --subnet-offset=0.0.100.0
+  phenix experiment clone <source experiment> <experiment name> --vlan-min=500 --vlan-max=599`
+
+	cmd := &cobra.Command{
+		Use:               "clone <source experiment> <experiment name>",
+		Short:             "Clone an experiment",
+		Long:              desc,
+		Example:           example,
+		ValidArgsFunction: expNameCompletion(false),
+		Args:              cobra.ExactArgs(cloneArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := []experiment.CloneOption{
+				experiment.CloneWithSource(args[0]),
+				experiment.CloneWithName(args[1]),
+				experiment.CloneWithHostnameSuffix(MustGetString(cmd.Flags(), "hostname-suffix")),
+				experiment.CloneWithSubnetOffset(MustGetString(cmd.Flags(), "subnet-offset")),
+				experiment.CloneWithVLANRange(MustGetInt(cmd.Flags(), "vlan-min"), MustGetInt(cmd.Flags(), "vlan-max")),
+				experiment.CloneWithBaseDirectory(MustGetString(cmd.Flags(), "base-dir")),
+			}
+
+			ctx := notes.Context(context.Background(), false)
+
+			if err := experiment.Clone(ctx, opts...); err != nil {
+				err := util.HumanizeError(err, "Unable to clone the %s experiment", args[0])
+
+				return err.Humanized()
+			}
+
+			notes.PrettyPrint(ctx, false)
+
+			plog.Info(plog.TypeSystem, "experiment cloned", "source", args[0], "exp", args[1])
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("hostname-suffix", "", "Suffix to append to every hostname (optional)")
+	cmd.Flags().String("subnet-offset", "", "Offset in dotted IPv4 notation to add to every IPv4 address (optional)")
+	cmd.Flags().StringP("base-dir", "d", "", "Base directory to use for experiment (optional)")
+	cmd.Flags().Int("vlan-min", 0, "VLAN pool minimum (allocated automatically if not set)")
+	cmd.Flags().Int("vlan-max", 0, "VLAN pool maximum (allocated automatically if not set)")
+
+	return cmd
+}
+
 func newExperimentEditCmd() *cobra.Command {
 	desc := `Edit an experiment
 
@@ -291,6 +361,16 @@ func newExperimentEditCmd() *cobra.Command {
 					return nil
 				}
 
+				if config.IsConfigConflict(err) {
+					err := util.HumanizeError(
+						err,
+						"The %s experiment was modified by someone else while being edited, please edit it again",
+						args[0],
+					)
+
+					return err.Humanized()
+				}
+
 				err := util.HumanizeError(err, "Unable to edit the %s experiment", args[0])
 
 				return err.Humanized()
@@ -380,11 +460,19 @@ func newExperimentDeleteCmd() *cobra.Command {
 	return cmd
 }
 
+//nolint:funlen // command definition
 func newExperimentScheduleCmd() *cobra.Command {
 	desc := `Schedule an experiment
 
   Apply an algorithm to a given experiment. Run 'phenix experiment schedulers'
-  to return a list of algorithms`
+  to return a list of algorithms
+
+  The overcommit ratios are used by resource-aware algorithms (bin-pack) to
+  determine the vCPU and memory capacity of each cluster host.
+
+  The --explain flag runs the algorithm against a copy of the experiment and
+  prints where each VM would be placed and why, along with the resulting
+  totals for each cluster host, without modifying the experiment's schedule.`
 
 	cmd := &cobra.Command{
 		Use:   "schedule <experiment name> <algorithm>",
@@ -411,6 +499,27 @@ func newExperimentScheduleCmd() *cobra.Command {
 			opts := []experiment.ScheduleOption{
 				experiment.ScheduleForName(args[0]),
 				experiment.ScheduleWithAlgorithm(args[1]),
+				experiment.ScheduleWithSchedulerOptions(
+					scheduler.CPUOvercommit(MustGetFloat64(cmd.Flags(), "cpu-overcommit")),
+					scheduler.MemoryOvercommit(MustGetFloat64(cmd.Flags(), "memory-overcommit")),
+				),
+			}
+
+			if MustGetBool(cmd.Flags(), "explain") {
+				report, err := experiment.PreviewSchedule(opts...)
+				if err != nil {
+					err := util.HumanizeError(
+						err,
+						"%s",
+						"Unable to preview scheduling the "+args[0]+" experiment with the "+args[1]+" algorithm",
+					)
+
+					return err.Humanized()
+				}
+
+				printer.PrintTableOfScheduleReport(os.Stdout, report)
+
+				return nil
 			}
 
 			err := experiment.Schedule(opts...)
@@ -430,6 +539,10 @@ func newExperimentScheduleCmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().Float64("cpu-overcommit", scheduler.DefaultCPUOvercommit, "Ratio of vCPUs to physical CPUs allowed on each host")
+	cmd.Flags().Float64("memory-overcommit", scheduler.DefaultMemoryOvercommit, "Ratio of VM memory to physical memory allowed on each host")
+	cmd.Flags().Bool("explain", false, "Show the proposed placement and why without modifying the experiment")
+
 	return cmd
 }
 
@@ -986,6 +1099,183 @@ func newExperimentScorchCmd() *cobra.Command {
 	return cmd
 }
 
+func newExperimentCheckpointCmd() *cobra.Command {
+	desc := `Checkpoint a running experiment
+
+  Pauses all the VMs in a running experiment, takes disk and memory snapshots
+  of each one, and records the experiment's VLAN mappings and app status in a
+  checkpoint manifest. VMs that were running are resumed once the checkpoint
+  has been taken. Use 'phenix experiment restore' to return the experiment to
+  the checkpointed state.`
+
+	cmd := &cobra.Command{
+		Use:               "checkpoint <experiment name> <checkpoint name>",
+		Short:             "Checkpoint a running experiment",
+		Long:              desc,
+		ValidArgsFunction: expNameCompletion(false),
+		Args:              cobra.ExactArgs(checkpointArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			progress := func(p checkpoint.Progress) {
+				plog.Debug(plog.TypeSystem, "checkpoint progress", "exp", args[0], "stage", p.Stage, "vm", p.VM, "percent", p.Percent)
+			}
+
+			manifest, err := checkpoint.Create(args[0], args[1], checkpoint.WithProgress(progress))
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to checkpoint the %s experiment", args[0])
+
+				return err.Humanized()
+			}
+
+			plog.Info(plog.TypeSystem, "experiment checkpointed", "exp", args[0], "checkpoint", args[1], "vms", len(manifest.VMs), "skipped", manifest.Skipped)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newExperimentRestoreCmd() *cobra.Command {
+	desc := `Restore a running experiment from a checkpoint
+
+  Restores every VM in a running experiment from the disk and memory snapshots
+  taken by 'phenix experiment checkpoint', then resumes the VMs that were
+  running when the checkpoint was taken and restores the experiment's app
+  status. The experiment's VLAN mappings must match those recorded in the
+  checkpoint.`
+
+	cmd := &cobra.Command{
+		Use:               "restore <experiment name> <checkpoint name>",
+		Short:             "Restore a running experiment from a checkpoint",
+		Long:              desc,
+		ValidArgsFunction: expNameCompletion(false),
+		Args:              cobra.ExactArgs(checkpointArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			progress := func(p checkpoint.Progress) {
+				plog.Debug(plog.TypeSystem, "restore progress", "exp", args[0], "stage", p.Stage, "vm", p.VM, "percent", p.Percent)
+			}
+
+			manifest, err := checkpoint.Restore(args[0], args[1], checkpoint.WithProgress(progress))
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to restore the %s experiment from checkpoint %s", args[0], args[1])
+
+				return err.Humanized()
+			}
+
+			plog.Info(plog.TypeSystem, "experiment restored", "exp", args[0], "checkpoint", args[1], "vms", len(manifest.VMs))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newExperimentCheckpointsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "checkpoints <experiment name>",
+		Short:             "List checkpoints taken of an experiment",
+		ValidArgsFunction: expNameCompletion(false),
+		Args:              cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			manifests, err := checkpoint.List(args[0])
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to list checkpoints for the %s experiment", args[0])
+
+				return err.Humanized()
+			}
+
+			if len(manifests) == 0 {
+				fmt.Fprintln(os.Stdout, "There are no checkpoints for the "+args[0]+" experiment")
+			} else {
+				printer.PrintTableOfCheckpoints(os.Stdout, manifests)
+			}
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newExperimentGraphCmd() *cobra.Command {
+	desc := `Export an experiment topology as a graph
+
+  This subcommand is used to export the topology of an experiment as a graph,
+  for documentation or for analysis with tools like Graphviz and networkx.
+  Topology nodes are exported as graph nodes, and each VLAN is exported as a
+  hub node connected to the interfaces in the VLAN. Edges are labeled with the
+  interface name and its addresses.
+
+  Supported formats are dot (Graphviz), graphml and json-graph (node-link JSON
+  as read by networkx). Use --live to include the state of each VM and the
+  cluster host it's placed on.`
+
+	example := `
+  phenix experiment graph foo --format dot | dot -Tsvg -o foo.svg
+  phenix experiment graph foo --format json-graph --live --ignore MGMT --output foo.json`
+
+	cmd := &cobra.Command{
+		Use:               "graph <experiment name>",
+		Short:             "Export an experiment topology as a graph",
+		Long:              desc,
+		Example:           example,
+		ValidArgsFunction: expNameCompletion(false),
+		Args:              cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := graph.ParseFormat(MustGetString(cmd.Flags(), "format"))
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to export the %s experiment graph", args[0])
+
+				return err.Humanized()
+			}
+
+			opts := []graph.Option{graph.IgnoreVLANs(MustGetStringArray(cmd.Flags(), "ignore")...)}
+
+			if MustGetBool(cmd.Flags(), "live") {
+				opts = append(opts, graph.WithLiveState())
+			}
+
+			g, err := graph.Experiment(args[0], opts...)
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to build the %s experiment graph", args[0])
+
+				return err.Humanized()
+			}
+
+			out := os.Stdout
+
+			if path := MustGetString(cmd.Flags(), "output"); path != "" {
+				f, err := os.Create(path)
+				if err != nil {
+					err := util.HumanizeError(err, "Unable to create graph output file %s", path)
+
+					return err.Humanized()
+				}
+
+				defer f.Close()
+
+				out = f
+			}
+
+			if err := g.Encode(out, format); err != nil {
+				err := util.HumanizeError(err, "Unable to export the %s experiment graph", args[0])
+
+				return err.Humanized()
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringP("format", "f", string(graph.FormatDOT), "Graph format (dot, graphml, json-graph)")
+	cmd.Flags().StringP("output", "o", "", "Path to write the graph to (defaults to STDOUT)")
+	cmd.Flags().Bool("live", false, "Include live VM state and cluster host placement")
+	cmd.Flags().StringArray("ignore", nil, "VLAN alias to leave out of the graph (can be specified multiple times)")
+
+	return cmd
+}
+
 func init() { //nolint:gochecknoinits // cobra command
 	experimentCmd := newExperimentCmd()
 
@@ -993,6 +1283,7 @@ func init() { //nolint:gochecknoinits // cobra command
 	experimentCmd.AddCommand(newExperimentAppsCmd())
 	experimentCmd.AddCommand(newExperimentSchedulersCmd())
 	experimentCmd.AddCommand(newExperimentCreateCmd())
+	experimentCmd.AddCommand(newExperimentCloneCmd())
 	experimentCmd.AddCommand(newExperimentEditCmd())
 	experimentCmd.AddCommand(newExperimentDeleteCmd())
 	experimentCmd.AddCommand(newExperimentScheduleCmd())
@@ -1002,6 +1293,27 @@ func init() { //nolint:gochecknoinits // cobra command
 	experimentCmd.AddCommand(newExperimentReconfigureCmd())
 	experimentCmd.AddCommand(newExperimentTriggerRunningCmd())
 	experimentCmd.AddCommand(newExperimentScorchCmd())
+	experimentCmd.AddCommand(newExperimentCheckpointCmd())
+	experimentCmd.AddCommand(newExperimentRestoreCmd())
+	experimentCmd.AddCommand(newExperimentCheckpointsCmd())
+	experimentCmd.AddCommand(newExperimentGraphCmd())
 
 	rootCmd.AddCommand(experimentCmd)
 }
+
+// topologyParameters returns the topology parameter values set with the
+// command's `--param key=value` flags.
+func topologyParameters(cmd *cobra.Command) (map[string]string, error) {
+	params := make(map[string]string)
+
+	for _, param := range MustGetStringArray(cmd.Flags(), "param") {
+		k, v, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("topology parameter %s must be in the form key=value", param)
+		}
+
+		params[strings.TrimSpace(k)] = v
+	}
+
+	return params, nil
+}
@@ -284,6 +284,16 @@ func newImageEditCmd() *cobra.Command {
 					return nil
 				}
 
+				if config.IsConfigConflict(err) {
+					err := util.HumanizeError(
+						err,
+						"The %s image was modified by someone else while being edited, please edit it again",
+						args[0],
+					)
+
+					return err.Humanized()
+				}
+
 				err := util.HumanizeError(err, "Unable to edit the %s image", args[0])
 
 				return err.Humanized()
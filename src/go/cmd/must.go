@@ -33,6 +33,15 @@ func MustGetInt(flags *pflag.FlagSet, name string) int {
 	return val
 }
 
+func MustGetFloat64(flags *pflag.FlagSet, name string) float64 {
+	val, err := flags.GetFloat64(name)
+	if err != nil {
+		panic(fmt.Sprintf("Getting value for %s: %v", name, err))
+	}
+
+	return val
+}
+
 func MustGetStringArray(flags *pflag.FlagSet, name string) []string {
 	val, err := flags.GetStringArray(name)
 	if err != nil {
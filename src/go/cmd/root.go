@@ -21,6 +21,7 @@ import (
 	_ "phenix/api/scorch"
 	"phenix/store"
 	"phenix/util/common"
+	"phenix/util/mm"
 	"phenix/util/plog"
 	"phenix/web"
 )
@@ -154,6 +155,23 @@ var rootCmd = &cobra.Command{
 			cmd.Flags().Changed("hostname-suffixes"),
 		)
 
+		if getEffectiveBool("minimega.fake", cmd.Flags().Changed("minimega.fake")) {
+			var opts []mm.FakeOption
+
+			for _, spec := range viper.GetStringSlice("minimega.fake-hosts") {
+				opt, err := mm.ParseFakeHost(spec)
+				if err != nil {
+					return fmt.Errorf("configuring fake minimega: %w", err)
+				}
+
+				opts = append(opts, opt)
+			}
+
+			mm.DefaultMM = mm.NewFake(opts...) //nolint:reassign // configuration injection
+
+			plog.Warn(plog.TypeSystem, "using simulated minimega cluster - no VMs will actually be deployed")
+		}
+
 		endpoint := getEffectiveString("store.endpoint", cmd.Flags().Changed("store.endpoint"))
 
 		common.StoreEndpoint = endpoint //nolint:reassign // configuration injection
@@ -388,6 +406,10 @@ func init() {
 		String("deploy-mode", "", "deploy mode for minimega VMs (options: all | no-headnode | only-headnode)")
 	rootCmd.PersistentFlags().
 		Bool("use-gre-mesh", false, "use GRE tunnels between mesh nodes for VLAN trunking")
+	rootCmd.PersistentFlags().
+		Bool("minimega.fake", false, "use an in-memory simulated minimega cluster instead of a real one (for testing)")
+	rootCmd.PersistentFlags().
+		StringSlice("minimega.fake-hosts", nil, "compute nodes for simulated minimega cluster (name:cpus:mem)")
 	rootCmd.PersistentFlags().
 		String("unix-socket", "/tmp/phenix.sock", "phēnix unix socket to listen on (ui subcommand) or connect to")
 
@@ -14,6 +14,7 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"phenix/api/settings"
+	"phenix/store"
 	"phenix/types"
 	"phenix/util"
 	"phenix/util/plog"
@@ -399,6 +400,68 @@ func newSettingsGetCmd() *cobra.Command {
 	return cmd
 }
 
+func newSettingsDBMigrateCmd() *cobra.Command {
+	desc := `Migrate the database to a different store
+
+  Copies every config (topologies, scenarios, experiments, images, users,
+  roles, settings and config revisions) from the current store to the store
+  at the given endpoint (for example, sqlite:///etc/phenix/store.db). Update the
+  store.endpoint setting afterwards to start using the new store.`
+
+	example := `
+  phenix settings db migrate sqlite:///etc/phenix/store.db
+  phenix settings db migrate --source bolt:///etc/phenix/store.bdb sqlite:///etc/phenix/store.db`
+
+	cmd := &cobra.Command{
+		Use:     "migrate <destination endpoint>",
+		Short:   "Copy the database to a different store",
+		Long:    desc,
+		Example: example,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src := store.DefaultStore
+
+			if endpoint := MustGetString(cmd.Flags(), "source"); endpoint != "" {
+				var err error
+
+				src, err = store.NewFromEndpoint(endpoint)
+				if err != nil {
+					err := util.HumanizeError(err, "Unable to open source store %s", endpoint)
+
+					return err.Humanized()
+				}
+
+				defer func() { _ = src.Close() }()
+			}
+
+			dst, err := store.NewFromEndpoint(args[0])
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to open destination store %s", args[0])
+
+				return err.Humanized()
+			}
+
+			defer func() { _ = dst.Close() }()
+
+			count, err := store.Copy(src, dst, MustGetBool(cmd.Flags(), "overwrite"))
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to migrate store")
+
+				return err.Humanized()
+			}
+
+			plog.Info(plog.TypeSystem, "store migrated", "destination", args[0], "configs", count)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().String("source", "", "Endpoint of store to migrate from (defaults to current store)")
+	cmd.Flags().Bool("overwrite", false, "Overwrite configs that already exist in the destination store")
+
+	return cmd
+}
+
 //nolint:funlen // command definition
 func newSettingsUnsetCmd() *cobra.Command {
 	cmd := &cobra.Command{
@@ -544,6 +607,7 @@ func init() { //nolint:gochecknoinits // cobra command
 	dbCmd := newSettingsDBCmd()
 	dbCmd.AddCommand(newSettingsDBListCmd())
 	dbCmd.AddCommand(newSettingsDBEditCmd())
+	dbCmd.AddCommand(newSettingsDBMigrateCmd())
 	settingsCmd.AddCommand(dbCmd)
 
 	settingsCmd.AddCommand(newSettingsSetCmd())
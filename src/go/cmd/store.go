@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"phenix/api/backup"
+	"phenix/util"
+	"phenix/util/plog"
+	"phenix/util/printer"
+)
+
+func newStoreCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "store",
+		Short: "Store backup and restore",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Help()
+		},
+	}
+
+	return cmd
+}
+
+func newStoreBackupCmd() *cobra.Command {
+	desc := `Backup the store to an archive
+
+  Writes every config in the store (topologies, scenarios, experiments with
+  status, images, users, roles, settings and config revisions) to a gzipped tar
+  archive, along with a manifest of config API versions. The store can be
+  backed up while phenix is running.`
+
+	cmd := &cobra.Command{
+		Use:     "backup <file.tar.gz>",
+		Short:   "Backup the store to an archive",
+		Long:    desc,
+		Example: "  phenix store backup /tmp/phenix-backup.tar.gz",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := os.Create(args[0])
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to create backup file %s", args[0])
+
+				return err.Humanized()
+			}
+
+			manifest, err := backup.Backup(f)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+
+			if err != nil {
+				_ = os.Remove(args[0])
+
+				err := util.HumanizeError(err, "Unable to backup store")
+
+				return err.Humanized()
+			}
+
+			plog.Info(plog.TypeSystem, "store backed up", "file", args[0], "configs", len(manifest.Configs))
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newStoreRestoreCmd() *cobra.Command {
+	desc := `Restore the store from an archive
+
+  Restores configs from an archive created by 'phenix store backup'. The
+  archive can be restored onto a different store backend, and configs stored
+  at older API versions are upgraded as they're restored.
+
+  The --conflict flag determines what happens to configs that already exist in
+  the store: 'skip' leaves them in place, 'overwrite' replaces them, and
+  'rename' restores them under a new name (for example, foo-restored).`
+
+	example := `
+  phenix store restore /tmp/phenix-backup.tar.gz
+  phenix store restore --dry-run --conflict overwrite /tmp/phenix-backup.tar.gz
+  phenix store restore --only topology --only scenario /tmp/phenix-backup.tar.gz`
+
+	cmd := &cobra.Command{
+		Use:     "restore <file>",
+		Short:   "Restore the store from an archive",
+		Long:    desc,
+		Example: example,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			policy, err := backup.ParseConflictPolicy(MustGetString(cmd.Flags(), "conflict"))
+			if err != nil {
+				err := util.HumanizeError(err, "Invalid conflict policy")
+
+				return err.Humanized()
+			}
+
+			f, err := os.Open(args[0])
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to open backup file %s", args[0])
+
+				return err.Humanized()
+			}
+
+			defer func() { _ = f.Close() }()
+
+			dryRun := MustGetBool(cmd.Flags(), "dry-run")
+
+			results, err := backup.Restore(
+				f,
+				backup.RestoreWithDryRun(dryRun),
+				backup.RestoreWithKinds(MustGetStringArray(cmd.Flags(), "only")...),
+				backup.RestoreWithConflictPolicy(policy),
+			)
+
+			if len(results) > 0 {
+				printer.PrintTableOfRestoreResults(os.Stdout, results)
+			}
+
+			if err != nil {
+				err := util.HumanizeError(err, "Unable to restore store")
+
+				return err.Humanized()
+			}
+
+			if !dryRun {
+				plog.Info(plog.TypeSystem, "store restored", "file", args[0], "configs", len(results))
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Show what would be restored without modifying the store")
+	cmd.Flags().StringArray("only", nil, "Only restore configs of the given kind (can be specified multiple times)")
+	cmd.Flags().String("conflict", string(backup.ConflictSkip), "Policy for configs that already exist (skip, overwrite, rename)")
+
+	return cmd
+}
+
+func init() { //nolint:gochecknoinits // cobra command
+	storeCmd := newStoreCmd()
+
+	storeCmd.AddCommand(newStoreBackupCmd())
+	storeCmd.AddCommand(newStoreRestoreCmd())
+
+	rootCmd.AddCommand(storeCmd)
+}
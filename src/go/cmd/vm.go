@@ -35,6 +35,7 @@ const (
 	stopSubnetArgs   = 2
 	stopAllArgs      = 1
 	memSnapArgs      = 3
+	execArgs         = 3
 )
 
 func vmArgsCompletion(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -1067,6 +1068,44 @@ func newVMMemorySnapshotCmd() *cobra.Command {
 	return cmd
 }
 
+func newVMExecCmd() *cobra.Command {
+	desc := `Execute a command in a container VM
+
+  Used to run a shell command in the console of a running container VM and
+  print its output. Container VMs do not have a VNC console, and do not need
+  the miniccc agent for this. Everything after the VM name is treated as the
+  command to run.`
+
+	cmd := &cobra.Command{
+		Use:               "exec <experiment name> <vm name> <command>...",
+		Short:             "Execute a command in a container VM",
+		Long:              desc,
+		Example:           "phenix vm exec myexp web-1 -- ip addr show eth0",
+		Args:              cobra.MinimumNArgs(execArgs),
+		ValidArgsFunction: vmArgsCompletion,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				expName = args[0]
+				vmName  = args[1]
+				command = strings.Join(args[2:], " ")
+			)
+
+			out, err := vm.Exec(expName, vmName, command)
+			if err != nil {
+				err := util.HumanizeError(err, "%s", "Unable to execute command in the "+vmName+" VM")
+
+				return err.Humanized()
+			}
+
+			fmt.Println(out)
+
+			return nil
+		},
+	}
+
+	return cmd
+}
+
 func init() { //nolint:gochecknoinits // cobra command
 	vmCmd := newVMCmd()
 
@@ -1082,6 +1121,7 @@ func init() { //nolint:gochecknoinits // cobra command
 	vmCmd.AddCommand(newVMNetCmd())
 	vmCmd.AddCommand(newVMCaptureCmd())
 	vmCmd.AddCommand(newVMMemorySnapshotCmd())
+	vmCmd.AddCommand(newVMExecCmd())
 
 	rootCmd.AddCommand(vmCmd)
 }
@@ -0,0 +1,264 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	ifaces "phenix/types/interfaces"
+	"phenix/util/mm"
+)
+
+const bytesPerMB = 1 << 20
+
+// ErrOverCapacity is returned by resource-aware schedulers when the cluster
+// doesn't have the capacity to run every VM in an experiment.
+var ErrOverCapacity = errors.New("cluster over capacity")
+
+func init() { //nolint:gochecknoinits // scheduler registration
+	schedulers["bin-pack"] = new(binPack)
+}
+
+// binPack packs experiment VMs onto as few cluster hosts as possible using each
+// VM's vCPUs, memory and drive sizes, taking into account what's already
+// committed on each host and the disk space left in its minimega base
+// directory. VMs with drives also aren't placed on hosts whose disk usage is at
+// or above the MaxDiskUsage threshold. Unlike the other default schedulers, it
+// refuses to schedule an experiment that would exceed the capacity of the
+// cluster.
+type binPack struct {
+	options Options
+}
+
+func (bp *binPack) Init(opts ...Option) error {
+	bp.options = NewOptions(opts...)
+
+	return nil
+}
+
+func (binPack) Name() string {
+	return "bin-pack"
+}
+
+func (bp binPack) Schedule(spec ifaces.ExperimentSpec) error {
+	if len(spec.Topology().Nodes()) == 0 {
+		return errors.New("no VMs defined for experiment")
+	}
+
+	cluster, err := mm.GetClusterHosts(true)
+	if err != nil {
+		return fmt.Errorf("getting cluster hosts: %w", err)
+	}
+
+	if len(cluster) == 0 {
+		return errors.New("no schedulable cluster hosts")
+	}
+
+	hosts := make(map[string]*binPackHost)
+
+	for _, host := range cluster {
+		hosts[host.Name] = &binPackHost{
+			name:     host.Name,
+			cpuCap:   float64(host.CPUs) * bp.options.CPUOvercommit,
+			memCap:   float64(host.MemTotal) * bp.options.MemoryOvercommit,
+			cpu:      host.CPUCommit,
+			mem:      host.MemCommit,
+			diskFree: host.DiskUsage.MinimegaFree,
+			diskFull: host.DiskUsage.Minimega >= bp.options.MaxDiskUsage,
+		}
+	}
+
+	var (
+		unscheduled []binPackVM
+		overloaded  []string
+	)
+
+	// Account for VMs manually scheduled before packing the rest.
+	for _, node := range spec.Topology().Nodes() {
+		if node.External() {
+			continue
+		}
+
+		vm := newBinPackVM(node)
+
+		name, ok := spec.Schedules()[vm.name]
+		if !ok {
+			unscheduled = append(unscheduled, vm)
+
+			continue
+		}
+
+		if host, ok := hosts[name]; ok {
+			if !host.fits(vm) {
+				overloaded = append(overloaded, fmt.Sprintf("%s (manually scheduled on %s)", vm, name))
+			}
+
+			host.add(vm)
+		}
+	}
+
+	// Place the largest VMs first, since they're the hardest to fit.
+	sort.SliceStable(unscheduled, func(i, j int) bool {
+		a, b := unscheduled[i], unscheduled[j]
+
+		if a.mem != b.mem {
+			return a.mem > b.mem
+		}
+
+		if a.cpu != b.cpu {
+			return a.cpu > b.cpu
+		}
+
+		return a.disk > b.disk
+	})
+
+	placements := make(map[string]string)
+
+	for _, vm := range unscheduled {
+		host := bestFit(hosts, vm)
+		if host == nil {
+			overloaded = append(overloaded, vm.String())
+
+			continue
+		}
+
+		host.add(vm)
+		placements[vm.name] = host.name
+
+		bp.options.Reasons.record(
+			vm.name, "best fit: %.0f vCPU and %.0f MB memory free on host after placement",
+			host.cpuCap-float64(host.cpu), host.memFree(),
+		)
+	}
+
+	if len(overloaded) > 0 {
+		return fmt.Errorf(
+			"%w: unable to fit %d node(s): %s",
+			ErrOverCapacity, len(overloaded), strings.Join(overloaded, ", "),
+		)
+	}
+
+	for vm, host := range placements {
+		spec.Schedules()[vm] = host
+	}
+
+	return nil
+}
+
+// bestFit returns the host the given VM fits on that will have the least memory
+// remaining once the VM is placed on it, or nil if the VM doesn't fit on any
+// host.
+func bestFit(hosts map[string]*binPackHost, vm binPackVM) *binPackHost {
+	var best *binPackHost
+
+	for _, host := range hosts {
+		if !host.fits(vm) {
+			continue
+		}
+
+		if best == nil {
+			best = host
+
+			continue
+		}
+
+		if host.memFree() < best.memFree() || (host.memFree() == best.memFree() && host.name < best.name) {
+			best = host
+		}
+	}
+
+	return best
+}
+
+type binPackHost struct {
+	name string
+
+	cpuCap float64
+	memCap float64
+
+	cpu  int
+	mem  int
+	disk int64
+
+	// diskFree is the number of bytes available on the host for VM drives, or 0
+	// if unknown, in which case drive sizes aren't checked against it.
+	diskFree int64
+
+	// diskFull is true if the host's disk usage is at or above the configured
+	// maximum, in which case VMs with drives won't be placed on it.
+	diskFull bool
+}
+
+// fits checks if the given VM fits within the vCPU, memory and disk capacity
+// left on the host.
+func (h binPackHost) fits(vm binPackVM) bool {
+	if vm.drives > 0 && h.diskFull {
+		return false
+	}
+
+	if h.diskFree > 0 && h.disk+vm.disk > h.diskFree {
+		return false
+	}
+
+	return float64(h.cpu+vm.cpu) <= h.cpuCap && float64(h.mem+vm.mem) <= h.memCap
+}
+
+func (h binPackHost) memFree() float64 {
+	return h.memCap - float64(h.mem)
+}
+
+func (h *binPackHost) add(vm binPackVM) {
+	h.cpu += vm.cpu
+	h.mem += vm.mem
+	h.disk += vm.disk
+}
+
+type binPackVM struct {
+	name   string
+	cpu    int
+	mem    int
+	drives int
+
+	// disk is the total size (in bytes) of the VM's drive images.
+	disk int64
+}
+
+func newBinPackVM(node ifaces.NodeSpec) binPackVM {
+	vm := binPackVM{ //nolint:exhaustruct // partial initialization
+		name:   node.General().Hostname(),
+		cpu:    node.Hardware().VCPU(),
+		mem:    node.Hardware().Memory(),
+		drives: len(node.Hardware().Drives()),
+	}
+
+	for _, drive := range node.Hardware().Drives() {
+		vm.disk += driveSize(drive.Image())
+	}
+
+	return vm
+}
+
+func (vm binPackVM) String() string {
+	if vm.disk > 0 {
+		return fmt.Sprintf("%s (%d vCPU, %d MB, %d MB disk)", vm.name, vm.cpu, vm.mem, vm.disk/bytesPerMB)
+	}
+
+	return fmt.Sprintf("%s (%d vCPU, %d MB)", vm.name, vm.cpu, vm.mem)
+}
+
+// driveSize returns the size (in bytes) of the given drive image, or 0 if the
+// image can't be found on this host.
+func driveSize(image string) int64 {
+	if image == "" {
+		return 0
+	}
+
+	info, err := os.Stat(mm.GetMMFullPath(image))
+	if err != nil {
+		return 0
+	}
+
+	return info.Size()
+}
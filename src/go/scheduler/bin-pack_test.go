@@ -0,0 +1,123 @@
+package scheduler_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"phenix/scheduler"
+	v1 "phenix/types/version/v1"
+	"phenix/util/mm"
+)
+
+func newTestSpec(mem ...int) *v1.ExperimentSpec {
+	topo := new(v1.TopologySpec)
+
+	for i, m := range mem {
+		topo.NodesF = append(topo.NodesF, &v1.Node{ //nolint:exhaustruct // partial initialization
+			TypeF:     "VirtualMachine",
+			GeneralF:  &v1.General{HostnameF: fmt.Sprintf("vm-%d", i)},      //nolint:exhaustruct // partial initialization
+			HardwareF: &v1.Hardware{VCPUF: 2, MemoryF: m, OSTypeF: "linux"}, //nolint:exhaustruct // partial initialization
+		})
+	}
+
+	return &v1.ExperimentSpec{ //nolint:exhaustruct // partial initialization
+		TopologyF:  topo,
+		SchedulesF: make(map[string]string),
+	}
+}
+
+func TestBinPack(t *testing.T) {
+	orig := mm.DefaultMM
+	mm.DefaultMM = mm.NewFake( //nolint:reassign // testing
+		mm.FakeHeadnode("head", false),
+		mm.FakeHost("compute1", 8, 16384),
+		mm.FakeHost("compute2", 8, 16384),
+	)
+
+	t.Cleanup(func() { mm.DefaultMM = orig }) //nolint:reassign // testing
+
+	spec := newTestSpec(8192, 4096, 4096, 8192)
+
+	if err := scheduler.Schedule("bin-pack", spec); err != nil {
+		t.Fatal(err)
+	}
+
+	perHost := make(map[string]int)
+
+	for _, host := range spec.Schedules() {
+		perHost[host]++
+	}
+
+	if len(spec.Schedules()) != 4 || perHost["compute1"] != 2 || perHost["compute2"] != 2 {
+		t.Fatalf("expected VMs to be packed two per host, got %v", spec.Schedules())
+	}
+
+	spec = newTestSpec(16384, 16384, 4096)
+
+	err := scheduler.Schedule("bin-pack", spec)
+	if !errors.Is(err, scheduler.ErrOverCapacity) || !strings.Contains(err.Error(), "vm-2") {
+		t.Fatalf("expected over capacity error naming vm-2, got %v", err)
+	}
+
+	if len(spec.Schedules()) != 0 {
+		t.Fatalf("expected no VMs to be scheduled when over capacity, got %v", spec.Schedules())
+	}
+
+	spec = newTestSpec(16384, 16384, 4096)
+
+	if err := scheduler.Schedule("bin-pack", spec, scheduler.MemoryOvercommit(1.5)); err != nil {
+		t.Fatalf("expected VMs to fit with memory overcommit, got %v", err)
+	}
+}
+
+func TestBinPackDisk(t *testing.T) {
+	orig := mm.DefaultMM
+	mm.DefaultMM = mm.NewFake( //nolint:reassign // testing
+		mm.FakeHeadnode("head", false),
+		mm.FakeHost("compute1", 8, 16384),
+		mm.FakeHost("compute2", 8, 16384),
+		mm.FakeHostDiskFree("compute1", 1<<20),
+		mm.FakeHostDiskFree("compute2", 3<<20),
+	)
+
+	t.Cleanup(func() { mm.DefaultMM = orig }) //nolint:reassign // testing
+
+	// Sparse 2 MB drive image; only its size matters.
+	image := filepath.Join(t.TempDir(), "linux.qc2")
+
+	if err := os.WriteFile(image, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Truncate(image, 2<<20); err != nil {
+		t.Fatal(err)
+	}
+
+	spec := newTestSpec(4096, 4096)
+
+	for _, node := range spec.TopologyF.NodesF {
+		node.HardwareF.DrivesF = []*v1.Drive{{ImageF: image}} //nolint:exhaustruct // partial initialization
+	}
+
+	// The first VM would fit compute1 by vCPUs and memory, but its drive doesn't
+	// fit on the host's disk. Only one of the drives fits on compute2.
+	err := scheduler.Schedule("bin-pack", spec)
+	if !errors.Is(err, scheduler.ErrOverCapacity) || !strings.Contains(err.Error(), "2 MB disk") {
+		t.Fatalf("expected over capacity error for disk, got %v", err)
+	}
+
+	spec = newTestSpec(4096)
+	spec.TopologyF.NodesF[0].HardwareF.DrivesF = []*v1.Drive{{ImageF: image}} //nolint:exhaustruct // partial initialization
+
+	if err := scheduler.Schedule("bin-pack", spec); err != nil {
+		t.Fatal(err)
+	}
+
+	if host := spec.Schedules()["vm-0"]; host != "compute2" {
+		t.Fatalf("expected vm-0 to be placed on compute2 for disk, got %s", host)
+	}
+}
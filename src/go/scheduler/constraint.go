@@ -0,0 +1,659 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	ifaces "phenix/types/interfaces"
+	"phenix/util/mm"
+)
+
+// Node label/annotation keys used to declare scheduling constraints. Affinity
+// and anti-affinity values are one or more (comma-separated) group names; VMs
+// sharing an affinity group are placed on the same host and VMs sharing an
+// anti-affinity group are each placed on a different host. Host selector
+// values are one or more (comma-separated) cluster hosts the VM may be placed
+// on.
+const (
+	AffinityKey     = "scheduler/affinity"
+	AntiAffinityKey = "scheduler/anti-affinity"
+	HostSelectorKey = "scheduler/hosts"
+)
+
+// maxPlacementSteps bounds the search for a placement satisfying anti-affinity
+// rules.
+const maxPlacementSteps = 100000
+
+// ErrConstraintViolation is returned when an experiment's scheduling
+// constraints can't be satisfied.
+var ErrConstraintViolation = errors.New("scheduling constraint violation")
+
+// ConstraintError lists every scheduling constraint that couldn't be
+// satisfied. It matches ErrConstraintViolation using `errors.Is`.
+type ConstraintError struct {
+	Violations []string
+}
+
+func (e ConstraintError) Error() string {
+	return fmt.Sprintf(
+		"%d %s(s): %s",
+		len(e.Violations), ErrConstraintViolation, strings.Join(e.Violations, "; "),
+	)
+}
+
+func (ConstraintError) Is(target error) bool {
+	return target == ErrConstraintViolation //nolint:errorlint // sentinel comparison
+}
+
+// Constrained wraps the given scheduler so the affinity, anti-affinity and host
+// selector rules declared in the experiment's `scheduling` spec and in node
+// labels/annotations are honored. VMs with constraints are placed before the
+// wrapped scheduler runs (which treats them as manually scheduled), and the
+// final placement is verified once it's done.
+func Constrained(s Scheduler) Scheduler { //nolint:ireturn // wrapper
+	return &constrained{Scheduler: s} //nolint:exhaustruct // partial initialization
+}
+
+type constrained struct {
+	Scheduler
+
+	options Options
+}
+
+func (c *constrained) Init(opts ...Option) error {
+	c.options = NewOptions(opts...)
+
+	return c.Scheduler.Init(opts...) //nolint:wrapcheck // wrapped scheduler
+}
+
+func (c constrained) Schedule(spec ifaces.ExperimentSpec) error {
+	cons, err := newConstraints(spec)
+	if err != nil {
+		return err
+	}
+
+	if cons.empty() {
+		return c.Scheduler.Schedule(spec)
+	}
+
+	cluster, err := mm.GetClusterHosts(true)
+	if err != nil {
+		return fmt.Errorf("getting cluster hosts: %w", err)
+	}
+
+	if err := cons.place(spec, cluster, c.options.Reasons); err != nil {
+		return err
+	}
+
+	if err := c.Scheduler.Schedule(spec); err != nil {
+		return err //nolint:wrapcheck // wrapped scheduler
+	}
+
+	return cons.verify(spec.Schedules())
+}
+
+type constraints struct {
+	// affinity groups of VMs (VMs without affinity are in a group of their own)
+	groups  [][]string
+	groupOf map[string]int
+
+	anti      [][]string
+	selectors map[string][]string
+}
+
+func newConstraints(spec ifaces.ExperimentSpec) (*constraints, error) {
+	var (
+		cons = &constraints{groupOf: make(map[string]int), selectors: make(map[string][]string)} //nolint:exhaustruct // partial initialization
+
+		violations []string
+		vms        []string
+
+		affinity     = spec.Scheduling().Affinity()
+		antiAffinity = spec.Scheduling().AntiAffinity()
+		selectors    = make(map[string][][]string)
+
+		affinityGroups = make(map[string][]string)
+		antiGroups     = make(map[string][]string)
+	)
+
+	for _, node := range spec.Topology().Nodes() {
+		if node.External() {
+			continue
+		}
+
+		name := node.General().Hostname()
+		vms = append(vms, name)
+
+		for _, group := range constraintValues(node, AffinityKey) {
+			affinityGroups[group] = append(affinityGroups[group], name)
+		}
+
+		for _, group := range constraintValues(node, AntiAffinityKey) {
+			antiGroups[group] = append(antiGroups[group], name)
+		}
+
+		if hosts := constraintValues(node, HostSelectorKey); len(hosts) > 0 {
+			selectors[name] = append(selectors[name], hosts)
+		}
+	}
+
+	for _, group := range sortedKeys(affinityGroups) {
+		affinity = append(affinity, affinityGroups[group])
+	}
+
+	for _, group := range sortedKeys(antiGroups) {
+		antiAffinity = append(antiAffinity, antiGroups[group])
+	}
+
+	for vm, hosts := range spec.Scheduling().HostSelectors() {
+		selectors[vm] = append(selectors[vm], hosts)
+	}
+
+	unknown := func(rule string, names ...string) bool {
+		var missing bool
+
+		for _, name := range names {
+			if !slices.Contains(vms, name) {
+				violations = append(violations, fmt.Sprintf("%s rule references unknown node %s", rule, name))
+				missing = true
+			}
+		}
+
+		return missing
+	}
+
+	// union VMs with affinity for each other into the same group
+	for _, vm := range vms {
+		cons.groupOf[vm] = len(cons.groups)
+		cons.groups = append(cons.groups, []string{vm})
+	}
+
+	for _, rule := range affinity {
+		if unknown("affinity", rule...) || len(rule) < 2 { //nolint:mnd // pair of VMs
+			continue
+		}
+
+		into := cons.groupOf[rule[0]]
+
+		for _, vm := range rule[1:] {
+			from := cons.groupOf[vm]
+			if from == into {
+				continue
+			}
+
+			for _, member := range cons.groups[from] {
+				cons.groupOf[member] = into
+			}
+
+			cons.groups[into] = append(cons.groups[into], cons.groups[from]...)
+			cons.groups[from] = nil
+		}
+	}
+
+	for _, rule := range antiAffinity {
+		if unknown("anti-affinity", rule...) || len(rule) < 2 { //nolint:mnd // pair of VMs
+			continue
+		}
+
+		for i, a := range rule {
+			for _, b := range rule[i+1:] {
+				if cons.groupOf[a] == cons.groupOf[b] {
+					violations = append(
+						violations,
+						fmt.Sprintf("nodes %s and %s have both affinity and anti-affinity for each other", a, b),
+					)
+				}
+			}
+		}
+
+		cons.anti = append(cons.anti, rule)
+	}
+
+	for _, vm := range sortedKeys(selectors) {
+		if unknown("host selector", vm) {
+			continue
+		}
+
+		allowed := selectors[vm][0]
+
+		for _, hosts := range selectors[vm][1:] {
+			allowed = intersect(allowed, hosts)
+		}
+
+		if len(allowed) == 0 {
+			violations = append(violations, fmt.Sprintf("host selectors for node %s don't have any hosts in common", vm))
+
+			continue
+		}
+
+		cons.selectors[vm] = allowed
+	}
+
+	for _, group := range cons.groups {
+		if len(group) == 0 {
+			continue
+		}
+
+		if _, ok := cons.allowed(group); !ok {
+			violations = append(
+				violations,
+				fmt.Sprintf("no host satisfies the host selectors of nodes %s, which must share a host", strings.Join(group, ", ")),
+			)
+		}
+
+		var manual []string
+
+		for _, vm := range group {
+			if host, ok := spec.Schedules()[vm]; ok && !slices.Contains(manual, host) {
+				manual = append(manual, host)
+			}
+		}
+
+		if len(manual) > 1 {
+			violations = append(
+				violations,
+				fmt.Sprintf("nodes %s must share a host but are manually scheduled on %s", strings.Join(group, ", "), strings.Join(manual, ", ")),
+			)
+		}
+	}
+
+	if len(violations) > 0 {
+		return nil, ConstraintError{Violations: violations}
+	}
+
+	return cons, nil
+}
+
+func (c constraints) empty() bool {
+	if len(c.anti) > 0 || len(c.selectors) > 0 {
+		return false
+	}
+
+	for _, group := range c.groups {
+		if len(group) > 1 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// allowed returns the hosts the given group of VMs is allowed to be placed on,
+// or nil if there aren't any restrictions. It returns false if the VMs' host
+// selectors don't have any hosts in common.
+func (c constraints) allowed(group []string) ([]string, bool) {
+	var (
+		allowed    []string
+		restricted bool
+	)
+
+	for _, vm := range group {
+		hosts, ok := c.selectors[vm]
+		if !ok {
+			continue
+		}
+
+		if !restricted {
+			allowed = hosts
+			restricted = true
+
+			continue
+		}
+
+		allowed = intersect(allowed, hosts)
+	}
+
+	return allowed, !restricted || len(allowed) > 0
+}
+
+// constrainedGroups returns the indexes of the affinity groups that have any
+// constraints, ordered by how constrained they are.
+func (c constraints) constrainedGroups() []int {
+	var indexes []int
+
+	for i, group := range c.groups {
+		if len(group) == 0 {
+			continue
+		}
+
+		if len(group) > 1 || c.hasAnti(i) {
+			indexes = append(indexes, i)
+
+			continue
+		}
+
+		if _, ok := c.selectors[group[0]]; ok {
+			indexes = append(indexes, i)
+		}
+	}
+
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return len(c.groups[indexes[i]]) > len(c.groups[indexes[j]])
+	})
+
+	return indexes
+}
+
+func (c constraints) hasAnti(group int) bool {
+	for _, rule := range c.anti {
+		for _, vm := range rule {
+			if c.groupOf[vm] == group {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// conflicts returns true if the two groups of VMs have anti-affinity for each
+// other.
+func (c constraints) conflicts(a, b int) bool {
+	for _, rule := range c.anti {
+		var hasA, hasB bool
+
+		for _, vm := range rule {
+			switch c.groupOf[vm] {
+			case a:
+				hasA = true
+			case b:
+				hasB = true
+			}
+		}
+
+		if hasA && hasB {
+			return true
+		}
+	}
+
+	return false
+}
+
+// place schedules every VM with constraints on a cluster host that satisfies
+// them, leaving the rest of the VMs to be scheduled by the wrapped scheduler.
+func (c constraints) place(spec ifaces.ExperimentSpec, cluster mm.Hosts, reasons Reasons) error {
+	var (
+		groups     = c.constrainedGroups()
+		placement  = make(map[int]string)
+		candidates = make(map[int][]string)
+		free       = make(map[string]int)
+		memory     = make(map[int]int)
+		violations []string
+	)
+
+	for _, host := range cluster {
+		free[host.Name] = host.MemTotal - host.MemCommit
+	}
+
+	nodes := make(map[string]ifaces.NodeSpec)
+
+	for _, node := range spec.Topology().Nodes() {
+		nodes[node.General().Hostname()] = node
+	}
+
+	for _, idx := range groups {
+		group := c.groups[idx]
+
+		for _, vm := range group {
+			memory[idx] += nodes[vm].Hardware().Memory()
+
+			if host, ok := spec.Schedules()[vm]; ok {
+				placement[idx] = host
+			}
+		}
+
+		allowed, _ := c.allowed(group)
+
+		for _, host := range cluster {
+			if allowed == nil || slices.Contains(allowed, host.Name) {
+				candidates[idx] = append(candidates[idx], host.Name)
+			}
+		}
+
+		if _, ok := placement[idx]; ok {
+			continue
+		}
+
+		if len(candidates[idx]) == 0 {
+			violations = append(
+				violations,
+				fmt.Sprintf("none of the hosts selected for nodes %s are schedulable", strings.Join(group, ", ")),
+			)
+		}
+	}
+
+	for _, idx := range groups {
+		if host, ok := placement[idx]; ok {
+			free[host] -= memory[idx]
+		}
+	}
+
+	if len(violations) > 0 {
+		return ConstraintError{Violations: violations}
+	}
+
+	// Place the most constrained groups first.
+	sort.SliceStable(groups, func(i, j int) bool {
+		return len(candidates[groups[i]]) < len(candidates[groups[j]])
+	})
+
+	var (
+		steps int
+		solve func(int) bool
+	)
+
+	solve = func(n int) bool {
+		if n == len(groups) {
+			return true
+		}
+
+		if steps++; steps > maxPlacementSteps {
+			return false
+		}
+
+		idx := groups[n]
+
+		if _, ok := placement[idx]; ok {
+			return solve(n + 1)
+		}
+
+		hosts := slices.Clone(candidates[idx])
+
+		// prefer hosts with the most free memory
+		sort.SliceStable(hosts, func(i, j int) bool { return free[hosts[i]] > free[hosts[j]] })
+
+		for _, host := range hosts {
+			if c.conflictsOn(host, idx, placement) {
+				continue
+			}
+
+			placement[idx] = host
+			free[host] -= memory[idx]
+
+			if solve(n + 1) {
+				return true
+			}
+
+			delete(placement, idx)
+			free[host] += memory[idx]
+		}
+
+		return false
+	}
+
+	if !solve(0) {
+		var vms []string
+
+		for _, rule := range c.anti {
+			vms = append(vms, strings.Join(rule, ", "))
+		}
+
+		return ConstraintError{Violations: []string{
+			fmt.Sprintf(
+				"unable to satisfy anti-affinity rules (%s) with %d schedulable host(s)",
+				strings.Join(vms, "; "), len(cluster),
+			),
+		}}
+	}
+
+	for idx, host := range placement {
+		for _, vm := range c.groups[idx] {
+			if _, ok := spec.Schedules()[vm]; ok {
+				continue
+			}
+
+			spec.Schedules()[vm] = host
+			reasons.record(vm, "scheduling constraints (%s)", c.describe(vm))
+		}
+	}
+
+	return nil
+}
+
+// describe returns the constraints that determined where the given VM was
+// placed.
+func (c constraints) describe(vm string) string {
+	var (
+		group = c.groups[c.groupOf[vm]]
+		rules []string
+	)
+
+	if len(group) > 1 {
+		others := slices.DeleteFunc(slices.Clone(group), func(other string) bool { return other == vm })
+		rules = append(rules, "affinity with "+strings.Join(others, ", "))
+	}
+
+	for _, rule := range c.anti {
+		if slices.Contains(rule, vm) {
+			others := slices.DeleteFunc(slices.Clone(rule), func(other string) bool { return other == vm })
+			rules = append(rules, "anti-affinity with "+strings.Join(others, ", "))
+		}
+	}
+
+	if allowed, _ := c.allowed(group); allowed != nil {
+		rules = append(rules, "host selector "+strings.Join(allowed, ", "))
+	}
+
+	return strings.Join(rules, "; ")
+}
+
+func (c constraints) conflictsOn(host string, group int, placement map[int]string) bool {
+	for other, placed := range placement {
+		if placed == host && c.conflicts(group, other) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verify checks that the given schedule satisfies every constraint.
+func (c constraints) verify(schedules map[string]string) error {
+	var violations []string
+
+	for _, group := range c.groups {
+		if len(group) < 2 { //nolint:mnd // pair of VMs
+			continue
+		}
+
+		hosts := make(map[string]struct{})
+
+		for _, vm := range group {
+			hosts[schedules[vm]] = struct{}{}
+		}
+
+		if len(hosts) > 1 {
+			violations = append(violations, fmt.Sprintf("nodes %s were not placed on the same host", strings.Join(group, ", ")))
+		}
+	}
+
+	for _, rule := range c.anti {
+		placed := make(map[string]string)
+
+		for _, vm := range rule {
+			host := schedules[vm]
+
+			if other, ok := placed[host]; ok {
+				violations = append(violations, fmt.Sprintf("nodes %s and %s were both placed on host %s", other, vm, host))
+
+				continue
+			}
+
+			placed[host] = vm
+		}
+	}
+
+	for _, vm := range sortedKeys(c.selectors) {
+		if host := schedules[vm]; !slices.Contains(c.selectors[vm], host) {
+			violations = append(
+				violations,
+				fmt.Sprintf("node %s was placed on host %s, which isn't one of its selected hosts (%s)", vm, host, strings.Join(c.selectors[vm], ", ")),
+			)
+		}
+	}
+
+	if len(violations) > 0 {
+		return ConstraintError{Violations: violations}
+	}
+
+	return nil
+}
+
+// constraintValues returns the values of the given constraint key from the
+// node's labels and annotations. Values can be comma-separated strings or, for
+// annotations, lists of strings.
+func constraintValues(node ifaces.NodeSpec, key string) []string {
+	var values []string
+
+	split := func(s string) {
+		for v := range strings.SplitSeq(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+
+	if v, ok := node.Labels()[key]; ok {
+		split(v)
+	}
+
+	switch v := node.Annotations()[key].(type) {
+	case string:
+		split(v)
+	case []any:
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				split(s)
+			}
+		}
+	}
+
+	return values
+}
+
+func intersect(a, b []string) []string {
+	var common []string
+
+	for _, v := range a {
+		if slices.Contains(b, v) {
+			common = append(common, v)
+		}
+	}
+
+	return common
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
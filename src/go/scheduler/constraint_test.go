@@ -0,0 +1,75 @@
+package scheduler_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"phenix/scheduler"
+	v1 "phenix/types/version/v1"
+	"phenix/util/mm"
+)
+
+func TestConstraints(t *testing.T) {
+	orig := mm.DefaultMM
+	mm.DefaultMM = mm.NewFake( //nolint:reassign // testing
+		mm.FakeHeadnode("head", false),
+		mm.FakeHost("compute1", 8, 16384),
+		mm.FakeHost("compute2", 8, 16384),
+		mm.FakeHost("compute3", 8, 16384),
+	)
+
+	t.Cleanup(func() { mm.DefaultMM = orig }) //nolint:reassign // testing
+
+	spec := newTestSpec(1024, 1024, 1024, 1024, 1024)
+
+	spec.SchedulingF = &v1.Scheduling{
+		AffinityF:      [][]string{{"vm-0", "vm-1"}},
+		HostSelectorsF: map[string][]string{"vm-1": {"compute3"}},
+	}
+
+	spec.TopologyF.NodesF[2].LabelsF = map[string]string{scheduler.AntiAffinityKey: "web"}
+	spec.TopologyF.NodesF[3].AnnotationsF = map[string]any{scheduler.AntiAffinityKey: []any{"web"}}
+	spec.TopologyF.NodesF[4].AnnotationsF = map[string]any{scheduler.AntiAffinityKey: "web"}
+
+	// isolate-experiment overwrites any existing schedules, so the anti-affinity
+	// rules should be reported as violated.
+	if err := scheduler.Schedule("isolate-experiment", spec); !errors.Is(err, scheduler.ErrConstraintViolation) {
+		t.Fatalf("expected constraint violation isolating experiment, got %v", err)
+	}
+
+	spec.SchedulesF = make(map[string]string)
+
+	if err := scheduler.Schedule("round-robin", spec); err != nil {
+		t.Fatal(err)
+	}
+
+	sched := spec.Schedules()
+
+	if sched["vm-0"] != "compute3" || sched["vm-1"] != "compute3" {
+		t.Fatalf("expected vm-0 and vm-1 to be placed on compute3, got %v", sched)
+	}
+
+	if sched["vm-2"] == sched["vm-3"] || sched["vm-2"] == sched["vm-4"] || sched["vm-3"] == sched["vm-4"] {
+		t.Fatalf("expected vm-2, vm-3 and vm-4 to be placed on different hosts, got %v", sched)
+	}
+
+	spec = newTestSpec(1024, 1024)
+
+	spec.SchedulingF = &v1.Scheduling{
+		AffinityF:     [][]string{{"vm-0", "vm-1"}},
+		AntiAffinityF: [][]string{{"vm-0", "vm-1"}, {"vm-0", "bogus"}},
+	}
+
+	err := scheduler.Schedule("round-robin", spec)
+
+	var cerr scheduler.ConstraintError
+
+	if !errors.Is(err, scheduler.ErrConstraintViolation) || !errors.As(err, &cerr) || len(cerr.Violations) != 2 {
+		t.Fatalf("expected two constraint violations, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), "unknown node bogus") {
+		t.Fatalf("expected violation naming unknown node, got %v", err)
+	}
+}
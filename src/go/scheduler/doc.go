@@ -5,12 +5,27 @@ etc. and cluster node capacity.
 
 Default Schedulers
 
+  - bin-pack.go:           packs experiment VMs onto as few cluster nodes as
+    possible based on VM vCPUs, memory and drive sizes and cluster node
+    capacity (with configurable overcommit ratios), skipping nodes over the
+    disk usage threshold for VMs with drives and refusing to schedule
+    experiments that exceed cluster capacity
   - isolate-experiment.go: isolates all experiment VMs on a single cluster node
   - round-robin.go:        assigns experiment VMs to cluster nodes in a
     round-robin fashion
   - subnet-compute.go:     assigns experiment VMs to cluster nodes based on
     interface VLAN assignments
 
+# Scheduling Constraints
+
+Every scheduler is wrapped by `constraint.go`, which honors affinity,
+anti-affinity and host selector rules declared in the experiment's
+`scheduling` spec or in node labels/annotations (`scheduler/affinity`,
+`scheduler/anti-affinity` and `scheduler/hosts`). VMs with constraints are
+placed before the scheduler runs, and the final placement is verified
+afterwards. Constraints that can't be satisfied result in a ConstraintError
+listing each violation.
+
 # Custom User Schedulers
 
 Custom user schedulers are interacted with through STDIN and STDOUT. The
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"fmt"
+	"sort"
+
+	ifaces "phenix/types/interfaces"
+	"phenix/util/mm"
+)
+
+// Reasons maps VM hostnames to the reason each VM was placed on its host.
+type Reasons map[string]string
+
+// record sets the reason the given VM was placed on its host. It's a no-op if
+// the scheduler wasn't asked to explain its placements.
+func (r Reasons) record(vm, format string, args ...any) {
+	if r == nil {
+		return
+	}
+
+	r[vm] = fmt.Sprintf(format, args...)
+}
+
+// Placement is the host a VM was placed on and why.
+type Placement struct {
+	VM     string `json:"vm"`
+	Host   string `json:"host"`
+	Reason string `json:"reason"`
+}
+
+// HostTotals are the resources on a cluster host used by the VMs placed on it,
+// along with the host's capacity and what's already committed on it.
+type HostTotals struct {
+	Host string `json:"host"`
+
+	// Totals for the VMs placed on the host.
+	VMs    int `json:"vms"`
+	CPU    int `json:"cpu"`
+	Memory int `json:"memory"`
+
+	// Resources already committed on the host.
+	CommittedVMs    int `json:"committedVMs"`
+	CommittedCPU    int `json:"committedCPU"`
+	CommittedMemory int `json:"committedMemory"`
+
+	// Host capacity.
+	CPUs     int `json:"cpus"`
+	MemTotal int `json:"memTotal"`
+}
+
+// Report is the result of previewing a scheduler against an experiment.
+type Report struct {
+	Scheduler  string       `json:"scheduler"`
+	Placements []Placement  `json:"placements"`
+	Hosts      []HostTotals `json:"hosts"`
+}
+
+// Preview runs the scheduler with the given name against the given experiment
+// spec and reports where each VM was placed and why, along with the resulting
+// per-host totals. The spec's schedules are updated in place, so callers
+// should pass a copy of the spec they don't intend to save.
+func Preview(name string, spec ifaces.ExperimentSpec, opts ...Option) (*Report, error) {
+	reasons := make(Reasons)
+
+	for vm, host := range spec.Schedules() {
+		reasons.record(vm, "manually scheduled on %s", host)
+	}
+
+	cluster, err := mm.GetClusterHosts(true)
+	if err != nil {
+		return nil, fmt.Errorf("getting cluster hosts: %w", err)
+	}
+
+	if err := Schedule(name, spec, append(opts, Explain(reasons))...); err != nil {
+		return nil, err
+	}
+
+	var (
+		report = &Report{Scheduler: name} //nolint:exhaustruct // partial initialization
+		totals = make(map[string]*HostTotals)
+	)
+
+	for _, host := range cluster {
+		totals[host.Name] = &HostTotals{ //nolint:exhaustruct // partial initialization
+			Host:            host.Name,
+			CommittedVMs:    host.VMs,
+			CommittedCPU:    host.CPUCommit,
+			CommittedMemory: host.MemCommit,
+			CPUs:            host.CPUs,
+			MemTotal:        host.MemTotal,
+		}
+	}
+
+	for _, node := range spec.Topology().Nodes() {
+		if node.External() {
+			continue
+		}
+
+		vm := node.General().Hostname()
+		host := spec.Schedules()[vm]
+
+		reason, ok := reasons[vm]
+		if !ok {
+			reason = fmt.Sprintf("placed by %s scheduler", name)
+		}
+
+		report.Placements = append(report.Placements, Placement{VM: vm, Host: host, Reason: reason})
+
+		// Hosts not in the cluster (for example, manually scheduled on a host
+		// that's down) are still reported, just without capacity information.
+		t, ok := totals[host]
+		if !ok {
+			t = &HostTotals{Host: host} //nolint:exhaustruct // partial initialization
+			totals[host] = t
+		}
+
+		t.VMs++
+		t.CPU += node.Hardware().VCPU()
+		t.Memory += node.Hardware().Memory()
+	}
+
+	for _, t := range totals {
+		report.Hosts = append(report.Hosts, *t)
+	}
+
+	sort.Slice(report.Hosts, func(i, j int) bool { return report.Hosts[i].Host < report.Hosts[j].Host })
+
+	return report, nil
+}
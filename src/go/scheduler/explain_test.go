@@ -0,0 +1,60 @@
+package scheduler_test
+
+import (
+	"strings"
+	"testing"
+
+	"phenix/scheduler"
+	"phenix/util/mm"
+)
+
+func TestPreview(t *testing.T) {
+	orig := mm.DefaultMM
+	mm.DefaultMM = mm.NewFake( //nolint:reassign // testing
+		mm.FakeHeadnode("head", false),
+		mm.FakeHost("compute1", 8, 16384),
+		mm.FakeHost("compute2", 8, 16384),
+	)
+
+	t.Cleanup(func() { mm.DefaultMM = orig }) //nolint:reassign // testing
+
+	spec := newTestSpec(2048, 4096, 1024)
+	spec.SchedulesF["vm-2"] = "compute2"
+
+	report, err := scheduler.Preview("round-robin", spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Placements) != 3 {
+		t.Fatalf("expected 3 placements, got %+v", report.Placements)
+	}
+
+	for _, p := range report.Placements {
+		switch p.VM {
+		case "vm-2":
+			if p.Host != "compute2" || !strings.Contains(p.Reason, "manually scheduled") {
+				t.Fatalf("expected vm-2 to be reported as manually scheduled, got %+v", p)
+			}
+		default:
+			if !strings.Contains(p.Reason, "fewest VMs") {
+				t.Fatalf("expected round-robin reason for %s, got %+v", p.VM, p)
+			}
+		}
+	}
+
+	var vms, mem int
+
+	for _, h := range report.Hosts {
+		vms += h.VMs
+		mem += h.Memory
+
+		if h.CPUs != 8 || h.MemTotal != 16384 {
+			t.Fatalf("expected host capacity to be reported, got %+v", h)
+		}
+	}
+
+	if vms != 3 || mem != 7168 {
+		t.Fatalf("expected host totals of 3 VMs and 7168 MB, got %d VMs and %d MB", vms, mem)
+	}
+}
@@ -14,9 +14,13 @@ func init() { //nolint:gochecknoinits // scheduler registration
 	schedulers["isolate-experiment"] = new(isolateExperiment)
 }
 
-type isolateExperiment struct{}
+type isolateExperiment struct {
+	options Options
+}
+
+func (ie *isolateExperiment) Init(opts ...Option) error {
+	ie.options = NewOptions(opts...)
 
-func (isolateExperiment) Init(...Option) error {
 	return nil
 }
 
@@ -24,7 +28,7 @@ func (isolateExperiment) Name() string {
 	return "isolate-experiment"
 }
 
-func (isolateExperiment) Schedule(spec ifaces.ExperimentSpec) error {
+func (ie isolateExperiment) Schedule(spec ifaces.ExperimentSpec) error {
 	if len(spec.Topology().Nodes()) == 0 {
 		return errors.New("no VMs defined for experiment")
 	}
@@ -65,11 +69,7 @@ func (isolateExperiment) Schedule(spec ifaces.ExperimentSpec) error {
 					fmt.Printf("Using host %s. It may become overloaded.", host.Name) //nolint:forbidigo // CLI output
 				}
 
-				for _, node := range spec.Topology().Nodes() {
-					if !node.External() {
-						spec.Schedules()[node.General().Hostname()] = host.Name
-					}
-				}
+				ie.place(spec, host.Name, "isolated with %s, which was manually scheduled on this unoccupied host", first)
 
 				return nil
 			}
@@ -89,11 +89,7 @@ func (isolateExperiment) Schedule(spec ifaces.ExperimentSpec) error {
 			memUsage := float64(totalMEM+host.MemCommit) / float64(host.MemTotal)
 
 			if cpuUsage < maxUsageRatio && memUsage < maxUsageRatio {
-				for _, node := range spec.Topology().Nodes() {
-					if !node.External() {
-						spec.Schedules()[node.General().Hostname()] = host.Name
-					}
-				}
+				ie.place(spec, host.Name, "isolated on the unoccupied host with the most unallocated memory that fits the experiment")
 
 				return nil
 			}
@@ -104,11 +100,7 @@ func (isolateExperiment) Schedule(spec ifaces.ExperimentSpec) error {
 
 	for _, host := range cluster {
 		if host.VMs == 0 {
-			for _, node := range spec.Topology().Nodes() {
-				if !node.External() {
-					spec.Schedules()[node.General().Hostname()] = host.Name
-				}
-			}
+			ie.place(spec, host.Name, "isolated on the first unoccupied host (experiment doesn't fit on any unoccupied host)")
 
 			return nil
 		}
@@ -118,3 +110,13 @@ func (isolateExperiment) Schedule(spec ifaces.ExperimentSpec) error {
 
 	return errors.New("no unused hosts -- cannot isolate experiment")
 }
+
+// place schedules every VM in the experiment on the given host.
+func (ie isolateExperiment) place(spec ifaces.ExperimentSpec, host, reason string, args ...any) {
+	for _, node := range spec.Topology().Nodes() {
+		if !node.External() {
+			spec.Schedules()[node.General().Hostname()] = host
+			ie.options.Reasons.record(node.General().Hostname(), reason, args...)
+		}
+	}
+}
@@ -1,5 +1,20 @@
 package scheduler
 
+const (
+	// DefaultCPUOvercommit is the default ratio of vCPUs that can be scheduled
+	// per physical CPU on a cluster host.
+	DefaultCPUOvercommit = 4.0
+
+	// DefaultMemoryOvercommit is the default ratio of VM memory that can be
+	// scheduled per MB of physical memory on a cluster host.
+	DefaultMemoryOvercommit = 1.0
+
+	// DefaultMaxDiskUsage is the default disk usage (percent) of the minimega
+	// base directory at which a cluster host is no longer considered for VMs
+	// with drives.
+	DefaultMaxDiskUsage = 95.0
+)
+
 // Option is a function that configures options for a phenix scheduler. It is
 // used in `scheduler.Init`.
 type Option func(*Options)
@@ -7,11 +22,24 @@ type Option func(*Options)
 // Options represents a set of options generic to all schedulers.
 type Options struct {
 	Name string // used to set the scheduler name
+
+	// Capacity options used by resource-aware schedulers.
+	CPUOvercommit    float64
+	MemoryOvercommit float64
+	MaxDiskUsage     float64
+
+	// Reasons, if set, is where schedulers record why each VM was placed on the
+	// host it was.
+	Reasons Reasons
 }
 
 // NewOptions returns an Options struct initialized with the given option list.
 func NewOptions(opts ...Option) Options {
-	o := Options{} //nolint:exhaustruct // partial initialization
+	o := Options{ //nolint:exhaustruct // partial initialization
+		CPUOvercommit:    DefaultCPUOvercommit,
+		MemoryOvercommit: DefaultMemoryOvercommit,
+		MaxDiskUsage:     DefaultMaxDiskUsage,
+	}
 
 	for _, opt := range opts {
 		opt(&o)
@@ -26,3 +54,40 @@ func Name(n string) Option {
 		o.Name = n
 	}
 }
+
+// CPUOvercommit sets the ratio of vCPUs that can be scheduled per physical CPU
+// on a cluster host. Ratios less than or equal to zero are ignored.
+func CPUOvercommit(r float64) Option {
+	return func(o *Options) {
+		if r > 0 {
+			o.CPUOvercommit = r
+		}
+	}
+}
+
+// MemoryOvercommit sets the ratio of VM memory that can be scheduled per MB of
+// physical memory on a cluster host. Ratios less than or equal to zero are
+// ignored.
+func MemoryOvercommit(r float64) Option {
+	return func(o *Options) {
+		if r > 0 {
+			o.MemoryOvercommit = r
+		}
+	}
+}
+
+// MaxDiskUsage sets the disk usage (percent) of the minimega base directory at
+// which a cluster host is no longer considered for VMs with drives.
+func MaxDiskUsage(p float64) Option {
+	return func(o *Options) {
+		o.MaxDiskUsage = p
+	}
+}
+
+// Explain sets the map schedulers record the reason each VM was placed on its
+// host to.
+func Explain(r Reasons) Option {
+	return func(o *Options) {
+		o.Reasons = r
+	}
+}
@@ -12,9 +12,13 @@ func init() { //nolint:gochecknoinits // scheduler registration
 	schedulers["round-robin"] = new(roundRobin)
 }
 
-type roundRobin struct{}
+type roundRobin struct {
+	options Options
+}
+
+func (rr *roundRobin) Init(opts ...Option) error {
+	rr.options = NewOptions(opts...)
 
-func (roundRobin) Init(...Option) error {
 	return nil
 }
 
@@ -22,7 +26,7 @@ func (roundRobin) Name() string {
 	return "round-robin"
 }
 
-func (roundRobin) Schedule(spec ifaces.ExperimentSpec) error {
+func (rr roundRobin) Schedule(spec ifaces.ExperimentSpec) error {
 	if len(spec.Topology().Nodes()) == 0 {
 		return errors.New("no VMs defined for experiment")
 	}
@@ -51,6 +55,7 @@ func (roundRobin) Schedule(spec ifaces.ExperimentSpec) error {
 
 		if _, ok := spec.Schedules()[node.General().Hostname()]; !ok {
 			spec.Schedules()[node.General().Hostname()] = cluster[0].Name
+			rr.options.Reasons.record(node.General().Hostname(), "host with the fewest VMs (%d)", cluster[0].VMs)
 
 			cluster[0].VMs += 1
 			cluster.SortByVMs(true)
@@ -1,11 +1,17 @@
 package scheduler
 
 import (
+	"fmt"
+	"sync"
+
 	ifaces "phenix/types/interfaces"
 	"phenix/util/shell"
 )
 
-var schedulers = make(map[string]Scheduler) //nolint:gochecknoglobals // global registry
+var (
+	schedulers = make(map[string]Scheduler) //nolint:gochecknoglobals // global registry
+	scheduleMu sync.Mutex                   //nolint:gochecknoglobals // global lock
+)
 
 // Scheduler is the interface that identifies all the required functionality for
 // a phenix scheduler.
@@ -33,11 +39,26 @@ func List() []string {
 	return names
 }
 
-func Schedule(name string, spec ifaces.ExperimentSpec) error {
+// Schedule runs the scheduler with the given name against the given experiment
+// spec. The given options are used to initialize the scheduler before it runs.
+// Any scheduling constraints declared in the experiment spec or node
+// labels/annotations are honored regardless of the scheduler used.
+func Schedule(name string, spec ifaces.ExperimentSpec, opts ...Option) error {
+	// Registered schedulers are shared, so serialize initializing and running
+	// them to keep options from one call from leaking into another.
+	scheduleMu.Lock()
+	defer scheduleMu.Unlock()
+
 	scheduler, ok := schedulers[name]
 	if !ok {
 		scheduler = new(userScheduler)
-		_ = scheduler.Init(Name(name))
+		opts = append([]Option{Name(name)}, opts...)
+	}
+
+	scheduler = Constrained(scheduler)
+
+	if err := scheduler.Init(opts...); err != nil {
+		return fmt.Errorf("initializing scheduler %s: %w", name, err)
 	}
 
 	return scheduler.Schedule(spec)
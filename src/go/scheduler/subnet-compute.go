@@ -12,9 +12,13 @@ func init() { //nolint:gochecknoinits // scheduler registration
 	schedulers["subnet-compute"] = new(subnetCompute)
 }
 
-type subnetCompute struct{}
+type subnetCompute struct {
+	options Options
+}
+
+func (sc *subnetCompute) Init(opts ...Option) error {
+	sc.options = NewOptions(opts...)
 
-func (subnetCompute) Init(...Option) error {
 	return nil
 }
 
@@ -22,7 +26,7 @@ func (subnetCompute) Name() string {
 	return "subnet-compute"
 }
 
-func (subnetCompute) Schedule(spec ifaces.ExperimentSpec) error {
+func (sc subnetCompute) Schedule(spec ifaces.ExperimentSpec) error {
 	if len(spec.Topology().Nodes()) == 0 {
 		return errors.New("no VMs defined for experiment")
 	}
@@ -88,6 +92,12 @@ func (subnetCompute) Schedule(spec ifaces.ExperimentSpec) error {
 					if (host.MemCommit + node.Hardware().Memory()) < host.MemTotal {
 						scheduled = host
 
+						sc.options.Reasons.record(
+							node.General().Hostname(),
+							"VLAN %s already mapped to host, which has %d MB memory uncommitted",
+							vlan, host.MemTotal-host.MemCommit,
+						)
+
 						break
 					}
 				}
@@ -99,6 +109,12 @@ func (subnetCompute) Schedule(spec ifaces.ExperimentSpec) error {
 			// approach to find a cluster host to map it to.
 			scheduled = &cluster[0]
 
+			sc.options.Reasons.record(
+				node.General().Hostname(),
+				"VLAN %s not mapped to a host with room yet; host with the least committed memory (%d MB)",
+				vlan, scheduled.MemCommit,
+			)
+
 			hosts := vlans[vlan]
 			hosts = append(hosts, scheduled.Name)
 			vlans[vlan] = hosts
@@ -1,8 +1,8 @@
 package store
 
 import (
+	"encoding/binary"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"sync"
@@ -11,13 +11,19 @@ import (
 	"go.etcd.io/bbolt"
 )
 
-const boltFileMode = 0o600
+const (
+	boltFileMode        = 0o600
+	boltChangeLogBucket = "changelog"
+)
 
 type BoltDB struct {
 	mu sync.Mutex
 
 	db   *bbolt.DB
 	path string
+
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 func NewBoltDB() Store { //nolint:ireturn // factory
@@ -37,6 +43,7 @@ func (b *BoltDB) Init(opts ...Option) error {
 	}
 
 	b.path = u.Host + u.Path
+	b.done = make(chan struct{})
 
 	if err := b.InitializeComponent(ComponentStore); err != nil {
 		return fmt.Errorf("initializing component %s: %w", ComponentStore, err)
@@ -50,7 +57,7 @@ func (b *BoltDB) IsInitialized(component Component) bool {
 		return false
 	}
 
-	defer func() { _ = b.Close() }()
+	defer func() { _ = b.close() }()
 
 	v, err := b.get("phenix", string(component))
 	if err != nil {
@@ -66,7 +73,7 @@ func (b *BoltDB) InitializeComponent(component Component) error {
 		return err
 	}
 
-	defer func() { _ = b.Close() }()
+	defer func() { _ = b.close() }()
 
 	err = b.put("phenix", string(component), []byte{1})
 	if err != nil {
@@ -89,7 +96,19 @@ func (b *BoltDB) open() error {
 	return nil
 }
 
+// Close stops any active watches. The Bolt database file itself is only held
+// open for the duration of each store operation.
 func (b *BoltDB) Close() error {
+	b.closeOnce.Do(func() {
+		if b.done != nil {
+			close(b.done)
+		}
+	})
+
+	return nil
+}
+
+func (b *BoltDB) close() error {
 	defer b.mu.Unlock()
 
 	if b.db == nil {
@@ -105,7 +124,7 @@ func (b *BoltDB) List(kinds ...string) (Configs, error) {
 		return nil, err
 	}
 
-	defer func() { _ = b.Close() }()
+	defer func() { _ = b.close() }()
 
 	var configs Configs
 
@@ -148,7 +167,7 @@ func (b *BoltDB) Get(c *Config) error {
 		return err
 	}
 
-	defer func() { _ = b.Close() }()
+	defer func() { _ = b.close() }()
 
 	v, err := b.get(c.Kind, c.Metadata.Name)
 	if err != nil {
@@ -167,7 +186,7 @@ func (b *BoltDB) Create(c *Config) error {
 		return err
 	}
 
-	defer func() { _ = b.Close() }()
+	defer func() { _ = b.close() }()
 
 	if _, err := b.get(c.Kind, c.Metadata.Name); err == nil {
 		return ErrExist
@@ -185,6 +204,7 @@ func (b *BoltDB) Create(c *Config) error {
 	}
 
 	c.Metadata.Updated = now
+	c.Metadata.ResourceVersion = 1
 
 	v, err := json.Marshal(c)
 	if err != nil {
@@ -195,21 +215,36 @@ func (b *BoltDB) Create(c *Config) error {
 		return fmt.Errorf("writing config JSON to Bolt: %w", err)
 	}
 
+	if err := b.logChange(EventCreate, c.Kind, c.Metadata.Name); err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (b *BoltDB) Update(c *Config) error {
 	_ = b.open()
 
-	defer func() { _ = b.Close() }()
+	defer func() { _ = b.close() }()
 
-	if _, err := b.get(c.Kind, c.Metadata.Name); err != nil {
+	v, err := b.get(c.Kind, c.Metadata.Name)
+	if err != nil {
 		return ErrNotExist
 	}
 
+	var stored Config
+
+	if err := json.Unmarshal(v, &stored); err != nil {
+		return fmt.Errorf("unmarshaling config JSON: %w", err)
+	}
+
+	if err := checkResourceVersion(c, stored.Metadata.ResourceVersion); err != nil {
+		return err
+	}
+
 	c.Metadata.Updated = time.Now().Format(time.RFC3339)
 
-	v, err := json.Marshal(c)
+	v, err = json.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("marshaling config JSON: %w", err)
 	}
@@ -218,17 +253,69 @@ func (b *BoltDB) Update(c *Config) error {
 		return fmt.Errorf("writing config JSON to Bolt: %w", err)
 	}
 
+	if err := b.logChange(EventUpdate, c.Kind, c.Metadata.Name); err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (b *BoltDB) Patch(*Config, map[string]any) error {
-	return errors.New("boltDB.Patch not implemented")
+func (b *BoltDB) Patch(c *Config, data map[string]any) error {
+	if err := b.open(); err != nil {
+		return err
+	}
+
+	defer func() { _ = b.close() }()
+
+	if err := b.ensureBucket(c.Kind); err != nil {
+		return err
+	}
+
+	// The read and write are done in a single Bolt transaction so concurrent
+	// patches to the same config don't clobber each other.
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(c.Kind))
+
+		v := bucket.Get([]byte(c.Metadata.Name))
+		if v == nil {
+			return ErrNotExist
+		}
+
+		patched, err := patchConfig(v, c.Metadata.ResourceVersion, data)
+		if err != nil {
+			return err
+		}
+
+		patched.Metadata.Updated = time.Now().Format(time.RFC3339)
+
+		v, err = json.Marshal(patched)
+		if err != nil {
+			return fmt.Errorf("marshaling config JSON: %w", err)
+		}
+
+		if err := bucket.Put([]byte(c.Metadata.Name), v); err != nil {
+			return fmt.Errorf("writing config JSON to Bolt: %w", err)
+		}
+
+		if err := appendBoltChange(tx, EventUpdate, c.Kind, c.Metadata.Name); err != nil {
+			return err
+		}
+
+		*c = *patched
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("patching key %s in bucket %s: %w", c.Metadata.Name, c.Kind, err)
+	}
+
+	return nil
 }
 
 func (b *BoltDB) Delete(c *Config) error {
 	_ = b.open()
 
-	defer func() { _ = b.Close() }()
+	defer func() { _ = b.close() }()
 
 	if err := b.ensureBucket(c.Kind); err != nil {
 		return nil
@@ -242,7 +329,11 @@ func (b *BoltDB) Delete(c *Config) error {
 			return ErrNotExist
 		}
 
-		return b.Delete([]byte(c.Metadata.Name))
+		if err := b.Delete([]byte(c.Metadata.Name)); err != nil {
+			return err
+		}
+
+		return appendBoltChange(tx, EventDelete, c.Kind, c.Metadata.Name)
 	})
 	if err != nil {
 		return fmt.Errorf("deleting key %s in bucket %s: %w", c.Metadata.Name, c.Kind, err)
@@ -300,3 +391,109 @@ func (b *BoltDB) ensureBucket(name string) error {
 		return nil
 	})
 }
+
+func (b *BoltDB) Watch(kinds ...string) <-chan Event {
+	return watchChangeLog(b, b.done, kinds)
+}
+
+func (b *BoltDB) latestChange() (uint64, error) {
+	if err := b.open(); err != nil {
+		return 0, err
+	}
+
+	defer func() { _ = b.close() }()
+
+	var seq uint64
+
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		if bucket := tx.Bucket([]byte(boltChangeLogBucket)); bucket != nil {
+			seq = bucket.Sequence()
+		}
+
+		return nil
+	})
+
+	return seq, err
+}
+
+func (b *BoltDB) changesSince(seq uint64) ([]changeLogEntry, error) {
+	if err := b.open(); err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = b.close() }()
+
+	var changes []changeLogEntry
+
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(boltChangeLogBucket))
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+
+		for k, v := c.Seek(boltChangeKey(seq + 1)); k != nil; k, v = c.Next() {
+			var change changeLogEntry
+
+			if err := json.Unmarshal(v, &change); err != nil {
+				return fmt.Errorf("unmarshaling change log entry: %w", err)
+			}
+
+			changes = append(changes, change)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading Bolt change log: %w", err)
+	}
+
+	return changes, nil
+}
+
+func (b *BoltDB) logChange(typ EventType, kind, name string) error {
+	return b.db.Update(func(tx *bbolt.Tx) error {
+		return appendBoltChange(tx, typ, kind, name)
+	})
+}
+
+// appendBoltChange records a change in the change log as part of the given
+// transaction, pruning the oldest change if the change log is full.
+func appendBoltChange(tx *bbolt.Tx, typ EventType, kind, name string) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(boltChangeLogBucket))
+	if err != nil {
+		return fmt.Errorf("creating change log bucket in Bolt: %w", err)
+	}
+
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return fmt.Errorf("getting next change log sequence: %w", err)
+	}
+
+	v, err := json.Marshal(changeLogEntry{Seq: seq, Type: typ, Kind: kind, Name: name})
+	if err != nil {
+		return fmt.Errorf("marshaling change log entry: %w", err)
+	}
+
+	if err := bucket.Put(boltChangeKey(seq), v); err != nil {
+		return fmt.Errorf("writing change log entry to Bolt: %w", err)
+	}
+
+	if seq > changeLogSize {
+		if err := bucket.Delete(boltChangeKey(seq - changeLogSize)); err != nil {
+			return fmt.Errorf("pruning change log in Bolt: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// boltChangeKey encodes the given sequence number as a big endian key so change
+// log entries are iterated in order.
+func boltChangeKey(seq uint64) []byte {
+	k := make([]byte, 8) //nolint:mnd // uint64
+	binary.BigEndian.PutUint64(k, seq)
+
+	return k
+}
@@ -3,13 +3,14 @@ package store
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
 	"go.etcd.io/etcd/v3/clientv3"
+	"go.etcd.io/etcd/v3/mvcc/mvccpb"
 )
 
 type Etcd struct {
@@ -111,7 +112,7 @@ func (e Etcd) Get(c *Config) error {
 	}
 
 	if resp.Count == 0 {
-		return fmt.Errorf("config %s not found", key)
+		return fmt.Errorf("config %s: %w", key, ErrNotExist)
 	}
 
 	entry := resp.Kvs[0]
@@ -126,22 +127,25 @@ func (e Etcd) Get(c *Config) error {
 func (e Etcd) Create(c *Config) error {
 	key := fmt.Sprintf("%s/%s", strings.ToLower(c.Kind), c.Metadata.Name)
 
-	if resp, _ := e.cli.Get(context.Background(), key); resp.Count != 0 {
-		return fmt.Errorf("config %s/%s already exists", c.Kind, c.Metadata.Name)
-	}
-
 	now := time.Now().Format(time.RFC3339)
 
 	c.Metadata.Created = now
 	c.Metadata.Updated = now
+	c.Metadata.ResourceVersion = 1
 
 	v, err := json.Marshal(c)
 	if err != nil {
 		return fmt.Errorf("marshaling config JSON: %w", err)
 	}
 
-	if _, err := e.cli.Put(context.Background(), key, string(v)); err != nil {
-		return fmt.Errorf("writing config JSON to Etcd: %w", err)
+	// A create revision of 0 means the key doesn't exist yet.
+	ok, err := e.put(key, 0, v)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("config %s/%s: %w", c.Kind, c.Metadata.Name, ErrExist)
 	}
 
 	return nil
@@ -150,28 +154,80 @@ func (e Etcd) Create(c *Config) error {
 func (e Etcd) Update(c *Config) error {
 	key := fmt.Sprintf("%s/%s", strings.ToLower(c.Kind), c.Metadata.Name)
 
-	if resp, _ := e.cli.Get(context.Background(), key); resp.Count == 0 {
-		return fmt.Errorf("config %s/%s doesn't exist", c.Kind, c.Metadata.Name)
-	}
+	expected := c.Metadata.ResourceVersion
 
-	now := time.Now().Format(time.RFC3339)
+	// Retry if the key was modified between reading and writing it. If the
+	// caller provided a resource version the retry will result in a conflict
+	// error, otherwise the update is applied to the latest version.
+	for {
+		kv, err := e.get(key)
+		if err != nil {
+			return fmt.Errorf("config %s/%s: %w", c.Kind, c.Metadata.Name, err)
+		}
 
-	c.Metadata.Updated = now
+		var stored Config
 
-	v, err := json.Marshal(c)
-	if err != nil {
-		return fmt.Errorf("marshaling config JSON: %w", err)
-	}
+		if err := json.Unmarshal(kv.Value, &stored); err != nil {
+			return fmt.Errorf("unmarshaling config JSON: %w", err)
+		}
 
-	if _, err := e.cli.Put(context.Background(), key, string(v)); err != nil {
-		return fmt.Errorf("writing config JSON to Etcd: %w", err)
-	}
+		c.Metadata.ResourceVersion = expected
 
-	return nil
+		if err := checkResourceVersion(c, stored.Metadata.ResourceVersion); err != nil {
+			return err
+		}
+
+		c.Metadata.Updated = time.Now().Format(time.RFC3339)
+
+		v, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("marshaling config JSON: %w", err)
+		}
+
+		ok, err := e.put(key, kv.ModRevision, v)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			return nil
+		}
+	}
 }
 
-func (e Etcd) Patch(c *Config, u map[string]any) error {
-	return errors.New("not implemented")
+func (e Etcd) Patch(c *Config, data map[string]any) error {
+	key := fmt.Sprintf("%s/%s", strings.ToLower(c.Kind), c.Metadata.Name)
+
+	// See comment in `Etcd.Update` about retrying.
+	for {
+		kv, err := e.get(key)
+		if err != nil {
+			return fmt.Errorf("config %s/%s: %w", c.Kind, c.Metadata.Name, err)
+		}
+
+		patched, err := patchConfig(kv.Value, c.Metadata.ResourceVersion, data)
+		if err != nil {
+			return err
+		}
+
+		patched.Metadata.Updated = time.Now().Format(time.RFC3339)
+
+		v, err := json.Marshal(patched)
+		if err != nil {
+			return fmt.Errorf("marshaling config JSON: %w", err)
+		}
+
+		ok, err := e.put(key, kv.ModRevision, v)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			*c = *patched
+
+			return nil
+		}
+	}
 }
 
 func (e Etcd) Delete(c *Config) error {
@@ -183,3 +239,117 @@ func (e Etcd) Delete(c *Config) error {
 
 	return nil
 }
+
+func (e Etcd) get(key string) (*mvccpb.KeyValue, error) {
+	resp, err := e.cli.Get(context.Background(), key)
+	if err != nil {
+		return nil, fmt.Errorf("getting key %s from Etcd: %w", key, err)
+	}
+
+	if resp.Count == 0 {
+		return nil, ErrNotExist
+	}
+
+	return resp.Kvs[0], nil
+}
+
+// put writes the given value to the given key only if the key's modification
+// revision still matches the given revision. A revision of 0 requires that the
+// key not exist. It returns false if the key was modified.
+func (e Etcd) put(key string, rev int64, v []byte) (bool, error) {
+	resp, err := e.cli.Txn(context.Background()).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", rev)).
+		Then(clientv3.OpPut(key, string(v))).
+		Commit()
+	if err != nil {
+		return false, fmt.Errorf("writing config JSON to Etcd: %w", err)
+	}
+
+	return resp.Succeeded, nil
+}
+
+// Watch uses native Etcd watches, so only changes made while the Etcd client is
+// connected are reported.
+func (e Etcd) Watch(kinds ...string) <-chan Event {
+	var (
+		events   = make(chan Event, watchBuffer)
+		prefixes []string
+		wg       sync.WaitGroup
+	)
+
+	for _, kind := range kinds {
+		prefixes = append(prefixes, strings.ToLower(kind)+"/")
+	}
+
+	// An empty prefix watches every key in Etcd.
+	if len(prefixes) == 0 {
+		prefixes = []string{""}
+	}
+
+	for _, prefix := range prefixes {
+		wg.Add(1)
+
+		watch := e.cli.Watch(context.Background(), prefix, clientv3.WithPrefix(), clientv3.WithPrevKV())
+
+		go func() {
+			defer wg.Done()
+
+			for resp := range watch {
+				for _, ev := range resp.Events {
+					if event, ok := etcdEvent(ev); ok {
+						events <- event
+					}
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+
+	return events
+}
+
+func etcdEvent(ev *clientv3.Event) (Event, bool) {
+	// Skip component keys, which aren't configs.
+	if strings.HasPrefix(string(ev.Kv.Key), "phenix/") {
+		return Event{}, false //nolint:exhaustruct // unused
+	}
+
+	var (
+		c     Config
+		event Event
+	)
+
+	switch {
+	case ev.Type == clientv3.EventTypeDelete:
+		event.Type = EventDelete
+
+		if ev.PrevKv == nil {
+			return event, false
+		}
+
+		if err := json.Unmarshal(ev.PrevKv.Value, &c); err != nil {
+			return event, false
+		}
+	case ev.IsCreate():
+		event.Type = EventCreate
+	default:
+		event.Type = EventUpdate
+	}
+
+	if event.Type != EventDelete {
+		if err := json.Unmarshal(ev.Kv.Value, &c); err != nil {
+			return event, false
+		}
+
+		event.Config = &c
+	}
+
+	event.Kind = c.Kind
+	event.Name = c.Metadata.Name
+
+	return event, true
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"phenix/types/version"
+)
+
+// Kinds returns every config kind persisted in the store, sorted by name. This
+// includes all versioned kinds along with internal kinds like settings and
+// config revisions.
+func Kinds() []string {
+	kinds := slices.Collect(maps.Keys(version.StoredVersion))
+	kinds = append(kinds, "Revision", "RevisionIndex", "Setting")
+
+	slices.Sort(kinds)
+
+	return kinds
+}
+
+// Copy copies every config of the given kinds (or all kinds if none are given)
+// from the src store to the dst store, along with the initialization state of
+// phenix components. Configs that already exist in the dst store are skipped
+// unless overwrite is true. It returns the number of configs copied.
+func Copy(src, dst Store, overwrite bool, kinds ...string) (int, error) {
+	if len(kinds) == 0 {
+		kinds = Kinds()
+	}
+
+	var copied int
+
+	for _, kind := range kinds {
+		configs, err := src.List(kind)
+		if err != nil {
+			return copied, fmt.Errorf("listing %s configs in source store: %w", kind, err)
+		}
+
+		for _, c := range configs {
+			err := dst.Create(&c)
+			if errors.Is(err, ErrExist) {
+				if !overwrite {
+					continue
+				}
+
+				// Overwrite the config regardless of its resource version in the
+				// destination store.
+				c.Metadata.ResourceVersion = 0
+
+				err = dst.Update(&c)
+			}
+
+			if err != nil {
+				return copied, fmt.Errorf("copying config %s to destination store: %w", c.FullName(), err)
+			}
+
+			copied++
+		}
+	}
+
+	for _, component := range []Component{ComponentStore, ComponentConfigs} {
+		if !src.IsInitialized(component) {
+			continue
+		}
+
+		if err := dst.InitializeComponent(component); err != nil {
+			return copied, fmt.Errorf("initializing component %s in destination store: %w", component, err)
+		}
+	}
+
+	return copied, nil
+}
@@ -10,21 +10,47 @@ var DefaultStore Store = NewBoltDB() //nolint:gochecknoglobals // default implem
 func Init(opts ...Option) error {
 	options := NewOptions(opts...)
 
-	u, err := url.Parse(options.Endpoint)
+	s, err := newStoreForEndpoint(options.Endpoint)
 	if err != nil {
-		return fmt.Errorf("parsing store endpoint: %w", err)
+		return err
+	}
+
+	DefaultStore = s
+
+	return DefaultStore.Init(opts...)
+}
+
+// NewFromEndpoint returns a new, initialized store for the given endpoint
+// without modifying the default store.
+func NewFromEndpoint(endpoint string) (Store, error) { //nolint:ireturn // factory
+	s, err := newStoreForEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Init(Endpoint(endpoint)); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func newStoreForEndpoint(endpoint string) (Store, error) { //nolint:ireturn // factory
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parsing store endpoint: %w", err)
 	}
 
 	switch u.Scheme {
 	case "bolt":
-		DefaultStore = NewBoltDB()
+		return NewBoltDB(), nil
 	case "etcd":
-		DefaultStore = NewEtcd()
+		return NewEtcd(), nil
+	case "sqlite":
+		return NewSQLite(), nil
 	default:
-		return fmt.Errorf("unknown store scheme '%s'", u.Scheme)
+		return nil, fmt.Errorf("unknown store scheme '%s'", u.Scheme)
 	}
-
-	return DefaultStore.Init(opts...)
 }
 
 func Close() error {
@@ -55,6 +81,10 @@ func Delete(config *Config) error {
 	return DefaultStore.Delete(config)
 }
 
+func Watch(kinds ...string) <-chan Event {
+	return DefaultStore.Watch(kinds...)
+}
+
 func IsInitialized(component Component) bool {
 	return DefaultStore.IsInitialized(component)
 }
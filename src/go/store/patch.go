@@ -0,0 +1,87 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// patchConfig applies the given data to the given JSON-encoded config as a
+// JSON merge patch (RFC 7386). Nested maps are merged recursively, nil values
+// remove keys, and all other values replace existing ones. The config's kind,
+// name and created timestamp cannot be changed by a patch. If expected is not
+// zero, it must match the resource version of the given config.
+func patchConfig(v []byte, expected int64, data map[string]any) (*Config, error) {
+	var doc map[string]any
+
+	if err := json.Unmarshal(v, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshaling config JSON: %w", err)
+	}
+
+	// Round-trip the patch data through JSON so typed values (structs, typed
+	// slices, etc.) are merged the same way they would be stored.
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling patch JSON: %w", err)
+	}
+
+	var patch map[string]any
+
+	if err := json.Unmarshal(body, &patch); err != nil {
+		return nil, fmt.Errorf("unmarshaling patch JSON: %w", err)
+	}
+
+	var orig Config
+
+	if err := json.Unmarshal(v, &orig); err != nil {
+		return nil, fmt.Errorf("unmarshaling config JSON: %w", err)
+	}
+
+	stored := orig.Metadata.ResourceVersion
+	orig.Metadata.ResourceVersion = expected
+
+	if err := checkResourceVersion(&orig, stored); err != nil {
+		return nil, err
+	}
+
+	MergePatch(doc, patch)
+
+	body, err = json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling patched config JSON: %w", err)
+	}
+
+	var c Config
+
+	if err := json.Unmarshal(body, &c); err != nil {
+		return nil, fmt.Errorf("unmarshaling patched config JSON: %w", err)
+	}
+
+	c.Kind = orig.Kind
+	c.Metadata.Name = orig.Metadata.Name
+	c.Metadata.Created = orig.Metadata.Created
+	c.Metadata.ResourceVersion = orig.Metadata.ResourceVersion
+
+	return &c, nil
+}
+
+// MergePatch applies the given JSON merge patch (RFC 7386) to the given
+// JSON-decoded document in place.
+func MergePatch(dst, patch map[string]any) {
+	for k, v := range patch {
+		if v == nil {
+			delete(dst, k)
+
+			continue
+		}
+
+		if pm, ok := v.(map[string]any); ok {
+			if dm, ok := dst[k].(map[string]any); ok {
+				MergePatch(dm, pm)
+
+				continue
+			}
+		}
+
+		dst[k] = v
+	}
+}
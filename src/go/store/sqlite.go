@@ -0,0 +1,403 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // register SQLite driver
+)
+
+const sqliteBusyTimeout = 5000 // milliseconds
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS configs (
+	kind        TEXT NOT NULL,
+	name        TEXT NOT NULL,
+	api_version TEXT NOT NULL,
+	created     TEXT NOT NULL,
+	updated     TEXT NOT NULL,
+	data        TEXT NOT NULL,
+	PRIMARY KEY (kind, name)
+);
+
+CREATE TABLE IF NOT EXISTS components (
+	name        TEXT PRIMARY KEY,
+	initialized INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS changelog (
+	seq  INTEGER PRIMARY KEY AUTOINCREMENT,
+	type TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	name TEXT NOT NULL
+);
+`
+
+type SQLite struct {
+	db   *sql.DB
+	path string
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewSQLite() Store { //nolint:ireturn // factory
+	return new(SQLite)
+}
+
+func (s *SQLite) Init(opts ...Option) error {
+	options := NewOptions(opts...)
+
+	u, err := url.Parse(options.Endpoint)
+	if err != nil {
+		return fmt.Errorf("parsing SQLite endpoint: %w", err)
+	}
+
+	if u.Scheme != "sqlite" {
+		return fmt.Errorf("invalid scheme '%s' for SQLite endpoint", u.Scheme)
+	}
+
+	s.path = u.Host + u.Path
+	s.done = make(chan struct{})
+
+	// WAL mode allows concurrent readers alongside a single writer, and the busy
+	// timeout keeps concurrent writers from failing immediately with
+	// SQLITE_BUSY.
+	dsn := fmt.Sprintf(
+		"file:%s?_pragma=busy_timeout(%d)&_pragma=journal_mode(WAL)&_txlock=immediate",
+		s.path, sqliteBusyTimeout,
+	)
+
+	s.db, err = sql.Open("sqlite", dsn)
+	if err != nil {
+		return fmt.Errorf("opening SQLite database %s: %w", s.path, err)
+	}
+
+	if _, err := s.db.Exec(sqliteSchema); err != nil {
+		return fmt.Errorf("creating SQLite schema: %w", err)
+	}
+
+	if err := s.InitializeComponent(ComponentStore); err != nil {
+		return fmt.Errorf("initializing component %s: %w", ComponentStore, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) IsInitialized(component Component) bool {
+	var initialized bool
+
+	row := s.db.QueryRow("SELECT initialized FROM components WHERE name = ?", string(component))
+
+	if err := row.Scan(&initialized); err != nil {
+		return false
+	}
+
+	return initialized
+}
+
+func (s *SQLite) InitializeComponent(component Component) error {
+	_, err := s.db.Exec(
+		"INSERT INTO components (name, initialized) VALUES (?, 1) ON CONFLICT (name) DO UPDATE SET initialized = 1",
+		string(component),
+	)
+	if err != nil {
+		return fmt.Errorf("marking component %s as initialized: %w", component, err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) Close() error {
+	s.closeOnce.Do(func() {
+		if s.done != nil {
+			close(s.done)
+		}
+	})
+
+	if s.db == nil {
+		return nil
+	}
+
+	return s.db.Close()
+}
+
+func (s *SQLite) List(kinds ...string) (Configs, error) {
+	var configs Configs
+
+	for _, kind := range kinds {
+		rows, err := s.db.Query("SELECT data FROM configs WHERE kind = ? ORDER BY name", kind)
+		if err != nil {
+			return nil, fmt.Errorf("getting configs from store: %w", err)
+		}
+
+		for rows.Next() {
+			var (
+				v string
+				c Config
+			)
+
+			if err := rows.Scan(&v); err != nil {
+				_ = rows.Close()
+
+				return nil, fmt.Errorf("scanning %s config: %w", kind, err)
+			}
+
+			if err := json.Unmarshal([]byte(v), &c); err != nil {
+				_ = rows.Close()
+
+				return nil, fmt.Errorf("unmarshaling config JSON: %w", err)
+			}
+
+			configs = append(configs, c)
+		}
+
+		err = errors.Join(rows.Err(), rows.Close())
+		if err != nil {
+			return nil, fmt.Errorf("iterating %s configs: %w", kind, err)
+		}
+	}
+
+	return configs, nil
+}
+
+func (s *SQLite) Get(c *Config) error {
+	v, err := s.get(s.db, c.Kind, c.Metadata.Name)
+	if err != nil {
+		return fmt.Errorf("getting config: %w", err)
+	}
+
+	if err := json.Unmarshal(v, c); err != nil {
+		return fmt.Errorf("unmarshaling config JSON: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) Create(c *Config) error {
+	return s.tx(func(tx *sql.Tx) error {
+		if _, err := s.get(tx, c.Kind, c.Metadata.Name); err == nil {
+			return ErrExist
+		}
+
+		now := time.Now().Format(time.RFC3339)
+
+		// See comment in `BoltDB.Create` about why the created timestamp may
+		// already be set.
+		if c.Metadata.Created == "" {
+			c.Metadata.Created = now
+		}
+
+		c.Metadata.Updated = now
+		c.Metadata.ResourceVersion = 1
+
+		if err := s.put(tx, c); err != nil {
+			return err
+		}
+
+		return s.logChange(tx, EventCreate, c.Kind, c.Metadata.Name)
+	})
+}
+
+func (s *SQLite) Update(c *Config) error {
+	return s.tx(func(tx *sql.Tx) error {
+		v, err := s.get(tx, c.Kind, c.Metadata.Name)
+		if err != nil {
+			return ErrNotExist
+		}
+
+		var stored Config
+
+		if err := json.Unmarshal(v, &stored); err != nil {
+			return fmt.Errorf("unmarshaling config JSON: %w", err)
+		}
+
+		if err := checkResourceVersion(c, stored.Metadata.ResourceVersion); err != nil {
+			return err
+		}
+
+		c.Metadata.Updated = time.Now().Format(time.RFC3339)
+
+		if err := s.put(tx, c); err != nil {
+			return err
+		}
+
+		return s.logChange(tx, EventUpdate, c.Kind, c.Metadata.Name)
+	})
+}
+
+func (s *SQLite) Patch(c *Config, data map[string]any) error {
+	return s.tx(func(tx *sql.Tx) error {
+		v, err := s.get(tx, c.Kind, c.Metadata.Name)
+		if err != nil {
+			return ErrNotExist
+		}
+
+		patched, err := patchConfig(v, c.Metadata.ResourceVersion, data)
+		if err != nil {
+			return err
+		}
+
+		patched.Metadata.Updated = time.Now().Format(time.RFC3339)
+
+		if err := s.put(tx, patched); err != nil {
+			return err
+		}
+
+		if err := s.logChange(tx, EventUpdate, c.Kind, c.Metadata.Name); err != nil {
+			return err
+		}
+
+		*c = *patched
+
+		return nil
+	})
+}
+
+func (s *SQLite) Delete(c *Config) error {
+	return s.tx(func(tx *sql.Tx) error {
+		res, err := tx.Exec(
+			"DELETE FROM configs WHERE kind = ? AND name = ?",
+			c.Kind, c.Metadata.Name,
+		)
+		if err != nil {
+			return fmt.Errorf("deleting config %s/%s: %w", c.Kind, c.Metadata.Name, err)
+		}
+
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("deleting config %s/%s: %w", c.Kind, c.Metadata.Name, ErrNotExist)
+		}
+
+		return s.logChange(tx, EventDelete, c.Kind, c.Metadata.Name)
+	})
+}
+
+func (s *SQLite) Watch(kinds ...string) <-chan Event {
+	return watchChangeLog(s, s.done, kinds)
+}
+
+func (s *SQLite) latestChange() (uint64, error) {
+	var seq uint64
+
+	if err := s.db.QueryRow("SELECT COALESCE(MAX(seq), 0) FROM changelog").Scan(&seq); err != nil {
+		return 0, fmt.Errorf("querying latest change: %w", err)
+	}
+
+	return seq, nil
+}
+
+func (s *SQLite) changesSince(seq uint64) ([]changeLogEntry, error) {
+	rows, err := s.db.Query("SELECT seq, type, kind, name FROM changelog WHERE seq > ? ORDER BY seq", seq)
+	if err != nil {
+		return nil, fmt.Errorf("querying change log: %w", err)
+	}
+
+	var changes []changeLogEntry
+
+	for rows.Next() {
+		var change changeLogEntry
+
+		if err := rows.Scan(&change.Seq, &change.Type, &change.Kind, &change.Name); err != nil {
+			_ = rows.Close()
+
+			return nil, fmt.Errorf("scanning change log entry: %w", err)
+		}
+
+		changes = append(changes, change)
+	}
+
+	if err := errors.Join(rows.Err(), rows.Close()); err != nil {
+		return nil, fmt.Errorf("iterating change log: %w", err)
+	}
+
+	return changes, nil
+}
+
+// logChange records a change in the change log as part of the given
+// transaction, pruning changes that have fallen out of the change log window.
+func (s *SQLite) logChange(tx *sql.Tx, typ EventType, kind, name string) error {
+	res, err := tx.Exec(
+		"INSERT INTO changelog (type, kind, name) VALUES (?, ?, ?)",
+		string(typ), kind, name,
+	)
+	if err != nil {
+		return fmt.Errorf("writing change log entry to SQLite: %w", err)
+	}
+
+	seq, err := res.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("getting change log sequence: %w", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM changelog WHERE seq <= ?", seq-changeLogSize); err != nil {
+		return fmt.Errorf("pruning change log in SQLite: %w", err)
+	}
+
+	return nil
+}
+
+// sqliteQuerier is implemented by both *sql.DB and *sql.Tx.
+type sqliteQuerier interface {
+	QueryRow(string, ...any) *sql.Row
+}
+
+func (s *SQLite) get(q sqliteQuerier, kind, name string) ([]byte, error) {
+	var v string
+
+	err := q.QueryRow("SELECT data FROM configs WHERE kind = ? AND name = ?", kind, name).Scan(&v)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: config %s/%s does not exist", ErrNotExist, kind, name)
+		}
+
+		return nil, fmt.Errorf("querying config %s/%s: %w", kind, name, err)
+	}
+
+	return []byte(v), nil
+}
+
+func (s *SQLite) put(tx *sql.Tx, c *Config) error {
+	v, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("marshaling config JSON: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO configs (kind, name, api_version, created, updated, data) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (kind, name) DO UPDATE SET
+			api_version = excluded.api_version,
+			updated = excluded.updated,
+			data = excluded.data`,
+		c.Kind, c.Metadata.Name, c.Version, c.Metadata.Created, c.Metadata.Updated, string(v),
+	)
+	if err != nil {
+		return fmt.Errorf("writing config JSON to SQLite: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) tx(fn func(*sql.Tx) error) error {
+	tx, err := s.db.BeginTx(context.Background(), nil)
+	if err != nil {
+		return fmt.Errorf("starting SQLite transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing SQLite transaction: %w", err)
+	}
+
+	return nil
+}
@@ -1,12 +1,56 @@
 package store
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrExist    = errors.New("config already exists")
 	ErrNotExist = errors.New("config does not exist")
+	ErrConflict = errors.New("config has been modified")
 )
 
+// ConflictError is returned by Update and Patch when the resource version of
+// the given config doesn't match the version currently in the store, meaning
+// the config was modified since it was read. It satisfies
+// errors.Is(err, ErrConflict).
+type ConflictError struct {
+	Kind     string
+	Name     string
+	Expected int64
+	Actual   int64
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf(
+		"config %s/%s has been modified (resource version is %d, expected %d)",
+		e.Kind, e.Name, e.Actual, e.Expected,
+	)
+}
+
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict //nolint:errorlint // sentinel comparison
+}
+
+// checkResourceVersion ensures the resource version of the given config, if
+// set, matches the resource version of the stored config. On success, the given
+// config's resource version is set to the next version.
+func checkResourceVersion(c *Config, stored int64) error {
+	if c.Metadata.ResourceVersion != 0 && c.Metadata.ResourceVersion != stored {
+		return &ConflictError{
+			Kind:     c.Kind,
+			Name:     c.Metadata.Name,
+			Expected: c.Metadata.ResourceVersion,
+			Actual:   stored,
+		}
+	}
+
+	c.Metadata.ResourceVersion = stored + 1
+
+	return nil
+}
+
 type (
 	Component string
 )
@@ -36,16 +80,24 @@ type Store interface {
 	// Create persists the given config to the store if it doesn't already exist.
 	Create(*Config) error
 
-	// Update persists the given config to the store if it already exists.
+	// Update persists the given config to the store if it already exists. If the
+	// given config has a resource version set, a ConflictError is returned if it
+	// doesn't match the version in the store.
 	Update(*Config) error
 
 	// Patch modifies the given config in the store with the given data if the
-	// config already exists.
+	// config already exists. Resource versions are checked the same as Update.
 	Patch(*Config, map[string]any) error
 
 	// Delete removes the given config from the config store.
 	Delete(*Config) error
 
+	// Watch returns a channel of events for changes made to configs of the
+	// given kind(s), or all kinds if none are given, by any process using the
+	// store. Only changes made after Watch is called are reported. The channel
+	// is closed when the store is closed.
+	Watch(...string) <-chan Event
+
 	// IsInitialized checks if the given phenix components have been
 	// initialized. This is used to avoid re-initializing the store or
 	// default configs.
@@ -0,0 +1,417 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestStores returns an initialized instance of every file-backed store
+// implementation so the same behavior tests can be run against each of them.
+func newTestStores(t *testing.T) map[string]Store {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	endpoints := map[string]string{
+		"bolt":   "bolt://" + filepath.Join(dir, "store.bdb"),
+		"sqlite": "sqlite://" + filepath.Join(dir, "store.db"),
+	}
+
+	stores := make(map[string]Store)
+
+	for name, endpoint := range endpoints {
+		s, err := NewFromEndpoint(endpoint)
+		if err != nil {
+			t.Fatalf("creating %s store: %v", name, err)
+		}
+
+		t.Cleanup(func() { _ = s.Close() })
+
+		stores[name] = s
+	}
+
+	return stores
+}
+
+func newTestConfig(name string) *Config {
+	return &Config{ //nolint:exhaustruct // partial initialization
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Topology",
+		Metadata: ConfigMetadata{Name: name}, //nolint:exhaustruct // partial initialization
+		Spec:     map[string]any{"nodes": []any{map[string]any{"type": "VirtualMachine"}}},
+	}
+}
+
+func TestStoreCRUD(t *testing.T) {
+	for name, s := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			c := newTestConfig("foo")
+
+			if err := s.Create(c); err != nil {
+				t.Fatalf("creating config: %v", err)
+			}
+
+			if c.Metadata.Created == "" || c.Metadata.Updated == "" {
+				t.Fatal("expected created and updated timestamps to be set")
+			}
+
+			if err := s.Create(newTestConfig("foo")); !errors.Is(err, ErrExist) {
+				t.Fatalf("expected ErrExist creating duplicate config, got %v", err)
+			}
+
+			got := &Config{Kind: "Topology", Metadata: ConfigMetadata{Name: "foo"}} //nolint:exhaustruct // partial initialization
+
+			if err := s.Get(got); err != nil {
+				t.Fatalf("getting config: %v", err)
+			}
+
+			if got.Version != c.Version || len(got.Spec["nodes"].([]any)) != 1 { //nolint:forcetypeassert // test
+				t.Fatalf("unexpected config %+v", got)
+			}
+
+			missing := newTestConfig("bar")
+
+			if err := s.Get(missing); !errors.Is(err, ErrNotExist) {
+				t.Fatalf("expected ErrNotExist getting missing config, got %v", err)
+			}
+
+			if err := s.Update(missing); !errors.Is(err, ErrNotExist) {
+				t.Fatalf("expected ErrNotExist updating missing config, got %v", err)
+			}
+
+			got.Spec["nodes"] = []any{}
+
+			if err := s.Update(got); err != nil {
+				t.Fatalf("updating config: %v", err)
+			}
+
+			if err := s.Create(newTestConfig("bar")); err != nil {
+				t.Fatalf("creating config: %v", err)
+			}
+
+			configs, err := s.List("Topology", "Scenario")
+			if err != nil {
+				t.Fatalf("listing configs: %v", err)
+			}
+
+			if len(configs) != 2 {
+				t.Fatalf("expected 2 configs, got %d", len(configs))
+			}
+
+			if err := s.Delete(c); err != nil {
+				t.Fatalf("deleting config: %v", err)
+			}
+
+			if err := s.Delete(c); !errors.Is(err, ErrNotExist) {
+				t.Fatalf("expected ErrNotExist deleting missing config, got %v", err)
+			}
+
+			if configs, _ := s.List("Topology"); len(configs) != 1 {
+				t.Fatalf("expected 1 config after delete, got %d", len(configs))
+			}
+		})
+	}
+}
+
+func TestStorePatch(t *testing.T) {
+	for name, s := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			c := newTestConfig("foo")
+			c.Status = map[string]any{"state": "stopped", "apps": map[string]any{"ntp": "ok"}}
+
+			if err := s.Create(c); err != nil {
+				t.Fatalf("creating config: %v", err)
+			}
+
+			created := c.Metadata.Created
+
+			patch := map[string]any{
+				"metadata": map[string]any{"name": "renamed", "created": "never"},
+				"status":   map[string]any{"state": "started", "apps": map[string]any{"dns": "ok"}},
+				"spec":     nil,
+			}
+
+			if err := s.Patch(c, patch); err != nil {
+				t.Fatalf("patching config: %v", err)
+			}
+
+			got := &Config{Kind: "Topology", Metadata: ConfigMetadata{Name: "foo"}} //nolint:exhaustruct // partial initialization
+
+			if err := s.Get(got); err != nil {
+				t.Fatalf("getting patched config: %v", err)
+			}
+
+			if got.Metadata.Created != created {
+				t.Fatalf("expected created timestamp to be preserved, got %s", got.Metadata.Created)
+			}
+
+			if got.Spec != nil {
+				t.Fatalf("expected spec to be removed, got %v", got.Spec)
+			}
+
+			apps, _ := got.Status["apps"].(map[string]any)
+
+			if got.Status["state"] != "started" || apps["ntp"] != "ok" || apps["dns"] != "ok" {
+				t.Fatalf("unexpected patched status %v", got.Status)
+			}
+
+			if err := s.Patch(newTestConfig("bar"), patch); !errors.Is(err, ErrNotExist) {
+				t.Fatalf("expected ErrNotExist patching missing config, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStoreResourceVersion(t *testing.T) {
+	for name, s := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			c := newTestConfig("foo")
+
+			if err := s.Create(c); err != nil {
+				t.Fatalf("creating config: %v", err)
+			}
+
+			if c.Metadata.ResourceVersion != 1 {
+				t.Fatalf("expected resource version 1 after create, got %d", c.Metadata.ResourceVersion)
+			}
+
+			stale := *c
+
+			if err := s.Update(c); err != nil {
+				t.Fatalf("updating config: %v", err)
+			}
+
+			if c.Metadata.ResourceVersion != 2 {
+				t.Fatalf("expected resource version 2 after update, got %d", c.Metadata.ResourceVersion)
+			}
+
+			err := s.Update(&stale)
+			if !errors.Is(err, ErrConflict) {
+				t.Fatalf("expected ErrConflict updating stale config, got %v", err)
+			}
+
+			var conflict *ConflictError
+
+			if !errors.As(err, &conflict) || conflict.Expected != 1 || conflict.Actual != 2 {
+				t.Fatalf("unexpected conflict error %v", err)
+			}
+
+			if err := s.Patch(&stale, map[string]any{"status": map[string]any{"foo": "bar"}}); !errors.Is(err, ErrConflict) {
+				t.Fatalf("expected ErrConflict patching stale config, got %v", err)
+			}
+
+			if err := s.Patch(c, map[string]any{"metadata": map[string]any{"resourceVersion": 1}}); err != nil {
+				t.Fatalf("patching config: %v", err)
+			}
+
+			if c.Metadata.ResourceVersion != 3 {
+				t.Fatalf("expected resource version 3 after patch, got %d", c.Metadata.ResourceVersion)
+			}
+
+			// Configs without a resource version are written unconditionally.
+			unversioned := newTestConfig("foo")
+
+			if err := s.Update(unversioned); err != nil {
+				t.Fatalf("updating unversioned config: %v", err)
+			}
+
+			if unversioned.Metadata.ResourceVersion != 4 {
+				t.Fatalf("expected resource version 4 after update, got %d", unversioned.Metadata.ResourceVersion)
+			}
+		})
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	for name, s := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			events := s.Watch("Topology")
+
+			expect := func(typ EventType) {
+				t.Helper()
+
+				select {
+				case event := <-events:
+					if event.Type != typ || event.FullName() != "Topology/foo" {
+						t.Fatalf("expected %s event for Topology/foo, got %s event for %s", typ, event.Type, event.FullName())
+					}
+
+					if (typ == EventDelete) != (event.Config == nil) {
+						t.Fatalf("unexpected config with %s event: %+v", typ, event.Config)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatalf("timed out waiting for %s event", typ)
+				}
+			}
+
+			c := newTestConfig("foo")
+
+			if err := s.Create(c); err != nil {
+				t.Fatalf("creating config: %v", err)
+			}
+
+			expect(EventCreate)
+
+			// Changes to kinds not being watched are not reported.
+			scenario := newTestConfig("foo")
+			scenario.Kind = "Scenario"
+
+			if err := s.Create(scenario); err != nil {
+				t.Fatalf("creating config: %v", err)
+			}
+
+			if err := s.Patch(c, map[string]any{"status": map[string]any{"state": "started"}}); err != nil {
+				t.Fatalf("patching config: %v", err)
+			}
+
+			expect(EventUpdate)
+
+			if err := s.Delete(c); err != nil {
+				t.Fatalf("deleting config: %v", err)
+			}
+
+			expect(EventDelete)
+
+			if err := s.Close(); err != nil {
+				t.Fatalf("closing store: %v", err)
+			}
+
+			if _, ok := <-events; ok {
+				t.Fatal("expected events channel to be closed when store is closed")
+			}
+		})
+	}
+}
+
+func TestStoreComponents(t *testing.T) {
+	for name, s := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if !s.IsInitialized(ComponentStore) {
+				t.Fatal("expected store component to be initialized")
+			}
+
+			if s.IsInitialized(ComponentConfigs) {
+				t.Fatal("expected configs component to not be initialized")
+			}
+
+			if err := s.InitializeComponent(ComponentConfigs); err != nil {
+				t.Fatalf("initializing component: %v", err)
+			}
+
+			if !s.IsInitialized(ComponentConfigs) {
+				t.Fatal("expected configs component to be initialized")
+			}
+		})
+	}
+}
+
+func TestStoreConcurrentAccess(t *testing.T) {
+	const workers = 10
+
+	for name, s := range newTestStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := s.Create(newTestConfig("shared")); err != nil {
+				t.Fatalf("creating config: %v", err)
+			}
+
+			var (
+				wg   sync.WaitGroup
+				errs = make(chan error, workers*2)
+			)
+
+			for i := range workers {
+				wg.Add(1)
+
+				go func() {
+					defer wg.Done()
+
+					if err := s.Create(newTestConfig(fmt.Sprintf("worker-%d", i))); err != nil {
+						errs <- err
+					}
+
+					patch := map[string]any{"status": map[string]any{fmt.Sprintf("worker-%d", i): true}}
+
+					if err := s.Patch(newTestConfig("shared"), patch); err != nil {
+						errs <- err
+					}
+				}()
+			}
+
+			wg.Wait()
+			close(errs)
+
+			for err := range errs {
+				t.Fatalf("concurrent access: %v", err)
+			}
+
+			configs, err := s.List("Topology")
+			if err != nil {
+				t.Fatalf("listing configs: %v", err)
+			}
+
+			if len(configs) != workers+1 {
+				t.Fatalf("expected %d configs, got %d", workers+1, len(configs))
+			}
+
+			shared := newTestConfig("shared")
+
+			if err := s.Get(shared); err != nil {
+				t.Fatalf("getting config: %v", err)
+			}
+
+			if len(shared.Status) != workers {
+				t.Fatalf("expected %d patched status keys, got %v", workers, shared.Status)
+			}
+		})
+	}
+}
+
+func TestCopy(t *testing.T) {
+	stores := newTestStores(t)
+	src, dst := stores["bolt"], stores["sqlite"]
+
+	for _, name := range []string{"foo", "bar"} {
+		if err := src.Create(newTestConfig(name)); err != nil {
+			t.Fatalf("creating config: %v", err)
+		}
+	}
+
+	if err := src.InitializeComponent(ComponentConfigs); err != nil {
+		t.Fatalf("initializing component: %v", err)
+	}
+
+	if err := dst.Create(newTestConfig("foo")); err != nil {
+		t.Fatalf("creating config: %v", err)
+	}
+
+	// Bump the version of the existing config in the destination store so it
+	// differs from the version in the source store.
+	for range 2 {
+		existing := newTestConfig("foo")
+
+		if err := dst.Update(existing); err != nil {
+			t.Fatalf("updating config: %v", err)
+		}
+	}
+
+	count, err := Copy(src, dst, false)
+	if err != nil {
+		t.Fatalf("copying store: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected 1 config copied without overwrite, got %d", count)
+	}
+
+	if count, _ := Copy(src, dst, true); count != 2 {
+		t.Fatalf("expected 2 configs copied with overwrite, got %d", count)
+	}
+
+	if !dst.IsInitialized(ComponentConfigs) {
+		t.Fatal("expected configs component to be copied")
+	}
+}
@@ -39,6 +39,11 @@ type ConfigMetadata struct {
 	Created     string      `json:"created"               yaml:"created"`
 	Updated     string      `json:"updated"               yaml:"updated"`
 	Annotations Annotations `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+
+	// ResourceVersion is incremented by the store every time the config is
+	// written. When set on a config passed to Update or Patch, the write only
+	// succeeds if it matches the version currently in the store.
+	ResourceVersion int64 `json:"resourceVersion,omitempty" yaml:"resourceVersion,omitempty"`
 }
 
 // Performs case-insensitive lookup of a config kind.
@@ -0,0 +1,143 @@
+package store
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"phenix/util/plog"
+)
+
+type EventType string
+
+const (
+	EventCreate EventType = "create"
+	EventUpdate EventType = "update"
+	EventDelete EventType = "delete"
+)
+
+// Event describes a change made to a config in the store.
+type Event struct {
+	Type EventType `json:"type"`
+	Kind string    `json:"kind"`
+	Name string    `json:"name"`
+
+	// Config is the config as of the event, or nil for delete events. Stores
+	// backed by a change log use the latest version of the config at the time
+	// the event is delivered.
+	Config *Config `json:"config,omitempty"`
+}
+
+// FullName returns the full name (kind/name) of the config the event is for.
+func (e Event) FullName() string {
+	return e.Kind + "/" + e.Name
+}
+
+const (
+	// changeLogSize is the number of changes kept in the change log of stores
+	// that don't support native watches. Watchers that fall further behind than
+	// this will miss events.
+	changeLogSize = 1000
+
+	// watchInterval is how often watchers of stores that don't support native
+	// watches poll the change log for new changes.
+	watchInterval = 500 * time.Millisecond
+
+	watchBuffer = 256
+)
+
+// changeLogEntry is a single change recorded in the change log of stores that
+// don't support native watches. Only the config kind and name are recorded
+// to keep the change log small.
+type changeLogEntry struct {
+	Seq  uint64    `json:"seq"`
+	Type EventType `json:"type"`
+	Kind string    `json:"kind"`
+	Name string    `json:"name"`
+}
+
+// changeLog is implemented by stores that record changes in an internal change
+// log. The change log is shared by all processes using the store, so changes
+// made by the CLI or by user apps are seen by watchers in other processes.
+type changeLog interface {
+	Get(*Config) error
+
+	// latestChange returns the sequence number of the latest change.
+	latestChange() (uint64, error)
+
+	// changesSince returns all changes with a sequence number greater than the
+	// given one, ordered by sequence number.
+	changesSince(uint64) ([]changeLogEntry, error)
+}
+
+// watchChangeLog polls the given change log for changes to configs of the
+// given kinds (or all kinds if none are given) until done is closed.
+func watchChangeLog(log changeLog, done <-chan struct{}, kinds []string) <-chan Event {
+	events := make(chan Event, watchBuffer)
+
+	// Only changes made after the watch was started are reported.
+	last, err := log.latestChange()
+	if err != nil {
+		plog.Error(plog.TypeSystem, "getting latest change from store change log", "err", err)
+	}
+
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			changes, err := log.changesSince(last)
+			if err != nil {
+				plog.Error(plog.TypeSystem, "getting changes from store change log", "err", err)
+
+				continue
+			}
+
+			for _, change := range changes {
+				last = change.Seq
+
+				if !watchingKind(kinds, change.Kind) {
+					continue
+				}
+
+				event := Event{Type: change.Type, Kind: change.Kind, Name: change.Name} //nolint:exhaustruct // partial initialization
+
+				if change.Type != EventDelete {
+					c := &Config{Kind: change.Kind, Metadata: ConfigMetadata{Name: change.Name}} //nolint:exhaustruct // partial initialization
+
+					// The config may have been deleted since the change was made, in
+					// which case a delete event will follow.
+					if err := log.Get(c); err != nil {
+						continue
+					}
+
+					event.Config = c
+				}
+
+				select {
+				case events <- event:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	return events
+}
+
+func watchingKind(kinds []string, kind string) bool {
+	if len(kinds) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(kinds, func(k string) bool { return strings.EqualFold(k, kind) })
+}
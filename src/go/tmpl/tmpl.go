@@ -45,6 +45,14 @@ func GenerateFromTemplate(name string, data any, w io.Writer) error {
 
 			return fmt.Sprintf("%d.%d.%d.%d", mask[0], mask[1], mask[2], mask[3])
 		},
+		"isIPv6": func(a string) bool {
+			// supports both plain addresses and addresses in CIDR notation
+			ip, _, _ := strings.Cut(a, "/")
+
+			addr := net.ParseIP(ip)
+
+			return addr != nil && addr.To4() == nil
+		},
 		"toBool": func(val any) bool {
 			switch v := val.(type) {
 			case string:
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -16,6 +17,18 @@ import (
 	"phenix/util/mm"
 )
 
+// AnnotationOwner is the experiment annotation holding the name of the user
+// that created the experiment.
+const AnnotationOwner = "owner"
+
+// AnnotationTopologyParameters is the experiment annotation holding the JSON
+// encoded topology parameter values the experiment was created with.
+const AnnotationTopologyParameters = "topology-parameters"
+
+// maxWriteAttempts limits how many times a status-only write of an experiment
+// is retried when it conflicts with a concurrent write.
+const maxWriteAttempts = 5
+
 type Experiment struct {
 	Metadata store.ConfigMetadata    `json:"metadata" yaml:"metadata"` // experiment configuration metadata
 	Spec     ifaces.ExperimentSpec   `json:"spec"     yaml:"spec"`     // reference to latest versioned experiment spec
@@ -65,31 +78,50 @@ func (e *Experiment) Reload() error {
 	return nil
 }
 
-func (e Experiment) WriteToStore(statusOnly bool) error {
+// WriteToStore persists the experiment to the store. When writing the spec, the
+// write fails with a store.ConflictError if the experiment was modified in the
+// store since it was read. Status-only writes are always applied to the latest
+// version of the experiment in the store.
+func (e *Experiment) WriteToStore(statusOnly bool) error {
 	name := e.Metadata.Name
 
-	c, _ := store.NewConfig("experiment/" + name)
+	for attempt := 0; ; attempt++ {
+		c, _ := store.NewConfig("experiment/" + name)
 
-	err := store.Get(c)
-	if err != nil {
-		return fmt.Errorf("getting experiment %s from store: %w", name, err)
-	}
+		err := store.Get(c)
+		if err != nil {
+			return fmt.Errorf("getting experiment %s from store: %w", name, err)
+		}
 
-	// limit metadata updates to annotations so name doesn't accidentally get changed
-	c.Metadata.Annotations = e.Metadata.Annotations
+		// limit metadata updates to annotations so name doesn't accidentally get changed
+		c.Metadata.Annotations = e.Metadata.Annotations
 
-	if !statusOnly {
-		c.Spec = structs.MapDefaultCase(e.Spec, structs.CASESNAKE)
-	}
+		if !statusOnly {
+			c.Spec = structs.MapDefaultCase(e.Spec, structs.CASESNAKE)
 
-	c.Status = structs.MapDefaultCase(e.Status, structs.CASESNAKE)
+			// Don't overwrite changes made to the spec since it was read.
+			if e.Metadata.ResourceVersion != 0 {
+				c.Metadata.ResourceVersion = e.Metadata.ResourceVersion
+			}
+		}
+
+		c.Status = structs.MapDefaultCase(e.Status, structs.CASESNAKE)
+
+		err = store.Update(c)
+		if err == nil {
+			e.Metadata.ResourceVersion = c.Metadata.ResourceVersion
+
+			return nil
+		}
+
+		// A conflict on a status-only write just means the experiment was written
+		// to between getting and updating it, so try again.
+		if statusOnly && errors.Is(err, store.ErrConflict) && attempt < maxWriteAttempts {
+			continue
+		}
 
-	err = store.Update(c)
-	if err != nil {
 		return fmt.Errorf("saving experiment config: %w", err)
 	}
-
-	return nil
 }
 
 func (e *Experiment) SetSpec(spec ifaces.ExperimentSpec) {
@@ -142,6 +174,29 @@ func (e Experiment) DryRun() bool {
 	return strings.Contains(e.Status.StartTime(), "DRYRUN")
 }
 
+// Owner returns the name of the user that created the experiment, or an empty
+// string if it wasn't created by a user (e.g. via the command line).
+func (e Experiment) Owner() string {
+	return e.Metadata.Annotations[AnnotationOwner]
+}
+
+// TopologyParameters returns the topology parameter values the experiment was
+// created with, which are needed to render its topology again.
+func (e Experiment) TopologyParameters() (map[string]string, error) {
+	raw, ok := e.Metadata.Annotations[AnnotationTopologyParameters]
+	if !ok {
+		return nil, nil //nolint:nilnil // no parameters
+	}
+
+	var params map[string]string
+
+	if err := json.Unmarshal([]byte(raw), &params); err != nil {
+		return nil, fmt.Errorf("decoding topology parameters annotation: %w", err)
+	}
+
+	return params, nil
+}
+
 func (e Experiment) FilesDir() string {
 	return filepath.Join(common.PhenixBase, "images", e.Metadata.Name, "files")
 }
@@ -9,9 +9,51 @@ type VLANSpec interface {
 	Min() int
 	Max() int
 
+	// Subnets returns the IPAM subnet pool (in CIDR notation) for each VLAN
+	// alias.
+	Subnets() map[string]string
+
 	SetAliases(map[string]int)
 	SetMin(int)
 	SetMax(int)
+	SetSubnets(map[string]string)
+}
+
+// SchedulingSpec declares placement constraints for experiment VMs that are
+// honored by every scheduler.
+type SchedulingSpec interface {
+	// Affinity returns groups of VMs that must be placed on the same host.
+	Affinity() [][]string
+
+	// AntiAffinity returns groups of VMs that must each be placed on a
+	// different host.
+	AntiAffinity() [][]string
+
+	// HostSelectors returns the hosts each VM is allowed to be placed on.
+	HostSelectors() map[string][]string
+}
+
+// LeaseSpec limits how long an experiment can run before it's automatically
+// stopped.
+type LeaseSpec interface {
+	// MaxRuntime returns the max amount of time, as a duration string (e.g. 8h),
+	// the experiment can run for before it's stopped.
+	MaxRuntime() string
+
+	// IdleTimeout returns the amount of time, as a duration string (e.g. 2h),
+	// the experiment can run without any VNC or API activity before it's
+	// stopped.
+	IdleTimeout() string
+}
+
+// CronScheduleSpec declares cron-style windows an experiment should be
+// automatically started and stopped in.
+type CronScheduleSpec interface {
+	// Start returns the cron expression for when to start the experiment.
+	Start() string
+
+	// Stop returns the cron expression for when to stop the experiment.
+	Stop() string
 }
 
 type ExperimentSpec interface { //nolint:interfacebloat // legacy interface
@@ -24,6 +66,9 @@ type ExperimentSpec interface { //nolint:interfacebloat // legacy interface
 	Scenario() ScenarioSpec
 	VLANs() VLANSpec
 	Schedules() map[string]string
+	Scheduling() SchedulingSpec
+	Lease() LeaseSpec
+	CronSchedule() CronScheduleSpec
 	DeployMode() string
 	UseGREMesh() bool
 
@@ -42,6 +87,13 @@ type ExperimentSpec interface { //nolint:interfacebloat // legacy interface
 	ScheduleNode(string, string) error
 }
 
+// AppResult is the outcome of applying an app for a lifecycle stage.
+type AppResult interface {
+	Attempts() int
+	OnFailure() string
+	LastError() string
+}
+
 type ExperimentStatus interface { //nolint:interfacebloat // legacy interface
 	Init() error
 
@@ -52,12 +104,27 @@ type ExperimentStatus interface { //nolint:interfacebloat // legacy interface
 	VLANs() map[string]int
 	Schedules() map[string]string
 
+	// IPAM returns the interface addresses allocated by IPAM, keyed by
+	// `<hostname>/<interface>`, in CIDR notation.
+	IPAM() map[string]string
+
+	// LeaseExpires returns the time, in RFC3339 format, the running experiment's
+	// lease expires at.
+	LeaseExpires() string
+
+	// AppResults returns the outcome of the last time each app was applied for
+	// each lifecycle stage, keyed by `<app>/<stage>`.
+	AppResults() map[string]AppResult
+
 	SetStartTime(string)
 	SetAppStatus(string, any)
 	SetAppFrequency(string, string)
 	SetAppRunning(string, bool)
 	SetVLANs(map[string]int)
 	SetSchedule(map[string]string)
+	SetIPAM(map[string]string)
+	SetLeaseExpires(string)
+	SetAppResult(app, stage string, attempts int, onFailure, err string)
 
 	ParseAppStatus(string, any) error
 	ResetAppStatus()
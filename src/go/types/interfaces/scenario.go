@@ -3,8 +3,10 @@ package ifaces
 type ScenarioSpec interface {
 	Apps() []ScenarioApp
 	App(string) ScenarioApp
+	ParallelApps() bool
 
 	AddApp(string) ScenarioApp
+	SetParallelApps(bool)
 }
 
 type ScenarioApp interface { //nolint:interfacebloat // legacy interface
@@ -15,6 +17,18 @@ type ScenarioApp interface { //nolint:interfacebloat // legacy interface
 	Hosts() []ScenarioAppHost
 	RunPeriodically() string
 	Disabled() bool
+	DependsOn() []string
+
+	// Timeout, Retries and OnFailure return the execution policy of the app for
+	// the given lifecycle stage. Settings for the stage take precedence over the
+	// ones for the app as a whole.
+	Timeout(string) string
+	Retries(string) int
+	OnFailure(string) string
+
+	// RPC returns the transport (stdio or unix) used to talk JSON-RPC to a
+	// persistent user app, or an empty string if the app is run once per stage.
+	RPC() string
 
 	SetAssetDir(string)
 	SetMetadata(map[string]any)
@@ -22,6 +36,7 @@ type ScenarioApp interface { //nolint:interfacebloat // legacy interface
 	AddHost(string) ScenarioAppHost
 	SetRunPeriodically(string)
 	SetDisabled(bool)
+	SetDependsOn([]string)
 
 	ParseMetadata(any) error
 	ParseHostMetadata(string, any) error
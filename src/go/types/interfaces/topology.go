@@ -36,6 +36,8 @@ type NodeSpec interface { //nolint:interfacebloat // legacy interface
 	Overrides() map[string]string
 	Commands() []string
 	External() bool
+	Container() NodeContainer
+	IsContainer() bool
 
 	SetInjections([]NodeInjection)
 	SetDeletions([]NodeDeletion)
@@ -112,6 +114,15 @@ type NodeGeneral interface {
 	SetDoNotBoot(bool)
 }
 
+// NodeContainer holds the settings specific to nodes launched as minimega
+// containers (`vm_type: container`) instead of KVM VMs.
+type NodeContainer interface {
+	Filesystem() string
+	Init() string
+
+	SetFilesystem(string)
+}
+
 type NodeHardware interface {
 	CPU() string
 	VCPU() int
@@ -139,6 +150,7 @@ type NodeNetwork interface {
 	Interfaces() []NodeNetworkInterface
 	Routes() []NodeNetworkRoute
 	OSPF() NodeNetworkOSPF
+	BGP() NodeNetworkBGP
 	Rulesets() []NodeNetworkRuleset
 	NAT() []NodeNetworkNAT
 
@@ -171,6 +183,18 @@ type NodeNetworkInterface interface { //nolint:interfacebloat // legacy interfac
 	RulesetIn() string
 	RulesetOut() string
 
+	// Gateway6 returns the IPv6 default gateway for dual-stack interfaces.
+	Gateway6() string
+
+	// Addresses returns any addresses, in CIDR notation, configured on the
+	// interface in addition to its primary address. They can be either IPv4 or
+	// IPv6 addresses.
+	Addresses() []string
+
+	// CIDRs returns the primary address and all additional addresses of the
+	// interface in CIDR notation, primary address first.
+	CIDRs() []string
+
 	SetName(string)
 	SetType(string)
 	SetProto(string)
@@ -189,6 +213,8 @@ type NodeNetworkInterface interface { //nolint:interfacebloat // legacy interfac
 	SetQinQ(bool)
 	SetRulesetIn(string)
 	SetRulesetOut(string)
+	SetGateway6(string)
+	SetAddresses([]string)
 }
 
 type NodeNetworkRoute interface {
@@ -214,6 +240,43 @@ type NodeNetworkOSPFAreaNetwork interface {
 	Network() string
 }
 
+// NodeNetworkBGP is the BGP configuration of a router. Neighbors with the same
+// remote ASN as the router are iBGP peers, all others are eBGP peers.
+type NodeNetworkBGP interface {
+	ASN() int
+	RouterID() string
+	Neighbors() []NodeNetworkBGPNeighbor
+	Networks() []string
+	Redistribute() []NodeNetworkBGPRedistribute
+	RouteMaps() []NodeNetworkBGPRouteMap
+}
+
+type NodeNetworkBGPNeighbor interface {
+	Address() string
+	RemoteASN() int
+	Description() string
+	EBGPMultihop() int
+}
+
+// NodeNetworkBGPRedistribute redistributes routes from another protocol
+// (connected, static or ospf) into BGP, optionally filtered by a route map.
+type NodeNetworkBGPRedistribute interface {
+	Protocol() string
+	RouteMap() string
+}
+
+type NodeNetworkBGPRouteMap interface {
+	Name() string
+	Rules() []NodeNetworkBGPRouteMapRule
+}
+
+// NodeNetworkBGPRouteMapRule permits or denies routes matching any of its
+// prefixes, or all routes if it has no prefixes.
+type NodeNetworkBGPRouteMapRule interface {
+	Action() string
+	Prefixes() []string
+}
+
 type NodeNetworkRuleset interface {
 	Name() string
 	Description() string
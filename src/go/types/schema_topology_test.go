@@ -22,6 +22,17 @@ func validNode() map[string]any {
 	}
 }
 
+// containerNode returns the minimal container node the topology schema
+// accepts.
+func containerNode() map[string]any {
+	return map[string]any{
+		"type":      "VirtualMachine",
+		"general":   map[string]any{"hostname": "container-node", "vm_type": "container"},
+		"hardware":  map[string]any{"os_type": "linux"},
+		"container": map[string]any{"filesystem": "alpine-rootfs", "init": "/sbin/init"},
+	}
+}
+
 // staticInterface returns a minimal static interface the schema accepts.
 func staticInterface() map[string]any {
 	return map[string]any{
@@ -34,6 +45,25 @@ func staticInterface() map[string]any {
 	}
 }
 
+// validBGP returns a BGP block the schema accepts.
+func validBGP() map[string]any {
+	return map[string]any{
+		"asn":       65001,
+		"router_id": "0.0.0.1",
+		"neighbors": []any{map[string]any{"address": "10.0.0.1", "remote_asn": 65002}},
+		"networks":  []any{"10.1.0.0/16"},
+		"redistribute": []any{
+			map[string]any{"protocol": "connected", "route_map": "CONNECTED"},
+		},
+		"route_maps": []any{
+			map[string]any{
+				"name":  "CONNECTED",
+				"rules": []any{map[string]any{"action": "permit", "prefixes": []any{"10.0.0.0/8"}}},
+			},
+		},
+	}
+}
+
 func topologyConfig(node map[string]any) store.Config {
 	return store.Config{
 		Version: "phenix.sandia.gov/v1",
@@ -117,6 +147,55 @@ func TestTopologySchema(t *testing.T) {
 			}(),
 			wantErr: true,
 		},
+		{
+			name: "node with no drives key is rejected",
+			node: func() map[string]any {
+				n := validNode()
+				delete(n["hardware"].(map[string]any), "drives")
+				return n
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "container node with filesystem and no drives is valid",
+			node: containerNode(),
+		},
+		{
+			// Container nodes round-trip through the Go structs as drives:null.
+			name: "container node with null drives is valid",
+			node: func() map[string]any {
+				n := containerNode()
+				n["hardware"].(map[string]any)["drives"] = nil
+				return n
+			}(),
+		},
+		{
+			name: "container node without container section is rejected",
+			node: func() map[string]any {
+				n := containerNode()
+				delete(n, "container")
+				return n
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "container node without filesystem is rejected",
+			node: func() map[string]any {
+				n := containerNode()
+				n["container"] = map[string]any{"init": "/sbin/init"}
+				return n
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "kvm node with container section and no drives is rejected",
+			node: func() map[string]any {
+				n := containerNode()
+				n["general"].(map[string]any)["vm_type"] = "kvm"
+				return n
+			}(),
+			wantErr: true,
+		},
 		{
 			name: "static interface is valid",
 			node: func() map[string]any {
@@ -136,6 +215,40 @@ func TestTopologySchema(t *testing.T) {
 			}(),
 			wantErr: true,
 		},
+		{
+			name: "IPv6 address with mask above 32 is accepted",
+			node: func() map[string]any {
+				n := validNode()
+				iface := staticInterface()
+				iface["address"] = "2001:db8::2"
+				iface["mask"] = 64
+				n["network"] = map[string]any{"interfaces": []any{iface}}
+				return n
+			}(),
+		},
+		{
+			name: "mask above 128 is rejected",
+			node: func() map[string]any {
+				n := validNode()
+				iface := staticInterface()
+				iface["address"] = "2001:db8::2"
+				iface["mask"] = 129
+				n["network"] = map[string]any{"interfaces": []any{iface}}
+				return n
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "dual-stack addresses and IPv6 gateway are accepted",
+			node: func() map[string]any {
+				n := validNode()
+				iface := staticInterface()
+				iface["addresses"] = []any{"2001:db8::2/64"}
+				iface["gateway6"] = "2001:db8::1"
+				n["network"] = map[string]any{"interfaces": []any{iface}}
+				return n
+			}(),
+		},
 		{
 			name: "malformed MAC is rejected",
 			node: func() map[string]any {
@@ -201,6 +314,36 @@ func TestTopologySchema(t *testing.T) {
 				return n
 			}(),
 		},
+		{
+			name: "BGP block is accepted",
+			node: func() map[string]any {
+				n := validNode()
+				n["network"] = map[string]any{"interfaces": []any{staticInterface()}, "bgp": validBGP()}
+				return n
+			}(),
+		},
+		{
+			name: "BGP block without ASN is rejected",
+			node: func() map[string]any {
+				n := validNode()
+				bgp := validBGP()
+				delete(bgp, "asn")
+				n["network"] = map[string]any{"interfaces": []any{staticInterface()}, "bgp": bgp}
+				return n
+			}(),
+			wantErr: true,
+		},
+		{
+			name: "BGP redistribution of unknown protocol is rejected",
+			node: func() map[string]any {
+				n := validNode()
+				bgp := validBGP()
+				bgp["redistribute"] = []any{map[string]any{"protocol": "rip"}}
+				n["network"] = map[string]any{"interfaces": []any{staticInterface()}, "bgp": bgp}
+				return n
+			}(),
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -16,18 +16,106 @@ import (
 	v1 "phenix/types/version/v1"
 )
 
+// DecodeTopologyFromConfig decodes the topology in the given config as-is.
+// Topology templates aren't rendered, so the nodes produced by generators are
+// missing and templates in the topology's nodes are left in place. Use
+// DecodeTopologyFromConfigWithParameters to decode the concrete topology used
+// for an experiment.
 func DecodeTopologyFromConfig(c store.Config) (ifaces.TopologySpec, error) { //nolint:ireturn // interface
-	return decodeTopologyRecursive(c, map[string]bool{})
+	return decodeTopologyRecursive(c, map[string]bool{}, nil, false)
+}
+
+// DecodeTopologyFromConfigWithParameters decodes the topology in the given
+// config, rendering its parameters and generators using the given parameter
+// values (see RenderTopologyTemplate). Parameters are also passed to included
+// topologies that declare them, so each given parameter must be declared by the
+// topology or one of the topologies it includes.
+func DecodeTopologyFromConfigWithParameters( //nolint:ireturn // interface
+	c store.Config,
+	params map[string]string,
+) (ifaces.TopologySpec, error) {
+	if len(params) > 0 {
+		declared, err := declaredTopologyParameters(c, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(declared) == 0 {
+			return nil, fmt.Errorf("%w: topology does not declare any parameters", ErrInvalidTopologyParameter)
+		}
+
+		for name := range params {
+			if !declared[name] {
+				return nil, fmt.Errorf("%w: unknown parameter %s", ErrInvalidTopologyParameter, name)
+			}
+		}
+	}
+
+	return decodeTopologyRecursive(c, map[string]bool{}, params, true)
+}
+
+// declaredTopologyParameters returns the names of the parameters declared by
+// the topology in the given config and all the topologies it includes.
+func declaredTopologyParameters(c store.Config, visited map[string]bool) (map[string]bool, error) {
+	if visited[c.Metadata.Name] {
+		return nil, fmt.Errorf("cyclic import detected: %s", c.Metadata.Name)
+	}
+
+	visited[c.Metadata.Name] = true
+
+	var spec struct {
+		Parameters map[string]any `mapstructure:"parameters"`
+		Includes   []string       `mapstructure:"includeTopologies"`
+	}
+
+	if err := mapstructure.Decode(c.Spec, &spec); err != nil {
+		return nil, fmt.Errorf("decoding topology %s parameters: %w", c.Metadata.Name, err)
+	}
+
+	declared := make(map[string]bool)
+
+	for name := range spec.Parameters {
+		declared[name] = true
+	}
+
+	for _, include := range spec.Includes {
+		child, err := loadTopology(include)
+		if err != nil {
+			return nil, fmt.Errorf("loading included topology %s: %w", include, err)
+		}
+
+		names, err := declaredTopologyParameters(*child, maps.Clone(visited))
+		if err != nil {
+			return nil, err
+		}
+
+		maps.Copy(declared, names)
+	}
+
+	return declared, nil
 }
 
 func decodeTopologyRecursive( //nolint:ireturn // interface
 	c store.Config,
 	visited map[string]bool,
+	params map[string]string,
+	render bool,
 ) (ifaces.TopologySpec, error) {
 	if visited[c.Metadata.Name] {
 		return nil, fmt.Errorf("cyclic import detected: %s", c.Metadata.Name)
 	}
 
+	if render {
+		// The given parameters are checked against the whole include tree
+		// beforehand, so each topology ignores the ones it doesn't declare.
+		spec, err := renderTopologyTemplate(c.Spec, params, false)
+		if err != nil {
+			return nil, fmt.Errorf("rendering topology %s: %w", c.Metadata.Name, err)
+		}
+
+		c.Spec = spec
+	}
+
 	newVisited := make(map[string]bool)
 	maps.Copy(newVisited, visited)
 
@@ -36,6 +124,7 @@ func decodeTopologyRecursive( //nolint:ireturn // interface
 	var (
 		iface         any
 		latestVersion = version.StoredVersion[c.Kind]
+		err           error
 	)
 
 	if c.APIVersion() != latestVersion {
@@ -46,15 +135,11 @@ func decodeTopologyRecursive( //nolint:ireturn // interface
 			return nil, fmt.Errorf("no upgrader found for topology version %s", latestVersion)
 		}
 
-		var err error
-
 		iface, err = upgrader.Upgrade(c.APIVersion(), c.Spec, c.Metadata)
 		if err != nil {
 			return nil, fmt.Errorf("upgrading topology to %s: %w", latestVersion, err)
 		}
 	} else {
-		var err error
-
 		iface, err = version.GetVersionedSpecForKind(c.Kind, c.APIVersion())
 		if err != nil {
 			return nil, fmt.Errorf("getting versioned spec for config: %w", err)
@@ -65,19 +150,19 @@ func decodeTopologyRecursive( //nolint:ireturn // interface
 		}
 	}
 
-	spec, ok := iface.(ifaces.TopologySpec)
+	topo, ok := iface.(ifaces.TopologySpec)
 	if !ok {
 		return nil, errors.New("invalid spec in config")
 	}
 
-	if v1Spec, ok := spec.(*v1.TopologySpec); ok {
+	if v1Spec, ok := topo.(*v1.TopologySpec); ok {
 		for _, include := range v1Spec.IncludeTopologiesF {
 			childConfig, err := loadTopology(include)
 			if err != nil {
 				return nil, fmt.Errorf("loading included topology %s: %w", include, err)
 			}
 
-			childSpec, err := decodeTopologyRecursive(*childConfig, newVisited)
+			childSpec, err := decodeTopologyRecursive(*childConfig, newVisited, params, render)
 			if err != nil {
 				return nil, fmt.Errorf("decoding included topology %s: %w", include, err)
 			}
@@ -107,7 +192,7 @@ func decodeTopologyRecursive( //nolint:ireturn // interface
 		}
 	}
 
-	return spec, nil
+	return topo, nil
 }
 
 func loadTopology(source string) (*store.Config, error) {
@@ -0,0 +1,397 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// ErrInvalidTopologyParameter is returned when a parameter provided for a
+// topology template is unknown, missing, or of the wrong type.
+var ErrInvalidTopologyParameter = errors.New("invalid topology parameter")
+
+// ErrMissingTopologyParameter is returned when no value is provided for a
+// topology template parameter that doesn't have a default. It wraps
+// ErrInvalidTopologyParameter.
+var ErrMissingTopologyParameter = fmt.Errorf("%w: missing value", ErrInvalidTopologyParameter)
+
+// topologyParameter is a typed variable declared in the `parameters` block of
+// a topology.
+type topologyParameter struct {
+	Type        string `mapstructure:"type"`
+	Default     any    `mapstructure:"default"`
+	Description string `mapstructure:"description"`
+}
+
+// topologyGenerator generates nodes from templates in the `generators` block of
+// a topology, once for each instance in the generator's range.
+type topologyGenerator struct {
+	// Count is the number of instances to generate. It can be an integer or a
+	// template that renders to an integer (for example, `{{ .substations }}`).
+	Count any `mapstructure:"count"`
+
+	// Start is the index of the first instance. Defaults to 1.
+	Start *int `mapstructure:"start"`
+
+	// Vars are per-instance variables, rendered for each instance and made
+	// available to the generator's node templates.
+	Vars map[string]any `mapstructure:"vars"`
+
+	// Overrides replace per-instance variables for specific instances, keyed by
+	// instance index.
+	Overrides map[string]map[string]any `mapstructure:"overrides"`
+
+	Nodes []any `mapstructure:"nodes"`
+}
+
+// IsTopologyTemplate returns true if the given topology spec declares any
+// parameters or generators.
+func IsTopologyTemplate(spec map[string]any) bool {
+	_, hasParams := spec["parameters"]
+	_, hasGenerators := spec["generators"]
+
+	return hasParams || hasGenerators
+}
+
+// RenderTopologyTemplate renders the `parameters` and `generators` blocks of
+// the given topology spec into a concrete topology spec. Templates in string
+// values of the topology's nodes are rendered using the parameter values, and
+// nodes produced by each generator are appended to the topology's nodes. The
+// given parameter values override the parameters' defaults. The given spec is
+// not modified. Specs without parameters or generators are returned as-is.
+func RenderTopologyTemplate(spec map[string]any, params map[string]string) (map[string]any, error) {
+	return renderTopologyTemplate(spec, params, true)
+}
+
+// renderTopologyTemplate renders the given topology spec. If strict is false,
+// provided parameters the topology doesn't declare are ignored, which is used
+// when decoding topologies since the parameters are checked against every
+// topology in the include tree instead.
+//
+//nolint:cyclop,funlen // complex logic
+func renderTopologyTemplate(spec map[string]any, params map[string]string, strict bool) (map[string]any, error) {
+	if !IsTopologyTemplate(spec) {
+		if strict && len(params) > 0 {
+			return nil, fmt.Errorf("%w: topology does not declare any parameters", ErrInvalidTopologyParameter)
+		}
+
+		return spec, nil
+	}
+
+	var declared map[string]topologyParameter
+
+	if err := mapstructure.Decode(spec["parameters"], &declared); err != nil {
+		return nil, fmt.Errorf("decoding topology parameters: %w", err)
+	}
+
+	if strict {
+		for name := range params {
+			if _, ok := declared[name]; !ok {
+				return nil, fmt.Errorf("%w: unknown parameter %s", ErrInvalidTopologyParameter, name)
+			}
+		}
+	}
+
+	values := make(map[string]any)
+
+	for name, param := range declared {
+		raw, ok := params[name]
+		if !ok {
+			if param.Default == nil {
+				return nil, fmt.Errorf("%w: parameter %s is required", ErrMissingTopologyParameter, name)
+			}
+
+			raw = fmt.Sprint(param.Default)
+		}
+
+		value, err := parseTopologyParameter(param.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: parameter %s: %w", ErrInvalidTopologyParameter, name, err)
+		}
+
+		values[name] = value
+	}
+
+	rendered := make(map[string]any)
+
+	for k, v := range spec {
+		if k == "parameters" || k == "generators" {
+			continue
+		}
+
+		rendered[k] = v
+	}
+
+	var nodes []any
+
+	if spec["nodes"] != nil {
+		v, err := renderTopologyValue(spec["nodes"], values)
+		if err != nil {
+			return nil, fmt.Errorf("rendering topology nodes: %w", err)
+		}
+
+		var ok bool
+
+		if nodes, ok = v.([]any); !ok {
+			return nil, errors.New("topology nodes must be a list")
+		}
+	}
+
+	var generators []topologyGenerator
+
+	if err := mapstructure.Decode(spec["generators"], &generators); err != nil {
+		return nil, fmt.Errorf("decoding topology generators: %w", err)
+	}
+
+	for i, gen := range generators {
+		generated, err := gen.generate(values)
+		if err != nil {
+			return nil, fmt.Errorf("rendering topology generator %d: %w", i, err)
+		}
+
+		nodes = append(nodes, generated...)
+	}
+
+	rendered["nodes"] = nodes
+
+	return rendered, nil
+}
+
+func (g topologyGenerator) generate(params map[string]any) ([]any, error) {
+	count, err := g.count(params)
+	if err != nil {
+		return nil, err
+	}
+
+	start := 1
+
+	if g.Start != nil {
+		start = *g.Start
+	}
+
+	var nodes []any
+
+	for idx := start; idx < start+count; idx++ {
+		data := maps.Clone(params)
+		data["index"] = idx
+
+		vars := maps.Clone(g.Vars)
+
+		if vars == nil {
+			vars = make(map[string]any)
+		}
+
+		maps.Copy(vars, g.Overrides[strconv.Itoa(idx)])
+
+		// Variables are rendered in name order, so they can only reference
+		// variables with names that sort before them.
+		for _, name := range slices.Sorted(maps.Keys(vars)) {
+			value, err := renderTopologyValue(vars[name], data)
+			if err != nil {
+				return nil, fmt.Errorf("rendering variable %s for instance %d: %w", name, idx, err)
+			}
+
+			data[name] = value
+		}
+
+		for _, node := range g.Nodes {
+			rendered, err := renderTopologyValue(node, data)
+			if err != nil {
+				return nil, fmt.Errorf("rendering node for instance %d: %w", idx, err)
+			}
+
+			nodes = append(nodes, rendered)
+		}
+	}
+
+	return nodes, nil
+}
+
+func (g topologyGenerator) count(params map[string]any) (int, error) {
+	switch count := g.Count.(type) {
+	case int:
+		return count, nil
+	case float64:
+		return int(count), nil
+	case string:
+		rendered, err := renderTopologyString(count, params)
+		if err != nil {
+			return 0, fmt.Errorf("rendering count: %w", err)
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(rendered))
+		if err != nil {
+			return 0, fmt.Errorf("count %q is not an integer", rendered)
+		}
+
+		return n, nil
+	default:
+		return 0, fmt.Errorf("invalid count %v", g.Count)
+	}
+}
+
+func parseTopologyParameter(typ, raw string) (any, error) {
+	switch strings.ToLower(typ) {
+	case "", "string":
+		return raw, nil
+	case "int", "integer":
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not an integer", raw)
+		}
+
+		return v, nil
+	case "bool", "boolean":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("value %q is not a boolean", raw)
+		}
+
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unknown type %s", typ)
+	}
+}
+
+// renderTopologyValue recursively renders templates in all the string values
+// of the given value.
+func renderTopologyValue(value any, data map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return renderTopologyString(v, data)
+	case map[string]any:
+		rendered := make(map[string]any, len(v))
+
+		for key, val := range v {
+			r, err := renderTopologyValue(val, data)
+			if err != nil {
+				return nil, err
+			}
+
+			rendered[key] = r
+		}
+
+		return rendered, nil
+	case []any:
+		rendered := make([]any, len(v))
+
+		for i, val := range v {
+			r, err := renderTopologyValue(val, data)
+			if err != nil {
+				return nil, err
+			}
+
+			rendered[i] = r
+		}
+
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
+
+func renderTopologyString(s string, data map[string]any) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	tmpl, err := template.New("topology").Funcs(topologyTemplateFuncs()).Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("parsing template %q: %w", s, err)
+	}
+
+	var sb strings.Builder
+
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("executing template %q: %w", s, err)
+	}
+
+	return sb.String(), nil
+}
+
+func topologyTemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"add":        func(a, b int) int { return a + b },
+		"sub":        func(a, b int) int { return a - b },
+		"mul":        func(a, b int) int { return a * b },
+		"cidrHost":   cidrHost,
+		"cidrSubnet": cidrSubnet,
+	}
+}
+
+// cidrHost returns the address of the given host number within the given
+// prefix, for example `cidrHost "10.1.0.0/24" 5` returns 10.1.0.5.
+func cidrHost(prefix string, host int) (string, error) {
+	p, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return "", fmt.Errorf("parsing prefix %s: %w", prefix, err)
+	}
+
+	p = p.Masked()
+
+	n := addrToInt(p.Addr())
+	n.Add(n, big.NewInt(int64(host)))
+
+	addr, ok := intToAddr(n, p.Addr().Is4())
+	if !ok || !p.Contains(addr) {
+		return "", fmt.Errorf("host number %d is outside of prefix %s", host, p)
+	}
+
+	return addr.String(), nil
+}
+
+// cidrSubnet returns the given subnet number of the given prefix extended by
+// the given number of bits, for example `cidrSubnet "10.0.0.0/16" 8 5` returns
+// 10.0.5.0/24.
+func cidrSubnet(prefix string, bits, subnet int) (string, error) {
+	p, err := netip.ParsePrefix(prefix)
+	if err != nil {
+		return "", fmt.Errorf("parsing prefix %s: %w", prefix, err)
+	}
+
+	p = p.Masked()
+
+	length := p.Bits() + bits
+	if bits < 0 || length > p.Addr().BitLen() {
+		return "", fmt.Errorf("cannot extend prefix %s by %d bits", p, bits)
+	}
+
+	if subnet < 0 || big.NewInt(int64(subnet)).BitLen() > bits {
+		return "", fmt.Errorf("subnet number %d does not fit in %d bits", subnet, bits)
+	}
+
+	offset := new(big.Int).Lsh(big.NewInt(int64(subnet)), uint(p.Addr().BitLen()-length)) //nolint:gosec // length checked above
+
+	n := addrToInt(p.Addr())
+	n.Add(n, offset)
+
+	addr, _ := intToAddr(n, p.Addr().Is4())
+
+	return netip.PrefixFrom(addr, length).String(), nil
+}
+
+func addrToInt(addr netip.Addr) *big.Int {
+	return new(big.Int).SetBytes(addr.AsSlice())
+}
+
+func intToAddr(n *big.Int, is4 bool) (netip.Addr, bool) {
+	size := 16
+
+	if is4 {
+		size = 4
+	}
+
+	if n.Sign() < 0 || len(n.Bytes()) > size {
+		return netip.Addr{}, false
+	}
+
+	return netip.AddrFromSlice(n.FillBytes(make([]byte, size)))
+}
@@ -0,0 +1,275 @@
+package types_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"phenix/store"
+	"phenix/types"
+)
+
+func gridTopology() map[string]any {
+	return map[string]any{
+		"parameters": map[string]any{
+			"substations": map[string]any{"type": "int", "default": 3},
+			"prefix":      map[string]any{"type": "string", "default": "sub"},
+		},
+		"nodes": []any{
+			map[string]any{
+				"type":    "Router",
+				"general": map[string]any{"hostname": "core"},
+				"hardware": map[string]any{
+					"os_type": "linux",
+					"drives":  []any{map[string]any{"image": "vyos.qc2"}},
+				},
+				"network": map[string]any{
+					"interfaces": []any{
+						map[string]any{
+							"name":    "eth0",
+							"vlan":    "{{ .prefix }}-uplink",
+							"type":    "ethernet",
+							"proto":   "static",
+							"address": "10.0.0.1",
+							"mask":    24,
+						},
+					},
+				},
+			},
+		},
+		"generators": []any{
+			map[string]any{
+				"count": "{{ .substations }}",
+				"vars": map[string]any{
+					"hostname": "{{ .prefix }}-{{ .index }}",
+					"subnet":   `{{ cidrSubnet "10.10.0.0/16" 8 .index }}`,
+				},
+				"overrides": map[string]any{
+					"2": map[string]any{"hostname": "control-center"},
+				},
+				"nodes": []any{
+					map[string]any{
+						"type":    "VirtualMachine",
+						"general": map[string]any{"hostname": "{{ .hostname }}"},
+						"network": map[string]any{
+							"interfaces": []any{
+								map[string]any{
+									"name":    "eth0",
+									"address": "{{ cidrHost .subnet 10 }}",
+									"mask":    24,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func hostnames(t *testing.T, spec map[string]any) []string {
+	t.Helper()
+
+	var names []string
+
+	for _, node := range spec["nodes"].([]any) {
+		general := node.(map[string]any)["general"].(map[string]any)
+		names = append(names, general["hostname"].(string))
+	}
+
+	return names
+}
+
+func TestRenderTopologyTemplate(t *testing.T) {
+	spec, err := types.RenderTopologyTemplate(gridTopology(), map[string]string{"substations": "4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := spec["parameters"]; ok {
+		t.Error("expected parameters to be removed from rendered spec")
+	}
+
+	if _, ok := spec["generators"]; ok {
+		t.Error("expected generators to be removed from rendered spec")
+	}
+
+	expected := []string{"core", "sub-1", "control-center", "sub-3", "sub-4"}
+	names := hostnames(t, spec)
+
+	if len(names) != len(expected) {
+		t.Fatalf("expected hostnames %v, got %v", expected, names)
+	}
+
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("expected hostnames %v, got %v", expected, names)
+		}
+	}
+
+	nodes := spec["nodes"].([]any)
+
+	uplink := nodes[0].(map[string]any)["network"].(map[string]any)["interfaces"].([]any)[0].(map[string]any)
+	if uplink["vlan"] != "sub-uplink" {
+		t.Errorf("expected VLAN sub-uplink, got %v", uplink["vlan"])
+	}
+
+	iface := nodes[3].(map[string]any)["network"].(map[string]any)["interfaces"].([]any)[0].(map[string]any)
+	if iface["address"] != "10.10.3.10" {
+		t.Errorf("expected address 10.10.3.10, got %v", iface["address"])
+	}
+
+	if iface["mask"] != 24 {
+		t.Errorf("expected non-string values to be left as-is, got mask %v", iface["mask"])
+	}
+}
+
+func TestRenderTopologyTemplateParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		spec   map[string]any
+		params map[string]string
+	}{
+		{
+			name:   "unknown parameter",
+			spec:   gridTopology(),
+			params: map[string]string{"bogus": "1"},
+		},
+		{
+			name:   "wrong parameter type",
+			spec:   gridTopology(),
+			params: map[string]string{"substations": "many"},
+		},
+		{
+			name: "missing required parameter",
+			spec: func() map[string]any {
+				spec := gridTopology()
+				delete(spec["parameters"].(map[string]any)["prefix"].(map[string]any), "default")
+
+				return spec
+			}(),
+		},
+		{
+			name:   "parameters for topology without parameters",
+			spec:   map[string]any{"nodes": []any{}},
+			params: map[string]string{"substations": "1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := types.RenderTopologyTemplate(tt.spec, tt.params)
+			if !errors.Is(err, types.ErrInvalidTopologyParameter) {
+				t.Fatalf("expected ErrInvalidTopologyParameter, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeTopologyWithParameters(t *testing.T) {
+	c := store.Config{
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Topology",
+		Metadata: store.ConfigMetadata{Name: "grid"},
+		Spec:     gridTopology(),
+	}
+
+	if err := types.ValidateConfigSpec(c); err != nil {
+		t.Fatalf("expected topology template to be valid, got %v", err)
+	}
+
+	topo, err := types.DecodeTopologyFromConfigWithParameters(c, map[string]string{"substations": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(topo.Nodes()); n != 3 {
+		t.Fatalf("expected 3 nodes, got %d", n)
+	}
+
+	if _, ok := c.Spec["generators"]; !ok {
+		t.Error("expected original config spec to be left unmodified")
+	}
+}
+
+func TestDecodeTopologyWithoutRendering(t *testing.T) {
+	spec := gridTopology()
+	spec["parameters"].(map[string]any)["prefix"] = map[string]any{"type": "string"} //nolint:forcetypeassert // test data
+
+	c := store.Config{
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Topology",
+		Metadata: store.ConfigMetadata{Name: "grid"},
+		Spec:     spec,
+	}
+
+	// Decoding as-is doesn't need values for required parameters.
+	topo, err := types.DecodeTopologyFromConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(topo.Nodes()); n != 1 {
+		t.Fatalf("expected only the static node, got %d nodes", n)
+	}
+
+	if vlan := topo.Nodes()[0].Network().Interfaces()[0].VLAN(); vlan != "{{ .prefix }}-uplink" {
+		t.Fatalf("expected node template to be left in place, got %s", vlan)
+	}
+
+	if _, err := types.DecodeTopologyFromConfigWithParameters(c, nil); !errors.Is(err, types.ErrMissingTopologyParameter) {
+		t.Fatalf("expected missing topology parameter error, got %v", err)
+	}
+}
+
+func TestDecodeTopologyWithIncludedParameters(t *testing.T) {
+	child := `apiVersion: phenix.sandia.gov/v1
+kind: Topology
+metadata:
+  name: site
+spec:
+  parameters:
+    hosts:
+      type: int
+  generators:
+  - count: '{{ .hosts }}'
+    nodes:
+    - type: VirtualMachine
+      general:
+        hostname: 'site-{{ .index }}'
+`
+
+	path := filepath.Join(t.TempDir(), "site.yml")
+
+	if err := os.WriteFile(path, []byte(child), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := store.Config{
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Topology",
+		Metadata: store.ConfigMetadata{Name: "root"},
+		Spec: map[string]any{
+			"includeTopologies": []any{path},
+			"nodes": []any{
+				map[string]any{"type": "VirtualMachine", "general": map[string]any{"hostname": "hq"}},
+			},
+		},
+	}
+
+	// Only the included topology declares the parameter.
+	topo, err := types.DecodeTopologyFromConfigWithParameters(c, map[string]string{"hosts": "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := len(topo.Nodes()); n != 3 {
+		t.Fatalf("expected 3 nodes, got %d", n)
+	}
+
+	_, err = types.DecodeTopologyFromConfigWithParameters(c, map[string]string{"hosts": "2", "bogus": "1"})
+	if !errors.Is(err, types.ErrInvalidTopologyParameter) {
+		t.Fatalf("expected error for parameter not declared anywhere in the include tree, got %v", err)
+	}
+}
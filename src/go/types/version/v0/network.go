@@ -41,6 +41,11 @@ func (n Network) OSPF() ifaces.NodeNetworkOSPF { //nolint:ireturn // interface
 	return n.OSPFF
 }
 
+// BGP isn't supported in v0 topologies.
+func (n Network) BGP() ifaces.NodeNetworkBGP { //nolint:ireturn // interface
+	return nil
+}
+
 func (n Network) Rulesets() []ifaces.NodeNetworkRuleset {
 	sets := make([]ifaces.NodeNetworkRuleset, len(n.RulesetsF))
 
@@ -117,6 +122,8 @@ type Interface struct {
 	AddressF    string   `json:"address"     mapstructure:"address"     structs:"address"     yaml:"address"`
 	MaskF       int      `json:"mask"        mapstructure:"mask"        structs:"mask"        yaml:"mask"`
 	GatewayF    string   `json:"gateway"     mapstructure:"gateway"     structs:"gateway"     yaml:"gateway"`
+	Gateway6F   string   `json:"gateway6"    mapstructure:"gateway6"    structs:"gateway6"    yaml:"gateway6"`
+	AddressesF  []string `json:"addresses"   mapstructure:"addresses"   structs:"addresses"   yaml:"addresses"`
 	DNSF        []string `json:"dns"         mapstructure:"dns"         structs:"dns"         yaml:"dns"`
 	QinQF       bool     `json:"qinq"        mapstructure:"qinq"        structs:"qinq"        yaml:"qinq"`
 	RulesetInF  string   `json:"ruleset_in"  mapstructure:"ruleset_in"  structs:"ruleset_in"  yaml:"ruleset_in"`
@@ -183,6 +190,26 @@ func (i Interface) Gateway() string {
 	return i.GatewayF
 }
 
+func (i Interface) Gateway6() string {
+	return i.Gateway6F
+}
+
+func (i Interface) Addresses() []string {
+	return i.AddressesF
+}
+
+// CIDRs returns the primary address and any additional addresses configured
+// for the interface, in CIDR notation. The primary address is always first.
+func (i Interface) CIDRs() []string {
+	var cidrs []string
+
+	if i.AddressF != "" {
+		cidrs = append(cidrs, fmt.Sprintf("%s/%d", i.AddressF, i.MaskF))
+	}
+
+	return append(cidrs, i.AddressesF...)
+}
+
 func (i Interface) DNS() []string {
 	return i.DNSF
 }
@@ -255,6 +282,14 @@ func (i *Interface) SetGateway(gw string) {
 	i.GatewayF = gw
 }
 
+func (i *Interface) SetGateway6(gw string) {
+	i.Gateway6F = gw
+}
+
+func (i *Interface) SetAddresses(addrs []string) {
+	i.AddressesF = addrs
+}
+
 func (i *Interface) SetDNS(dns []string) {
 	i.DNSF = dns
 }
@@ -85,6 +85,16 @@ func (n Node) External() bool {
 	return false
 }
 
+// Container always returns nil since v0 topologies do not support container
+// nodes.
+func (Node) Container() ifaces.NodeContainer { //nolint:ireturn // interface
+	return nil
+}
+
+func (Node) IsContainer() bool {
+	return false
+}
+
 func (n *Node) SetInjections(injections []ifaces.NodeInjection) {
 	injects := make([]*Injection, len(injections))
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"path/filepath"
 	"reflect"
+	"strings"
 
 	"github.com/activeshadow/structs"
 	"github.com/mitchellh/mapstructure"
@@ -17,9 +18,10 @@ import (
 )
 
 type VLANSpec struct {
-	AliasesF map[string]int `json:"aliases" mapstructure:"aliases" structs:"aliases" yaml:"aliases"`
-	MinF     int            `json:"min"     mapstructure:"min"     structs:"min"     yaml:"min"`
-	MaxF     int            `json:"max"     mapstructure:"max"     structs:"max"     yaml:"max"`
+	AliasesF map[string]int    `json:"aliases"           mapstructure:"aliases" structs:"aliases"           yaml:"aliases"`
+	MinF     int               `json:"min"               mapstructure:"min"     structs:"min"               yaml:"min"`
+	MaxF     int               `json:"max"               mapstructure:"max"     structs:"max"               yaml:"max"`
+	SubnetsF map[string]string `json:"subnets,omitempty" mapstructure:"subnets" structs:"subnets,omitempty" yaml:"subnets,omitempty"`
 }
 
 func (v *VLANSpec) Init() error {
@@ -46,6 +48,14 @@ func (v VLANSpec) Max() int {
 	return v.MaxF
 }
 
+func (v VLANSpec) Subnets() map[string]string {
+	if v.SubnetsF == nil {
+		return make(map[string]string)
+	}
+
+	return v.SubnetsF
+}
+
 func (v *VLANSpec) SetAliases(a map[string]int) {
 	v.AliasesF = a
 }
@@ -58,6 +68,10 @@ func (v *VLANSpec) SetMax(m int) {
 	v.MaxF = m
 }
 
+func (v *VLANSpec) SetSubnets(s map[string]string) {
+	v.SubnetsF = s
+}
+
 func (v VLANSpec) Validate() error {
 	for k, val := range v.AliasesF {
 		if v.MinF != 0 && val < v.MinF {
@@ -83,15 +97,18 @@ func (v VLANSpec) Validate() error {
 }
 
 type ExperimentSpec struct {
-	ExperimentNameF string            `json:"experimentName,omitempty" mapstructure:"experimentName" structs:"experimentName" yaml:"experimentName,omitempty"`
-	BaseDirF        string            `json:"baseDir"                  mapstructure:"baseDir"        structs:"baseDir"        yaml:"baseDir"`
-	DefaultBridgeF  string            `json:"defaultBridge"            mapstructure:"defaultBridge"  structs:"defaultBridge"  yaml:"defaultBridge"`
-	TopologyF       *TopologySpec     `json:"topology"                 mapstructure:"topology"       structs:"topology"       yaml:"topology"`
-	ScenarioF       *v2.ScenarioSpec  `json:"scenario"                 mapstructure:"scenario"       structs:"scenario"       yaml:"scenario"`
-	VLANsF          *VLANSpec         `json:"vlans"                    mapstructure:"vlans"          structs:"vlans"          yaml:"vlans"`
-	SchedulesF      map[string]string `json:"schedules"                mapstructure:"schedules"      structs:"schedules"      yaml:"schedules"`
-	DeployModeF     string            `json:"deployMode"               mapstructure:"deployMode"     structs:"deployMode"     yaml:"deployMode"`
-	UseGREMeshF     bool              `json:"useGREMesh"               mapstructure:"useGREMesh"     structs:"useGREMesh"     yaml:"useGREMesh"`
+	ExperimentNameF string            `json:"experimentName,omitempty" mapstructure:"experimentName" structs:"experimentName"       yaml:"experimentName,omitempty"`
+	BaseDirF        string            `json:"baseDir"                  mapstructure:"baseDir"        structs:"baseDir"              yaml:"baseDir"`
+	DefaultBridgeF  string            `json:"defaultBridge"            mapstructure:"defaultBridge"  structs:"defaultBridge"        yaml:"defaultBridge"`
+	TopologyF       *TopologySpec     `json:"topology"                 mapstructure:"topology"       structs:"topology"             yaml:"topology"`
+	ScenarioF       *v2.ScenarioSpec  `json:"scenario"                 mapstructure:"scenario"       structs:"scenario"             yaml:"scenario"`
+	VLANsF          *VLANSpec         `json:"vlans"                    mapstructure:"vlans"          structs:"vlans"                yaml:"vlans"`
+	SchedulesF      map[string]string `json:"schedules"                mapstructure:"schedules"      structs:"schedules"            yaml:"schedules"`
+	SchedulingF     *Scheduling       `json:"scheduling,omitempty"     mapstructure:"scheduling"     structs:"scheduling,omitempty" yaml:"scheduling,omitempty"`
+	LeaseF          *Lease            `json:"lease,omitempty"          mapstructure:"lease"          structs:"lease,omitempty"      yaml:"lease,omitempty"`
+	CronScheduleF   *CronSchedule     `json:"schedule,omitempty"       mapstructure:"schedule"       structs:"schedule,omitempty"   yaml:"schedule,omitempty"`
+	DeployModeF     string            `json:"deployMode"               mapstructure:"deployMode"     structs:"deployMode"           yaml:"deployMode"`
+	UseGREMeshF     bool              `json:"useGREMesh"               mapstructure:"useGREMesh"     structs:"useGREMesh"           yaml:"useGREMesh"`
 }
 
 func (e *ExperimentSpec) Init() error {
@@ -188,6 +205,30 @@ func (e ExperimentSpec) Schedules() map[string]string {
 	return e.SchedulesF
 }
 
+func (e ExperimentSpec) Scheduling() ifaces.SchedulingSpec { //nolint:ireturn // interface
+	if e.SchedulingF == nil {
+		return new(Scheduling)
+	}
+
+	return e.SchedulingF
+}
+
+func (e ExperimentSpec) Lease() ifaces.LeaseSpec { //nolint:ireturn // interface
+	if e.LeaseF == nil {
+		return new(Lease)
+	}
+
+	return e.LeaseF
+}
+
+func (e ExperimentSpec) CronSchedule() ifaces.CronScheduleSpec { //nolint:ireturn // interface
+	if e.CronScheduleF == nil {
+		return new(CronSchedule)
+	}
+
+	return e.CronScheduleF
+}
+
 func (e ExperimentSpec) DeployMode() string {
 	return e.DeployModeF
 }
@@ -333,11 +374,66 @@ func (e ExperimentSpec) SnapshotName(node string) string {
 	return fmt.Sprintf("%s_%s_%s_snapshot", mm.Headnode(), e.ExperimentNameF, node)
 }
 
+type Scheduling struct {
+	AffinityF      [][]string          `json:"affinity,omitempty"      mapstructure:"affinity"      structs:"affinity,omitempty"      yaml:"affinity,omitempty"`
+	AntiAffinityF  [][]string          `json:"antiAffinity,omitempty"  mapstructure:"antiAffinity"  structs:"antiAffinity,omitempty"  yaml:"antiAffinity,omitempty"`
+	HostSelectorsF map[string][]string `json:"hostSelectors,omitempty" mapstructure:"hostSelectors" structs:"hostSelectors,omitempty" yaml:"hostSelectors,omitempty"`
+}
+
+func (s Scheduling) Affinity() [][]string {
+	return s.AffinityF
+}
+
+func (s Scheduling) AntiAffinity() [][]string {
+	return s.AntiAffinityF
+}
+
+func (s Scheduling) HostSelectors() map[string][]string {
+	if s.HostSelectorsF == nil {
+		return make(map[string][]string)
+	}
+
+	return s.HostSelectorsF
+}
+
+type Lease struct {
+	MaxRuntimeF  string `json:"maxRuntime,omitempty"  mapstructure:"maxRuntime"  structs:"maxRuntime,omitempty"  yaml:"maxRuntime,omitempty"`
+	IdleTimeoutF string `json:"idleTimeout,omitempty" mapstructure:"idleTimeout" structs:"idleTimeout,omitempty" yaml:"idleTimeout,omitempty"`
+}
+
+func (l Lease) MaxRuntime() string {
+	return l.MaxRuntimeF
+}
+
+func (l Lease) IdleTimeout() string {
+	return l.IdleTimeoutF
+}
+
+type CronSchedule struct {
+	StartF string `json:"start,omitempty" mapstructure:"start" structs:"start,omitempty" yaml:"start,omitempty"`
+	StopF  string `json:"stop,omitempty"  mapstructure:"stop"  structs:"stop,omitempty"  yaml:"stop,omitempty"`
+}
+
+func (c CronSchedule) Start() string {
+	return c.StartF
+}
+
+func (c CronSchedule) Stop() string {
+	return c.StopF
+}
+
 type ExperimentStatus struct {
-	StartTimeF string            `json:"startTime" mapstructure:"startTime" structs:"startTime" yaml:"startTime"`
-	SchedulesF map[string]string `json:"schedules" mapstructure:"schedules" structs:"schedules" yaml:"schedules"`
-	AppsF      map[string]any    `json:"apps"      mapstructure:"apps"      structs:"apps"      yaml:"apps"`
-	VLANsF     map[string]int    `json:"vlans"     mapstructure:"vlans"     structs:"vlans"     yaml:"vlans"`
+	StartTimeF string            `json:"startTime"      mapstructure:"startTime" structs:"startTime"      yaml:"startTime"`
+	SchedulesF map[string]string `json:"schedules"      mapstructure:"schedules" structs:"schedules"      yaml:"schedules"`
+	AppsF      map[string]any    `json:"apps"           mapstructure:"apps"      structs:"apps"           yaml:"apps"`
+	VLANsF     map[string]int    `json:"vlans"          mapstructure:"vlans"     structs:"vlans"          yaml:"vlans"`
+	IPAMF      map[string]string `json:"ipam,omitempty" mapstructure:"ipam"      structs:"ipam,omitempty" yaml:"ipam,omitempty"`
+
+	LeaseExpiresF string `json:"leaseExpires,omitempty" mapstructure:"leaseExpires" structs:"leaseExpires,omitempty" yaml:"leaseExpires,omitempty"`
+
+	// Outcome of the last time each app was applied for each lifecycle stage,
+	// keyed by `<app>/<stage>`.
+	AppResultsF map[string]*AppResult `json:"appResults,omitempty" mapstructure:"appResults" structs:"appResults,omitempty" yaml:"appResults,omitempty"`
 
 	// Used to track details of an app's running stage. Requires special attention
 	// since it can be run periodically in the background and/or triggered
@@ -406,6 +502,14 @@ func (s ExperimentStatus) Schedules() map[string]string {
 	return s.SchedulesF
 }
 
+func (s ExperimentStatus) IPAM() map[string]string {
+	if s.IPAMF == nil {
+		return make(map[string]string)
+	}
+
+	return s.IPAMF
+}
+
 func (s *ExperimentStatus) SetStartTime(t string) {
 	s.StartTimeF = t
 }
@@ -467,6 +571,18 @@ func (s *ExperimentStatus) SetSchedule(sched map[string]string) {
 	s.SchedulesF = sched
 }
 
+func (s *ExperimentStatus) SetIPAM(ipam map[string]string) {
+	s.IPAMF = ipam
+}
+
+func (s ExperimentStatus) LeaseExpires() string {
+	return s.LeaseExpiresF
+}
+
+func (s *ExperimentStatus) SetLeaseExpires(t string) {
+	s.LeaseExpiresF = t
+}
+
 func (s ExperimentStatus) ParseAppStatus(name string, status any) error {
 	if s.AppsF == nil {
 		return fmt.Errorf("missing status for app %s", name)
@@ -485,9 +601,57 @@ func (s ExperimentStatus) ParseAppStatus(name string, status any) error {
 	return nil
 }
 
+func (s ExperimentStatus) AppResults() map[string]ifaces.AppResult {
+	results := make(map[string]ifaces.AppResult, len(s.AppResultsF))
+
+	for k, v := range s.AppResultsF {
+		results[k] = v
+	}
+
+	return results
+}
+
+func (s *ExperimentStatus) SetAppResult(app, stage string, attempts int, onFailure, err string) {
+	if s.AppResultsF == nil {
+		s.AppResultsF = make(map[string]*AppResult)
+	}
+
+	s.AppResultsF[app+"/"+stage] = &AppResult{
+		AttemptsF:  attempts,
+		OnFailureF: onFailure,
+		ErrorF:     err,
+	}
+}
+
 func (s *ExperimentStatus) ResetAppStatus() {
 	s.AppsF = make(map[string]any)
 
 	s.FrequencyF = nil
 	s.RunningF = nil
+
+	// Results from the configure stage are kept since it isn't run again when
+	// the experiment is redeployed.
+	for k := range s.AppResultsF {
+		if !strings.HasSuffix(k, "/configure") {
+			delete(s.AppResultsF, k)
+		}
+	}
+}
+
+type AppResult struct {
+	AttemptsF  int    `json:"attempts"            mapstructure:"attempts"  structs:"attempts"  yaml:"attempts"`
+	OnFailureF string `json:"onFailure,omitempty" mapstructure:"onFailure" structs:"onFailure" yaml:"onFailure,omitempty"`
+	ErrorF     string `json:"error,omitempty"     mapstructure:"error"     structs:"error"     yaml:"error,omitempty"`
+}
+
+func (r AppResult) Attempts() int {
+	return r.AttemptsF
+}
+
+func (r AppResult) OnFailure() string {
+	return r.OnFailureF
+}
+
+func (r AppResult) LastError() string {
+	return r.ErrorF
 }
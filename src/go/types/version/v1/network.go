@@ -14,6 +14,7 @@ type Network struct {
 	InterfacesF []*Interface `json:"interfaces" mapstructure:"interfaces" structs:"interfaces" yaml:"interfaces"`
 	RoutesF     []Route      `json:"routes"     mapstructure:"routes"     structs:"routes"     yaml:"routes"`
 	OSPFF       *OSPF        `json:"ospf"       mapstructure:"ospf"       structs:"ospf"       yaml:"ospf"`
+	BGPF        *BGP         `json:"bgp"        mapstructure:"bgp"        structs:"bgp"        yaml:"bgp"`
 	RulesetsF   []*Ruleset   `json:"rulesets"   mapstructure:"rulesets"   structs:"rulesets"   yaml:"rulesets"`
 	NATF        []NAT        `json:"nat"        mapstructure:"nat"        structs:"nat"        yaml:"nat"`
 }
@@ -60,6 +61,14 @@ func (n *Network) OSPF() ifaces.NodeNetworkOSPF { //nolint:ireturn // interface
 	return n.OSPFF
 }
 
+func (n *Network) BGP() ifaces.NodeNetworkBGP { //nolint:ireturn // interface
+	if n == nil || n.BGPF == nil {
+		return nil
+	}
+
+	return n.BGPF
+}
+
 func (n *Network) Rulesets() []ifaces.NodeNetworkRuleset {
 	if n == nil {
 		return nil
@@ -150,6 +159,8 @@ type Interface struct {
 	AddressF    string   `json:"address"     mapstructure:"address"     structs:"address"     yaml:"address"`
 	MaskF       int      `json:"mask"        mapstructure:"mask"        structs:"mask"        yaml:"mask"`
 	GatewayF    string   `json:"gateway"     mapstructure:"gateway"     structs:"gateway"     yaml:"gateway"`
+	Gateway6F   string   `json:"gateway6"    mapstructure:"gateway6"    structs:"gateway6"    yaml:"gateway6"`
+	AddressesF  []string `json:"addresses"   mapstructure:"addresses"   structs:"addresses"   yaml:"addresses"`
 	DNSF        []string `json:"dns"         mapstructure:"dns"         structs:"dns"         yaml:"dns"`
 	QinQF       bool     `json:"qinq"        mapstructure:"qinq"        structs:"qinq"        yaml:"qinq"`
 	RulesetInF  string   `json:"ruleset_in"  mapstructure:"ruleset_in"  structs:"ruleset_in"  yaml:"ruleset_in"`
@@ -218,6 +229,26 @@ func (i Interface) Gateway() string {
 	return i.GatewayF
 }
 
+func (i Interface) Gateway6() string {
+	return i.Gateway6F
+}
+
+func (i Interface) Addresses() []string {
+	return i.AddressesF
+}
+
+// CIDRs returns the primary address and any additional addresses configured
+// for the interface, in CIDR notation. The primary address is always first.
+func (i Interface) CIDRs() []string {
+	var cidrs []string
+
+	if i.AddressF != "" {
+		cidrs = append(cidrs, fmt.Sprintf("%s/%d", i.AddressF, i.MaskF))
+	}
+
+	return append(cidrs, i.AddressesF...)
+}
+
 func (i Interface) DNS() []string {
 	return i.DNSF
 }
@@ -290,6 +321,14 @@ func (i *Interface) SetGateway(gw string) {
 	i.GatewayF = gw
 }
 
+func (i *Interface) SetGateway6(gw string) {
+	i.Gateway6F = gw
+}
+
+func (i *Interface) SetAddresses(addrs []string) {
+	i.AddressesF = addrs
+}
+
 func (i *Interface) SetDNS(dns []string) {
 	i.DNSF = dns
 }
@@ -385,6 +424,125 @@ func (a AreaNetwork) Network() string {
 	return a.NetworkF
 }
 
+type BGP struct {
+	ASNF          int               `json:"asn"          mapstructure:"asn"          structs:"asn"          yaml:"asn"`
+	RouterIDF     string            `json:"router_id"    mapstructure:"router_id"    structs:"router_id"    yaml:"router_id"`
+	NeighborsF    []BGPNeighbor     `json:"neighbors"    mapstructure:"neighbors"    structs:"neighbors"    yaml:"neighbors"`
+	NetworksF     []string          `json:"networks"     mapstructure:"networks"     structs:"networks"     yaml:"networks"`
+	RedistributeF []BGPRedistribute `json:"redistribute" mapstructure:"redistribute" structs:"redistribute" yaml:"redistribute"`
+	RouteMapsF    []BGPRouteMap     `json:"route_maps"   mapstructure:"route_maps"   structs:"route_maps"   yaml:"route_maps"`
+}
+
+func (b BGP) ASN() int {
+	return b.ASNF
+}
+
+func (b BGP) RouterID() string {
+	return b.RouterIDF
+}
+
+func (b BGP) Neighbors() []ifaces.NodeNetworkBGPNeighbor {
+	neighbors := make([]ifaces.NodeNetworkBGPNeighbor, len(b.NeighborsF))
+
+	for i, n := range b.NeighborsF {
+		neighbors[i] = n
+	}
+
+	return neighbors
+}
+
+func (b BGP) Networks() []string {
+	return b.NetworksF
+}
+
+func (b BGP) Redistribute() []ifaces.NodeNetworkBGPRedistribute {
+	redist := make([]ifaces.NodeNetworkBGPRedistribute, len(b.RedistributeF))
+
+	for i, r := range b.RedistributeF {
+		redist[i] = r
+	}
+
+	return redist
+}
+
+func (b BGP) RouteMaps() []ifaces.NodeNetworkBGPRouteMap {
+	maps := make([]ifaces.NodeNetworkBGPRouteMap, len(b.RouteMapsF))
+
+	for i, m := range b.RouteMapsF {
+		maps[i] = m
+	}
+
+	return maps
+}
+
+type BGPNeighbor struct {
+	AddressF      string `json:"address"       mapstructure:"address"       structs:"address"       yaml:"address"`
+	RemoteASNF    int    `json:"remote_asn"    mapstructure:"remote_asn"    structs:"remote_asn"    yaml:"remote_asn"`
+	DescriptionF  string `json:"description"   mapstructure:"description"   structs:"description"   yaml:"description"`
+	EBGPMultihopF int    `json:"ebgp_multihop" mapstructure:"ebgp_multihop" structs:"ebgp_multihop" yaml:"ebgp_multihop"`
+}
+
+func (n BGPNeighbor) Address() string {
+	return n.AddressF
+}
+
+func (n BGPNeighbor) RemoteASN() int {
+	return n.RemoteASNF
+}
+
+func (n BGPNeighbor) Description() string {
+	return n.DescriptionF
+}
+
+func (n BGPNeighbor) EBGPMultihop() int {
+	return n.EBGPMultihopF
+}
+
+type BGPRedistribute struct {
+	ProtocolF string `json:"protocol"  mapstructure:"protocol"  structs:"protocol"  yaml:"protocol"`
+	RouteMapF string `json:"route_map" mapstructure:"route_map" structs:"route_map" yaml:"route_map"`
+}
+
+func (r BGPRedistribute) Protocol() string {
+	return r.ProtocolF
+}
+
+func (r BGPRedistribute) RouteMap() string {
+	return r.RouteMapF
+}
+
+type BGPRouteMap struct {
+	NameF  string            `json:"name"  mapstructure:"name"  structs:"name"  yaml:"name"`
+	RulesF []BGPRouteMapRule `json:"rules" mapstructure:"rules" structs:"rules" yaml:"rules"`
+}
+
+func (m BGPRouteMap) Name() string {
+	return m.NameF
+}
+
+func (m BGPRouteMap) Rules() []ifaces.NodeNetworkBGPRouteMapRule {
+	rules := make([]ifaces.NodeNetworkBGPRouteMapRule, len(m.RulesF))
+
+	for i, r := range m.RulesF {
+		rules[i] = r
+	}
+
+	return rules
+}
+
+type BGPRouteMapRule struct {
+	ActionF   string   `json:"action"   mapstructure:"action"   structs:"action"   yaml:"action"`
+	PrefixesF []string `json:"prefixes" mapstructure:"prefixes" structs:"prefixes" yaml:"prefixes"`
+}
+
+func (r BGPRouteMapRule) Action() string {
+	return r.ActionF
+}
+
+func (r BGPRouteMapRule) Prefixes() []string {
+	return r.PrefixesF
+}
+
 type Ruleset struct {
 	NameF        string  `json:"name"        mapstructure:"name"        structs:"name"        yaml:"name"`
 	DescriptionF string  `json:"description" mapstructure:"description" structs:"description" yaml:"description"`
@@ -26,6 +26,7 @@ type Node struct {
 	DelayF       *Delay            `json:"delay"       mapstructure:"delay"       structs:"delay"       yaml:"delay"`
 	CommandsF    []string          `json:"commands"    mapstructure:"commands"    structs:"commands"    yaml:"commands"`
 	ExternalF    *bool             `json:"external"    mapstructure:"external"    structs:"external"    yaml:"external"`
+	ContainerF   *Container        `json:"container"   mapstructure:"container"   structs:"container"   yaml:"container"`
 }
 
 func (n Node) Annotations() map[string]any {
@@ -104,6 +105,20 @@ func (n Node) External() bool {
 	return n.ExternalF != nil
 }
 
+func (n Node) Container() ifaces.NodeContainer { //nolint:ireturn // interface
+	if n.ContainerF == nil {
+		return nil
+	}
+
+	return n.ContainerF
+}
+
+// IsContainer returns true if the node is launched as a minimega container
+// instead of a KVM VM.
+func (n Node) IsContainer() bool {
+	return strings.EqualFold(n.General().VMType(), "container")
+}
+
 func (n *Node) SetInjections(injections []ifaces.NodeInjection) {
 	injects := make([]*Injection, len(injections))
 
@@ -431,6 +446,31 @@ func (g *General) SetDoNotBoot(b bool) {
 	g.DoNotBootF = &b
 }
 
+type Container struct {
+	FilesystemF string `json:"filesystem" mapstructure:"filesystem" structs:"filesystem" yaml:"filesystem"`
+	InitF       string `json:"init"       mapstructure:"init"       structs:"init"       yaml:"init"`
+}
+
+func (c *Container) Filesystem() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.FilesystemF
+}
+
+func (c *Container) Init() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.InitF
+}
+
+func (c *Container) SetFilesystem(fs string) {
+	c.FilesystemF = fs
+}
+
 type Hardware struct {
 	CPUF    string   `json:"cpu"     mapstructure:"cpu"     structs:"cpu"     yaml:"cpu"`
 	VCPUF   int      `json:"vcpus"   mapstructure:"vcpus"   structs:"vcpus"   yaml:"vcpus"`
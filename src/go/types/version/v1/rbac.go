@@ -1,8 +1,9 @@
 package v1
 
 type RoleSpec struct {
-	Name     string        `json:"roleName" mapstructure:"roleName" structs:"roleName" yaml:"roleNname"`
-	Policies []*PolicySpec `json:"policies" mapstructure:"policies" structs:"policies" yaml:"policies"`
+	Name     string        `json:"roleName"        mapstructure:"roleName" structs:"roleName" yaml:"roleNname"`
+	Policies []*PolicySpec `json:"policies"        mapstructure:"policies" structs:"policies" yaml:"policies"`
+	Quota    *QuotaSpec    `json:"quota,omitempty" mapstructure:"quota"    structs:"quota"    yaml:"quota,omitempty"`
 }
 
 type PolicySpec struct {
@@ -10,3 +11,13 @@ type PolicySpec struct {
 	ResourceNames []string `json:"resourceNames" mapstructure:"resourceNames" structs:"resourceNames" yaml:"resourceNames"`
 	Verbs         []string `json:"verbs"         mapstructure:"verbs"         structs:"verbs"         yaml:"verbs"`
 }
+
+// QuotaSpec limits the resources a user can consume. A zero value for any of
+// the limits means the resource is unlimited. Memory is in MB.
+type QuotaSpec struct {
+	RunningExperiments int   `json:"runningExperiments,omitempty" mapstructure:"runningExperiments" structs:"runningExperiments" yaml:"runningExperiments,omitempty"`
+	VCPUs              int   `json:"vcpus,omitempty"              mapstructure:"vcpus"              structs:"vcpus"              yaml:"vcpus,omitempty"`
+	Memory             int   `json:"memory,omitempty"             mapstructure:"memory"             structs:"memory"             yaml:"memory,omitempty"`
+	VLANs              int   `json:"vlans,omitempty"              mapstructure:"vlans"              structs:"vlans"              yaml:"vlans,omitempty"`
+	DiskBytes          int64 `json:"diskBytes,omitempty"          mapstructure:"diskBytes"          structs:"diskBytes"          yaml:"diskBytes,omitempty"`
+}
@@ -10,8 +10,8 @@ import (
 )
 
 type TopologySpec struct {
-	IncludeTopologiesF []string `json:"includeTopologies" mapstructure:"includeTopologies" structs:"includeTopologies" yaml:"includeTopologies"`
-	NodesF             []*Node  `json:"nodes"             mapstructure:"nodes"             structs:"nodes"             yaml:"nodes"`
+	IncludeTopologiesF []string `json:"includeTopologies,omitempty" mapstructure:"includeTopologies" structs:"includeTopologies,omitempty" yaml:"includeTopologies,omitempty"`
+	NodesF             []*Node  `json:"nodes"                       mapstructure:"nodes"             structs:"nodes"                       yaml:"nodes"`
 }
 
 func (t *TopologySpec) IncludedTopologies() []string {
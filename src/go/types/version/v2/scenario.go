@@ -9,7 +9,8 @@ import (
 )
 
 type ScenarioSpec struct {
-	AppsF []*ScenarioApp `json:"apps" mapstructure:"apps" structs:"apps" yaml:"apps"`
+	AppsF         []*ScenarioApp `json:"apps"                   mapstructure:"apps"         structs:"apps"         yaml:"apps"`
+	ParallelAppsF bool           `json:"parallelApps,omitempty" mapstructure:"parallelApps" structs:"parallelApps" yaml:"parallelApps,omitempty"`
 }
 
 func (ss *ScenarioSpec) Apps() []ifaces.ScenarioApp {
@@ -40,6 +41,18 @@ func (ss *ScenarioSpec) App(name string) ifaces.ScenarioApp { //nolint:ireturn /
 	return nil
 }
 
+func (ss *ScenarioSpec) ParallelApps() bool {
+	if ss == nil {
+		return false
+	}
+
+	return ss.ParallelAppsF
+}
+
+func (ss *ScenarioSpec) SetParallelApps(p bool) {
+	ss.ParallelAppsF = p
+}
+
 func (ss *ScenarioSpec) AddApp(name string) ifaces.ScenarioApp { //nolint:ireturn // returns interface
 	a := &ScenarioApp{ //nolint:exhaustruct // partial initialization
 		NameF: name,
@@ -51,13 +64,27 @@ func (ss *ScenarioSpec) AddApp(name string) ifaces.ScenarioApp { //nolint:iretur
 }
 
 type ScenarioApp struct {
-	NameF            string             `json:"name"                      mapstructure:"name"            structs:"name"            yaml:"name"`
-	FromScenarioF    string             `json:"fromScenario,omitempty"    mapstructure:"fromScenario"    structs:"fromScenario"    yaml:"fromScenario,omitempty"`
-	AssetDirF        string             `json:"assetDir,omitempty"        mapstructure:"assetDir"        structs:"assetDir"        yaml:"assetDir,omitempty"`
-	MetadataF        map[string]any     `json:"metadata,omitempty"        mapstructure:"metadata"        structs:"metadata"        yaml:"metadata,omitempty"`
-	HostsF           []*ScenarioAppHost `json:"hosts,omitempty"           mapstructure:"hosts"           structs:"hosts"           yaml:"hosts,omitempty"`
-	RunPeriodicallyF string             `json:"runPeriodically,omitempty" mapstructure:"runPeriodically" structs:"runPeriodically" yaml:"runPeriodically,omitempty"`
-	DisabledF        bool               `json:"disabled,omitempty"        mapstructure:"disabled"        structs:"disabled"        yaml:"disabled,omitempty"`
+	NameF            string                        `json:"name"                      mapstructure:"name"            structs:"name"            yaml:"name"`
+	FromScenarioF    string                        `json:"fromScenario,omitempty"    mapstructure:"fromScenario"    structs:"fromScenario"    yaml:"fromScenario,omitempty"`
+	AssetDirF        string                        `json:"assetDir,omitempty"        mapstructure:"assetDir"        structs:"assetDir"        yaml:"assetDir,omitempty"`
+	MetadataF        map[string]any                `json:"metadata,omitempty"        mapstructure:"metadata"        structs:"metadata"        yaml:"metadata,omitempty"`
+	HostsF           []*ScenarioAppHost            `json:"hosts,omitempty"           mapstructure:"hosts"           structs:"hosts"           yaml:"hosts,omitempty"`
+	RunPeriodicallyF string                        `json:"runPeriodically,omitempty" mapstructure:"runPeriodically" structs:"runPeriodically" yaml:"runPeriodically,omitempty"`
+	DisabledF        bool                          `json:"disabled,omitempty"        mapstructure:"disabled"        structs:"disabled"        yaml:"disabled,omitempty"`
+	DependsOnF       []string                      `json:"dependsOn,omitempty"       mapstructure:"dependsOn"       structs:"dependsOn"       yaml:"dependsOn,omitempty"`
+	TimeoutF         string                        `json:"timeout,omitempty"         mapstructure:"timeout"         structs:"timeout"         yaml:"timeout,omitempty"`
+	RetriesF         int                           `json:"retries,omitempty"         mapstructure:"retries"         structs:"retries"         yaml:"retries,omitempty"`
+	OnFailureF       string                        `json:"onFailure,omitempty"       mapstructure:"onFailure"       structs:"onFailure"       yaml:"onFailure,omitempty"`
+	RPCF             string                        `json:"rpc,omitempty"             mapstructure:"rpc"             structs:"rpc"             yaml:"rpc,omitempty"`
+	StagesF          map[string]*ScenarioAppPolicy `json:"stages,omitempty"          mapstructure:"stages"          structs:"stages"          yaml:"stages,omitempty"`
+}
+
+// ScenarioAppPolicy holds the execution policy settings of an app for a single
+// lifecycle stage.
+type ScenarioAppPolicy struct {
+	TimeoutF   string `json:"timeout,omitempty"   mapstructure:"timeout"   structs:"timeout"   yaml:"timeout,omitempty"`
+	RetriesF   int    `json:"retries,omitempty"   mapstructure:"retries"   structs:"retries"   yaml:"retries,omitempty"`
+	OnFailureF string `json:"onFailure,omitempty" mapstructure:"onFailure" structs:"onFailure" yaml:"onFailure,omitempty"`
 }
 
 func (sa ScenarioApp) Name() string {
@@ -94,6 +121,38 @@ func (sa ScenarioApp) Disabled() bool {
 	return sa.DisabledF
 }
 
+func (sa ScenarioApp) DependsOn() []string {
+	return sa.DependsOnF
+}
+
+func (sa ScenarioApp) Timeout(stage string) string {
+	if p, ok := sa.StagesF[stage]; ok && p != nil && p.TimeoutF != "" {
+		return p.TimeoutF
+	}
+
+	return sa.TimeoutF
+}
+
+func (sa ScenarioApp) Retries(stage string) int {
+	if p, ok := sa.StagesF[stage]; ok && p != nil && p.RetriesF != 0 {
+		return p.RetriesF
+	}
+
+	return sa.RetriesF
+}
+
+func (sa ScenarioApp) OnFailure(stage string) string {
+	if p, ok := sa.StagesF[stage]; ok && p != nil && p.OnFailureF != "" {
+		return p.OnFailureF
+	}
+
+	return sa.OnFailureF
+}
+
+func (sa ScenarioApp) RPC() string {
+	return sa.RPCF
+}
+
 func (sa *ScenarioApp) SetAssetDir(dir string) {
 	sa.AssetDirF = dir
 }
@@ -131,6 +190,10 @@ func (sa *ScenarioApp) SetDisabled(d bool) {
 	sa.DisabledF = d
 }
 
+func (sa *ScenarioApp) SetDependsOn(deps []string) {
+	sa.DependsOnF = deps
+}
+
 func (sa ScenarioApp) ParseMetadata(md any) error {
 	if sa.MetadataF == nil {
 		return fmt.Errorf("missing metadata for app %s", sa.NameF)
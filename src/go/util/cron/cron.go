@@ -0,0 +1,237 @@
+// Package cron contains a parser for standard five field cron expressions.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	numFields = 5
+
+	// How far ahead Next looks for a matching time before giving up, which only
+	// happens for expressions that can never match (e.g. `0 0 31 2 *`).
+	searchLimitYears = 5
+)
+
+//nolint:gochecknoglobals // predefined schedules
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow []bool
+
+	// Per cron convention, if both the day of month and day of week fields are
+	// restricted (not `*`), a day matches if either of them matches.
+	domStar, dowStar bool
+}
+
+type bounds struct {
+	name     string
+	min, max int
+}
+
+// Parse parses a cron expression with five space separated fields (minute,
+// hour, day of month, month and day of week). Each field can be `*`, a value,
+// a range (`1-5`) or a comma separated list of them, optionally with a step
+// (`*/15`, `0-30/10`). Day of week 0 and 7 are both Sunday. The predefined
+// schedules `@yearly`, `@monthly`, `@weekly`, `@daily` and `@hourly` are also
+// supported.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+
+	if d, ok := descriptors[expr]; ok {
+		expr = d
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != numFields {
+		return nil, fmt.Errorf("cron expression %q must have %d fields", expr, numFields)
+	}
+
+	var (
+		s   Schedule
+		err error
+	)
+
+	if s.minute, err = parseField(fields[0], bounds{"minute", 0, 59}); err != nil { //nolint:mnd // cron bounds
+		return nil, err
+	}
+
+	if s.hour, err = parseField(fields[1], bounds{"hour", 0, 23}); err != nil { //nolint:mnd // cron bounds
+		return nil, err
+	}
+
+	if s.dom, err = parseField(fields[2], bounds{"day of month", 1, 31}); err != nil { //nolint:mnd // cron bounds
+		return nil, err
+	}
+
+	if s.month, err = parseField(fields[3], bounds{"month", 1, 12}); err != nil { //nolint:mnd // cron bounds
+		return nil, err
+	}
+
+	if s.dow, err = parseField(fields[4], bounds{"day of week", 0, 7}); err != nil { //nolint:mnd // cron bounds
+		return nil, err
+	}
+
+	// Sunday can be either 0 or 7.
+	if s.dow[7] {
+		s.dow[0] = true
+	}
+
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+
+	return &s, nil
+}
+
+// Next returns the first time after the given time, truncated to the minute,
+// that matches the schedule. A zero time is returned if the schedule never
+// matches.
+func (s *Schedule) Next(after time.Time) time.Time {
+	var (
+		t     = after.Truncate(time.Minute).Add(time.Minute)
+		limit = t.AddDate(searchLimitYears, 0, 0)
+		loc   = t.Location()
+	)
+
+	for t.Before(limit) {
+		if !s.month[t.Month()] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.hour[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+// Matches returns true if the given time, truncated to the minute, matches the
+// schedule.
+func (s *Schedule) Matches(t time.Time) bool {
+	return s.month[t.Month()] && s.matchesDay(t) && s.hour[t.Hour()] && s.minute[t.Minute()]
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	var (
+		dom = s.dom[t.Day()]
+		dow = s.dow[t.Weekday()]
+	)
+
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+func parseField(field string, b bounds) ([]bool, error) {
+	values := make([]bool, b.max+1)
+
+	for _, part := range strings.Split(field, ",") {
+		if err := parsePart(part, b, values); err != nil {
+			return nil, fmt.Errorf("parsing %s field %q: %w", b.name, field, err)
+		}
+	}
+
+	return values, nil
+}
+
+func parsePart(part string, b bounds, values []bool) error {
+	var (
+		rng, stepStr, hasStep = strings.Cut(part, "/")
+		start, end            = b.min, b.max
+		step                  = 1
+	)
+
+	if hasStep {
+		var err error
+
+		if step, err = strconv.Atoi(stepStr); err != nil || step < 1 {
+			return fmt.Errorf("invalid step %q", stepStr)
+		}
+	}
+
+	switch {
+	case rng == "*":
+	case strings.Contains(rng, "-"):
+		lo, hi, _ := strings.Cut(rng, "-")
+
+		var err error
+
+		if start, err = parseValue(lo, b); err != nil {
+			return err
+		}
+
+		if end, err = parseValue(hi, b); err != nil {
+			return err
+		}
+
+		if start > end {
+			return fmt.Errorf("invalid range %q", rng)
+		}
+	default:
+		var err error
+
+		if start, err = parseValue(rng, b); err != nil {
+			return err
+		}
+
+		// A single value with a step (e.g. 5/15) runs from the value to the max.
+		if hasStep {
+			end = b.max
+		} else {
+			end = start
+		}
+	}
+
+	for i := start; i <= end; i += step {
+		values[i] = true
+	}
+
+	return nil
+}
+
+func parseValue(value string, b bounds) (int, error) {
+	if value == "" {
+		return 0, errors.New("missing value")
+	}
+
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", value)
+	}
+
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, b.min, b.max)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,50 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"phenix/util/cron"
+)
+
+func TestNext(t *testing.T) {
+	// Friday, March 15, 2024
+	from := time.Date(2024, time.March, 15, 17, 42, 30, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, time.March, 15, 17, 43, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, time.March, 15, 17, 45, 0, 0, time.UTC)},
+		{"0 18 * * 1-5", time.Date(2024, time.March, 15, 18, 0, 0, 0, time.UTC)},
+		{"0 8 * * 1-5", time.Date(2024, time.March, 18, 8, 0, 0, 0, time.UTC)},
+		{"30 9 1 * *", time.Date(2024, time.April, 1, 9, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1 * 1", time.Date(2024, time.March, 18, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := cron.Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("expected next time %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "5-1 * * * *", "*/0 * * * *", "a * * * *"} {
+		if _, err := cron.Parse(expr); err == nil {
+			t.Errorf("expected error parsing %q", expr)
+		}
+	}
+}
@@ -81,7 +81,7 @@ func (MMClusterFiles) GetExperimentFiles(exp, filter string) (Files, error) {
 	for _, command := range commands {
 		cmd.Command = command
 
-		for _, row := range mmcli.RunTabular(cmd) {
+		for _, row := range mm.RunTabular(cmd) {
 			name := filepath.Base(row["name"])
 			file := File{Name: name, Path: strings.TrimPrefix(row["name"], root)} //nolint:exhaustruct // partial initialization
 
@@ -223,7 +223,7 @@ func (MMClusterFiles) CopyFile(path, dest string, status CopyStatus) error {
 		cmd.Command = fmt.Sprintf(`mesh send %s file get %s`, dest, path)
 	}
 
-	err := mmcli.ErrorResponse(mmcli.Run(cmd))
+	err := mmcli.ErrorResponse(mm.Run(cmd))
 	if err != nil {
 		return fmt.Errorf("copying file to destination: %w", err)
 	}
@@ -237,7 +237,7 @@ func (MMClusterFiles) CopyFile(path, dest string, status CopyStatus) error {
 	for {
 		var found bool
 
-		for _, row := range mmcli.RunTabular(cmd) {
+		for _, row := range mm.RunTabular(cmd) {
 			if row["filename"] == path {
 				comp := strings.Split(row["completed"], "/")
 
@@ -268,7 +268,7 @@ func (MMClusterFiles) SyncFile(path string, status CopyStatus) error {
 	cmd := mmcli.NewCommand()
 	cmd.Command = "mesh send all file get " + path
 
-	err := mmcli.ErrorResponse(mmcli.Run(cmd))
+	err := mmcli.ErrorResponse(mm.Run(cmd))
 	if err != nil {
 		return fmt.Errorf("syncing file to cluster nodes: %w", err)
 	}
@@ -292,7 +292,7 @@ func (MMClusterFiles) DeleteFile(path string) error {
 	for _, command := range commands {
 		cmd.Command = fmt.Sprintf("%s %s", command, path)
 
-		err := mmcli.ErrorResponse(mmcli.Run(cmd))
+		err := mmcli.ErrorResponse(mm.Run(cmd))
 		if err != nil {
 			return fmt.Errorf("deleting file from cluster nodes: %w", err)
 		}
@@ -0,0 +1,362 @@
+// Package jsonrpc implements a bidirectional JSON-RPC 2.0 connection over a
+// stream (e.g. a process's stdin/stdout or a unix socket). Messages are JSON
+// objects separated by newlines. Either side can send requests and
+// notifications, and a request can be canceled by sending a `$/cancelRequest`
+// notification with the ID of the request.
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// CancelMethod is the method of the notification sent when a pending request
+// is canceled. Its params are an object with the `id` of the request.
+const CancelMethod = "$/cancelRequest"
+
+// Standard JSON-RPC 2.0 error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeCanceled       = -32800
+)
+
+// ErrClosed is returned when calling a method on a closed connection.
+var ErrClosed = errors.New("connection closed")
+
+// Error is a JSON-RPC error object. It's returned by Conn.Call when the peer
+// responds with an error, and handlers can return it to control the error code
+// sent to the peer.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("jsonrpc error %d: %s", e.Code, e.Message)
+}
+
+// Message is a JSON-RPC request, notification or response. Notifications have
+// no ID, and responses have no method.
+type Message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Handler handles requests and notifications sent by the peer. The result is
+// ignored for notifications. The context is canceled if the peer cancels the
+// request or the connection is closed.
+type Handler func(ctx context.Context, method string, params json.RawMessage) (any, error)
+
+// Conn is a JSON-RPC connection.
+type Conn struct {
+	rwc     io.ReadWriteCloser
+	handler Handler
+
+	wmu sync.Mutex // guards writes to enc
+	enc *json.Encoder
+
+	mu       sync.Mutex
+	nextID   int64
+	pending  map[int64]chan *Message
+	inflight map[string]context.CancelFunc
+	err      error
+
+	ctx    context.Context //nolint:containedctx // canceled when connection closes
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewConn returns a new connection using the given stream and starts reading
+// messages from it. Requests and notifications sent by the peer are passed to
+// the given handler, each in its own goroutine. A nil handler responds to all
+// requests with a method not found error.
+func NewConn(rwc io.ReadWriteCloser, handler Handler) *Conn {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	c := &Conn{ //nolint:exhaustruct // partial initialization
+		rwc:      rwc,
+		handler:  handler,
+		enc:      json.NewEncoder(rwc),
+		pending:  make(map[int64]chan *Message),
+		inflight: make(map[string]context.CancelFunc),
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+
+	go c.read()
+
+	return c
+}
+
+// Call sends a request with the given method and params to the peer and waits
+// for the response, decoding its result into result (if not nil). If the given
+// context is done before the response is received, a cancel notification is
+// sent to the peer and the context's error is returned.
+func (c *Conn) Call(ctx context.Context, method string, params, result any) error {
+	c.mu.Lock()
+
+	if c.err != nil {
+		c.mu.Unlock()
+
+		return c.err
+	}
+
+	c.nextID++
+
+	id := c.nextID
+	ch := make(chan *Message, 1)
+
+	c.pending[id] = ch
+
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	rawID, _ := json.Marshal(id)
+
+	req := &Message{JSONRPC: "2.0", ID: rawID, Method: method} //nolint:exhaustruct // partial initialization
+
+	if err := c.send(req, params); err != nil {
+		return err
+	}
+
+	select {
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+
+		if result == nil {
+			return nil
+		}
+
+		if err := json.Unmarshal(msg.Result, result); err != nil {
+			return fmt.Errorf("unmarshaling result of %s: %w", method, err)
+		}
+
+		return nil
+	case <-ctx.Done():
+		_ = c.Notify(CancelMethod, map[string]any{"id": id})
+
+		return ctx.Err() //nolint:wrapcheck // passthrough
+	case <-c.done:
+		return c.Err()
+	}
+}
+
+// Notify sends a notification with the given method and params to the peer.
+func (c *Conn) Notify(method string, params any) error {
+	msg := &Message{JSONRPC: "2.0", Method: method} //nolint:exhaustruct // partial initialization
+
+	return c.send(msg, params)
+}
+
+// Close closes the connection and the underlying stream. Pending calls return
+// ErrClosed.
+func (c *Conn) Close() error {
+	c.shutdown(ErrClosed)
+
+	return c.rwc.Close() //nolint:wrapcheck // passthrough
+}
+
+// Done returns a channel that's closed when the connection is closed, either
+// by calling Close or because the stream was closed by the peer.
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// Err returns the reason the connection was closed, or nil if it's still open.
+func (c *Conn) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.err
+}
+
+func (c *Conn) send(msg *Message, params any) error {
+	if params != nil {
+		body, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("marshaling params of %s: %w", msg.Method, err)
+		}
+
+		msg.Params = body
+	}
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	if err := c.enc.Encode(msg); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+
+	return nil
+}
+
+func (c *Conn) read() {
+	dec := json.NewDecoder(c.rwc)
+
+	for {
+		var msg Message
+
+		if err := dec.Decode(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				c.shutdown(ErrClosed)
+			} else {
+				c.shutdown(fmt.Errorf("reading message: %w", err))
+			}
+
+			return
+		}
+
+		switch {
+		case msg.Method == CancelMethod:
+			c.cancelInflight(msg.Params)
+		case msg.Method != "":
+			// Track requests before handling them so a cancel notification sent
+			// right after the request isn't missed.
+			ctx, cancel := context.WithCancel(c.ctx)
+
+			if msg.ID != nil {
+				c.mu.Lock()
+				c.inflight[string(msg.ID)] = cancel
+				c.mu.Unlock()
+			}
+
+			go c.handle(ctx, cancel, msg)
+		case msg.ID != nil:
+			c.respond(msg)
+		}
+	}
+}
+
+// respond passes the given response to the pending call with the same ID.
+// Responses to calls that are no longer pending (e.g. canceled calls) are
+// dropped.
+func (c *Conn) respond(msg Message) {
+	var id int64
+
+	if err := json.Unmarshal(msg.ID, &id); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	ch, ok := c.pending[id]
+	c.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	// Drop duplicate responses instead of blocking the read loop.
+	select {
+	case ch <- &msg:
+	default:
+	}
+}
+
+// handle passes the given request or notification to the handler and, if it's
+// a request, sends the response.
+func (c *Conn) handle(ctx context.Context, cancel context.CancelFunc, msg Message) {
+	defer cancel()
+
+	if msg.ID != nil {
+		defer func() {
+			c.mu.Lock()
+			delete(c.inflight, string(msg.ID))
+			c.mu.Unlock()
+		}()
+	}
+
+	var (
+		result any
+		err    error
+	)
+
+	if c.handler == nil {
+		err = &Error{Code: CodeMethodNotFound, Message: "method not found: " + msg.Method} //nolint:exhaustruct // no data
+	} else {
+		result, err = c.handler(ctx, msg.Method, msg.Params)
+	}
+
+	if msg.ID == nil {
+		return
+	}
+
+	resp := &Message{JSONRPC: "2.0", ID: msg.ID} //nolint:exhaustruct // partial initialization
+
+	if err != nil {
+		var rpcErr *Error
+
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{Code: CodeInternalError, Message: err.Error()} //nolint:exhaustruct // no data
+
+			if ctx.Err() != nil {
+				rpcErr.Code = CodeCanceled
+			}
+		}
+
+		resp.Error = rpcErr
+	} else {
+		body, err := json.Marshal(result)
+		if err != nil {
+			resp.Error = &Error{Code: CodeInternalError, Message: err.Error()} //nolint:exhaustruct // no data
+		} else {
+			resp.Result = body
+		}
+	}
+
+	_ = c.send(resp, nil)
+}
+
+// cancelInflight cancels the context of the request handler with the ID in the
+// given cancel notification params.
+func (c *Conn) cancelInflight(params json.RawMessage) {
+	var p struct {
+		ID json.RawMessage `json:"id"`
+	}
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	cancel, ok := c.inflight[string(p.ID)]
+	c.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+func (c *Conn) shutdown(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.err != nil {
+		return
+	}
+
+	c.err = err
+	c.cancel()
+
+	close(c.done)
+}
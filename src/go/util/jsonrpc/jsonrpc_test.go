@@ -0,0 +1,185 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"phenix/util/jsonrpc"
+)
+
+func pipe(t *testing.T, server jsonrpc.Handler) (*jsonrpc.Conn, *jsonrpc.Conn) {
+	t.Helper()
+
+	a, b := net.Pipe()
+
+	client := jsonrpc.NewConn(a, nil)
+	srv := jsonrpc.NewConn(b, server)
+
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = srv.Close()
+	})
+
+	return client, srv
+}
+
+func TestCall(t *testing.T) {
+	client, _ := pipe(t, func(_ context.Context, method string, params json.RawMessage) (any, error) {
+		switch method {
+		case "add":
+			var args []int
+
+			if err := json.Unmarshal(params, &args); err != nil {
+				return nil, &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: err.Error()}
+			}
+
+			return args[0] + args[1], nil
+		case "fail":
+			return nil, errors.New("boom")
+		}
+
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound, Message: method}
+	})
+
+	var sum int
+
+	if err := client.Call(context.Background(), "add", []int{2, 3}, &sum); err != nil {
+		t.Fatal(err)
+	}
+
+	if sum != 5 {
+		t.Errorf("expected 5, got %d", sum)
+	}
+
+	tests := map[string]int{
+		"fail":    jsonrpc.CodeInternalError,
+		"missing": jsonrpc.CodeMethodNotFound,
+	}
+
+	for method, code := range tests {
+		var rpcErr *jsonrpc.Error
+
+		err := client.Call(context.Background(), method, nil, nil)
+		if !errors.As(err, &rpcErr) {
+			t.Fatalf("expected JSON-RPC error for %s, got %v", method, err)
+		}
+
+		if rpcErr.Code != code {
+			t.Errorf("expected code %d for %s, got %d", code, method, rpcErr.Code)
+		}
+	}
+}
+
+func TestCallCanceled(t *testing.T) {
+	canceled := make(chan struct{})
+
+	client, _ := pipe(t, func(ctx context.Context, _ string, _ json.RawMessage) (any, error) {
+		<-ctx.Done()
+		close(canceled)
+
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := client.Call(ctx, "wait", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("expected handler context to be canceled")
+	}
+}
+
+func TestBidirectional(t *testing.T) {
+	var (
+		a, b     = net.Pipe()
+		notified = make(chan string, 1)
+		callee   *jsonrpc.Conn
+	)
+
+	caller := jsonrpc.NewConn(a, func(_ context.Context, method string, params json.RawMessage) (any, error) {
+		if method == "ping" {
+			return "pong", nil
+		}
+
+		var msg string
+
+		_ = json.Unmarshal(params, &msg)
+		notified <- method + ":" + msg
+
+		return nil, nil
+	})
+
+	// The callee calls back into the caller while handling a request.
+	callee = jsonrpc.NewConn(b, func(ctx context.Context, _ string, _ json.RawMessage) (any, error) {
+		var pong string
+
+		if err := callee.Call(ctx, "ping", nil, &pong); err != nil {
+			return nil, err
+		}
+
+		return "got " + pong, nil
+	})
+
+	t.Cleanup(func() {
+		_ = caller.Close()
+		_ = callee.Close()
+	})
+
+	if err := callee.Notify("progress", "half way"); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case got := <-notified:
+		if got != "progress:half way" {
+			t.Errorf("expected progress notification, got %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected notification")
+	}
+
+	var result string
+
+	if err := caller.Call(context.Background(), "stage", nil, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result != "got pong" {
+		t.Errorf("expected got pong, got %s", result)
+	}
+}
+
+func TestClosed(t *testing.T) {
+	a, b := net.Pipe()
+
+	client := jsonrpc.NewConn(a, nil)
+
+	// Close the peer's end of the stream while a call is pending.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		_ = b.Close()
+	}()
+
+	if err := client.Call(context.Background(), "wait", nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	select {
+	case <-client.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected connection to be closed")
+	}
+
+	if err := client.Call(context.Background(), "again", nil, nil); !errors.Is(err, jsonrpc.ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+}
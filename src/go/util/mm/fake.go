@@ -0,0 +1,1769 @@
+package mm
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/activeshadow/libminimega/minicli"
+	"github.com/activeshadow/libminimega/miniclient"
+	"github.com/gofrs/uuid/v5"
+
+	"phenix/util/common"
+	"phenix/util/mm/mmcli"
+)
+
+const (
+	fakeVLANMin          = 101
+	fakeVLANMax          = 4096
+	fakeVNCPortBase      = 5900
+	fakeC2CheckInterval  = 50 * time.Millisecond
+	fakeHostSpecFields   = 3
+	fakeDefaultCPUs      = 32
+	fakeDefaultMem       = 131072
+	fakeDefaultHeadCPUs  = 8
+	fakeDefaultHeadMem   = 32768
+	fakeDefaultHostCount = 3
+)
+
+// Regular expression used to identify MAC addresses in `vm config net` specs.
+var fakeMACRegex = regexp.MustCompile(`^([0-9a-fA-F]{2}:){5}[0-9a-fA-F]{2}$`)
+
+// FakeC2Responder is called by the Fake minimega implementation each time a C2
+// command is issued to a VM. The command is passed as it would be sent to
+// minimega after the `cc` prefix (for example, `exec ls -l`, `send foo.txt` or
+// `test-conn tcp 10.0.0.1 80`). The returned stdout and stderr are stored as
+// the command's response. A non-nil error causes the command to fail.
+type FakeC2Responder func(ns, vm, command string) (string, string, error)
+
+// FakeOption configures a Fake minimega implementation.
+type FakeOption func(*Fake)
+
+// FakeHeadnode sets the name of the simulated cluster headnode and whether or
+// not VMs can be scheduled on it.
+func FakeHeadnode(name string, schedulable bool) FakeOption {
+	return func(f *Fake) {
+		f.headnode = Host{ //nolint:exhaustruct // partial initialization
+			Name:        name,
+			CPUs:        fakeDefaultHeadCPUs,
+			MemTotal:    fakeDefaultHeadMem,
+			Schedulable: schedulable,
+			Headnode:    true,
+		}
+	}
+}
+
+// FakeHost adds a simulated compute node to the cluster with the given number
+// of CPUs and amount of memory (in MB). The first use of this option replaces
+// the default set of compute nodes.
+func FakeHost(name string, cpus, mem int) FakeOption {
+	return func(f *Fake) {
+		if !f.customHosts {
+			f.hosts = nil
+			f.customHosts = true
+		}
+
+		f.hosts = append(
+			f.hosts,
+			Host{Name: name, CPUs: cpus, MemTotal: mem, Schedulable: true}, //nolint:exhaustruct // partial initialization
+		)
+	}
+}
+
+// FakeHostDiskFree sets the number of bytes available in the minimega base
+// directory of the simulated host with the given name, which must already have
+// been added with FakeHost or FakeHeadnode.
+func FakeHostDiskFree(name string, free int64) FakeOption {
+	return func(f *Fake) {
+		if f.headnode.Name == name {
+			f.headnode.DiskUsage.MinimegaFree = free
+		}
+
+		for i := range f.hosts {
+			if f.hosts[i].Name == name {
+				f.hosts[i].DiskUsage.MinimegaFree = free
+			}
+		}
+	}
+}
+
+// FakeVLANRange sets the global VLAN range used when a namespace does not
+// specify its own range.
+func FakeVLANRange(minID, maxID int) FakeOption {
+	return func(f *Fake) {
+		f.vlanMin = minID
+		f.vlanMax = maxID
+	}
+}
+
+// FakeC2 sets the responder used to generate responses to C2 commands.
+func FakeC2(r FakeC2Responder) FakeOption {
+	return func(f *Fake) {
+		f.c2 = r
+	}
+}
+
+// FakeC2Disabled keeps C2 clients from automatically becoming active when VMs
+// are started. Use `SetC2Active` to activate them individually.
+func FakeC2Disabled() FakeOption {
+	return func(f *Fake) {
+		f.c2Disabled = true
+	}
+}
+
+// ParseFakeHost parses a compute node spec in the form of `name:cpus:mem` and
+// returns the corresponding FakeHost option.
+func ParseFakeHost(spec string) (FakeOption, error) {
+	tokens := strings.Split(spec, ":")
+
+	if len(tokens) != fakeHostSpecFields {
+		return nil, fmt.Errorf("invalid fake host spec %s (expected name:cpus:mem)", spec)
+	}
+
+	cpus, err := strconv.Atoi(tokens[1])
+	if err != nil {
+		return nil, fmt.Errorf("parsing CPUs for fake host %s: %w", tokens[0], err)
+	}
+
+	mem, err := strconv.Atoi(tokens[2])
+	if err != nil {
+		return nil, fmt.Errorf("parsing memory for fake host %s: %w", tokens[0], err)
+	}
+
+	return FakeHost(tokens[0], cpus, mem), nil
+}
+
+type fakeVM struct {
+	VM
+
+	vmType    string
+	schedule  string
+	nets      []fakeNet
+	started   time.Time
+	c2Active  bool
+	launched  bool
+	vncOffset int
+}
+
+type fakeNet struct {
+	bridge string
+	alias  string
+	mac    string
+}
+
+type fakeC2Command struct {
+	vm     string
+	stdout string
+	stderr string
+}
+
+type fakeNamespace struct {
+	name string
+
+	vlanMin int
+	vlanMax int
+	vlans   map[string]int
+
+	vms      []*fakeVM
+	captures []Capture
+	tunnels  []map[string]string
+	bridges  []string
+	taps     []string
+
+	nextVMID  int
+	nextTapID int
+	nextTunID int
+
+	c2Responses map[string]fakeC2Command
+
+	// pending `vm config` settings used for the next `vm launch`
+	config fakeVM
+}
+
+// Fake is an in-memory implementation of the MM interface that simulates a
+// minimega cluster. It tracks namespaces, VMs and their state, cluster hosts
+// and their committed resources, VLAN allocations, captures, tunnels and C2
+// command responses, making it possible to run the experiment lifecycle
+// without a hypervisor.
+type Fake struct {
+	mu sync.Mutex
+
+	headnode    Host
+	hosts       []Host
+	customHosts bool
+
+	vlanMin int
+	vlanMax int
+
+	c2         FakeC2Responder
+	c2Disabled bool
+	c2NextID   int
+
+	namespaces map[string]*fakeNamespace
+	history    []string
+}
+
+// NewFake returns a new Fake minimega implementation configured with the given
+// options. By default, the simulated cluster consists of a non-schedulable
+// headnode and three compute nodes.
+func NewFake(opts ...FakeOption) *Fake {
+	f := &Fake{ //nolint:exhaustruct // partial initialization
+		headnode: Host{ //nolint:exhaustruct // partial initialization
+			Name:     "fake-headnode",
+			CPUs:     fakeDefaultHeadCPUs,
+			MemTotal: fakeDefaultHeadMem,
+			Headnode: true,
+		},
+		vlanMin:    fakeVLANMin,
+		vlanMax:    fakeVLANMax,
+		namespaces: make(map[string]*fakeNamespace),
+	}
+
+	for i := 1; i <= fakeDefaultHostCount; i++ {
+		f.hosts = append(f.hosts, Host{ //nolint:exhaustruct // partial initialization
+			Name:        fmt.Sprintf("fake-compute%d", i),
+			CPUs:        fakeDefaultCPUs,
+			MemTotal:    fakeDefaultMem,
+			Schedulable: true,
+		})
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if f.c2 == nil {
+		f.c2 = func(_, _, _ string) (string, string, error) { return "", "", nil }
+	}
+
+	return f
+}
+
+// History returns the minimega commands processed by the Fake, in order. Each
+// command is prefixed with its namespace, if any.
+func (f *Fake) History() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.history)
+}
+
+// Namespaces returns the names of all namespaces currently known to the Fake.
+func (f *Fake) Namespaces() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	names := slices.Collect(maps.Keys(f.namespaces))
+	sort.Strings(names)
+
+	return names
+}
+
+// SetC2Active sets whether or not the C2 client for the given VM is active.
+func (f *Fake) SetC2Active(ns, vm string, active bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(ns, vm)
+	if err != nil {
+		return err
+	}
+
+	v.c2Active = active
+
+	return nil
+}
+
+// SetVMState forces the given VM into the given state (for example, `ERROR`).
+func (f *Fake) SetVMState(ns, vm, state string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(ns, vm)
+	if err != nil {
+		return err
+	}
+
+	v.State = state
+	v.Running = state == "RUNNING"
+
+	return nil
+}
+
+func (f *Fake) ReadScriptFromFile(filename string) error {
+	body, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("reading mmcli script: %w", err)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var (
+		scanner = bufio.NewScanner(bytes.NewReader(body))
+		ns      *fakeNamespace
+		line    int
+	)
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		tokens := fakeFields(text)
+
+		if tokens[0] == "namespace" && len(tokens) == 2 { //nolint:mnd // namespace <name>
+			ns = f.namespace(tokens[1], true)
+			f.record(ns, text)
+
+			continue
+		}
+
+		f.record(ns, text)
+
+		if err := f.scriptCommand(ns, tokens); err != nil {
+			return fmt.Errorf("reading mmcli script: line %d (%s): %w", line, text, err)
+		}
+	}
+
+	return nil
+}
+
+func (f *Fake) ClearNamespace(ns string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.record(nil, "clear namespace "+ns)
+	delete(f.namespaces, ns)
+
+	return nil
+}
+
+func (f *Fake) LaunchVMs(ns string, start ...string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := f.namespace(ns, true)
+	f.record(n, "vm launch")
+
+	for _, v := range n.vms {
+		if v.launched {
+			continue
+		}
+
+		host, err := f.place(v)
+		if err != nil {
+			return fmt.Errorf("launching VMs: %w", err)
+		}
+
+		v.Host = host
+		v.launched = true
+		v.State = "PAUSED"
+
+		for i, net := range v.nets {
+			id, err := f.allocateVLAN(n, net.alias)
+			if err != nil {
+				return fmt.Errorf("launching VMs: %w", err)
+			}
+
+			v.Networks[i] = fmt.Sprintf("%s (%d)", net.alias, id)
+		}
+	}
+
+	if start == nil {
+		f.record(n, "vm start all")
+
+		for _, v := range n.vms {
+			f.start(v)
+		}
+
+		return nil
+	}
+
+	for _, name := range start {
+		f.record(n, "vm start "+name)
+
+		v, err := f.findVM(ns, name)
+		if err != nil {
+			return fmt.Errorf("starting VM %s: %w", name, err)
+		}
+
+		f.start(v)
+	}
+
+	return nil
+}
+
+func (f *Fake) GetLaunchProgress(ns string, expected int) (float64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, ok := f.namespaces[ns]
+	if !ok || expected == 0 {
+		return 0.0, nil
+	}
+
+	var queued int
+
+	for _, v := range n.vms {
+		if !v.launched || v.State == "BUILDING" {
+			queued++
+		}
+	}
+
+	return float64(queued) / float64(expected), nil
+}
+
+func (f *Fake) GetVMInfo(opts ...Option) VMs {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var vms VMs
+
+	for _, n := range f.sortedNamespaces(o.ns) {
+		for _, v := range n.vms {
+			if !v.launched {
+				continue
+			}
+
+			if o.vm != "" && v.Name != o.vm {
+				continue
+			}
+
+			vm := v.Copy()
+			vm.CCActive = v.c2Active && v.Running
+			vm.Captures = n.vmCaptures(v.Name)
+
+			if v.Running {
+				vm.Uptime = time.Since(v.started).Seconds()
+			}
+
+			vms = append(vms, vm)
+		}
+	}
+
+	return vms
+}
+
+func (f *Fake) GetVMScreenshot(opts ...Option) ([]byte, error) {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err != nil || !v.Running {
+		return nil, ErrVMNotFound
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, 1, 1))
+	img.Set(0, 0, color.Black)
+
+	var buf bytes.Buffer
+
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encoding screenshot: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (f *Fake) GetVNCEndpoint(opts ...Option) (string, error) {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err != nil || v.vmType != "kvm" {
+		return "", errors.New("not found")
+	}
+
+	return fmt.Sprintf("%s:%d", v.Host, fakeVNCPortBase+v.vncOffset), nil
+}
+
+func (f *Fake) StartVM(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vms, err := f.targetVMs(o.ns, o.vm)
+	if err != nil {
+		return fmt.Errorf("starting VM %s in namespace %s: %w", o.vm, o.ns, err)
+	}
+
+	f.record(f.namespaces[o.ns], "vm start "+o.vm)
+
+	for _, v := range vms {
+		f.start(v)
+	}
+
+	return nil
+}
+
+func (f *Fake) StopVM(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vms, err := f.targetVMs(o.ns, o.vm)
+	if err != nil {
+		return fmt.Errorf("stopping VM %s in namespace %s: %w", o.vm, o.ns, err)
+	}
+
+	f.record(f.namespaces[o.ns], "vm stop "+o.vm)
+
+	for _, v := range vms {
+		if !v.Running {
+			if o.vm == "all" {
+				continue
+			}
+
+			return fmt.Errorf("stopping VM %s in namespace %s: vm not running", o.vm, o.ns)
+		}
+
+		v.State = "PAUSED"
+		v.Running = false
+	}
+
+	return nil
+}
+
+func (f *Fake) RedeployVM(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err != nil {
+		return fmt.Errorf("no info found for VM %s in namespace %s", o.vm, o.ns)
+	}
+
+	f.record(f.namespaces[o.ns], "vm kill "+o.vm)
+
+	if o.cpu != 0 {
+		v.CPUs = o.cpu
+	}
+
+	if o.mem != 0 {
+		v.RAM = o.mem
+	}
+
+	if o.disk != "" {
+		v.Disk = newDiskConfig(o.disk).path
+		v.InjectPartition = o.injectPart
+	}
+
+	f.record(f.namespaces[o.ns], "vm launch kvm "+o.vm)
+	f.start(v)
+
+	return nil
+}
+
+func (f *Fake) KillVM(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vms, err := f.targetVMs(o.ns, o.vm)
+	if err != nil {
+		return fmt.Errorf("killing VM %s in namespace %s: %w", o.vm, o.ns, err)
+	}
+
+	n, ok := f.namespaces[o.ns]
+	if !ok {
+		return nil
+	}
+
+	f.record(n, "vm kill "+o.vm)
+
+	// Killing and flushing a VM removes it from the namespace completely.
+	n.vms = slices.DeleteFunc(n.vms, func(v *fakeVM) bool { return slices.Contains(vms, v) })
+	n.captures = slices.DeleteFunc(n.captures, func(c Capture) bool {
+		return o.vm == "all" || c.VM == o.vm
+	})
+
+	return nil
+}
+
+func (f *Fake) GetVMHost(opts ...Option) (string, error) {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err != nil {
+		return "", fmt.Errorf("vm %s not found", o.vm)
+	}
+
+	return v.Host, nil
+}
+
+func (f *Fake) GetVMState(opts ...Option) (string, error) {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err != nil {
+		return "", fmt.Errorf("vm %s not found", o.vm)
+	}
+
+	return v.State, nil
+}
+
+func (f *Fake) SetVMTags(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err != nil {
+		return fmt.Errorf("failed to clear tags for vm %s: %w", o.vm, err)
+	}
+
+	v.Tags = maps.Clone(o.tags)
+
+	if v.Tags == nil {
+		v.Tags = make(map[string]string)
+	}
+
+	return nil
+}
+
+func (f *Fake) ConnectVMInterface(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err == nil && (o.connectIface < 0 || o.connectIface >= len(v.nets)) {
+		err = fmt.Errorf("invalid interface %d", o.connectIface)
+	}
+
+	if err != nil {
+		return fmt.Errorf(
+			"connecting interface %d on VM %s to VLAN %s in namespace %s: %w",
+			o.connectIface, o.vm, o.connectVLAN, o.ns, err,
+		)
+	}
+
+	n := f.namespaces[o.ns]
+
+	id, err := f.allocateVLAN(n, o.connectVLAN)
+	if err != nil {
+		return fmt.Errorf(
+			"connecting interface %d on VM %s to VLAN %s in namespace %s: %w",
+			o.connectIface, o.vm, o.connectVLAN, o.ns, err,
+		)
+	}
+
+	f.record(n, fmt.Sprintf("vm net connect %s %d %s", o.vm, o.connectIface, o.connectVLAN))
+
+	v.nets[o.connectIface].alias = o.connectVLAN
+	v.Networks[o.connectIface] = fmt.Sprintf("%s (%d)", o.connectVLAN, id)
+
+	return nil
+}
+
+func (f *Fake) DisconnectVMInterface(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err == nil && (o.connectIface < 0 || o.connectIface >= len(v.nets)) {
+		err = fmt.Errorf("invalid interface %d", o.connectIface)
+	}
+
+	if err != nil {
+		return fmt.Errorf(
+			"disconnecting interface %d on VM %s in namespace %s: %w",
+			o.connectIface, o.vm, o.ns, err,
+		)
+	}
+
+	f.record(f.namespaces[o.ns], fmt.Sprintf("vm net disconnect %s %d", o.vm, o.connectIface))
+
+	v.nets[o.connectIface].alias = ""
+	v.Networks[o.connectIface] = "disconnected"
+
+	return nil
+}
+
+func (f *Fake) CreateBridge(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := f.namespace(o.ns, true)
+	f.record(n, fmt.Sprintf("ns bridge %s gre", o.bridge))
+
+	if !slices.Contains(n.bridges, o.bridge) {
+		n.bridges = append(n.bridges, o.bridge)
+	}
+
+	return nil
+}
+
+func (f *Fake) CreateTunnel(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.findVM(o.ns, o.vm); err != nil {
+		return fmt.Errorf("unable to determine what host the VM is scheduled on: %w", err)
+	}
+
+	n := f.namespaces[o.ns]
+	n.nextTunID++
+
+	f.record(n, fmt.Sprintf("cc tunnel %s %d %s %d", o.vm, o.srcPort, o.dstHost, o.dstPort))
+
+	n.tunnels = append(n.tunnels, map[string]string{
+		"id":       strconv.Itoa(n.nextTunID),
+		"vm":       o.vm,
+		"src":      strconv.Itoa(o.srcPort),
+		"dst":      o.dstHost,
+		"dst port": strconv.Itoa(o.dstPort),
+	})
+
+	return nil
+}
+
+func (f *Fake) GetTunnels(opts ...Option) []map[string]string {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, ok := f.namespaces[o.ns]
+	if !ok {
+		return nil
+	}
+
+	var tunnels []map[string]string
+
+	for _, t := range n.fakeTunnels(o) {
+		tunnels = append(tunnels, maps.Clone(t))
+	}
+
+	return tunnels
+}
+
+func (f *Fake) CloseTunnel(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, err := f.findVM(o.ns, o.vm); err != nil {
+		return fmt.Errorf("unable to determine what host the VM is scheduled on: %w", err)
+	}
+
+	n := f.namespaces[o.ns]
+	matches := n.fakeTunnels(o)
+
+	for _, t := range matches {
+		f.record(n, fmt.Sprintf("cc tunnel close %s %s", o.vm, t["id"]))
+	}
+
+	n.tunnels = slices.DeleteFunc(n.tunnels, func(t map[string]string) bool {
+		for _, m := range matches {
+			if t["id"] == m["id"] {
+				return true
+			}
+		}
+
+		return false
+	})
+
+	return nil
+}
+
+func (f *Fake) StartVMCapture(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err != nil {
+		return fmt.Errorf("unable to determine what host the VM is scheduled on: %w", err)
+	}
+
+	n := f.namespaces[o.ns]
+
+	for _, capture := range n.vmCaptures(o.vm) {
+		if capture.Interface == o.captureIface {
+			return ErrCaptureExists
+		}
+	}
+
+	if filepath.IsAbs(o.captureFile) {
+		return errors.New("path for capture file should not be absolute")
+	}
+
+	if o.captureIface < 0 || o.captureIface >= len(v.nets) {
+		return fmt.Errorf(
+			"starting VM capture for interface %d on VM %s in namespace %s: invalid interface",
+			o.captureIface, o.vm, o.ns,
+		)
+	}
+
+	f.record(n, fmt.Sprintf("capture pcap vm %s %d %s", o.vm, o.captureIface, o.captureFile))
+
+	n.captures = append(n.captures, Capture{
+		VM:        o.vm,
+		Interface: o.captureIface,
+		Filepath:  filepath.Join(common.PhenixBase, "images", o.captureFile),
+	})
+
+	return nil
+}
+
+func (f *Fake) StopVMCapture(opts ...Option) error {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, ok := f.namespaces[o.ns]
+	if !ok || len(n.vmCaptures(o.vm)) == 0 {
+		return ErrNoCaptures
+	}
+
+	f.record(n, "capture pcap delete vm "+o.vm)
+
+	n.captures = slices.DeleteFunc(n.captures, func(c Capture) bool { return c.VM == o.vm })
+
+	return nil
+}
+
+func (f *Fake) GetExperimentCaptures(opts ...Option) []Capture {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, ok := f.namespaces[o.ns]
+	if !ok {
+		return nil
+	}
+
+	return slices.Clone(n.captures)
+}
+
+func (f *Fake) GetVMCaptures(opts ...Option) []Capture {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, ok := f.namespaces[o.ns]
+	if !ok {
+		return nil
+	}
+
+	return n.vmCaptures(o.vm)
+}
+
+func (f *Fake) GetClusterHosts(schedOnly bool) (Hosts, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var cluster Hosts
+
+	for _, host := range f.hosts {
+		cluster = append(cluster, f.hostUsage(host))
+	}
+
+	if !schedOnly || f.headnode.Schedulable {
+		cluster = append(cluster, f.hostUsage(f.headnode))
+	}
+
+	return cluster, nil
+}
+
+func (f *Fake) GetNamespaceHosts(ns string) (Hosts, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var hosts Hosts
+
+	for _, host := range f.schedulable() {
+		hosts = append(hosts, f.hostUsage(host))
+	}
+
+	return hosts, nil
+}
+
+func (f *Fake) Headnode() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.headnode.Name
+}
+
+func (f *Fake) IsHeadnode(node string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return common.TrimHostnameSuffixes(node) == f.headnode.Name
+}
+
+func (f *Fake) GetMMArgs() (map[string]string, error) {
+	return map[string]string{
+		"base":      common.MinimegaBase,
+		"namespace": "minimega",
+		"headnode":  f.Headnode(),
+	}, nil
+}
+
+func (f *Fake) GetVLANs(opts ...Option) (map[string]int, error) {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	vlans := make(map[string]int)
+
+	for _, n := range f.sortedNamespaces(o.ns) {
+		for alias, id := range n.vlans {
+			if o.ns == "" {
+				alias = fmt.Sprintf("%s//%s", n.name, alias)
+			}
+
+			vlans[alias] = id
+		}
+	}
+
+	return vlans, nil
+}
+
+func (f *Fake) IsC2ClientActive(opts ...C2Option) error {
+	o := NewC2Options(opts...)
+	if o.skipActiveClientCheck {
+		return nil
+	}
+
+	after := time.After(o.timeout)
+
+	for {
+		f.mu.Lock()
+		v, err := f.findVM(o.ns, o.vm)
+		active := err == nil && v.c2Active && v.Running
+		f.mu.Unlock()
+
+		if err != nil {
+			return fmt.Errorf("vm %s does not exist", o.vm)
+		}
+
+		if active {
+			return nil
+		}
+
+		select {
+		case <-o.ctx.Done():
+			return o.ctx.Err()
+		case <-after:
+			return ErrC2ClientNotActive
+		case <-time.After(fakeC2CheckInterval):
+		}
+	}
+}
+
+func (f *Fake) ExecC2Command(opts ...C2Option) (string, error) {
+	err := f.IsC2ClientActive(opts...)
+	if err != nil {
+		return "", fmt.Errorf("cannot execute command: %w", err)
+	}
+
+	o := NewC2Options(opts...)
+
+	var id string
+
+	if o.testConn != "" {
+		id, err = f.execC2(o.ns, o.vm, "test-conn "+o.testConn)
+		if err != nil {
+			return "", fmt.Errorf("calling 'cc test-conn %s' for vm %s: %w", o.testConn, o.vm, err)
+		}
+
+		return id, nil
+	}
+
+	if o.sendFile != "" {
+		id, err = f.execC2(o.ns, o.vm, "send "+o.sendFile)
+		if err != nil {
+			return "", fmt.Errorf("sending file '%s' to vm %s: %w", o.sendFile, o.vm, err)
+		}
+
+		if o.command == "" {
+			return id, nil
+		}
+	}
+
+	if o.command != "" {
+		id, err = f.execC2(o.ns, o.vm, "exec "+o.command)
+		if err != nil {
+			return "", fmt.Errorf("calling 'cc exec %s' for vm %s: %w", o.command, o.vm, err)
+		}
+
+		return id, nil
+	}
+
+	if o.mount != nil {
+		command := "clear cc mount " + o.vm
+
+		if *o.mount {
+			command = fmt.Sprintf("mount %s %s", o.vm, GetLocalMountPath(o.ns, o.vm))
+		}
+
+		id, err = f.execC2(o.ns, o.vm, command)
+		if err != nil {
+			return "", fmt.Errorf("error creating mount: %w", err)
+		}
+
+		return id, nil
+	}
+
+	return "", errors.New("no options to execute were provided")
+}
+
+func (f *Fake) GetC2Response(opts ...C2Option) (string, error) {
+	o := NewC2Options(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n, ok := f.namespaces[o.ns]
+	if !ok {
+		return "", fmt.Errorf("getting response for command %s: namespace not found", o.commandID)
+	}
+
+	resp, ok := n.c2Responses[o.commandID]
+	if !ok {
+		return "", fmt.Errorf("getting response for command %s: command not found", o.commandID)
+	}
+
+	switch o.responseType {
+	case C2ResponseStdout:
+		return resp.stdout, nil
+	case C2ResponseStderr:
+		return resp.stderr, nil
+	default:
+		return resp.stdout + resp.stderr, nil
+	}
+}
+
+func (f *Fake) WaitForC2Response(opts ...C2Option) (string, error) {
+	o := NewC2Options(opts...)
+
+	// Responses are generated synchronously when commands are executed, so
+	// there's nothing to wait for other than the response being available.
+	return f.GetC2Response(C2NS(o.ns), C2CommandID(o.commandID))
+}
+
+func (f *Fake) ClearC2Responses(opts ...C2Option) error {
+	o := NewC2Options(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if n, ok := f.namespaces[o.ns]; ok {
+		f.record(n, "clear cc responses")
+		n.c2Responses = make(map[string]fakeC2Command)
+	}
+
+	return nil
+}
+
+func (f *Fake) TapVLAN(opts ...TapOption) error {
+	o := NewTapOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.knownHost(o.host) {
+		return fmt.Errorf("creating tap %s on node %s: %w", o.name, o.host, ErrHostNotFound)
+	}
+
+	n := f.namespace(o.ns, true)
+
+	if o.untap {
+		f.record(n, fmt.Sprintf("mesh send %s tap delete %s", o.host, o.name))
+		n.taps = slices.DeleteFunc(n.taps, func(t string) bool { return t == o.name })
+
+		return nil
+	}
+
+	f.record(n, fmt.Sprintf("mesh send %s tap create %s bridge %s name %s", o.host, o.vlan, o.bridge, o.name))
+
+	_, alias, _ := strings.Cut(o.vlan, "//")
+
+	if _, err := f.allocateVLAN(n, alias); err != nil {
+		return fmt.Errorf("creating tap %s on node %s: %w", o.name, o.host, err)
+	}
+
+	n.taps = append(n.taps, o.name)
+
+	return nil
+}
+
+func (f *Fake) MeshShell(host, command string) error {
+	_, err := f.MeshShellResponse(host, command)
+
+	return err
+}
+
+func (f *Fake) MeshShellResponse(host, command string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if host == "" {
+		host = f.headnode.Name
+	}
+
+	if !f.knownHost(host) {
+		return "", fmt.Errorf("running shell command on host %s: %w", host, ErrHostNotFound)
+	}
+
+	f.record(nil, fmt.Sprintf("mesh send %s shell %s", host, command))
+
+	return "", nil
+}
+
+func (f *Fake) MeshSend(ns, host, command string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if !f.knownHost(host) {
+		return fmt.Errorf("sending command to host %s: %w", host, ErrHostNotFound)
+	}
+
+	f.record(f.namespaces[ns], fmt.Sprintf("mesh send %s %s", host, command))
+
+	return nil
+}
+
+func (f *Fake) ExecContainer(opts ...Option) (string, error) {
+	o := NewOptions(opts...)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	v, err := f.findVM(o.ns, o.vm)
+	if err != nil {
+		return "", fmt.Errorf("vm %s not found", o.vm)
+	}
+
+	if v.vmType != "container" {
+		return "", fmt.Errorf("vm %s is not a container", o.vm)
+	}
+
+	if !v.Running {
+		return "", fmt.Errorf("vm %s is not running", o.vm)
+	}
+
+	// The VM ID stands in for the container's PID.
+	f.record(nil, fmt.Sprintf("mesh send %s shell %s", v.Host, containerExecCommand(strconv.Itoa(v.ID), o.command)))
+
+	return "", nil
+}
+
+// Run processes a minimega command issued directly instead of through one of
+// the more specific methods. `vm info` is answered from the simulated VMs and
+// `vm start`, `vm stop` and `vm kill` update their state. Every other command
+// is recorded and succeeds without a response.
+func (f *Fake) Run(cmd *mmcli.Command) chan *miniclient.Response {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var (
+		tokens = fakeFields(cmd.Command)
+		resps  = minicli.Responses{&minicli.Response{Host: f.headnode.Name}} //nolint:exhaustruct // partial initialization
+	)
+
+	switch {
+	case len(tokens) == 2 && tokens[0] == "vm" && tokens[1] == "info":
+		resps = f.vmInfoResponses(cmd.Namespace, cmd.Filters)
+	case len(tokens) == 3 && tokens[0] == "vm" && slices.Contains([]string{"start", "stop", "kill"}, tokens[1]): //nolint:mnd // vm <action> <name>
+		f.record(f.namespaces[cmd.Namespace], cmd.Command)
+
+		if err := f.setVMState(cmd.Namespace, tokens[1], tokens[2]); err != nil {
+			resps[0].Error = err.Error()
+		}
+	default:
+		f.record(f.namespaces[cmd.Namespace], cmd.Command)
+	}
+
+	out := make(chan *miniclient.Response, 1)
+	out <- &miniclient.Response{Resp: resps, More: false} //nolint:exhaustruct // partial initialization
+
+	close(out)
+
+	return out
+}
+
+// scriptCommand processes a single tokenized minimega script command. It
+// assumes the Fake's lock is held.
+//
+//nolint:cyclop,funlen // complex logic
+func (f *Fake) scriptCommand(ns *fakeNamespace, tokens []string) error {
+	if ns == nil {
+		// Commands outside of a namespace (like `clear cc filter`) don't affect
+		// anything tracked by the Fake.
+		return nil
+	}
+
+	switch {
+	case len(tokens) == 4 && tokens[0] == "vlans" && tokens[1] == "range": //nolint:mnd // vlans range <min> <max>
+		minID, err1 := strconv.Atoi(tokens[2])
+		maxID, err2 := strconv.Atoi(tokens[3])
+
+		if err := errors.Join(err1, err2); err != nil {
+			return fmt.Errorf("invalid VLAN range: %w", err)
+		}
+
+		ns.vlanMin, ns.vlanMax = minID, maxID
+	case len(tokens) == 4 && tokens[0] == "vlans" && tokens[1] == "add": //nolint:mnd // vlans add <alias> <id>
+		id, err := strconv.Atoi(tokens[3])
+		if err != nil {
+			return fmt.Errorf("invalid VLAN ID: %w", err)
+		}
+
+		if owner := f.vlanOwner(id); owner != "" && owner != ns.name+"//"+tokens[2] {
+			return fmt.Errorf("VLAN %d already in use by %s", id, owner)
+		}
+
+		ns.vlans[tokens[2]] = id
+	case len(tokens) == 3 && tokens[0] == "clear" && tokens[1] == "vm" && tokens[2] == "config":
+		ns.config = fakeVM{} //nolint:exhaustruct // reset config
+	case len(tokens) > 3 && tokens[0] == "vm" && tokens[1] == "config": //nolint:mnd // vm config <key> <value>
+		return f.vmConfig(ns, tokens[2], tokens[3:])
+	case len(tokens) == 4 && tokens[0] == "vm" && tokens[1] == "launch": //nolint:mnd // vm launch <type> <name>
+		if _, err := f.findVM(ns.name, tokens[3]); err == nil {
+			return fmt.Errorf("vm %s already exists", tokens[3])
+		}
+
+		ns.nextVMID++
+
+		v := ns.config
+		v.ID = ns.nextVMID
+		v.Name = tokens[3]
+		v.Type = tokens[2]
+		v.vmType = tokens[2]
+		v.State = "BUILDING"
+		v.UUID = uuid.Must(uuid.NewV4()).String()
+		v.vncOffset = f.vmCount()
+		v.Tags = maps.Clone(ns.config.Tags)
+		v.nets = slices.Clone(ns.config.nets)
+		v.Networks = make([]string, len(v.nets))
+		v.Taps = make([]string, len(v.nets))
+
+		if v.Tags == nil {
+			v.Tags = make(map[string]string)
+		}
+
+		for i := range v.nets {
+			ns.nextTapID++
+			v.Taps[i] = fmt.Sprintf("mega_tap%d", ns.nextTapID)
+		}
+
+		ns.vms = append(ns.vms, &v)
+	}
+
+	// All other commands (disk snapshots and injections, cc commands, ns
+	// settings, etc.) are recorded in the history but otherwise ignored.
+	return nil
+}
+
+// vmConfig applies a `vm config` setting to the pending VM config for the
+// given namespace. It assumes the Fake's lock is held.
+func (f *Fake) vmConfig(ns *fakeNamespace, key string, values []string) error {
+	var err error
+
+	switch key {
+	case "schedule":
+		ns.config.schedule = values[0]
+	case "vcpus":
+		ns.config.CPUs, err = strconv.Atoi(values[0])
+	case "memory":
+		ns.config.RAM, err = strconv.Atoi(values[0])
+	case "snapshot":
+		ns.config.Snapshot, err = strconv.ParseBool(values[0])
+	case "disk":
+		ns.config.Disk = newDiskConfig(values[0]).path
+	case "cdrom":
+		ns.config.CdRom = values[0]
+	case "tags":
+		if ns.config.Tags == nil {
+			ns.config.Tags = make(map[string]string)
+		}
+
+		if len(values) > 1 {
+			ns.config.Tags[values[0]] = values[1]
+		}
+	case "net":
+		ns.config.nets = nil
+
+		for _, spec := range values {
+			ns.config.nets = append(ns.config.nets, parseFakeNet(spec))
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value for vm config %s: %w", key, err)
+	}
+
+	return nil
+}
+
+// place determines the cluster host a VM should be launched on, honoring any
+// explicit schedule. Otherwise, the schedulable host with the fewest VMs is
+// used, which mirrors minimega's default scheduler. It assumes the Fake's lock
+// is held.
+func (f *Fake) place(v *fakeVM) (string, error) {
+	if v.schedule != "" {
+		if !f.knownHost(v.schedule) {
+			return "", fmt.Errorf("scheduling VM %s on host %s: %w", v.Name, v.schedule, ErrHostNotFound)
+		}
+
+		return v.schedule, nil
+	}
+
+	var hosts Hosts
+
+	for _, host := range f.schedulable() {
+		hosts = append(hosts, f.hostUsage(host))
+	}
+
+	if len(hosts) == 0 {
+		return "", errors.New("no schedulable cluster hosts")
+	}
+
+	sort.SliceStable(hosts, func(i, j int) bool {
+		if hosts[i].VMs == hosts[j].VMs {
+			return hosts[i].Name < hosts[j].Name
+		}
+
+		return hosts[i].VMs < hosts[j].VMs
+	})
+
+	return hosts[0].Name, nil
+}
+
+// allocateVLAN returns the VLAN ID for the given alias in the given namespace,
+// allocating a new one from the namespace (or global) VLAN range if needed. It
+// assumes the Fake's lock is held.
+func (f *Fake) allocateVLAN(ns *fakeNamespace, alias string) (int, error) {
+	if id, ok := ns.vlans[alias]; ok {
+		return id, nil
+	}
+
+	minID, maxID := f.vlanMin, f.vlanMax
+
+	if ns.vlanMin != 0 && ns.vlanMax != 0 {
+		minID, maxID = ns.vlanMin, ns.vlanMax
+	}
+
+	for id := minID; id <= maxID; id++ {
+		if f.vlanOwner(id) == "" {
+			ns.vlans[alias] = id
+
+			return id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no VLANs available in range %d-%d for alias %s", minID, maxID, alias)
+}
+
+// vlanOwner returns the `namespace//alias` using the given VLAN ID, or an empty
+// string if it's not in use. It assumes the Fake's lock is held.
+func (f *Fake) vlanOwner(id int) string {
+	for _, n := range f.namespaces {
+		for alias, other := range n.vlans {
+			if other == id {
+				return n.name + "//" + alias
+			}
+		}
+	}
+
+	return ""
+}
+
+// hostUsage returns a copy of the given host updated with the resources
+// committed to it by VMs across all namespaces. It assumes the Fake's lock is
+// held.
+func (f *Fake) hostUsage(host Host) Host {
+	for _, n := range f.namespaces {
+		for _, v := range n.vms {
+			if !v.launched || v.Host != host.Name {
+				continue
+			}
+
+			host.VMs++
+			host.CPUCommit += v.CPUs
+			host.MemCommit += v.RAM
+
+			if v.Running {
+				host.MemUsed += v.RAM
+			}
+		}
+	}
+
+	host.Load = []string{"0.00", "0.00", "0.00"}
+	host.Bandwidth = "0.0/0.0 (rx/tx MB/s)"
+
+	return host
+}
+
+func (f *Fake) schedulable() []Host {
+	hosts := slices.Clone(f.hosts)
+
+	if f.headnode.Schedulable {
+		hosts = append(hosts, f.headnode)
+	}
+
+	return hosts
+}
+
+func (f *Fake) knownHost(name string) bool {
+	name = common.TrimHostnameSuffixes(name)
+
+	if name == f.headnode.Name {
+		return true
+	}
+
+	return slices.ContainsFunc(f.hosts, func(h Host) bool { return h.Name == name })
+}
+
+func (f *Fake) vmCount() int {
+	var count int
+
+	for _, n := range f.namespaces {
+		count += len(n.vms)
+	}
+
+	return count
+}
+
+func (f *Fake) start(v *fakeVM) {
+	if !v.launched || v.Running {
+		return
+	}
+
+	v.State = "RUNNING"
+	v.Running = true
+	v.started = time.Now()
+	v.c2Active = !f.c2Disabled
+}
+
+// setVMState applies a `vm start`, `vm stop` or `vm kill` command to the
+// targeted VMs. Unlike KillVM, killing a VM this way leaves it in the namespace
+// in the QUIT state, as minimega does until the VM is flushed. It assumes the
+// Fake's lock is held.
+func (f *Fake) setVMState(ns, action, name string) error {
+	vms, err := f.targetVMs(ns, name)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range vms {
+		switch action {
+		case "start":
+			f.start(v)
+		case "stop":
+			if v.Running {
+				v.State = "PAUSED"
+				v.Running = false
+			}
+		case "kill":
+			v.State = "QUIT"
+			v.Running = false
+		}
+	}
+
+	return nil
+}
+
+// vmInfoResponses builds the tabular `vm info` responses for the launched VMs
+// in the given namespace, one response per host, keeping only the VMs that
+// match every `column=value` filter. It assumes the Fake's lock is held.
+func (f *Fake) vmInfoResponses(ns string, filters []string) minicli.Responses {
+	header := []string{"id", "name", "state", "type", "disks", "snapshot", "pid"}
+
+	var (
+		hosts []string
+		rows  = make(map[string][][]string)
+	)
+
+	matches := func(row []string) bool {
+		for _, filter := range filters {
+			column, value, _ := strings.Cut(filter, "=")
+
+			if idx := slices.Index(header, column); idx < 0 || row[idx] != value {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	for _, n := range f.sortedNamespaces(ns) {
+		for _, v := range n.vms {
+			if !v.launched {
+				continue
+			}
+
+			// The VM ID stands in for the PID, as it does in ExecContainer.
+			row := []string{
+				strconv.Itoa(v.ID), v.Name, v.State, v.vmType, v.Disk,
+				strconv.FormatBool(v.Snapshot), strconv.Itoa(v.ID),
+			}
+
+			if !matches(row) {
+				continue
+			}
+
+			if _, ok := rows[v.Host]; !ok {
+				hosts = append(hosts, v.Host)
+			}
+
+			rows[v.Host] = append(rows[v.Host], row)
+		}
+	}
+
+	resps := make(minicli.Responses, len(hosts))
+
+	for i, host := range hosts {
+		resps[i] = &minicli.Response{Host: host, Header: header, Tabular: rows[host]} //nolint:exhaustruct // partial initialization
+	}
+
+	return resps
+}
+
+func (f *Fake) execC2(ns, vm, command string) (string, error) {
+	stdout, stderr, err := f.c2(ns, vm, command)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	n := f.namespace(ns, true)
+
+	f.c2NextID++
+	id := strconv.Itoa(f.c2NextID)
+
+	f.record(n, "cc filter name="+vm)
+	f.record(n, "cc "+command)
+
+	n.c2Responses[id] = fakeC2Command{vm: vm, stdout: stdout, stderr: stderr}
+
+	return id, nil
+}
+
+// findVM returns the launched VM with the given name in the given namespace.
+// It assumes the Fake's lock is held.
+func (f *Fake) findVM(ns, name string) (*fakeVM, error) {
+	n, ok := f.namespaces[ns]
+	if !ok {
+		return nil, ErrVMNotFound
+	}
+
+	for _, v := range n.vms {
+		if v.Name == name {
+			return v, nil
+		}
+	}
+
+	return nil, ErrVMNotFound
+}
+
+// targetVMs returns the VMs targeted by a command in the given namespace, where
+// the name `all` targets every VM. It assumes the Fake's lock is held.
+func (f *Fake) targetVMs(ns, name string) ([]*fakeVM, error) {
+	if name != "all" {
+		v, err := f.findVM(ns, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return []*fakeVM{v}, nil
+	}
+
+	n, ok := f.namespaces[ns]
+	if !ok {
+		return nil, nil
+	}
+
+	return slices.Clone(n.vms), nil
+}
+
+func (f *Fake) namespace(name string, create bool) *fakeNamespace {
+	n, ok := f.namespaces[name]
+	if !ok && create {
+		n = &fakeNamespace{ //nolint:exhaustruct // partial initialization
+			name:        name,
+			vlans:       make(map[string]int),
+			c2Responses: make(map[string]fakeC2Command),
+		}
+
+		f.namespaces[name] = n
+	}
+
+	return n
+}
+
+func (f *Fake) sortedNamespaces(ns string) []*fakeNamespace {
+	if ns != "" {
+		if n, ok := f.namespaces[ns]; ok {
+			return []*fakeNamespace{n}
+		}
+
+		return nil
+	}
+
+	names := slices.Collect(maps.Keys(f.namespaces))
+	sort.Strings(names)
+
+	namespaces := make([]*fakeNamespace, len(names))
+
+	for i, name := range names {
+		namespaces[i] = f.namespaces[name]
+	}
+
+	return namespaces
+}
+
+func (f *Fake) record(ns *fakeNamespace, command string) {
+	if ns != nil {
+		command = fmt.Sprintf("namespace %s %s", ns.name, command)
+	}
+
+	f.history = append(f.history, command)
+}
+
+func (n *fakeNamespace) vmCaptures(vm string) []Capture {
+	var captures []Capture
+
+	for _, capture := range n.captures {
+		if capture.VM == vm {
+			captures = append(captures, capture)
+		}
+	}
+
+	return captures
+}
+
+func (n *fakeNamespace) fakeTunnels(o options) []map[string]string {
+	var tunnels []map[string]string
+
+	for _, t := range n.tunnels {
+		if o.vm != "" && t["vm"] != o.vm {
+			continue
+		}
+
+		if o.dstHost != "" && t["dst"] != o.dstHost {
+			continue
+		}
+
+		if o.dstPort != 0 && t["dst port"] != strconv.Itoa(o.dstPort) {
+			continue
+		}
+
+		tunnels = append(tunnels, t)
+	}
+
+	return tunnels
+}
+
+// parseFakeNet parses a single `vm config net` spec, which is in the form of
+// `[bridge,]vlan[,mac][,driver][,qinq]`.
+func parseFakeNet(spec string) fakeNet {
+	var (
+		tokens = strings.Split(spec, ",")
+		net    = fakeNet{bridge: "mega_bridge", alias: tokens[0]} //nolint:exhaustruct // partial initialization
+	)
+
+	if len(tokens) > 1 {
+		net.bridge = tokens[0]
+		net.alias = tokens[1]
+
+		for _, token := range tokens[2:] {
+			if fakeMACRegex.MatchString(token) {
+				net.mac = token
+			}
+		}
+	}
+
+	return net
+}
+
+// fakeFields splits a minimega command into fields, respecting double quotes.
+func fakeFields(s string) []string {
+	var (
+		fields []string
+		field  strings.Builder
+		quoted bool
+		inside bool
+	)
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inside = true
+		case r == ' ' && !quoted:
+			if inside {
+				fields = append(fields, field.String())
+				field.Reset()
+
+				inside = false
+			}
+		default:
+			field.WriteRune(r)
+
+			inside = true
+		}
+	}
+
+	if inside {
+		fields = append(fields, field.String())
+	}
+
+	return fields
+}
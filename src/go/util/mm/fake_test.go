@@ -0,0 +1,388 @@
+package mm_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"phenix/api/config"
+	"phenix/api/experiment"
+	"phenix/store"
+	"phenix/util/mm"
+	"phenix/util/mm/mmcli"
+)
+
+const fakeScript = `namespace test
+ns queueing true
+vlans range 200 210
+vlans add MGMT 205
+
+## VM: host-00 ##
+clear vm config
+vm config schedule compute2
+vm config vcpus 2
+vm config memory 4096
+vm config disk linux.qc2,writeback
+vm config net phenix,MGMT phenix,EXP,00:11:22:33:44:55
+vm config tags "role" "plc"
+vm launch kvm host-00
+
+## VM: host-01 ##
+clear vm config
+vm config vcpus 4
+vm config memory 8192
+vm config disk windows.qc2
+vm config net phenix,EXP
+vm launch kvm host-01
+`
+
+func newFakeWithScript(t *testing.T, opts ...mm.FakeOption) *mm.Fake {
+	t.Helper()
+
+	script := filepath.Join(t.TempDir(), "test.mm")
+
+	if err := os.WriteFile(script, []byte(fakeScript), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts = append(
+		[]mm.FakeOption{mm.FakeHost("compute1", 16, 32768), mm.FakeHost("compute2", 16, 32768)},
+		opts...,
+	)
+
+	fake := mm.NewFake(opts...)
+
+	if err := fake.ReadScriptFromFile(script); err != nil {
+		t.Fatal(err)
+	}
+
+	return fake
+}
+
+func TestFakeLaunchLifecycle(t *testing.T) {
+	fake := newFakeWithScript(t)
+
+	if vms := fake.GetVMInfo(mm.NS("test")); len(vms) != 0 {
+		t.Fatalf("expected no VMs before launch, got %d", len(vms))
+	}
+
+	if err := fake.LaunchVMs("test", "host-00"); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := fake.GetVMState(mm.NS("test"), mm.VMName("host-00"))
+	if err != nil || state != "RUNNING" {
+		t.Fatalf("expected host-00 to be RUNNING, got %s (%v)", state, err)
+	}
+
+	state, _ = fake.GetVMState(mm.NS("test"), mm.VMName("host-01"))
+	if state != "PAUSED" {
+		t.Fatalf("expected host-01 to be PAUSED, got %s", state)
+	}
+
+	host, _ := fake.GetVMHost(mm.NS("test"), mm.VMName("host-00"))
+	if host != "compute2" {
+		t.Fatalf("expected host-00 to be scheduled on compute2, got %s", host)
+	}
+
+	host, _ = fake.GetVMHost(mm.NS("test"), mm.VMName("host-01"))
+	if host != "compute1" {
+		t.Fatalf("expected host-01 to be scheduled on compute1, got %s", host)
+	}
+
+	vms := fake.GetVMInfo(mm.NS("test"), mm.VMName("host-00"))
+	if len(vms) != 1 {
+		t.Fatalf("expected 1 VM, got %d", len(vms))
+	}
+
+	if vms[0].Networks[0] != "MGMT (205)" || vms[0].Networks[1] != "EXP (200)" {
+		t.Fatalf("unexpected networks %v", vms[0].Networks)
+	}
+
+	if vms[0].Tags["role"] != "plc" {
+		t.Fatalf("expected role tag to be set, got %v", vms[0].Tags)
+	}
+
+	hosts, _ := fake.GetClusterHosts(true)
+
+	compute2 := hosts.FindHostByName("compute2")
+	if compute2 == nil || compute2.VMs != 1 || compute2.CPUCommit != 2 || compute2.MemCommit != 4096 {
+		t.Fatalf("unexpected compute2 usage %+v", compute2)
+	}
+
+	if err := fake.StopVM(mm.NS("test"), mm.VMName("host-00")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.StopVM(mm.NS("test"), mm.VMName("host-00")); err == nil {
+		t.Fatal("expected error stopping a paused VM")
+	}
+
+	if err := fake.KillVM(mm.NS("test"), mm.VMName("host-01")); err != nil {
+		t.Fatal(err)
+	}
+
+	if vms := fake.GetVMInfo(mm.NS("test")); len(vms) != 1 {
+		t.Fatalf("expected 1 VM after kill, got %d", len(vms))
+	}
+
+	if err := fake.ClearNamespace("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.Namespaces()) != 0 {
+		t.Fatalf("expected no namespaces, got %v", fake.Namespaces())
+	}
+}
+
+func TestFakeVLANsDoNotOverlap(t *testing.T) {
+	fake := mm.NewFake(mm.FakeVLANRange(100, 101))
+
+	for _, ns := range []string{"a", "b"} {
+		if err := fake.TapVLAN(mm.TapNS(ns), mm.TapName(ns+"-tap"), mm.TapVLANAlias("EXP"), mm.TapHost(fake.Headnode())); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vlans, _ := fake.GetVLANs()
+	if vlans["a//EXP"] != 100 || vlans["b//EXP"] != 101 {
+		t.Fatalf("unexpected VLAN allocations %v", vlans)
+	}
+
+	err := fake.TapVLAN(mm.TapNS("c"), mm.TapName("c-tap"), mm.TapVLANAlias("EXP"), mm.TapHost(fake.Headnode()))
+	if err == nil {
+		t.Fatal("expected error when VLAN range is exhausted")
+	}
+}
+
+func TestFakeC2(t *testing.T) {
+	responder := func(_, vm, command string) (string, string, error) {
+		if command == "exec fail" {
+			return "", "", errors.New("boom")
+		}
+
+		return vm + ": " + command, "warning", nil
+	}
+
+	fake := newFakeWithScript(t, mm.FakeC2(responder), mm.FakeC2Disabled())
+
+	if err := fake.LaunchVMs("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []mm.C2Option{mm.C2NS("test"), mm.C2VM("host-00"), mm.C2Timeout(100 * time.Millisecond)}
+
+	if err := fake.IsC2ClientActive(opts...); !errors.Is(err, mm.ErrC2ClientNotActive) {
+		t.Fatalf("expected inactive C2 client, got %v", err)
+	}
+
+	if err := fake.SetC2Active("test", "host-00", true); err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := fake.ExecC2Command(append(opts, mm.C2Command("hostname"))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := fake.WaitForC2Response(mm.C2NS("test"), mm.C2CommandID(id))
+	if err != nil || resp != "host-00: exec hostnamewarning" {
+		t.Fatalf("unexpected response %q (%v)", resp, err)
+	}
+
+	resp, _ = fake.GetC2Response(mm.C2NS("test"), mm.C2VM("host-00"), mm.C2CommandID(id), mm.C2ResponseTypeStderr())
+	if resp != "warning" {
+		t.Fatalf("unexpected stderr response %q", resp)
+	}
+
+	if _, err := fake.ExecC2Command(append(opts, mm.C2Command("fail"))...); err == nil {
+		t.Fatal("expected error from C2 responder")
+	}
+}
+
+func TestFakeCaptures(t *testing.T) {
+	fake := newFakeWithScript(t)
+
+	if err := fake.LaunchVMs("test"); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []mm.Option{mm.NS("test"), mm.VMName("host-00"), mm.CaptureInterface(1), mm.CaptureFile("test/host-00.pcap")}
+
+	if err := fake.StartVMCapture(opts...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.StartVMCapture(opts...); !errors.Is(err, mm.ErrCaptureExists) {
+		t.Fatalf("expected capture exists error, got %v", err)
+	}
+
+	if captures := fake.GetExperimentCaptures(mm.NS("test")); len(captures) != 1 {
+		t.Fatalf("expected 1 capture, got %d", len(captures))
+	}
+
+	if err := fake.StopVMCapture(opts...); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fake.StopVMCapture(opts...); !errors.Is(err, mm.ErrNoCaptures) {
+		t.Fatalf("expected no captures error, got %v", err)
+	}
+}
+
+func TestFakeRun(t *testing.T) {
+	fake := newFakeWithScript(t)
+
+	if err := fake.LaunchVMs("test", "host-00"); err != nil {
+		t.Fatal(err)
+	}
+
+	orig := mm.DefaultMM
+	mm.DefaultMM = fake //nolint:reassign // testing
+
+	t.Cleanup(func() { mm.DefaultMM = orig }) //nolint:reassign // testing
+
+	cmd := mmcli.NewNamespacedCommand("test")
+	cmd.Command = "vm info"
+	cmd.Columns = []string{"host", "name", "state", "disks"}
+	cmd.Filters = []string{"name=host-00"}
+
+	status := mm.RunTabular(cmd)
+	if len(status) != 1 || status[0]["host"] != "compute2" || status[0]["state"] != "RUNNING" || status[0]["disks"] != "linux.qc2" {
+		t.Fatalf("unexpected vm info %v", status)
+	}
+
+	cmd = mmcli.NewNamespacedCommand("test")
+	cmd.Command = "vm kill host-00"
+
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unlike KillVM, a plain `vm kill` leaves the VM in place until it's flushed.
+	if state, _ := fake.GetVMState(mm.NS("test"), mm.VMName("host-00")); state != "QUIT" {
+		t.Fatalf("expected host-00 to be QUIT, got %s", state)
+	}
+
+	cmd.Command = "vm start missing"
+
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err == nil {
+		t.Fatal("expected error starting a missing VM")
+	}
+
+	cmd.Command = "disk snapshot linux.qc2 snap.qc2"
+
+	if err := mmcli.ErrorResponse(mm.Run(cmd)); err != nil {
+		t.Fatal(err)
+	}
+
+	history := fake.History()
+	if history[len(history)-1] != "namespace test disk snapshot linux.qc2 snap.qc2" {
+		t.Fatalf("expected disk snapshot to be recorded, got %v", history)
+	}
+}
+
+// TestFakeExperimentLifecycle drives an experiment through the experiment API
+// with the Fake standing in for minimega, as `--minimega.fake` does.
+func TestFakeExperimentLifecycle(t *testing.T) {
+	s, err := store.NewFromEndpoint("bolt://" + filepath.Join(t.TempDir(), "store.bdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := mm.NewFake(mm.FakeHost("compute1", 16, 32768))
+
+	origStore, origMM := store.DefaultStore, mm.DefaultMM
+	store.DefaultStore = s //nolint:reassign // testing
+	mm.DefaultMM = fake    //nolint:reassign // testing
+
+	t.Cleanup(func() {
+		_ = s.Close()
+		store.DefaultStore = origStore //nolint:reassign // testing
+		mm.DefaultMM = origMM          //nolint:reassign // testing
+	})
+
+	// The VM won't be booted unless its disk image exists.
+	image := filepath.Join(t.TempDir(), "linux.qc2")
+
+	if err := os.WriteFile(image, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	topo := &store.Config{ //nolint:exhaustruct // partial initialization
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "Topology",
+		Metadata: store.ConfigMetadata{Name: "lifecycle"}, //nolint:exhaustruct // partial initialization
+		Spec: map[string]any{
+			"nodes": []any{
+				map[string]any{
+					"type":    "VirtualMachine",
+					"general": map[string]any{"hostname": "vm"},
+					"hardware": map[string]any{
+						"os_type": "linux",
+						"vcpus":   1,
+						"memory":  512,
+						"drives":  []any{map[string]any{"image": image}},
+					},
+				},
+			},
+		},
+	}
+
+	if _, err := config.Create(config.CreateFromConfig(topo), config.CreateWithValidation()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	err = experiment.Create(
+		ctx,
+		experiment.CreateWithName("lifecycle"),
+		experiment.CreateWithTopology("lifecycle"),
+		experiment.CreateWithBaseDirectory(t.TempDir()),
+	)
+	if err != nil {
+		t.Fatalf("creating experiment: %v", err)
+	}
+
+	if err := experiment.Start(ctx, experiment.StartWithName("lifecycle")); err != nil {
+		t.Fatalf("starting experiment: %v", err)
+	}
+
+	if !experiment.Running("lifecycle") {
+		t.Fatal("expected experiment to be running after start")
+	}
+
+	// Old C2 responses are deleted from the cluster before the experiment starts.
+	if !slices.Contains(fake.History(), "file delete lifecycle/miniccc_responses") {
+		t.Fatalf("expected C2 responses to be deleted, got %v", fake.History())
+	}
+
+	if state, err := mm.GetVMState(mm.NS("lifecycle"), mm.VMName("vm")); err != nil || state != "RUNNING" {
+		t.Fatalf("expected vm to be RUNNING, got %s (%v)", state, err)
+	}
+
+	if err := experiment.Stop("lifecycle"); err != nil {
+		t.Fatalf("stopping experiment: %v", err)
+	}
+
+	if experiment.Running("lifecycle") {
+		t.Fatal("expected experiment to be stopped after stop")
+	}
+
+	if vms := mm.GetVMInfo(mm.NS("lifecycle")); len(vms) != 0 {
+		t.Fatalf("expected no VMs after stop, got %d", len(vms))
+	}
+
+	if err := experiment.Delete("lifecycle"); err != nil {
+		t.Fatalf("deleting experiment: %v", err)
+	}
+
+	if _, err := experiment.Get("lifecycle"); err == nil {
+		t.Fatal("expected experiment to be gone after delete")
+	}
+}
@@ -15,11 +15,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/activeshadow/libminimega/miniclient"
 	"github.com/hashicorp/go-multierror"
 
 	"phenix/util/common"
 	"phenix/util/mm/mmcli"
 	"phenix/util/plog"
+	"phenix/util/shell"
 )
 
 var (
@@ -885,6 +887,7 @@ func (m Minimega) GetClusterHosts(schedOnly bool) (Hosts, error) {
 		// Add disk info
 		host.DiskUsage.Phenix = m.getDiskUsage(host.Name, common.PhenixBase)
 		host.DiskUsage.Minimega = m.getDiskUsage(host.Name, common.MinimegaBase)
+		host.DiskUsage.MinimegaFree = m.getDiskFree(host.Name, common.MinimegaBase)
 
 		cluster = append(cluster, host)
 	}
@@ -898,6 +901,7 @@ func (m Minimega) GetClusterHosts(schedOnly bool) (Hosts, error) {
 	// Add disk info
 	head.DiskUsage.Phenix = m.getDiskUsage(head.Name, common.PhenixBase)
 	head.DiskUsage.Minimega = m.getDiskUsage(head.Name, common.MinimegaBase)
+	head.DiskUsage.MinimegaFree = m.getDiskFree(head.Name, common.MinimegaBase)
 
 	cluster = append(cluster, head)
 
@@ -916,6 +920,7 @@ func (m Minimega) GetNamespaceHosts(ns string) (Hosts, error) {
 		// Add disk info
 		host.DiskUsage.Phenix = m.getDiskUsage(host.Name, common.PhenixBase)
 		host.DiskUsage.Minimega = m.getDiskUsage(host.Name, common.MinimegaBase)
+		host.DiskUsage.MinimegaFree = m.getDiskFree(host.Name, common.MinimegaBase)
 
 		hosts = append(hosts, host)
 	}
@@ -1487,6 +1492,38 @@ func (Minimega) MeshSend(ns, host, command string) error {
 	return nil
 }
 
+// ExecContainer runs the given command in the namespaces of the given running
+// container VM on the cluster host it's running on, returning the command's
+// output.
+func (Minimega) ExecContainer(opts ...Option) (string, error) {
+	o := NewOptions(opts...)
+
+	cmd := mmcli.NewNamespacedCommand(o.ns)
+	cmd.Command = vmInfoCmd
+	cmd.Columns = []string{"host", "pid", "state", "type"}
+	cmd.Filters = []string{"name=" + o.vm}
+
+	status := mmcli.RunTabular(cmd)
+
+	if len(status) == 0 {
+		return "", fmt.Errorf("vm %s not found", o.vm)
+	}
+
+	if status[0]["type"] != "container" {
+		return "", fmt.Errorf("vm %s is not a container", o.vm)
+	}
+
+	if status[0]["state"] != "RUNNING" {
+		return "", fmt.Errorf("vm %s is not running", o.vm)
+	}
+
+	return MeshShellResponse(status[0]["host"], containerExecCommand(status[0]["pid"], o.command))
+}
+
+func (Minimega) Run(cmd *mmcli.Command) chan *miniclient.Response {
+	return mmcli.Run(cmd)
+}
+
 // GetLocalMountPath returns where the mount path should be on this filesystem
 // for the given namespace and VM.
 func GetLocalMountPath(ns, vm string) string {
@@ -1550,6 +1587,15 @@ func getResponse(ns, id string) (string, error) {
 	return resp, nil
 }
 
+// containerExecCommand returns the shell command that runs the given command
+// in the namespaces of the container process with the given PID.
+func containerExecCommand(pid, command string) string {
+	return fmt.Sprintf(
+		"nsenter --target %s --mount --uts --ipc --net --pid -- /bin/sh -c %s",
+		pid, shell.Quote(command),
+	)
+}
+
 func flush(ns string) error {
 	cmd := mmcli.NewNamespacedCommand(ns)
 	cmd.Command = "vm flush"
@@ -1629,6 +1675,20 @@ func processNamespaceHosts(namespace string) Hosts {
 	return hosts
 }
 
+// Run shell command to get the bytes available to `path` on `host`.
+func (m Minimega) getDiskFree(host, path string) int64 {
+	cmd := fmt.Sprintf(`bash -c "echo $(df -B1 --output=avail %s | tail -1)"`, path)
+	resp, err := m.MeshShellResponse(host, cmd)
+
+	if (resp == "") || (err != nil) {
+		return 0
+	}
+
+	free, _ := strconv.ParseInt(strings.TrimSpace(resp), 10, 64)
+
+	return free
+}
+
 // Run shell command to get disk usage for `path` on `host`.
 func (m Minimega) getDiskUsage(host, path string) float64 {
 	diskUsage := 0.0
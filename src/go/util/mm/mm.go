@@ -1,5 +1,11 @@
 package mm
 
+import (
+	"github.com/activeshadow/libminimega/miniclient"
+
+	"phenix/util/mm/mmcli"
+)
+
 var DefaultMM MM = new(Minimega) //nolint:gochecknoglobals // default implementation
 
 type MM interface { //nolint:interfacebloat // legacy interface
@@ -52,4 +58,10 @@ type MM interface { //nolint:interfacebloat // legacy interface
 	MeshShell(string, string) error
 	MeshShellResponse(string, string) (string, error)
 	MeshSend(string, string, string) error
+
+	ExecContainer(...Option) (string, error)
+
+	// Run runs the given minimega command, for callers that don't have a more
+	// specific method to use.
+	Run(*mmcli.Command) chan *miniclient.Response
 }
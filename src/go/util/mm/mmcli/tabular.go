@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/activeshadow/libminimega/minicli"
+	"github.com/activeshadow/libminimega/miniclient"
 
 	"phenix/util/plog"
 )
@@ -54,6 +55,12 @@ func tabularToMapCols(columns []string) tabularToMapper {
 // be in tabular form. A slice of maps is returned, with each map representing a
 // row in the tabular response and each map key representing the column.
 func RunTabular(cmd *Command) []map[string]string {
+	return TabularResponse(cmd, Run(cmd))
+}
+
+// TabularResponse converts the tabular responses to the given command into a
+// slice of maps, as returned by RunTabular.
+func TabularResponse(cmd *Command, responses chan *miniclient.Response) []map[string]string {
 	// copy all fields in header order
 	mapper := tabularToMap
 
@@ -64,7 +71,7 @@ func RunTabular(cmd *Command) []map[string]string {
 
 	res := []map[string]string{}
 
-	for resps := range Run(cmd) {
+	for resps := range responses {
 		for _, resp := range resps.Resp {
 			if resp.Error != "" {
 				plog.Error(
@@ -32,6 +32,8 @@ type options struct {
 
 	screenshotSize string
 
+	command string
+
 	// tunnels
 	srcPort int
 	dstPort int
@@ -138,6 +140,12 @@ func ScreenshotSize(s string) Option {
 	}
 }
 
+func Command(c string) Option {
+	return func(o *options) {
+		o.command = c
+	}
+}
+
 func TunnelSourcePort(p int) Option {
 	return func(o *options) {
 		o.srcPort = p
@@ -1,5 +1,11 @@
 package mm
 
+import (
+	"github.com/activeshadow/libminimega/miniclient"
+
+	"phenix/util/mm/mmcli"
+)
+
 func ReadScriptFromFile(filename string) error {
 	return DefaultMM.ReadScriptFromFile(filename)
 }
@@ -155,3 +161,15 @@ func MeshShellResponse(host, cmd string) (string, error) {
 func MeshSend(ns, host, command string) error {
 	return DefaultMM.MeshSend(ns, host, command)
 }
+
+func ExecContainer(opts ...Option) (string, error) {
+	return DefaultMM.ExecContainer(opts...)
+}
+
+func Run(cmd *mmcli.Command) chan *miniclient.Response {
+	return DefaultMM.Run(cmd)
+}
+
+func RunTabular(cmd *mmcli.Command) []map[string]string {
+	return mmcli.TabularResponse(cmd, DefaultMM.Run(cmd))
+}
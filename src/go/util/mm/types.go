@@ -144,6 +144,10 @@ type Host struct {
 type DiskUsage struct {
 	Phenix   float64 `json:"diskphenix"`
 	Minimega float64 `json:"diskminimega"`
+
+	// MinimegaFree is the number of bytes available in the minimega base
+	// directory, or 0 if it couldn't be determined.
+	MinimegaFree int64 `json:"freeminimega"`
 }
 
 type VMs []VM
@@ -210,6 +214,7 @@ type VM struct {
 	ID              int               `json:"id"`
 	Name            string            `json:"name"`
 	Type            string            `json:"type"`
+	VMType          string            `json:"vmType"`
 	Experiment      string            `json:"experiment"`
 	Host            string            `json:"host"`
 	IPv4            []string          `json:"ipv4"`
@@ -246,6 +251,12 @@ type VM struct {
 	UUID string `json:"-"`
 }
 
+// IsContainer returns true if the VM is a minimega container. Containers don't
+// have a screen, so there's no VNC console or screenshots for them.
+func (v VM) IsContainer() bool {
+	return v.VMType == "container"
+}
+
 // Copy returns a deep copy of the VM. It only makes deep copies of fields that
 // are exported as JSON.
 func (v VM) Copy() VM {
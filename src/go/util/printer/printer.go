@@ -11,6 +11,12 @@ import (
 
 	"github.com/olekukonko/tablewriter"
 
+	"phenix/api/backup"
+	"phenix/api/checkpoint"
+	"phenix/api/config"
+	"phenix/api/importer"
+	"phenix/api/lint"
+	"phenix/scheduler"
 	"phenix/store"
 	"phenix/types"
 	"phenix/util/mm"
@@ -35,6 +41,126 @@ func PrintTableOfConfigs(writer io.Writer, configs store.Configs) {
 	table.Render()
 }
 
+// PrintTableOfConfigRevisions writes the given config revisions to the given
+// writer as an ASCII table. The table headers are set to Revision, Action,
+// Author, and Timestamp.
+func PrintTableOfConfigRevisions(writer io.Writer, revs []config.Revision) {
+	table := tablewriter.NewWriter(writer)
+
+	table.SetHeader([]string{"Revision", "Action", "Author", "Timestamp"})
+
+	for _, r := range revs {
+		table.Append([]string{strconv.Itoa(r.Revision), string(r.Action), r.Author, r.Timestamp})
+	}
+
+	table.Render()
+}
+
+// PrintTableOfLintFindings writes the given topology lint findings to the
+// given writer as an ASCII table.
+func PrintTableOfLintFindings(writer io.Writer, findings lint.Findings) {
+	table := tablewriter.NewWriter(writer)
+
+	table.SetHeader([]string{"Severity", "Path", "Message", "Rule"})
+	table.SetColWidth(colWidth)
+
+	for _, f := range findings {
+		table.Append([]string{string(f.Severity), f.Path, f.Message, f.Rule})
+	}
+
+	table.Render()
+}
+
+// PrintTableOfImportIssues writes the given topology import issues to the
+// given writer as an ASCII table.
+func PrintTableOfImportIssues(writer io.Writer, issues []importer.Issue) {
+	table := tablewriter.NewWriter(writer)
+
+	table.SetHeader([]string{"Element", "Issue"})
+	table.SetColWidth(colWidth)
+
+	for _, i := range issues {
+		table.Append([]string{i.Element, i.Message})
+	}
+
+	table.Render()
+}
+
+// PrintTableOfRestoreResults writes the given store restore results to the
+// given writer as an ASCII table.
+func PrintTableOfRestoreResults(writer io.Writer, results []backup.RestoreResult) {
+	table := tablewriter.NewWriter(writer)
+
+	table.SetHeader([]string{"Kind", "Name", "Action", "Restored As", "Upgraded From"})
+
+	for _, r := range results {
+		table.Append([]string{r.Kind, r.Name, string(r.Action), r.NewName, r.UpgradedFrom})
+	}
+
+	table.Render()
+}
+
+// PrintTableOfScheduleReport writes the given schedule preview report to the
+// given writer as two ASCII tables, one with the placement of each VM and one
+// with the resulting totals for each cluster host.
+func PrintTableOfScheduleReport(writer io.Writer, report *scheduler.Report) {
+	table := tablewriter.NewWriter(writer)
+
+	table.SetHeader([]string{"VM", "Host", "Reason"})
+	table.SetColWidth(colWidth)
+
+	for _, p := range report.Placements {
+		table.Append([]string{p.VM, p.Host, p.Reason})
+	}
+
+	table.Render()
+
+	table = tablewriter.NewWriter(writer)
+
+	table.SetHeader([]string{"Host", "VMs", "vCPUs", "Memory (MB)", "Total vCPUs", "Total Memory (MB)"})
+
+	for _, h := range report.Hosts {
+		table.Append([]string{
+			h.Host,
+			strconv.Itoa(h.VMs),
+			strconv.Itoa(h.CPU),
+			strconv.Itoa(h.Memory),
+			fmt.Sprintf("%d / %d", h.CommittedCPU+h.CPU, h.CPUs),
+			fmt.Sprintf("%d / %d", h.CommittedMemory+h.Memory, h.MemTotal),
+		})
+	}
+
+	table.Render()
+}
+
+// PrintTableOfCheckpoints writes the given experiment checkpoints to the given
+// writer as an ASCII table. The table headers are set to Name, Created, VMs and
+// Apps.
+func PrintTableOfCheckpoints(writer io.Writer, manifests []checkpoint.Manifest) {
+	table := tablewriter.NewWriter(writer)
+
+	table.SetHeader([]string{"Name", "Created", "VMs", "Apps"})
+
+	for _, m := range manifests {
+		apps := make([]string, 0, len(m.AppStatus))
+
+		for app := range m.AppStatus {
+			apps = append(apps, app)
+		}
+
+		sort.Strings(apps)
+
+		table.Append([]string{
+			m.Name,
+			m.Created.Format(time.RFC3339),
+			strconv.Itoa(len(m.VMs)),
+			strings.Join(apps, ", "),
+		})
+	}
+
+	table.Render()
+}
+
 // PrintTableOfExperiments writes the given experiments to the given writer as
 // an ASCII table. The table headers are set to Name, Topology, Scenario,
 // Started, VM Count, VLAN Count, and Apps.
@@ -0,0 +1,10 @@
+package shell
+
+import "strings"
+
+// Quote returns the given string quoted so a POSIX shell treats it as a single
+// word with no expansion. It's wrapped in single quotes, with any single quotes
+// in it closed, escaped and reopened.
+func Quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,33 @@
+package shell_test
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+
+	"phenix/util/shell"
+)
+
+func TestQuote(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	for _, s := range []string{
+		"",
+		"echo hello",
+		`it's "quoted"`,
+		"$HOME `id` $(id) \\n; exit 1",
+		"'",
+		"a'b'c",
+	} {
+		out, err := exec.CommandContext(context.Background(), "sh", "-c", "printf %s "+shell.Quote(s)).Output()
+		if err != nil {
+			t.Fatalf("running quoted %q: %v", s, err)
+		}
+
+		if string(out) != s {
+			t.Errorf("expected quoted %q to round trip, got %q", s, out)
+		}
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"maps"
+	"slices"
 	"strings"
 
 	"phenix/api/vm"
 	"phenix/app"
+	"phenix/store"
+	"phenix/types/version"
 	putil "phenix/util"
 	"phenix/util/pubsub"
 	bt "phenix/web/broker/brokertypes"
@@ -27,8 +31,21 @@ func Start() {
 	triggerSub := pubsub.Subscribe("trigger-app")
 	delayedSub := pubsub.Subscribe("delayed-start")
 
+	// Watching the store (instead of relying on handlers to broadcast changes)
+	// ensures clients also see changes made by the CLI and by user apps.
+	storeSub := store.Watch(slices.Collect(maps.Keys(version.StoredVersion))...)
+
 	for {
 		select {
+		case event, ok := <-storeSub:
+			if !ok {
+				// The store was closed, so stop watching it.
+				storeSub = nil
+
+				continue
+			}
+
+			broadcast <- configPublication(event)
 		case pub := <-triggerSub:
 			var (
 				trigger, _ = pub.(app.TriggerPublication)
@@ -46,22 +63,7 @@ func Start() {
 				resource.Name = trigger.Resource
 			}
 
-			if trigger.State == "error" {
-				var (
-					humanized *putil.HumanizedError
-					result    []byte
-				)
-
-				if errors.As(trigger.Error, &humanized) {
-					result, _ = json.Marshal(map[string]any{"error": humanized.Humanized()})
-				} else {
-					result, _ = json.Marshal(map[string]any{"error": trigger.Error.Error()})
-				}
-
-				broadcast <- bt.Publish{RequestPolicy: policy, Resource: resource, Result: result}
-			} else {
-				broadcast <- bt.Publish{RequestPolicy: policy, Resource: resource, Result: nil}
-			}
+			broadcast <- bt.Publish{RequestPolicy: policy, Resource: resource, Result: triggerResult(trigger)}
 		case pub := <-delayedSub:
 			delayed, _ := pub.(string)
 			names := strings.Split(delayed, "/")
@@ -126,3 +128,62 @@ func Start() {
 func Broadcast(policy *bt.RequestPolicy, resource *bt.Resource, msg json.RawMessage) {
 	broadcast <- bt.Publish{RequestPolicy: policy, Resource: resource, Result: msg}
 }
+
+// configPublication converts the given store event into a publication for
+// clients allowed to list the config. Specs and statuses are left out since
+// clients only need metadata to update config listings.
+func configPublication(event store.Event) bt.Publish {
+	var (
+		name     = event.FullName()
+		policy   = bt.NewRequestPolicy("configs", "list", name)
+		resource = bt.NewResource("config", name, string(event.Type))
+		result   []byte
+	)
+
+	if event.Config != nil {
+		c := *event.Config
+
+		c.Spec = nil
+		c.Status = nil
+
+		result, _ = json.Marshal(c)
+	}
+
+	return bt.Publish{RequestPolicy: policy, Resource: resource, Result: result}
+}
+
+// triggerResult returns the result to broadcast for the given triggered app
+// event, which includes the error (if any) and, when the app is being applied
+// for a lifecycle stage, the stage and attempt details.
+func triggerResult(trigger app.TriggerPublication) []byte {
+	result := make(map[string]any)
+
+	if trigger.Error != nil {
+		var humanized *putil.HumanizedError
+
+		if errors.As(trigger.Error, &humanized) {
+			result["error"] = humanized.Humanized()
+		} else {
+			result["error"] = trigger.Error.Error()
+		}
+	}
+
+	if trigger.Stage != "" {
+		result["stage"] = trigger.Stage
+		result["attempt"] = trigger.Attempt
+		result["maxAttempts"] = trigger.MaxAttempts
+		result["onFailure"] = trigger.OnFailure
+	}
+
+	if trigger.Message != "" {
+		result["message"] = trigger.Message
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	body, _ := json.Marshal(result)
+
+	return body
+}
@@ -103,7 +103,13 @@ func CreateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 	topo.Metadata.Annotations = store.Annotations{"builder-xml": req.XML}
 	topo.Spec = req.Topology
 
-	config, err := config.Create(config.CreateFromConfig(topo), config.CreateWithValidation())
+	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+
+	_, err = config.Create(
+		config.CreateFromConfig(topo),
+		config.CreateWithValidation(),
+		config.CreateWithAuthor(user),
+	)
 	if err != nil {
 		if errors.Is(err, store.ErrExist) {
 			return weberror.NewWebError(err, "topology with same name already exists").
@@ -123,24 +129,6 @@ func CreateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 			WithMetadata("type", "topology", true)
 	}
 
-	// publish new topology
-
-	config.Spec = nil
-	config.Status = nil
-
-	body, err = json.Marshal(config)
-	if err != nil {
-		err := weberror.NewWebError(err, "marshaling topology %s", req.Name)
-
-		return err.SetStatus(http.StatusInternalServerError)
-	}
-
-	broker.Broadcast(
-		bt.NewRequestPolicy("configs", "list", config.FullName()),
-		bt.NewResource("config", config.FullName(), "create"),
-		body,
-	)
-
 	if err := cache.LockExperimentForCreation(req.Name); err != nil {
 		err := weberror.NewWebError(err, "locking experiment for creation")
 
@@ -179,6 +167,7 @@ func CreateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 		experiment.CreateWithTopology(req.Name),
 		experiment.CreateWithScenario(req.Scenario),
 		experiment.CreateWithVLANAliases(req.VLANs),
+		experiment.CreateWithOwner(user),
 	}
 
 	if err := experiment.Create(ctx, opts...); err != nil {
@@ -215,17 +204,6 @@ func CreateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 		return err.SetStatus(http.StatusInternalServerError)
 	}
 
-	config, _ = store.NewConfig("experiment/" + req.Name)
-	config.Metadata = exp.Metadata
-
-	body, _ = json.Marshal(config)
-
-	broker.Broadcast(
-		bt.NewRequestPolicy("configs", "list", config.FullName()),
-		bt.NewResource("config", config.FullName(), "create"),
-		body,
-	)
-
 	vms, _ := vm.List(req.Name)
 
 	body, err = marshaler.Marshal(util.ExperimentToProtobuf(*exp, "", vms))
@@ -241,7 +219,6 @@ func CreateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 		body,
 	)
 
-	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
 	plog.Info(
 		plog.TypeAction,
 		"created experiment from builder",
@@ -301,12 +278,26 @@ func UpdateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 	topo.Metadata.Annotations = store.Annotations{"builder-xml": req.XML}
 	topo.Spec = req.Topology
 
-	if err := config.Update(topo.FullName(), topo); err != nil {
+	// Fail with a conflict if the topology was modified since the builder loaded
+	// it (the builder sends the topology's ETag in the If-Match header).
+	if topo.Metadata.ResourceVersion, err = ifMatchVersion(r); err != nil {
+		return err
+	}
+
+	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+
+	if err := config.Update(topo.FullName(), topo, config.UpdateWithAuthor(user)); err != nil {
 		if errors.Is(err, store.ErrNotExist) {
 			return weberror.NewWebError(err, "topology with same name doesn't exist yet").
 				WithMetadata("type", "topology", true)
 		}
 
+		if errors.Is(err, store.ErrConflict) {
+			return weberror.NewWebError(err, "topology was modified by someone else").
+				WithMetadata("type", "topology", true).
+				SetStatus(http.StatusConflict)
+		}
+
 		if errors.Is(err, types.ErrValidationFailed) {
 			cause := errors.Unwrap(err)
 			lines := strings.Split(cause.Error(), "\n")
@@ -320,32 +311,7 @@ func UpdateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 			WithMetadata("type", "topology", true)
 	}
 
-	// Grab this now, before we clear the spec from the toplology config, just in
-	// case we need it later.
-	topoSpec, err := types.DecodeTopologyFromConfig(*topo)
-	if err != nil {
-		err := weberror.NewWebError(err, "decoding topology %s", req.Name)
-
-		return err.SetStatus(http.StatusInternalServerError)
-	}
-
-	// publish updated topology
-
-	topo.Spec = nil
-	topo.Status = nil
-
-	body, err = json.Marshal(topo)
-	if err != nil {
-		err := weberror.NewWebError(err, "marshaling topology %s", req.Name)
-
-		return err.SetStatus(http.StatusInternalServerError)
-	}
-
-	broker.Broadcast(
-		bt.NewRequestPolicy("configs", "list", topo.FullName()),
-		bt.NewResource("config", topo.FullName(), "update"),
-		body,
-	)
+	setETag(w, topo.Metadata.ResourceVersion)
 
 	// Create or update experiment using updated topology. It's possible that the
 	// topology already existed (so it's being updated), but an experiment with
@@ -411,12 +377,39 @@ func UpdateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 
 		defer cache.UnlockExperiment(req.Name)
 
-		// update existing experiment
+		// update existing experiment, rendering the topology with the parameters
+		// the experiment was created with
+
+		params, err := exp.TopologyParameters()
+		if err != nil {
+			err := weberror.NewWebError(err, "getting topology parameters for experiment %s", req.Name)
+
+			return err.SetStatus(http.StatusInternalServerError)
+		}
+
+		topoSpec, err := types.DecodeTopologyFromConfigWithParameters(*topo, params)
+		if err != nil {
+			status := http.StatusInternalServerError
+
+			if errors.Is(err, types.ErrInvalidTopologyParameter) {
+				status = http.StatusBadRequest
+			}
+
+			err := weberror.NewWebError(err, "decoding topology %s", req.Name)
+
+			return err.SetStatus(status)
+		}
 
 		exp.Spec.SetTopology(topoSpec)
 
 		err = exp.WriteToStore(false)
 		if err != nil {
+			if errors.Is(err, store.ErrConflict) {
+				err := weberror.NewWebError(err, "experiment %s was modified by someone else", req.Name)
+
+				return err.SetStatus(http.StatusConflict)
+			}
+
 			err := weberror.NewWebError(err, "updating experiment %s", req.Name)
 
 			return err.SetStatus(http.StatusInternalServerError)
@@ -461,6 +454,7 @@ func UpdateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 			experiment.CreateWithTopology(req.Name),
 			experiment.CreateWithScenario(req.Scenario),
 			experiment.CreateWithVLANAliases(req.VLANs),
+			experiment.CreateWithOwner(user),
 		}
 
 		err = experiment.Create(ctx, opts...)
@@ -499,22 +493,11 @@ func UpdateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 		return err.SetStatus(http.StatusInternalServerError)
 	}
 
-	config, _ := store.NewConfig("experiment/" + req.Name)
-	config.Metadata = exp.Metadata
-
-	body, _ = json.Marshal(config)
-
 	action := "create"
 	if exists {
 		action = "update"
 	}
 
-	broker.Broadcast(
-		bt.NewRequestPolicy("configs", "list", config.FullName()),
-		bt.NewResource("config", config.FullName(), action),
-		body,
-	)
-
 	vms, _ := vm.List(req.Name)
 
 	body, err = marshaler.Marshal(util.ExperimentToProtobuf(*exp, "", vms))
@@ -530,7 +513,6 @@ func UpdateExperimentFromBuilder(w http.ResponseWriter, r *http.Request) error {
 		body,
 	)
 
-	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
 	plog.Info(
 		plog.TypeAction,
 		"experiment updated from builder",
@@ -676,6 +658,7 @@ func GetBuilderTopology(w http.ResponseWriter, r *http.Request) error {
 
 	body := []byte(topology.Metadata.Annotations["builder-xml"])
 
+	setETag(w, topology.Metadata.ResourceVersion)
 	w.Header().Set("Content-Type", "application/xml")
 	_, _ = w.Write(body)
 
@@ -5,7 +5,13 @@ import (
 	"time"
 )
 
-const lockTimeout = 5 * time.Minute
+const (
+	lockTimeout = 5 * time.Minute
+
+	// Checkpointing or restoring an experiment snapshots or restores every VM,
+	// so it takes considerably longer than other experiment operations.
+	checkpointLockTimeout = 1 * time.Hour
+)
 
 func IsExperimentLocked(name string) Status {
 	key := "experiment|" + name
@@ -81,6 +87,26 @@ func LockExperimentForStopping(name string) error {
 	return nil
 }
 
+func LockExperimentForCheckpointing(name string) error {
+	key := "experiment|" + name
+
+	if status := Lock(key, StatusSnapshotting, checkpointLockTimeout); status != "" {
+		return fmt.Errorf("experiment %s is locked with status %s", name, status)
+	}
+
+	return nil
+}
+
+func LockExperimentForRestoring(name string) error {
+	key := "experiment|" + name
+
+	if status := Lock(key, StatusRestoring, checkpointLockTimeout); status != "" {
+		return fmt.Errorf("experiment %s is locked with status %s", name, status)
+	}
+
+	return nil
+}
+
 func LockVMForStarting(exp, name string) error {
 	key := fmt.Sprintf("vm|%s/%s", exp, name)
 
@@ -0,0 +1,280 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"phenix/api/checkpoint"
+	"phenix/util/plog"
+	"phenix/web/broker"
+	bt "phenix/web/broker/brokertypes"
+	"phenix/web/cache"
+	"phenix/web/middleware"
+	"phenix/web/util"
+	"phenix/web/weberror"
+)
+
+// GetExperimentCheckpoints - GET /experiments/{name}/checkpoints.
+func GetExperimentCheckpoints(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "GetExperimentCheckpoints")
+
+	var (
+		ctx  = r.Context()
+		role = middleware.RoleFromContext(ctx)
+		vars = mux.Vars(r)
+		name = vars["name"]
+	)
+
+	if !role.Allowed("experiments/checkpoints", "list", name) {
+		user := middleware.UserFromContext(ctx)
+		plog.Warn(
+			plog.TypeSecurity,
+			"listing experiment checkpoints not allowed",
+			"user",
+			user,
+			"exp",
+			name,
+		)
+		err := weberror.NewWebError(nil, "listing checkpoints for experiment %s not allowed for %s", name, user)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	manifests, err := checkpoint.List(name)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to list checkpoints for experiment %s", name)
+	}
+
+	body, err := json.Marshal(util.WithRoot("checkpoints", manifests))
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process checkpoints for experiment %s", name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
+
+// CreateExperimentCheckpoint - POST /experiments/{name}/checkpoints.
+//
+//nolint:funlen // handler
+func CreateExperimentCheckpoint(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "CreateExperimentCheckpoint")
+
+	var (
+		ctx  = r.Context()
+		role = middleware.RoleFromContext(ctx)
+		user = middleware.UserFromContext(ctx)
+		vars = mux.Vars(r)
+		name = vars["name"]
+	)
+
+	if !role.Allowed("experiments/checkpoints", "create", name) {
+		plog.Warn(
+			plog.TypeSecurity,
+			"creating experiment checkpoint not allowed",
+			"user",
+			user,
+			"exp",
+			name,
+		)
+		err := weberror.NewWebError(nil, "creating checkpoint for experiment %s not allowed for %s", name, user)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to read request body")
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		err := weberror.NewWebError(err, "unable to parse request body")
+
+		return err.SetStatus(http.StatusBadRequest)
+	}
+
+	if err := cache.LockExperimentForCheckpointing(name); err != nil {
+		err := weberror.NewWebError(err, "unable to lock experiment %s for checkpointing", name)
+
+		return err.SetStatus(http.StatusConflict)
+	}
+
+	defer cache.UnlockExperiment(name)
+
+	policy := bt.NewRequestPolicy("experiments/checkpoints", "create", name)
+
+	broker.Broadcast(policy, bt.NewResource("experiment/checkpoint", name+"/"+req.Name, "creating"), nil)
+
+	manifest, err := checkpoint.Create(name, req.Name, checkpoint.WithProgress(checkpointProgress(policy, name+"/"+req.Name)))
+	if err != nil {
+		broker.Broadcast(policy, bt.NewResource("experiment/checkpoint", name+"/"+req.Name, "errorCreating"), nil)
+
+		werr := weberror.NewWebError(err, "unable to checkpoint experiment %s", name)
+
+		if errors.Is(err, checkpoint.ErrCheckpointExists) {
+			return werr.SetStatus(http.StatusConflict)
+		}
+
+		return werr
+	}
+
+	body, err = json.Marshal(manifest)
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process checkpoint %s for experiment %s", req.Name, name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	broker.Broadcast(policy, bt.NewResource("experiment/checkpoint", name+"/"+req.Name, "create"), body)
+
+	plog.Info(plog.TypeAction, "experiment checkpoint created", "user", user, "exp", name, "checkpoint", req.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
+
+// RestoreExperimentCheckpoint - POST /experiments/{name}/checkpoints/{checkpoint}/restore.
+func RestoreExperimentCheckpoint(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "RestoreExperimentCheckpoint")
+
+	var (
+		ctx  = r.Context()
+		role = middleware.RoleFromContext(ctx)
+		user = middleware.UserFromContext(ctx)
+		vars = mux.Vars(r)
+		name = vars["name"]
+		cp   = vars["checkpoint"]
+	)
+
+	if !role.Allowed("experiments/checkpoints", "update", name) {
+		plog.Warn(
+			plog.TypeSecurity,
+			"restoring experiment checkpoint not allowed",
+			"user",
+			user,
+			"exp",
+			name,
+		)
+		err := weberror.NewWebError(nil, "restoring checkpoint for experiment %s not allowed for %s", name, user)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	if err := cache.LockExperimentForRestoring(name); err != nil {
+		err := weberror.NewWebError(err, "unable to lock experiment %s for restoring", name)
+
+		return err.SetStatus(http.StatusConflict)
+	}
+
+	defer cache.UnlockExperiment(name)
+
+	policy := bt.NewRequestPolicy("experiments/checkpoints", "update", name)
+
+	broker.Broadcast(policy, bt.NewResource("experiment/checkpoint", name+"/"+cp, "restoring"), nil)
+
+	manifest, err := checkpoint.Restore(name, cp, checkpoint.WithProgress(checkpointProgress(policy, name+"/"+cp)))
+	if err != nil {
+		broker.Broadcast(policy, bt.NewResource("experiment/checkpoint", name+"/"+cp, "errorRestoring"), nil)
+
+		werr := weberror.NewWebError(err, "unable to restore experiment %s from checkpoint %s", name, cp)
+
+		switch {
+		case errors.Is(err, checkpoint.ErrCheckpointNotFound):
+			return werr.SetStatus(http.StatusNotFound)
+		case errors.Is(err, checkpoint.ErrVLANMismatch):
+			return werr.SetStatus(http.StatusConflict)
+		}
+
+		return werr
+	}
+
+	body, err := json.Marshal(manifest)
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process checkpoint %s for experiment %s", cp, name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	broker.Broadcast(policy, bt.NewResource("experiment/checkpoint", name+"/"+cp, "restore"), body)
+
+	plog.Info(plog.TypeAction, "experiment checkpoint restored", "user", user, "exp", name, "checkpoint", cp)
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
+
+// DeleteExperimentCheckpoint - DELETE /experiments/{name}/checkpoints/{checkpoint}.
+func DeleteExperimentCheckpoint(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "DeleteExperimentCheckpoint")
+
+	var (
+		ctx  = r.Context()
+		role = middleware.RoleFromContext(ctx)
+		user = middleware.UserFromContext(ctx)
+		vars = mux.Vars(r)
+		name = vars["name"]
+		cp   = vars["checkpoint"]
+	)
+
+	if !role.Allowed("experiments/checkpoints", "delete", name) {
+		plog.Warn(
+			plog.TypeSecurity,
+			"deleting experiment checkpoint not allowed",
+			"user",
+			user,
+			"exp",
+			name,
+		)
+		err := weberror.NewWebError(nil, "deleting checkpoint for experiment %s not allowed for %s", name, user)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	if err := checkpoint.Delete(name, cp); err != nil {
+		werr := weberror.NewWebError(err, "unable to delete checkpoint %s for experiment %s", cp, name)
+
+		if errors.Is(err, checkpoint.ErrCheckpointNotFound) {
+			return werr.SetStatus(http.StatusNotFound)
+		}
+
+		return werr
+	}
+
+	broker.Broadcast(
+		bt.NewRequestPolicy("experiments/checkpoints", "delete", name),
+		bt.NewResource("experiment/checkpoint", name+"/"+cp, "delete"),
+		nil,
+	)
+
+	plog.Info(plog.TypeAction, "experiment checkpoint deleted", "user", user, "exp", name, "checkpoint", cp)
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+// checkpointProgress returns a function that publishes checkpoint progress
+// over the broker.
+func checkpointProgress(policy *bt.RequestPolicy, name string) func(checkpoint.Progress) {
+	return func(p checkpoint.Progress) {
+		body, _ := json.Marshal(p)
+
+		broker.Broadcast(policy, bt.NewResource("experiment/checkpoint", name, "progress"), body)
+	}
+}
@@ -0,0 +1,127 @@
+package web
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"phenix/api/experiment"
+	"phenix/api/vm"
+	"phenix/util/notes"
+	"phenix/util/plog"
+	"phenix/web/broker"
+	bt "phenix/web/broker/brokertypes"
+	"phenix/web/cache"
+	"phenix/web/middleware"
+	"phenix/web/util"
+	"phenix/web/weberror"
+)
+
+// CloneExperiment - POST /experiments/{name}/clone.
+//
+//nolint:funlen // handler
+func CloneExperiment(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "CloneExperiment")
+
+	var (
+		ctx  = r.Context()
+		role = middleware.RoleFromContext(ctx)
+		user = middleware.UserFromContext(ctx)
+		vars = mux.Vars(r)
+		name = vars["name"]
+	)
+
+	if !role.Allowed("experiments", "get", name) || !role.Allowed("experiments", "create") {
+		plog.Warn(
+			plog.TypeSecurity,
+			"cloning experiment not allowed",
+			"user",
+			user,
+			"exp",
+			name,
+		)
+		err := weberror.NewWebError(nil, "cloning experiment %s not allowed for %s", name, user)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to read request body")
+	}
+
+	var req struct {
+		Name           string `json:"name"`
+		HostnameSuffix string `json:"hostnameSuffix"`
+		SubnetOffset   string `json:"subnetOffset"`
+		VLANMin        int    `json:"vlanMin"`
+		VLANMax        int    `json:"vlanMax"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		err := weberror.NewWebError(err, "unable to parse request body")
+
+		return err.SetStatus(http.StatusBadRequest)
+	}
+
+	if err := cache.LockExperimentForCreation(req.Name); err != nil {
+		err := weberror.NewWebError(err, "unable to lock experiment %s for creation", req.Name)
+
+		return err.SetStatus(http.StatusConflict)
+	}
+
+	defer cache.UnlockExperiment(req.Name)
+
+	opts := []experiment.CloneOption{
+		experiment.CloneWithSource(name),
+		experiment.CloneWithName(req.Name),
+		experiment.CloneWithHostnameSuffix(req.HostnameSuffix),
+		experiment.CloneWithSubnetOffset(req.SubnetOffset),
+		experiment.CloneWithVLANRange(req.VLANMin, req.VLANMax),
+		experiment.CloneWithOwner(user),
+	}
+
+	if err := experiment.Clone(ctx, opts...); err != nil {
+		err := weberror.NewWebError(err, "unable to clone experiment %s", name)
+
+		return err.SetStatus(http.StatusBadRequest)
+	}
+
+	if warns := notes.Warnings(ctx, true); warns != nil {
+		for _, warn := range warns {
+			plog.Warn(plog.TypeSystem, "cloning experiment", "warnings", warn)
+		}
+	}
+
+	exp, err := experiment.Get(req.Name)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to get experiment %s", req.Name)
+	}
+
+	vms, err := vm.List(req.Name)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to list VMs for experiment %s", req.Name)
+	}
+
+	body, err = marshaler.Marshal(util.ExperimentToProtobuf(*exp, "", vms))
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process experiment %s", req.Name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	broker.Broadcast(
+		bt.NewRequestPolicy("experiments", "get", req.Name),
+		bt.NewResource("experiment", req.Name, "create"),
+		body,
+	)
+
+	plog.Info(plog.TypeAction, "experiment cloned", "user", user, "source", name, "exp", req.Name)
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -310,3 +312,30 @@ func stopExperiment(name string) ([]byte, error) {
 
 	return body, nil
 }
+
+// ifMatchVersion returns the resource version in the request's `If-Match`
+// header, which clients set to the ETag of the config they read so updates
+// fail with a conflict if the config was modified by someone else in the
+// meantime. Zero is returned if the header isn't set.
+func ifMatchVersion(r *http.Request) (int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	version, err := strconv.ParseInt(strings.Trim(strings.TrimPrefix(header, "W/"), `"`), 10, 64)
+	if err != nil || version < 1 {
+		err := weberror.NewWebError(err, "invalid If-Match header %s (must be a resource version)", header)
+
+		return 0, err.SetStatus(http.StatusBadRequest)
+	}
+
+	return version, nil
+}
+
+// setETag sets the response's `ETag` header to the given resource version.
+func setETag(w http.ResponseWriter, version int64) {
+	if version != 0 {
+		w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(version, 10)))
+	}
+}
@@ -0,0 +1,57 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIfMatchVersion(t *testing.T) {
+	valid := map[string]int64{
+		"":       0,
+		"*":      0,
+		"3":      3,
+		`"3"`:    3,
+		`W/"42"`: 42,
+	}
+
+	for header, expected := range valid {
+		r := httptest.NewRequest(http.MethodPatch, "/experiments/foo", nil)
+		r.Header.Set("If-Match", header)
+
+		version, err := ifMatchVersion(r)
+		if err != nil {
+			t.Errorf("unexpected error for If-Match %q: %v", header, err)
+		}
+
+		if version != expected {
+			t.Errorf("expected version %d for If-Match %q, got %d", expected, header, version)
+		}
+	}
+
+	for _, header := range []string{`"abc"`, "0", "-1"} {
+		r := httptest.NewRequest(http.MethodPatch, "/experiments/foo", nil)
+		r.Header.Set("If-Match", header)
+
+		if _, err := ifMatchVersion(r); err == nil {
+			t.Errorf("expected error for If-Match %q", header)
+		}
+	}
+}
+
+func TestSetETag(t *testing.T) {
+	w := httptest.NewRecorder()
+	setETag(w, 7)
+
+	if etag := w.Header().Get("ETag"); etag != `"7"` {
+		t.Errorf(`expected ETag "7", got %s`, etag)
+	}
+
+	// The ETag round trips through ifMatchVersion.
+	r := httptest.NewRequest(http.MethodPatch, "/experiments/foo", nil)
+	r.Header.Set("If-Match", w.Header().Get("ETag"))
+
+	if version, _ := ifMatchVersion(r); version != 7 {
+		t.Errorf("expected version 7, got %d", version)
+	}
+}
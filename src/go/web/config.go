@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,12 +18,11 @@ import (
 
 	"phenix/api/config"
 	"phenix/api/experiment"
+	"phenix/api/lint"
 	"phenix/store"
 	"phenix/types"
 	"phenix/types/version"
 	"phenix/util/plog"
-	"phenix/web/broker"
-	bt "phenix/web/broker/brokertypes"
 	"phenix/web/middleware"
 	"phenix/web/rbac"
 	"phenix/web/util"
@@ -287,9 +287,11 @@ func CreateConfig(w http.ResponseWriter, r *http.Request) error {
 		return err.SetStatus(http.StatusForbidden)
 	}
 
+	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+
 	var (
 		typ  = r.Header.Get("Content-Type")
-		opts = []config.CreateOption{config.CreateWithValidation()}
+		opts = []config.CreateOption{config.CreateWithValidation(), config.CreateWithAuthor(user)}
 	)
 
 	switch {
@@ -389,23 +391,8 @@ func CreateConfig(w http.ResponseWriter, r *http.Request) error {
 		Set("Location", strings.ToLower(fmt.Sprintf("/api/v1/configs/%s/%s", c.Kind, c.Metadata.Name)))
 	w.WriteHeader(http.StatusCreated)
 
-	c.Spec = nil
-	c.Status = nil
-
-	body, err := json.Marshal(c)
-	if err != nil {
-		plog.Error(plog.TypeSystem, "marshaling config", "config", c.FullName(), "err", err)
-
-		return nil
-	}
-
-	broker.Broadcast(
-		bt.NewRequestPolicy("configs", "list", c.FullName()),
-		bt.NewResource("config", c.FullName(), "create"),
-		body,
-	)
+	// Clients are notified of the new config by the broker's store watch.
 
-	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
 	plog.Info(
 		plog.TypeAction,
 		"created config",
@@ -627,11 +614,30 @@ func UpdateConfig(w http.ResponseWriter, r *http.Request) error {
 		c.Spec["experimentName"] = vars["name"]
 	}
 
-	if err := config.Update(name, c); err != nil {
+	// The If-Match header takes precedence over the resource version in the
+	// config's metadata.
+	expected, err := ifMatchVersion(r)
+	if err != nil {
+		return err
+	}
+
+	if expected != 0 {
+		c.Metadata.ResourceVersion = expected
+	}
+
+	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+
+	if err := config.Update(name, c, config.UpdateWithAuthor(user)); err != nil {
 		if errors.Is(err, store.ErrNotExist) {
 			return weberror.NewWebError(err, "config to update (%s) does not exist", name)
 		}
 
+		if errors.Is(err, store.ErrConflict) {
+			err := weberror.NewWebError(err, "config %s was modified by someone else", name)
+
+			return err.SetStatus(http.StatusConflict)
+		}
+
 		if errors.Is(err, types.ErrValidationFailed) {
 			cause := errors.Unwrap(err)
 			lines := strings.Split(cause.Error(), "\n")
@@ -659,32 +665,16 @@ func UpdateConfig(w http.ResponseWriter, r *http.Request) error {
 				c.Metadata.Name,
 			)
 		}
+	} else {
+		// Reconfiguring experiments updates them again, so their new resource
+		// version isn't known here.
+		setETag(w, c.Metadata.ResourceVersion)
 	}
 
 	w.Header().
 		Set("Location", strings.ToLower(fmt.Sprintf("/api/v1/configs/%s/%s", c.Kind, c.Metadata.Name)))
 	w.WriteHeader(http.StatusNoContent)
 
-	c.Spec = nil
-	c.Status = nil
-
-	body, err := json.Marshal(c)
-	if err != nil {
-		plog.Error(plog.TypeSystem, "marshaling config", "config", c.FullName(), "err", err)
-
-		return nil
-	}
-
-	broker.Broadcast(
-		bt.NewRequestPolicy("configs", "list", c.FullName()),
-		bt.NewResource(
-			"config",
-			name,
-			"update",
-		), // use old name in broadcast so client knows what to update
-		body,
-	)
-	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
 	plog.Info(
 		plog.TypeAction,
 		"updated config",
@@ -735,11 +725,6 @@ func DeleteConfig(w http.ResponseWriter, r *http.Request) error {
 
 	w.WriteHeader(http.StatusNoContent)
 
-	broker.Broadcast(
-		bt.NewRequestPolicy("configs", "list", name),
-		bt.NewResource("config", name, "delete"),
-		nil,
-	)
 	user, _ := ctx.Value(middleware.ContextKeyUser).(string)
 	plog.Info(
 		plog.TypeAction,
@@ -752,3 +737,343 @@ func DeleteConfig(w http.ResponseWriter, r *http.Request) error {
 
 	return nil
 }
+
+// GetConfigRevisions - GET /configs/{kind}/{name}/revisions.
+func GetConfigRevisions(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "GetConfigRevisions")
+
+	var (
+		ctx     = r.Context()
+		role, _ = ctx.Value(middleware.ContextKeyRole).(rbac.Role)
+		vars    = mux.Vars(r)
+		name    = store.ConfigFullName(vars["kind"], vars["name"])
+	)
+
+	if !role.Allowed("configs/revisions", "list", name) {
+		user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+		plog.Warn(
+			plog.TypeSecurity,
+			"listing config revisions not allowed",
+			"user",
+			user,
+			"config",
+			name,
+		)
+		err := weberror.NewWebError(
+			nil,
+			"listing revisions for config %s not allowed for %s",
+			name,
+			user,
+		)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	revs, err := config.History(name)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to get revisions for config %s", name)
+	}
+
+	// Full configs and diffs are only included when getting a single revision.
+	for i := range revs {
+		revs[i].Config = nil
+		revs[i].Diff = ""
+	}
+
+	body, err := json.Marshal(util.WithRoot("revisions", revs))
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process revisions for config %s", name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
+
+// GetConfigRevision - GET /configs/{kind}/{name}/revisions/{revision}.
+func GetConfigRevision(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "GetConfigRevision")
+
+	var (
+		ctx     = r.Context()
+		role, _ = ctx.Value(middleware.ContextKeyRole).(rbac.Role)
+		vars    = mux.Vars(r)
+		name    = store.ConfigFullName(vars["kind"], vars["name"])
+	)
+
+	if !role.Allowed("configs/revisions", "get", name) {
+		user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+		plog.Warn(
+			plog.TypeSecurity,
+			"getting config revision not allowed",
+			"user",
+			user,
+			"config",
+			name,
+		)
+		err := weberror.NewWebError(
+			nil,
+			"getting revisions for config %s not allowed for %s",
+			name,
+			user,
+		)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	num, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		return weberror.NewWebError(err, "invalid revision %s", vars["revision"])
+	}
+
+	rev, err := config.GetRevision(name, num)
+	if err != nil {
+		if errors.Is(err, store.ErrNotExist) {
+			err := weberror.NewWebError(err, "revision %d of config %s does not exist", num, name)
+
+			return err.SetStatus(http.StatusNotFound)
+		}
+
+		return weberror.NewWebError(err, "unable to get revision %d of config %s", num, name)
+	}
+
+	body, err := json.Marshal(rev)
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process revision %d of config %s", num, name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
+
+// GetConfigRevisionsDiff - GET /configs/{kind}/{name}/revisions/diff?from={revision}&to={revision}.
+func GetConfigRevisionsDiff(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "GetConfigRevisionsDiff")
+
+	var (
+		ctx     = r.Context()
+		role, _ = ctx.Value(middleware.ContextKeyRole).(rbac.Role)
+		vars    = mux.Vars(r)
+		query   = r.URL.Query()
+		name    = store.ConfigFullName(vars["kind"], vars["name"])
+	)
+
+	if !role.Allowed("configs/revisions", "get", name) {
+		user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+		plog.Warn(
+			plog.TypeSecurity,
+			"diffing config revisions not allowed",
+			"user",
+			user,
+			"config",
+			name,
+		)
+		err := weberror.NewWebError(
+			nil,
+			"getting revisions for config %s not allowed for %s",
+			name,
+			user,
+		)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	from, err := strconv.Atoi(query.Get("from"))
+	if err != nil {
+		return weberror.NewWebError(err, "invalid from revision %s", query.Get("from"))
+	}
+
+	to, err := strconv.Atoi(query.Get("to"))
+	if err != nil {
+		return weberror.NewWebError(err, "invalid to revision %s", query.Get("to"))
+	}
+
+	diff, err := config.DiffRevisions(name, from, to)
+	if err != nil {
+		if errors.Is(err, store.ErrNotExist) {
+			err := weberror.NewWebError(err, "revision of config %s does not exist", name)
+
+			return err.SetStatus(http.StatusNotFound)
+		}
+
+		return weberror.NewWebError(err, "unable to diff revisions of config %s", name)
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(diff)) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
+
+// RollbackConfig - POST /configs/{kind}/{name}/revisions/{revision}/rollback.
+func RollbackConfig(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "RollbackConfig")
+
+	var (
+		ctx     = r.Context()
+		role, _ = ctx.Value(middleware.ContextKeyRole).(rbac.Role)
+		user, _ = ctx.Value(middleware.ContextKeyUser).(string)
+		vars    = mux.Vars(r)
+		name    = store.ConfigFullName(vars["kind"], vars["name"])
+	)
+
+	if !role.Allowed("configs/revisions", "update", name) {
+		plog.Warn(
+			plog.TypeSecurity,
+			"rolling back config not allowed",
+			"user",
+			user,
+			"config",
+			name,
+		)
+		err := weberror.NewWebError(
+			nil,
+			"rolling back config %s not allowed for %s",
+			name,
+			user,
+		)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	num, err := strconv.Atoi(vars["revision"])
+	if err != nil {
+		return weberror.NewWebError(err, "invalid revision %s", vars["revision"])
+	}
+
+	c, err := config.Rollback(name, num, config.UpdateWithAuthor(user))
+	if err != nil {
+		if errors.Is(err, store.ErrNotExist) {
+			err := weberror.NewWebError(err, "revision %d of config %s does not exist", num, name)
+
+			return err.SetStatus(http.StatusNotFound)
+		}
+
+		if errors.Is(err, store.ErrConflict) {
+			err := weberror.NewWebError(err, "config %s was modified by someone else", name)
+
+			return err.SetStatus(http.StatusConflict)
+		}
+
+		if errors.Is(err, types.ErrValidationFailed) {
+			cause := errors.Unwrap(err)
+			lines := strings.Split(cause.Error(), "\n")
+
+			return weberror.NewWebError(cause, "%s", lines[0]).
+				WithMetadata("validation", cause.Error(), true)
+		}
+
+		return weberror.NewWebError(err, "unable to roll back config %s to revision %d", name, num)
+	}
+
+	if c.Kind == kindExperiment {
+		err := experiment.Reconfigure(c.Metadata.Name)
+		if err != nil {
+			return weberror.NewWebError(
+				err,
+				"unable to reconfigure rolled back experiment %s",
+				c.Metadata.Name,
+			)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	plog.Info(
+		plog.TypeAction,
+		"rolled back config",
+		"user",
+		user,
+		"config",
+		name,
+		"revision",
+		num,
+	)
+
+	return nil
+}
+
+// LintConfig - GET /configs/{kind}/{name}/lint.
+func LintConfig(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "LintConfig")
+
+	var (
+		ctx     = r.Context()
+		role, _ = ctx.Value(middleware.ContextKeyRole).(rbac.Role)
+		vars    = mux.Vars(r)
+		name    = store.ConfigFullName(vars["kind"], vars["name"])
+	)
+
+	if !role.Allowed("configs/lint", "get", name) {
+		user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+		plog.Warn(
+			plog.TypeSecurity,
+			"linting config not allowed",
+			"user",
+			user,
+			"config",
+			name,
+		)
+		err := weberror.NewWebError(
+			nil,
+			"linting config %s not allowed for %s",
+			name,
+			user,
+		)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	cfg, err := config.Get(name, false)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to get config %s from store", name)
+	}
+
+	if cfg.Kind != "Topology" && cfg.Kind != kindExperiment {
+		err := weberror.NewWebError(nil, "linting %s configs is not supported", cfg.Kind)
+
+		return err.SetStatus(http.StatusBadRequest)
+	}
+
+	// Topology template parameter values are passed as `param=key=value`.
+	params := make(map[string]string)
+
+	for _, param := range r.URL.Query()["param"] {
+		k, v, ok := strings.Cut(param, "=")
+		if !ok {
+			err := weberror.NewWebError(nil, "topology parameter %s must be in the form key=value", param)
+
+			return err.SetStatus(http.StatusBadRequest)
+		}
+
+		params[strings.TrimSpace(k)] = v
+	}
+
+	findings, err := lint.Config(*cfg, lint.SkipRules(r.URL.Query()["skip"]...), lint.TopologyParameters(params))
+	if err != nil {
+		return weberror.NewWebError(err, "unable to lint config %s", name)
+	}
+
+	if findings == nil {
+		findings = lint.Findings{}
+	}
+
+	body, err := json.Marshal(util.WithRoot("findings", findings))
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process lint findings for config %s", name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"phenix/api/disk"
+	"phenix/api/quota"
 	"phenix/util/mm"
 	"phenix/util/plog"
 	"phenix/web/middleware"
@@ -470,6 +471,15 @@ func UploadDisk(w http.ResponseWriter, r *http.Request) {
 
 	defer func() { _ = clientFile.Close() }()
 
+	user, _ := r.Context().Value(middleware.ContextKeyUser).(string)
+
+	if err := quota.CheckDisk(user, handler.Size); err != nil {
+		plog.Warn(plog.TypeSecurity, "uploading disk not allowed", "user", user, "err", err)
+		http.Error(w, err.Error(), http.StatusForbidden)
+
+		return
+	}
+
 	localFile, err := os.OpenFile( //nolint:gosec // Path traversal via taint analysis
 		mm.GetMMFullPath(handler.Filename),
 		os.O_WRONLY|os.O_CREATE,
@@ -483,7 +493,11 @@ func UploadDisk(w http.ResponseWriter, r *http.Request) {
 	defer func() { _ = localFile.Close() }()
 
 	_, _ = io.Copy(localFile, clientFile)
-	user, _ := r.Context().Value(middleware.ContextKeyUser).(string)
+
+	if err := quota.RecordDisk(user, localFile.Name()); err != nil {
+		plog.Error(plog.TypeSystem, "recording uploaded disk", "user", user, "err", err)
+	}
+
 	plog.Info(
 		plog.TypeAction,
 		"uploaded disk",
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"phenix/store"
+	v1 "phenix/types/version/v1"
+	"phenix/web/middleware"
+	"phenix/web/rbac"
+)
+
+func TestUploadDiskQuota(t *testing.T) {
+	s, err := store.NewFromEndpoint("bolt://" + filepath.Join(t.TempDir(), "store.bdb"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orig := store.DefaultStore
+	store.DefaultStore = s //nolint:reassign // testing
+
+	t.Cleanup(func() {
+		_ = s.Close()
+		store.DefaultStore = orig //nolint:reassign // testing
+	})
+
+	user := &store.Config{ //nolint:exhaustruct // partial initialization
+		Version:  "phenix.sandia.gov/v1",
+		Kind:     "User",
+		Metadata: store.ConfigMetadata{Name: "alice"}, //nolint:exhaustruct // partial initialization
+		Spec: map[string]any{
+			"username": "alice",
+			"quota":    map[string]any{"diskBytes": 16},
+		},
+	}
+
+	if err := store.Create(user); err != nil {
+		t.Fatal(err)
+	}
+
+	var body bytes.Buffer
+
+	form := multipart.NewWriter(&body)
+
+	part, err := form.CreateFormFile("file", "big.qc2")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, _ = part.Write([]byte(strings.Repeat("x", 32)))
+	_ = form.Close()
+
+	role := rbac.Role{Spec: &v1.RoleSpec{}} //nolint:exhaustruct // partial initialization
+	role.AddPolicy([]string{"disks"}, nil, []string{"upload"})
+
+	ctx := context.WithValue(context.Background(), middleware.ContextKeyRole, role)
+	ctx = context.WithValue(ctx, middleware.ContextKeyUser, "alice")
+
+	r := httptest.NewRequestWithContext(ctx, http.MethodPost, "/disks", &body)
+	r.Header.Set("Content-Type", form.FormDataContentType())
+
+	w := httptest.NewRecorder()
+
+	UploadDisk(w, r)
+
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), "disk limit of 16 bytes exceeded") {
+		t.Fatalf("expected disk upload over quota to be forbidden, got %d: %s", w.Code, w.Body.String())
+	}
+}
@@ -1,14 +1,17 @@
 package web
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
 	"inet.af/netaddr"
 
+	"phenix/api/graph"
 	"phenix/api/vm"
 	"phenix/util/cache"
 	"phenix/util/plog"
@@ -59,6 +62,74 @@ func GetExperimentTopology(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
 }
 
+// GetExperimentGraph - GET /experiments/{name}/graph[?format=dot|graphml|json-graph&live=true&ignore=MGMT].
+func GetExperimentGraph(w http.ResponseWriter, r *http.Request) {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "GetExperimentGraph")
+
+	var (
+		ctx     = r.Context()
+		role, _ = ctx.Value(middleware.ContextKeyRole).(rbac.Role)
+		vars    = mux.Vars(r)
+		name    = vars["name"]
+
+		query  = r.URL.Query()
+		ignore = query["ignore"]
+	)
+
+	if !role.Allowed("experiments/topology", "get", name) {
+		user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+		plog.Warn(
+			plog.TypeSecurity,
+			"getting experiment graph not allowed",
+			"user",
+			user,
+			"experiment",
+			name,
+		)
+		http.Error(w, "forbidden", http.StatusForbidden)
+
+		return
+	}
+
+	format := graph.FormatJSONGraph
+
+	if f := query.Get("format"); f != "" {
+		var err error
+
+		if format, err = graph.ParseFormat(f); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+	}
+
+	opts := []graph.Option{graph.IgnoreVLANs(ignore...)}
+
+	if live, _ := strconv.ParseBool(query.Get("live")); live {
+		opts = append(opts, graph.WithLiveState())
+	}
+
+	g, err := graph.Experiment(name, opts...)
+	if err != nil {
+		plog.Error(plog.TypeSystem, "building experiment graph", "exp", name, "err", err)
+		http.Error(w, "unable to build experiment graph", http.StatusBadRequest)
+
+		return
+	}
+
+	var buf bytes.Buffer
+
+	if err := g.Encode(&buf, format); err != nil {
+		plog.Error(plog.TypeSystem, "encoding experiment graph", "exp", name, "format", format, "err", err)
+		http.Error(w, "unable to encode experiment graph", http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	_, _ = w.Write(buf.Bytes()) //nolint:gosec // XSS via taint analysis
+}
+
 // SearchExperimentTopology - GET /experiments/{name}/topology/search?hostname=xyz&vlan=abc.
 func SearchExperimentTopology(w http.ResponseWriter, r *http.Request) {
 	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "SearchExperimentTopology")
@@ -32,6 +32,8 @@ import (
 	"phenix/api/settings"
 	"phenix/api/vm"
 	"phenix/app"
+	"phenix/scheduler"
+	"phenix/store"
 	putil "phenix/util"
 	"phenix/util/common"
 	"phenix/util/mm"
@@ -123,7 +125,7 @@ func GetExperiments(w http.ResponseWriter, r *http.Request) {
 
 		if exp.Running() && size != "" {
 			for i, v := range vms {
-				if !v.Running {
+				if !v.Running || v.IsContainer() {
 					continue
 				}
 
@@ -247,6 +249,7 @@ func CreateExperiment(w http.ResponseWriter, r *http.Request) {
 		experiment.CreateWithDeployMode(deployMode),
 		experiment.CreateWithDefaultBridge(req.GetDefaultBridge()),
 		experiment.CreateWithGREMesh(req.GetUseGreMesh()),
+		experiment.CreateWithOwner(middleware.UserFromContext(ctx)),
 	}
 
 	if req.GetWorkflowBranch() != "" {
@@ -351,6 +354,26 @@ func UpdateExperiment(w http.ResponseWriter, r *http.Request) error {
 		return err.SetStatus(http.StatusBadRequest)
 	}
 
+	expected, err := ifMatchVersion(r)
+	if err != nil {
+		return err
+	}
+
+	if expected != 0 {
+		if expected != exp.Metadata.ResourceVersion {
+			err := weberror.NewWebError(
+				&store.ConflictError{Kind: "Experiment", Name: name, Expected: expected, Actual: exp.Metadata.ResourceVersion},
+				"experiment %s was modified by someone else", name,
+			)
+
+			return err.SetStatus(http.StatusConflict)
+		}
+
+		// Have the store reject the update if the experiment is modified between
+		// getting it above and writing it below.
+		exp.Metadata.ResourceVersion = expected
+	}
+
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		err := weberror.NewWebError(err, "unable to parse update request for experiment %s", name)
@@ -379,12 +402,20 @@ func UpdateExperiment(w http.ResponseWriter, r *http.Request) error {
 
 		err := exp.WriteToStore(false)
 		if err != nil {
+			if errors.Is(err, store.ErrConflict) {
+				err := weberror.NewWebError(err, "experiment %s was modified by someone else", name)
+
+				return err.SetStatus(http.StatusConflict)
+			}
+
 			err := weberror.NewWebError(err, "unable to write updated experiment %s", name)
 
 			return err.SetStatus(http.StatusInternalServerError)
 		}
 	}
 
+	setETag(w, exp.Metadata.ResourceVersion)
+
 	user := middleware.UserFromContext(ctx)
 	plog.Info(
 		plog.TypeAction,
@@ -474,7 +505,7 @@ func GetExperiment(w http.ResponseWriter, r *http.Request) error {
 		}
 
 		if role.Allowed("vms", "list", fmt.Sprintf("%s/%s", name, vm.Name)) {
-			if vm.Running && size != "" {
+			if vm.Running && !vm.IsContainer() && size != "" {
 				screenshot, err := util.GetScreenshot(name, vm.Name, size)
 				if err != nil {
 					plog.Error(plog.TypeSystem, "getting screenshot", "err", err)
@@ -512,6 +543,8 @@ func GetExperiment(w http.ResponseWriter, r *http.Request) error {
 		return err.SetStatus(http.StatusInternalServerError)
 	}
 
+	setETag(w, exp.Metadata.ResourceVersion)
+
 	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
 
 	return nil
@@ -1027,6 +1060,107 @@ func ScheduleExperiment(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
 }
 
+// PreviewExperimentSchedule - GET /experiments/{name}/schedule/preview.
+//
+// Runs the scheduling algorithm given in the `algorithm` query parameter
+// against a copy of the experiment and returns the proposed placement without
+// modifying the experiment. The optional `cpuOvercommit` and
+// `memoryOvercommit` query parameters are passed to resource-aware algorithms.
+//
+//nolint:funlen // handler
+func PreviewExperimentSchedule(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx   = r.Context()
+		role  = middleware.RoleFromContext(ctx)
+		vars  = mux.Vars(r)
+		name  = vars["name"]
+		query = r.URL.Query()
+		algo  = query.Get("algorithm")
+	)
+
+	if !role.Allowed("experiments/schedule", "get", name) {
+		user := middleware.UserFromContext(ctx)
+		plog.Warn(
+			plog.TypeSecurity,
+			"previewing experiment schedule not allowed",
+			"user",
+			user,
+			"exp",
+			name,
+		)
+		http.Error(w, "forbidden", http.StatusForbidden)
+
+		return
+	}
+
+	if algo == "" {
+		http.Error(w, "missing algorithm query parameter", http.StatusBadRequest)
+
+		return
+	}
+
+	var schedOpts []scheduler.Option
+
+	for param, opt := range map[string]func(float64) scheduler.Option{
+		"cpuOvercommit":    scheduler.CPUOvercommit,
+		"memoryOvercommit": scheduler.MemoryOvercommit,
+	} {
+		if v := query.Get(param); v != "" {
+			ratio, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid %s query parameter: %v", param, err), http.StatusBadRequest)
+
+				return
+			}
+
+			schedOpts = append(schedOpts, opt(ratio))
+		}
+	}
+
+	report, err := experiment.PreviewSchedule(
+		experiment.ScheduleForName(name),
+		experiment.ScheduleWithAlgorithm(algo),
+		experiment.ScheduleWithSchedulerOptions(schedOpts...),
+	)
+	if err != nil {
+		plog.Error(
+			plog.TypeSystem,
+			"previewing experiment schedule",
+			"exp",
+			name,
+			"algorithm",
+			algo,
+			"err",
+			err,
+		)
+
+		status := http.StatusInternalServerError
+
+		if errors.Is(err, scheduler.ErrOverCapacity) || errors.Is(err, scheduler.ErrConstraintViolation) {
+			status = http.StatusUnprocessableEntity
+		}
+
+		http.Error(w, err.Error(), status)
+
+		return
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		plog.Error(plog.TypeSystem, "marshaling schedule preview for experiment", "exp", name, "err", err)
+		http.Error(
+			w,
+			http.StatusText(http.StatusInternalServerError),
+			http.StatusInternalServerError,
+		)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+}
+
 // GetExperimentCaptures - GET /experiments/{name}/captures.
 func GetExperimentCaptures(w http.ResponseWriter, r *http.Request) {
 	var (
@@ -1300,7 +1434,7 @@ func GetVMs(w http.ResponseWriter, r *http.Request) {
 
 	for _, vm := range vms {
 		if role.Allowed("vms", "list", fmt.Sprintf("%s/%s", expName, vm.Name)) {
-			if vm.Running && size != "" {
+			if vm.Running && !vm.IsContainer() && size != "" {
 				screenshot, err := util.GetScreenshot(expName, vm.Name, size)
 				if err != nil {
 					plog.Error(plog.TypeSystem, "getting screenshot", "err", err)
@@ -1387,7 +1521,7 @@ func GetVM(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if vm.Running && size != "" {
+	if vm.Running && !vm.IsContainer() && size != "" {
 		screenshot, err := util.GetScreenshot(expName, name, size)
 		if err != nil {
 			plog.Error(plog.TypeSystem, "getting screenshot", "err", err)
@@ -1509,7 +1643,7 @@ func UpdateVM(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if vm.Running {
+	if vm.Running && !vm.IsContainer() {
 		screenshot, err := util.GetScreenshot(expName, name, defaultScreenshotSize)
 		if err != nil {
 			plog.Error(plog.TypeSystem, "getting screenshot", "err", err)
@@ -1640,7 +1774,7 @@ func UpdateVMs(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		if vm.Running {
+		if vm.Running && !vm.IsContainer() {
 			screenshot, err := util.GetScreenshot(expName, vmRequest.GetName(), defaultScreenshotSize)
 			if err != nil {
 				plog.Error(plog.TypeSystem, "getting screenshot", "err", err)
@@ -4093,6 +4227,80 @@ func EjectOpticalDisc(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// ExecVM - POST /experiments/{exp}/vms/{name}/exec.
+//
+//nolint:funlen // handler
+func ExecVM(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx      = r.Context()
+		role     = middleware.RoleFromContext(ctx)
+		vars     = mux.Vars(r)
+		exp      = vars["exp"]
+		name     = vars["name"]
+		fullName = exp + "/" + name
+	)
+
+	if !role.Allowed("vms/exec", "create", fullName) {
+		user := middleware.UserFromContext(ctx)
+		plog.Warn(plog.TypeSecurity, "executing command in VM not allowed", "user", user, "exp", exp, "vm", name)
+		http.Error(w, "forbidden", http.StatusForbidden)
+
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		plog.Error(plog.TypeSystem, "reading request body", "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	var req struct {
+		Command string `json:"command"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		plog.Error(plog.TypeSystem, "unmarshaling request body", "err", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if req.Command == "" {
+		http.Error(w, "missing 'command' key", http.StatusBadRequest)
+
+		return
+	}
+
+	out, err := vm.Exec(exp, name, req.Command)
+	if err != nil {
+		plog.Error(plog.TypeSystem, "executing command in VM", "exp", exp, "vm", name, "err", err)
+
+		if errors.Is(err, vm.ErrNotContainer) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	user := middleware.UserFromContext(ctx)
+	plog.Info(plog.TypeAction, "command executed in VM", "user", user, "exp", exp, "vm", name, "command", req.Command)
+
+	body, err = json.Marshal(map[string]string{"output": out})
+	if err != nil {
+		plog.Error(plog.TypeSystem, "marshaling exec output", "err", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body)
+}
+
 // GetSettings - GET /settings.
 func GetSettings(w http.ResponseWriter, r *http.Request) {
 	settings, err := settings.GetSettings()
@@ -0,0 +1,496 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"phenix/api/experiment"
+	"phenix/types"
+	"phenix/util/cron"
+	"phenix/util/plog"
+	"phenix/web/broker"
+	bt "phenix/web/broker/brokertypes"
+	"phenix/web/middleware"
+	"phenix/web/weberror"
+)
+
+const (
+	leaseCheckInterval = time.Minute
+	leaseWarning       = 15 * time.Minute
+)
+
+// leaseTracker tracks the state the lease controller needs between checks that
+// isn't stored in experiment configs.
+type leaseTracker struct {
+	sync.Mutex
+
+	// experiment name --> time of last VNC or API activity
+	activity map[string]time.Time
+
+	// experiment name --> deadline owners were last warned about
+	warned map[string]time.Time
+
+	// experiment name --> whether an automatic start or stop is in progress
+	pending map[string]bool
+
+	// when the lease controller was started
+	started time.Time
+}
+
+//nolint:gochecknoglobals // global state
+var leases = leaseTracker{
+	activity: make(map[string]time.Time),
+	warned:   make(map[string]time.Time),
+	pending:  make(map[string]bool),
+}
+
+// recordActivity resets the idle timer of the experiment with the given name.
+func recordActivity(exp string) {
+	if exp == "" {
+		return
+	}
+
+	leases.Lock()
+	defer leases.Unlock()
+
+	leases.activity[exp] = time.Now()
+}
+
+// trackActivity periodically resets the idle timer of the experiment with the
+// given name until the given channel is closed. It's used for long lived
+// connections, like VNC, that only make a single API request.
+func trackActivity(exp string, done <-chan struct{}) {
+	ticker := time.NewTicker(leaseCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		recordActivity(exp)
+
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// leaseActivity is middleware that resets the idle timer of the experiment
+// referenced by each API request.
+func leaseActivity(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route := mux.CurrentRoute(r); route != nil {
+			if tmpl, err := route.GetPathTemplate(); err == nil && strings.Contains(tmpl, "/experiments/{") {
+				vars := mux.Vars(r)
+
+				if exp, ok := vars["exp"]; ok {
+					recordActivity(exp)
+				} else {
+					recordActivity(vars["name"])
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// runLeaseController enforces the lease and cron schedule of each experiment
+// until the given context is canceled, starting and stopping experiments the
+// same way the API does.
+func runLeaseController(ctx context.Context) {
+	var (
+		last   = time.Now()
+		ticker = time.NewTicker(leaseCheckInterval)
+	)
+
+	defer ticker.Stop()
+
+	leases.Lock()
+	leases.started = last
+	leases.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			exps, err := types.Experiments(false)
+			if err != nil {
+				plog.Error(plog.TypeSystem, "getting experiments for lease controller", "err", err)
+
+				continue
+			}
+
+			for _, exp := range exps {
+				checkCronSchedule(exp, last, now)
+				checkLease(exp, now)
+			}
+
+			last = now
+		}
+	}
+}
+
+// checkCronSchedule starts or stops the given experiment if its cron schedule
+// had a start or stop time between the given times.
+func checkCronSchedule(exp *types.Experiment, last, now time.Time) {
+	name := exp.Metadata.Name
+
+	start, stop, err := experiment.CronSchedules(exp.Spec.CronSchedule())
+	if err != nil {
+		plog.Error(plog.TypeSystem, "parsing experiment schedule", "exp", name, "err", err)
+
+		return
+	}
+
+	due := func(s *cron.Schedule) bool {
+		if s == nil {
+			return false
+		}
+
+		next := s.Next(last)
+
+		return !next.IsZero() && !next.After(now)
+	}
+
+	switch {
+	case !exp.Running() && due(start):
+		autoStartExperiment(name, "schedule", exp.Spec.CronSchedule().Start())
+	case exp.Running() && due(stop):
+		autoStopExperiment(name, "reason", "schedule", "schedule", exp.Spec.CronSchedule().Stop())
+	}
+}
+
+// checkLease stops the given experiment if its lease has expired or it's been
+// idle for longer than its idle timeout, and warns its owners over the broker
+// when either is about to happen.
+func checkLease(exp *types.Experiment, now time.Time) {
+	name := exp.Metadata.Name
+
+	if !exp.Running() {
+		leases.Lock()
+		delete(leases.warned, name)
+		leases.Unlock()
+
+		return
+	}
+
+	reason, deadline := leaseDeadline(exp)
+	if deadline.IsZero() {
+		return
+	}
+
+	if !now.Before(deadline) {
+		autoStopExperiment(name, "reason", reason, "deadline", deadline.Format(time.RFC3339))
+
+		return
+	}
+
+	if deadline.Sub(now) > leaseWarning {
+		return
+	}
+
+	leases.Lock()
+	warned := leases.warned[name].Equal(deadline)
+	leases.warned[name] = deadline
+	leases.Unlock()
+
+	if warned {
+		return
+	}
+
+	plog.Info(plog.TypeAction, "warning experiment owners of lease expiration", "exp", name, "reason", reason, "deadline", deadline.Format(time.RFC3339))
+
+	body, _ := json.Marshal(map[string]any{"reason": reason, "expires": deadline.Format(time.RFC3339)})
+
+	broker.Broadcast(
+		bt.NewRequestPolicy("experiments", "get", name),
+		bt.NewResource("experiment/lease", name, "expiring"),
+		body,
+	)
+}
+
+// leaseDeadline returns the earliest time the given running experiment will be
+// stopped, either because its lease expires or it becomes idle, along with the
+// reason. The zero time is returned if the experiment doesn't have a lease.
+func leaseDeadline(exp *types.Experiment) (string, time.Time) {
+	var (
+		reason   string
+		deadline time.Time
+	)
+
+	if expires, err := time.Parse(time.RFC3339, exp.Status.LeaseExpires()); err == nil {
+		reason, deadline = "lease expired", expires
+	}
+
+	_, idleTimeout, err := experiment.LeaseDurations(exp.Spec.Lease())
+	if err != nil || idleTimeout == 0 {
+		return reason, deadline
+	}
+
+	idle := lastActivity(exp).Add(idleTimeout)
+
+	if deadline.IsZero() || idle.Before(deadline) {
+		reason, deadline = "idle timeout", idle
+	}
+
+	return reason, deadline
+}
+
+// lastActivity returns the time of the last VNC or API activity for the given
+// running experiment. Activity isn't persisted, so the time the experiment was
+// started or the lease controller was started is used if it's later.
+func lastActivity(exp *types.Experiment) time.Time {
+	leases.Lock()
+	defer leases.Unlock()
+
+	last := leases.started
+
+	startTime := strings.TrimSuffix(exp.Status.StartTime(), "-DRYRUN")
+
+	if t, err := time.Parse(time.RFC3339, startTime); err == nil && t.After(last) {
+		last = t
+	}
+
+	if t, ok := leases.activity[exp.Metadata.Name]; ok && t.After(last) {
+		last = t
+	}
+
+	return last
+}
+
+// autoStartExperiment starts the experiment with the given name in the
+// background unless an automatic start or stop is already in progress for it.
+// The given key/value pairs are included in the action log.
+func autoStartExperiment(name string, args ...any) {
+	if !beginAutoAction(name) {
+		return
+	}
+
+	plog.Info(plog.TypeAction, "starting experiment automatically", append([]any{"exp", name}, args...)...)
+
+	go func() {
+		defer endAutoAction(name)
+
+		if _, err := startExperiment(name); err != nil {
+			plog.Error(plog.TypeSystem, "starting experiment automatically", "exp", name, "err", err)
+		}
+	}()
+}
+
+// autoStopExperiment stops the experiment with the given name in the
+// background unless an automatic start or stop is already in progress for it.
+// The given key/value pairs are included in the action log.
+func autoStopExperiment(name string, args ...any) {
+	if !beginAutoAction(name) {
+		return
+	}
+
+	plog.Info(plog.TypeAction, "stopping experiment automatically", append([]any{"exp", name}, args...)...)
+
+	go func() {
+		defer endAutoAction(name)
+
+		if _, err := stopExperiment(name); err != nil {
+			plog.Error(plog.TypeSystem, "stopping experiment automatically", "exp", name, "err", err)
+		}
+	}()
+}
+
+func beginAutoAction(name string) bool {
+	leases.Lock()
+	defer leases.Unlock()
+
+	if leases.pending[name] {
+		return false
+	}
+
+	leases.pending[name] = true
+
+	return true
+}
+
+func endAutoAction(name string) {
+	leases.Lock()
+	defer leases.Unlock()
+
+	delete(leases.pending, name)
+	delete(leases.warned, name)
+	delete(leases.activity, name)
+}
+
+type leaseStatus struct {
+	MaxRuntime   string `json:"maxRuntime,omitempty"`
+	IdleTimeout  string `json:"idleTimeout,omitempty"`
+	Expires      string `json:"expires,omitempty"`
+	LastActivity string `json:"lastActivity,omitempty"`
+	IdleExpires  string `json:"idleExpires,omitempty"`
+}
+
+func newLeaseStatus(exp *types.Experiment) leaseStatus {
+	status := leaseStatus{ //nolint:exhaustruct // partial initialization
+		MaxRuntime:  exp.Spec.Lease().MaxRuntime(),
+		IdleTimeout: exp.Spec.Lease().IdleTimeout(),
+		Expires:     exp.Status.LeaseExpires(),
+	}
+
+	if !exp.Running() {
+		return status
+	}
+
+	last := lastActivity(exp)
+	status.LastActivity = last.Format(time.RFC3339)
+
+	if _, idleTimeout, err := experiment.LeaseDurations(exp.Spec.Lease()); err == nil && idleTimeout != 0 {
+		status.IdleExpires = last.Add(idleTimeout).Format(time.RFC3339)
+	}
+
+	return status
+}
+
+// GetExperimentLease - GET /experiments/{name}/lease.
+func GetExperimentLease(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "GetExperimentLease")
+
+	var (
+		ctx  = r.Context()
+		role = middleware.RoleFromContext(ctx)
+		vars = mux.Vars(r)
+		name = vars["name"]
+	)
+
+	if !role.Allowed("experiments/lease", "get", name) {
+		user := middleware.UserFromContext(ctx)
+		plog.Warn(
+			plog.TypeSecurity,
+			"getting experiment lease not allowed",
+			"user",
+			user,
+			"exp",
+			name,
+		)
+		err := weberror.NewWebError(nil, "getting lease for experiment %s not allowed for %s", name, user)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	exp, err := experiment.Get(name)
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to get experiment %s", name)
+
+		return err.SetStatus(http.StatusNotFound)
+	}
+
+	body, err := json.Marshal(newLeaseStatus(exp))
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process lease for experiment %s", name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
+
+// ExtendExperimentLease - POST /experiments/{name}/lease.
+//
+//nolint:funlen // handler
+func ExtendExperimentLease(w http.ResponseWriter, r *http.Request) error {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "ExtendExperimentLease")
+
+	var (
+		ctx  = r.Context()
+		role = middleware.RoleFromContext(ctx)
+		user = middleware.UserFromContext(ctx)
+		vars = mux.Vars(r)
+		name = vars["name"]
+	)
+
+	if !role.Allowed("experiments/lease", "update", name) {
+		plog.Warn(
+			plog.TypeSecurity,
+			"extending experiment lease not allowed",
+			"user",
+			user,
+			"exp",
+			name,
+		)
+		err := weberror.NewWebError(nil, "extending lease for experiment %s not allowed for %s", name, user)
+
+		return err.SetStatus(http.StatusForbidden)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to read request body")
+	}
+
+	var req struct {
+		Duration string `json:"duration"`
+	}
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		err := weberror.NewWebError(err, "unable to parse request body")
+
+		return err.SetStatus(http.StatusBadRequest)
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		err := weberror.NewWebError(err, "invalid lease extension duration %s", req.Duration)
+
+		return err.SetStatus(http.StatusBadRequest)
+	}
+
+	if _, err := experiment.ExtendLease(name, duration); err != nil {
+		werr := weberror.NewWebError(err, "unable to extend lease for experiment %s", name)
+
+		if errors.Is(err, experiment.ErrNoLease) {
+			return werr.SetStatus(http.StatusBadRequest)
+		}
+
+		return werr
+	}
+
+	// Extending a lease also counts as activity, resetting the idle timer.
+	recordActivity(name)
+
+	leases.Lock()
+	delete(leases.warned, name)
+	leases.Unlock()
+
+	exp, err := experiment.Get(name)
+	if err != nil {
+		return weberror.NewWebError(err, "unable to get experiment %s", name)
+	}
+
+	body, err = json.Marshal(newLeaseStatus(exp))
+	if err != nil {
+		err := weberror.NewWebError(err, "unable to process lease for experiment %s", name)
+
+		return err.SetStatus(http.StatusInternalServerError)
+	}
+
+	broker.Broadcast(
+		bt.NewRequestPolicy("experiments", "get", name),
+		bt.NewResource("experiment/lease", name, "extended"),
+		body,
+	)
+
+	plog.Info(plog.TypeAction, "experiment lease extended", "user", user, "exp", name, "duration", req.Duration)
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(body) //nolint:gosec // XSS via taint analysis
+
+	return nil
+}
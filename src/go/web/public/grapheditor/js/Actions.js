@@ -123,7 +123,11 @@ Actions.prototype.init = function()
                         type: 'get',
                         dataType: 'text',
                         headers,
-                        success: function (data) {
+                        success: function (data, status, xhr) {
+                            // Sent back as the If-Match header when saving so the save
+                            // fails if someone else modified the topology in the meantime.
+                            window.currentTopologyVersion = xhr.getResponseHeader('ETag');
+
                             // Dynamically update path to pictures used when a file is imported.
                             var stencilPath = window.parent.STENCIL_PATH;
                             var imageEquals = "image=";
@@ -2657,6 +2657,10 @@ var viewJSONDialog = function(ui)
                     var method = 'post';
                     if (json.metadata.name == window.currentTopology) { 
                         method = 'put'; 
+
+                        if (window.currentTopologyVersion) {
+                            headers['If-Match'] = window.currentTopologyVersion;
+                        }
                     }
 
                     $.ajax({
@@ -2664,7 +2668,8 @@ var viewJSONDialog = function(ui)
                         type: method,
                         data: JSON.stringify(payload),
                         headers,
-                        success: function () {
+                        success: function (data, status, xhr) {
+                            window.currentTopologyVersion = xhr.getResponseHeader('ETag');
                             newDiv.html('The ' + payload.name + ' topology and experiment were created in the phēnix store');
                             newDiv.dialog({title: 'Success'}).parent().addClass('ui-state-highlight');
                             ui.hideDialog.apply(ui, arguments);
@@ -2707,6 +2712,10 @@ var viewJSONDialog = function(ui)
                     if (json.metadata.name == window.currentTopology) { 
                         url    = `${url}/topology/${json.metadata.name}`;
                         method = 'put'; 
+
+                        if (window.currentTopologyVersion) {
+                            headers['If-Match'] = window.currentTopologyVersion;
+                        }
                     }
 
                     $.ajax({
@@ -2714,7 +2723,8 @@ var viewJSONDialog = function(ui)
                         type: method,
                         data: JSON.stringify(json),
                         headers,
-                        success: function () {
+                        success: function (data, status, xhr) {
+                            window.currentTopologyVersion = xhr.getResponseHeader('ETag');
                             newDiv.html('The ' + json.metadata.name + ' topology was added to phēnix store');
                             newDiv.dialog({title: 'Success'}).parent().addClass('ui-state-highlight');
                             ui.hideDialog.apply(ui, arguments);
@@ -196,6 +196,16 @@ func Start(opts ...ServerOption) error {
 		Methods("DELETE", "OPTIONS")
 	api.Handle("/configs/download", weberror.ErrorHandler(DownloadConfigs)).
 		Methods("POST", "OPTIONS")
+	api.Handle("/configs/{kind}/{name}/lint", weberror.ErrorHandler(LintConfig)).
+		Methods("GET", "OPTIONS")
+	api.Handle("/configs/{kind}/{name}/revisions", weberror.ErrorHandler(GetConfigRevisions)).
+		Methods("GET", "OPTIONS")
+	api.Handle("/configs/{kind}/{name}/revisions/diff", weberror.ErrorHandler(GetConfigRevisionsDiff)).
+		Methods("GET", "OPTIONS")
+	api.Handle("/configs/{kind}/{name}/revisions/{revision}", weberror.ErrorHandler(GetConfigRevision)).
+		Methods("GET", "OPTIONS")
+	api.Handle("/configs/{kind}/{name}/revisions/{revision}/rollback", weberror.ErrorHandler(RollbackConfig)).
+		Methods("POST", "OPTIONS")
 	api.Handle("/schemas/{version}", weberror.ErrorHandler(GetSchemaSpec)).Methods("GET", "OPTIONS")
 	api.Handle("/schemas/{kind}/{version}", weberror.ErrorHandler(GetSchema)).
 		Methods("GET", "OPTIONS")
@@ -217,6 +227,12 @@ func Start(opts ...ServerOption) error {
 		Methods("POST", "OPTIONS")
 	api.Handle("/experiments/{name}/stop", weberror.ErrorHandler(StopExperiment)).
 		Methods("POST", "OPTIONS")
+	api.Handle("/experiments/{name}/clone", weberror.ErrorHandler(CloneExperiment)).
+		Methods("POST", "OPTIONS")
+	api.Handle("/experiments/{name}/lease", weberror.ErrorHandler(GetExperimentLease)).
+		Methods("GET", "OPTIONS")
+	api.Handle("/experiments/{name}/lease", weberror.ErrorHandler(ExtendExperimentLease)).
+		Methods("POST", "OPTIONS")
 	api.HandleFunc("/experiments/{exp}/netflow", GetNetflow).Methods("GET", "OPTIONS")
 	api.HandleFunc("/experiments/{exp}/netflow", StartNetflow).Methods("POST", "OPTIONS")
 	api.HandleFunc("/experiments/{exp}/netflow", StopNetflow).Methods("DELETE", "OPTIONS")
@@ -224,12 +240,22 @@ func Start(opts ...ServerOption) error {
 	api.HandleFunc("/experiments/{name}/topology", GetExperimentTopology).Methods("GET", "OPTIONS")
 	api.HandleFunc("/experiments/{name}/topology/search", SearchExperimentTopology).
 		Methods("GET", "OPTIONS")
+	api.HandleFunc("/experiments/{name}/graph", GetExperimentGraph).Methods("GET", "OPTIONS")
 	api.HandleFunc("/experiments/{name}/trigger", TriggerExperimentApps).Methods("POST", "OPTIONS")
 	api.HandleFunc("/experiments/{name}/trigger", CancelTriggeredExperimentApps).
 		Methods("DELETE", "OPTIONS")
 	api.HandleFunc("/experiments/{name}/schedule", GetExperimentSchedule).Methods("GET", "OPTIONS")
 	api.HandleFunc("/experiments/{name}/schedule", ScheduleExperiment).Methods("POST", "OPTIONS")
+	api.HandleFunc("/experiments/{name}/schedule/preview", PreviewExperimentSchedule).Methods("GET", "OPTIONS")
 	api.HandleFunc("/experiments/{name}/captures", GetExperimentCaptures).Methods("GET", "OPTIONS")
+	api.Handle("/experiments/{name}/checkpoints", weberror.ErrorHandler(GetExperimentCheckpoints)).
+		Methods("GET", "OPTIONS")
+	api.Handle("/experiments/{name}/checkpoints", weberror.ErrorHandler(CreateExperimentCheckpoint)).
+		Methods("POST", "OPTIONS")
+	api.Handle("/experiments/{name}/checkpoints/{checkpoint}", weberror.ErrorHandler(DeleteExperimentCheckpoint)).
+		Methods("DELETE", "OPTIONS")
+	api.Handle("/experiments/{name}/checkpoints/{checkpoint}/restore", weberror.ErrorHandler(RestoreExperimentCheckpoint)).
+		Methods("POST", "OPTIONS")
 	api.HandleFunc("/experiments/{exp}/captureSubnet", StartCaptureSubnet).
 		Methods("POST", "OPTIONS")
 	api.HandleFunc("/experiments/{exp}/stopCaptureSubnet", StopCaptureSubnet).
@@ -280,6 +306,7 @@ func Start(opts ...ServerOption) error {
 	api.HandleFunc("/experiments/{exp}/vms/{name}/vnc", GetVNC).Methods("GET", "OPTIONS")
 	api.HandleFunc("/experiments/{exp}/vms/{name}/vnc/ws", GetVNCWebSocket).
 		Methods("GET", "OPTIONS")
+	api.HandleFunc("/experiments/{exp}/vms/{name}/exec", ExecVM).Methods("POST", "OPTIONS")
 	api.HandleFunc("/experiments/{exp}/vms/{name}/captures", GetVMCaptures).
 		Methods("GET", "OPTIONS")
 	api.HandleFunc("/experiments/{exp}/vms/{name}/captures", StartVMCapture).
@@ -353,6 +380,7 @@ func Start(opts ...ServerOption) error {
 	api.HandleFunc("/users/{username}", UpdateUser).Methods("PATCH", "OPTIONS")
 	api.HandleFunc("/users/{username}", DeleteUser).Methods("DELETE", "OPTIONS")
 	api.HandleFunc("/users/{username}/tokens", CreateUserToken).Methods("POST", "OPTIONS")
+	api.HandleFunc("/users/{username}/usage", GetUserUsage).Methods("GET", "OPTIONS")
 	api.HandleFunc("/roles", GetRoles).Methods("GET", "OPTIONS")
 	api.HandleFunc("/signup", Signup).Methods("POST", "OPTIONS")
 	api.HandleFunc("/login", Login).Methods("GET", "POST", "OPTIONS")
@@ -401,6 +429,8 @@ func Start(opts ...ServerOption) error {
 		api.Use(middleware.LogRequests)
 	}
 
+	api.Use(leaseActivity)
+
 	plog.Info(plog.TypeSystem, "starting websockets broker")
 
 	go broker.Start()
@@ -413,6 +443,10 @@ func Start(opts ...ServerOption) error {
 
 	go SyncMinimegaLogs(context.Background(), o.minimegaLogs)
 
+	plog.Info(plog.TypeSystem, "starting experiment lease controller")
+
+	go runLeaseController(context.Background())
+
 	plog.Info(plog.TypeSystem, "using base path", "path", o.basePath)
 	plog.Info(plog.TypeSystem, "using JWT lifetime", "lifetime", o.jwtLifetime)
 
@@ -8,6 +8,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"phenix/api/config"
+	"phenix/api/quota"
 	"phenix/api/settings"
 	"phenix/util/plog"
 	"phenix/web/broker"
@@ -245,6 +246,62 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 	_, _ = w.Write(body)
 }
 
+// GetUserUsage - GET /users/{username}/usage.
+func GetUserUsage(w http.ResponseWriter, r *http.Request) {
+	plog.Debug(plog.TypeSystem, "HTTP handler called", "handler", "GetUserUsage")
+
+	var (
+		ctx      = r.Context()
+		uname, _ = ctx.Value(middleware.ContextKeyUser).(string)
+		role, _  = ctx.Value(middleware.ContextKeyRole).(rbac.Role)
+		vars     = mux.Vars(r)
+		username = vars["username"]
+	)
+
+	// Users can always see their own usage.
+	if username != uname && !role.Allowed("users", "get", username) {
+		plog.Warn(
+			plog.TypeSecurity,
+			"getting user usage not allowed",
+			"requester",
+			uname,
+			"user",
+			username,
+		)
+		http.Error(w, "forbidden", http.StatusForbidden)
+
+		return
+	}
+
+	limits, err := quota.ForUser(username)
+	if err != nil {
+		plog.Error(plog.TypeSystem, "getting user quota", "user", username, "err", err)
+		http.Error(w, "unable to get user quota", http.StatusInternalServerError)
+
+		return
+	}
+
+	usage, err := quota.UsageForUser(username)
+	if err != nil {
+		plog.Error(plog.TypeSystem, "getting user usage", "user", username, "err", err)
+		http.Error(w, "unable to get user usage", http.StatusInternalServerError)
+
+		return
+	}
+
+	resp := map[string]any{"quota": limits, "usage": usage}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		plog.Error(plog.TypeSystem, "marshaling user usage", "user", username, "err", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	_, _ = w.Write(body)
+}
+
 // UpdateUser - PATCH /users/{username}.
 //
 //nolint:funlen // handler
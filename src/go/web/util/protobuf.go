@@ -24,6 +24,8 @@ func ExperimentToProtobuf(
 		Running:   exp.Running(),
 		Status:    string(status),
 		VmCount:   uint32(len(vms)), //nolint:gosec // integer overflow conversion int -> uint32
+
+		ResourceVersion: exp.Metadata.ResourceVersion,
 	}
 
 	pb.Vms = make([]*proto.VM, len(vms))
@@ -115,6 +117,7 @@ func VMToProtobuf(exp string, vm mm.VM, topology ifaces.TopologySpec) *proto.VM
 		Tags:            vm.Tags,
 		CcActive:        vm.CCActive,
 		Snapshot:        vm.Snapshot,
+		VmType:          vm.VMType,
 	}
 
 	if topology == nil {
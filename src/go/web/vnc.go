@@ -53,6 +53,12 @@ func GetVNC(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if vm.IsContainer() {
+		http.Error(w, "VNC is not available for container VMs; use console exec instead", http.StatusBadRequest)
+
+		return
+	}
+
 	// The `token` variable will be an empty string if authentication is disabled,
 	// which is okay and will not cause any issues here.
 	token, _ := ctx.Value(middleware.ContextKeyJWT).(string)
@@ -131,6 +137,12 @@ func GetVNCWebSocket(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	done := make(chan struct{})
+	defer close(done)
+
+	// Keep the experiment from timing out as idle while the VNC session is open.
+	go trackActivity(exp, done)
+
 	websocket.Handler(util.ConnectWSHandler(endpoint)).ServeHTTP(w, r)
 }
 
@@ -170,6 +170,7 @@ func ApplyWorkflow(w http.ResponseWriter, r *http.Request) error {
 			experiment.CreateWithDeployMode(wf.ExperimentDeployMode()),
 			experiment.CreateWithDefaultBridge(wf.DefaultBridgeName()),
 			experiment.CreateWithGREMesh(wf.UseGREMesh),
+			experiment.CreateWithOwner(middleware.UserFromContext(ctx)),
 		}
 
 		err = experiment.Create(ctx, opts...)
@@ -194,6 +195,22 @@ func ApplyWorkflow(w http.ResponseWriter, r *http.Request) error {
 			return nil
 		}
 
+		// Check the version the client expects before the experiment is stopped,
+		// since stopping it updates the experiment in the store.
+		expected, err := ifMatchVersion(r)
+		if err != nil {
+			return err
+		}
+
+		if expected != 0 && expected != exp.Metadata.ResourceVersion {
+			err := weberror.NewWebError(
+				&store.ConflictError{Kind: "Experiment", Name: expName, Expected: expected, Actual: exp.Metadata.ResourceVersion},
+				"experiment %s was modified by someone else", expName,
+			)
+
+			return err.SetStatus(http.StatusConflict)
+		}
+
 		if exp.Running() {
 			if !wf.AutoRestart() {
 				return nil
@@ -256,15 +273,33 @@ func ApplyWorkflow(w http.ResponseWriter, r *http.Request) error {
 			return err.SetStatus(http.StatusInternalServerError)
 		}
 
-		topoSpec, err := types.DecodeTopologyFromConfig(*topo)
+		// Render the topology with the parameters the experiment was created with,
+		// unless the workflow switches the experiment to a different topology.
+		var params map[string]string
+
+		if topoName == annotations["topology"] {
+			if params, err = exp.TopologyParameters(); err != nil {
+				err := weberror.NewWebError(err, "unable to update experiment %s", expName)
+
+				return err.SetStatus(http.StatusInternalServerError)
+			}
+		}
+
+		topoSpec, err := types.DecodeTopologyFromConfigWithParameters(*topo, params)
 		if err != nil {
+			status := http.StatusInternalServerError
+
+			if errors.Is(err, types.ErrInvalidTopologyParameter) {
+				status = http.StatusBadRequest
+			}
+
 			err := weberror.NewWebError(
 				err,
 				"unable to update experiment with topology %s",
 				topoName,
 			)
 
-			return err.SetStatus(http.StatusInternalServerError)
+			return err.SetStatus(status)
 		}
 
 		exp.Spec.SetTopology(topoSpec)
@@ -379,6 +414,12 @@ func ApplyWorkflow(w http.ResponseWriter, r *http.Request) error {
 		exp.Spec.SetUseGREMesh(wf.UseGREMesh)
 
 		if err := exp.WriteToStore(false); err != nil {
+			if errors.Is(err, store.ErrConflict) {
+				err := weberror.NewWebError(err, "experiment %s was modified by someone else", expName)
+
+				return err.SetStatus(http.StatusConflict)
+			}
+
 			err := weberror.NewWebError(err, "unable to write updated experiment %s", expName)
 
 			return err.SetStatus(http.StatusInternalServerError)
@@ -498,12 +539,29 @@ func WorkflowUpsertConfig(w http.ResponseWriter, r *http.Request) error {
 			return err.SetStatus(http.StatusForbidden)
 		}
 
-		err := config.Update(name, cfg)
+		expected, err := ifMatchVersion(r)
+		if err != nil {
+			return err
+		}
+
+		if expected != 0 {
+			cfg.Metadata.ResourceVersion = expected
+		}
+
+		user, _ := ctx.Value(middleware.ContextKeyUser).(string)
+
+		err = config.Update(name, cfg, config.UpdateWithAuthor(user))
 		if err != nil {
 			if errors.Is(err, store.ErrNotExist) {
 				return weberror.NewWebError(err, "config to update (%s) does not exist", name)
 			}
 
+			if errors.Is(err, store.ErrConflict) {
+				err := weberror.NewWebError(err, "config %s was modified by someone else", name)
+
+				return err.SetStatus(http.StatusConflict)
+			}
+
 			if errors.Is(err, types.ErrValidationFailed) {
 				cause := errors.Unwrap(err)
 				lines := strings.Split(cause.Error(), "\n")
@@ -534,9 +592,11 @@ func WorkflowUpsertConfig(w http.ResponseWriter, r *http.Request) error {
 		}
 
 		var (
-			opts = []config.CreateOption{
+			user, _ = ctx.Value(middleware.ContextKeyUser).(string)
+			opts    = []config.CreateOption{
 				config.CreateFromConfig(cfg),
 				config.CreateWithValidation(),
+				config.CreateWithAuthor(user),
 			}
 			err error
 		)